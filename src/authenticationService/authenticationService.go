@@ -3,70 +3,210 @@ package main
 import (
 	// Native packages
 	"context"
+	"errors"
 	"fmt"
-	"log"
 	"net"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	// Required packages
-	"github.com/go-yaml/yaml"
 	authentication "github.com/nicholasbunn/mastersSandbox/src/authenticationStuff"
+	"github.com/nicholasbunn/mastersSandbox/src/configuration"
 
 	// gRPC packages
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 
 	// Proto packages
 	serverPB "github.com/nicholasbunn/mastersSandbox/src/authenticationService/proto"
+
+	// Interceptors
+	"github.com/nicholasbunn/mastersSandbox/src/interceptors"
+
+	// Logging
+	"github.com/nicholasbunn/mastersSandbox/src/logging"
 )
 
+// metricsJob is the job that this service's metrics are pushed to the pushgateway under
+const metricsJob = "AuthenticationService"
+
 var (
 	// Addresses
 	addrMyself string
 
+	// TLS stuff, the service verifies its callers (the desktop gateway)
+	serverTLS                 authentication.TLSFiles
+	certificateReloadInterval time.Duration // The interval at which the certificate files are checked for changes
+	certificateExpiryWarning  time.Duration // How long before a certificate expires to start logging warnings
+	serverCertificates        *authentication.CertificateManager
+
 	// JWT stuff, load this in from config
-	secretKey     string
-	tokenDuration time.Duration
-
-	// Logging stuff
-	DebugLogger   *log.Logger
-	InfoLogger    *log.Logger
-	WarningLogger *log.Logger
-	ErrorLogger   *log.Logger
+	jwtSecret             *authentication.Secret // The JWT signing secret, resolved from the reference in the config (see authentication.Secret)
+	secretReloadInterval  time.Duration          // The interval at which a secret held in a file is checked for rotation
+	tokenDuration         time.Duration
+	audience              string        // The name of this service, as used in exchanged tokens
+	exchangeTokenDuration time.Duration // How long tokens issued by a token exchange are valid for
+
+	// Second factor (TOTP) stuff
+	mfaIssuer            string        // The name authenticator apps show next to the user's codes
+	partialTokenDuration time.Duration // How long a user has to complete a login that requires a second factor
+
+	// Authorisation policy, used to derive the scopes granted to a user's roles
+	policyFile           string
+	policyReloadInterval time.Duration
+	policyManager        *authentication.PolicyManager
+
+	// User store, identity providers and login throttling
+	userStoreFile           string
+	userStore               authentication.UserStore
+	identityProviderConfigs []authentication.IdentityProviderConfig
+	identityProviders       *authentication.IdentityProviders
+	identityProvidersMutex  sync.RWMutex // The identity providers are replaced when the configuration is reloaded
+	loginLimiter            *authentication.LoginLimiter
+	trustForwardedAddress   bool // Whether to use the client address forwarded by the gateway, instead of the gateway's own address
+	loginMetrics            *interceptors.LoginMetricStruct
+
+	// API keys for scripts and scheduled jobs
+	apiKeyStoreFile string
+	apiKeyStore     authentication.APIKeyStore
+
+	// Sessions, one per login, so that users' tokens can be refused before they expire
+	sessionStoreFile string
+	sessionStore     authentication.SessionStore
+
+	// Audit log, shared with the other services
+	auditDirectory  string        // The directory (shared by the services) that audit files are written to
+	auditRetention  time.Duration // How long audit files are kept for
+	auditMaxResults int           // The most entries a single query returns
+	auditLog        *authentication.AuditLog
+
+	// Metrics, served for Prometheus to scrape and (optionally) pushed to the pushgateway
+	addrMetrics             string
+	addrPushgateway         string
+	metricsPushEnabled      bool
+	metricsPushInterval     time.Duration // The interval at which changed metrics are pushed
+	metricsMaxBackoff       time.Duration // The longest interval between pushes while the pushgateway is unreachable
+	metricExporter          *interceptors.MetricExporter
+	serverMetricInterceptor *interceptors.ServerMetricStruct
+
+	tracingConfig interceptors.TracingConfig // Where the authentication service's spans are exported to
+
+	loggingConfig logging.Config // How the authentication service's log lines are written, the logger is set up with it in main
+
+	// Health checks, the authentication service is ready while its certificates and configuration are usable
+	healthInterval time.Duration // The interval at which the health checks are run
+	healthTimeout  time.Duration // How long a health check may take before it fails
+
+	drainTimeout time.Duration // How long the calls in flight are given to finish when the service is stopped
+
+	// Configuration, reloaded while the service is running (see applyConfig)
+	configLoader         *configuration.Loader
+	loadedConfig         *Config
+	configReloadInterval time.Duration // The interval at which the configuration file is checked for changes
 )
 
 func init() {
-	/* The init functin is used to load in configuration variables, and set up the logger and metric interceptors whenever the service is started
+	/* The init functin is used to load in configuration variables, and set up the metric interceptors whenever the service is started
 	 */
 
 	// ________CONFIGURATION________
-	// Load YAML configurations into config struct
-	config, _ := DecodeConfig("src/authenticationService/configuration.yaml")
+	/* Load the configuration (the defaults, then the file, then AUTHENTICATIONSERVICE_* environment
+	variables) into the config struct, refusing to start if anything is missing or insecure */
+	configLoader = configuration.NewLoader("authentication service", "src/authenticationService/configuration.yaml", "AUTHENTICATIONSERVICE")
+	configCheck := authentication.NewConfigCheck("authentication service", configLoader.Path)
+	config := &Config{}
+	if err := configLoader.Load(config); err != nil {
+		configCheck.Problem(configLoader.Path, "could not be loaded, services have to be started from the repository root: %v", err)
+		configCheck.Enforce()
+	}
+	if configLoader.PrintRequested {
+		if err := configLoader.Print(os.Stdout, config); err != nil {
+			logging.Logger.Fatalf("Failed to print the configuration: \n%v", err)
+		}
+		os.Exit(0)
+	}
+	validateConfig(config, configCheck)
+	jwtSecret = configCheck.CheckSecretReference("server.authentication.jwt.secretKey", config.Server.Authentication.Jwt.SecretKey)
+	configCheck.Enforce()
+	loadedConfig = config
+	configReloadInterval = time.Duration(config.Server.Configuration.ReloadInterval) * time.Second
+
+	// Load addresses from config
+	addrMyself = config.Server.Host + ":" + config.Server.Port.Myself
 
-	// Load port addresses from config
-	addrMyself = os.Getenv("AUTHENTICATIONHOST") + ":" + config.Server.Port.Myself
+	// Load TLS parameters from config
+	serverTLS = config.Server.TLS
+	certificateReloadInterval = time.Duration(config.Server.Certificates.ReloadInterval) * time.Second
+	certificateExpiryWarning = time.Duration(config.Server.Certificates.ExpiryWarning) * 24 * time.Hour
 
 	// Load JWT parameters from config
-	secretKey = config.Server.Authentication.Jwt.SecretKey
+	secretReloadInterval = time.Duration(config.Server.Authentication.Jwt.ReloadInterval) * time.Second
 	tokenDuration = time.Duration(config.Server.Authentication.Jwt.TokenDuration) * (time.Minute)
+	audience = config.Server.Authentication.Jwt.Audience
+	exchangeTokenDuration = time.Duration(config.Server.Authentication.Exchange.TokenDuration) * time.Second
 
-	// If the file doesn't exist, create it, otherwise append to the file
-	pathSlice := strings.Split(os.Args[0], "/") // This just extracts the services name (filename)
-	file, err := os.OpenFile("program logs/"+pathSlice[len(pathSlice)-1]+".log", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0666)
-	if err != nil {
-		// If opening the log file throws an error, continue to create the loggers but print to terminal instead
-		log.Println("Unable to initialise log file, good luck :)")
-	} else {
-		log.SetOutput(file)
+	// Load second factor parameters from config
+	mfaIssuer = config.Server.Authentication.MFA.Issuer
+	partialTokenDuration = time.Duration(config.Server.Authentication.MFA.PartialTokenDuration) * time.Second
+
+	// Load authorisation policy parameters from config
+	policyFile = config.Server.Authentication.Policy.File
+	policyReloadInterval = time.Duration(config.Server.Authentication.Policy.ReloadInterval) * time.Second
+
+	// Load user store and login throttling parameters from config
+	userStoreFile = config.Server.Users.File
+	identityProviderConfigs = config.Server.Identity.Providers
+	loginLimiter = &authentication.LoginLimiter{
+		MaxFailures:     config.Server.Login.MaxFailures,
+		Backoff:         time.Duration(config.Server.Login.Backoff) * time.Second,
+		MaxBackoff:      time.Duration(config.Server.Login.MaxBackoff) * time.Second,
+		LockoutDuration: time.Duration(config.Server.Login.LockoutDuration) * time.Minute,
 	}
+	trustForwardedAddress = config.Server.Login.TrustForwardedAddress
+
+	// Load API key parameters from config
+	apiKeyStoreFile = config.Server.APIKeys.File
+
+	// Load session parameters from config
+	sessionStoreFile = config.Server.Sessions.File
+
+	// Load audit log parameters from config
+	auditDirectory = config.Server.Audit.Directory
+	auditRetention = time.Duration(config.Server.Audit.Retention) * 24 * time.Hour
+	auditMaxResults = config.Server.Audit.MaxResults
+
+	// Load metric parameters from config
+	addrMetrics = config.Server.Host + ":" + config.Server.Metrics.Port
+	addrPushgateway = config.Server.Metrics.Push.Host + ":" + config.Server.Metrics.Push.Port
+	metricsPushEnabled = config.Server.Metrics.Push.Enabled
+	metricsPushInterval = time.Duration(config.Server.Metrics.Push.Interval) * time.Second
+	metricsMaxBackoff = time.Duration(config.Server.Metrics.Push.MaxBackoff) * time.Second
+
+	// Load tracing parameters from config
+	tracingConfig = config.Server.Tracing
 
-	DebugLogger = log.New(file, "DEBUG: ", log.Ldate|log.Ltime|log.Lmicroseconds|log.Lshortfile)
-	InfoLogger = log.New(file, "INFO: ", log.Ldate|log.Ltime|log.Lmicroseconds|log.Lshortfile)
-	WarningLogger = log.New(file, "WARNING: ", log.Ldate|log.Ltime|log.Lmicroseconds|log.Lshortfile)
-	ErrorLogger = log.New(file, "ERROR: ", log.Ldate|log.Ltime|log.Lmicroseconds|log.Lshortfile)
+	// Load logging parameters from config
+	loggingConfig = config.Server.Logging
+
+	// Load health check parameters from config
+	healthInterval = time.Duration(config.Server.Health.Interval) * time.Second
+	healthTimeout = time.Duration(config.Server.Health.Timeout) * time.Second
+
+	// Load shutdown parameters from config
+	drainTimeout = time.Duration(config.Server.Shutdown.DrainTimeout) * time.Second
+
+	// Metric interceptors, registered on the service's own metrics registry
+	metricExporter = interceptors.NewMetricExporter(metricsJob)
+	metricExporter.Registerer.MustRegister(authentication.CertificateMetrics())
+	serverMetricInterceptor = interceptors.NewServerMetrics(metricExporter) // Custom metric (Prometheus) interceptor
+	loginMetrics = interceptors.NewLoginMetrics(metricExporter)             // Custom login (Prometheus) metrics
 }
 
 func main() {
@@ -74,52 +214,236 @@ func main() {
 	encrypts the server connection with TLS, and registers the services on
 	offer */
 
-	InfoLogger.Println("Stated authentication service")
+	// Set up the logger first, anything logged before this (while loading the configuration) went to stderr
+	stopLogging, err := logging.Setup(metricsJob, loggingConfig)
+	if err != nil {
+		logging.Logger.Fatalf("Failed to set up logging: \n%v", err)
+	}
+	defer stopLogging()
+	logging.Logger.Infoln("Stated authentication service")
+
+	// Load the authorisation policy and watch it for changes
+	policyManager, err = authentication.NewPolicyManager(policyFile)
+	if err != nil {
+		logging.Logger.Fatalf("Failed to load authorisation policy: \n%v", err)
+	}
+	policyManager.Watch(policyReloadInterval)
+	logging.Logger.Debugln("Succesfully loaded authorisation policy")
+
+	// Watch the JWT secret so that a rotated secret is picked up without restarting
+	jwtSecret.Watch(secretReloadInterval)
+	logging.Logger.Infoln("Using JWT secret ", jwtSecret)
+
+	// Open the user store, creating the default users if it is empty
+	store, err := authentication.NewFileUserStore(userStoreFile)
+	if err != nil {
+		logging.Logger.Fatalf("Failed to open user store: \n%v", err)
+	}
+	if err := seedUsers(store); err != nil {
+		logging.Logger.Fatalf("Failed to create default users: \n%v", err)
+	}
+	userStore = store
+	logging.Logger.Debugln("Succesfully opened user store")
+
+	// Set up the identity providers that check users' passwords
+	identityProviders, err = authentication.NewIdentityProviders(identityProviderConfigs, userStore)
+	if err != nil {
+		logging.Logger.Fatalf("Failed to set up identity providers: \n%v", err)
+	}
+	logging.Logger.Debugln("Succesfully set up identity providers")
+
+	// Reload the configuration on SIGHUP or when its file changes, applying the settings that can be changed while running (such as the identity providers set up above)
+	stopWatchingConfig := configLoader.Watch(loadedConfig, configReloadInterval, checkReloadedConfig, applyConfig)
+	defer stopWatchingConfig()
+
+	// Open the API key store
+	apiKeyStore, err = authentication.NewFileAPIKeyStore(apiKeyStoreFile)
+	if err != nil {
+		logging.Logger.Fatalf("Failed to open API key store: \n%v", err)
+	}
+	logging.Logger.Debugln("Succesfully opened API key store")
+
+	// Open the session store
+	sessionStore, err = authentication.NewFileSessionStore(sessionStoreFile)
+	if err != nil {
+		logging.Logger.Fatalf("Failed to open session store: \n%v", err)
+	}
+	logging.Logger.Debugln("Succesfully opened session store")
+
+	// Open the audit log, authorisation decisions and account activity are recorded in it
+	auditLog, err = authentication.NewAuditLog(auditDirectory, audience, auditRetention)
+	if err != nil {
+		logging.Logger.Fatalf("Failed to open audit log: \n%v", err)
+	}
+	defer auditLog.Close()
+	logging.Logger.Debugln("Succesfully opened audit log")
+
+	// Serve the metrics for Prometheus to scrape, and push them to the pushgateway in the background if enabled
+	stopServingMetrics, err := metricExporter.Serve(addrMetrics)
+	if err != nil {
+		logging.Logger.Fatalf("Failed to serve metrics on %v: \n%v", addrMetrics, err)
+	}
+	defer stopServingMetrics()
+	if metricsPushEnabled {
+		stopPushingMetrics := metricExporter.StartPushing(addrPushgateway, metricsPushInterval, metricsMaxBackoff)
+		defer stopPushingMetrics()
+	}
+
+	// Export the spans of the calls served, as part of the traces of the requests that made them
+	stopTracing, err := interceptors.StartTracing(metricsJob, tracingConfig)
+	if err != nil {
+		logging.Logger.Fatalf("Failed to start tracing: \n%v", err)
+	}
+	defer stopTracing()
 
 	// Load in TLS credentials
-	// creds, err := loadTLSCredentials()
-	// if err != nil {
-	// 	ErrorLogger.Printf("Error loading TLS credentials")
-	// } else {
-	// 	DebugLogger.Println("Succesfully loaded TLS certificates")
-	// }
+	creds, err := loadTLSCredentials()
+	if err != nil {
+		logging.Logger.Fatalf("Failed to load TLS credentials: \n%v", err)
+	} else {
+		logging.Logger.Debugln("Succesfully loaded TLS certificates")
+	}
 
 	// Create a listener on the specified tcp port
 	listener, err := net.Listen("tcp", addrMyself)
 	if err != nil {
-		ErrorLogger.Fatalf("Failed to listen on port %v: \n%v", addrMyself, err)
+		logging.Logger.Fatalf("Failed to listen on port %v: \n%v", addrMyself, err)
 	}
-	InfoLogger.Println("Listening on port: ", addrMyself)
+	logging.Logger.Infoln("Listening on port: ", addrMyself)
+
+	// Create the interceptors required for this connection
+	authInterceptor := interceptors.ServerAuthStruct{ // Custom auth (JWT) interceptor
+		JwtManager: authentication.NewJWTManager(jwtSecret, tokenDuration),
+		Policy:     policyManager,
+		APIKeys:    &apiKeyVerifier{},
+		Sessions:   &sessionVerifier{},
+		Audience:   audience,
+		Audit:      auditLog,
+	}
+	// Create interceptor chains (for unary and streaming calls) with the above interceptors
+	interceptorChain := grpc_middleware.ChainUnaryServer(
+		interceptors.ServerRecoveryInterceptor,
+		interceptors.ServerTracingInterceptor,
+		interceptors.ServerRequestIDInterceptor,
+		interceptors.ServerLoggingInterceptor,
+		serverMetricInterceptor.ServerMetricInterceptor,
+		authInterceptor.ServerAuthInterceptor,
+	)
+	streamInterceptorChain := grpc_middleware.ChainStreamServer(
+		interceptors.ServerRecoveryStreamInterceptor,
+		interceptors.ServerTracingStreamInterceptor,
+		interceptors.ServerRequestIDStreamInterceptor,
+		interceptors.ServerLoggingStreamInterceptor,
+		serverMetricInterceptor.ServerMetricStreamInterceptor,
+		authInterceptor.ServerAuthStreamInterceptor,
+	)
 
 	// Create a gRPC server object
 	authenticationServer := grpc.NewServer(
-	// grpc.Creds(creds), // Add the TLS credentials to this server
-	// grpc.UnaryInterceptor(interceptors.AuthenticationInterceptor), // Add the interceptor chain to this server
+		grpc.Creds(creds),                              // Add the TLS credentials to this server
+		grpc.UnaryInterceptor(interceptorChain),        // Add the interceptor chain to this server
+		grpc.StreamInterceptor(streamInterceptorChain), // And the stream interceptor chain, so that streaming RPCs are covered too
 	)
 
 	// Attach the authentication service offering to the server
 	serverPB.RegisterAuthenticationServiceServer(authenticationServer, &authServer{})
-	DebugLogger.Println("Succesfully registered Authentication Service to the server")
+	logging.Logger.Debugln("Succesfully registered Authentication Service to the server")
+
+	// Attach the health service, and check the service's certificates and configuration in the background
+	healthChecker := newHealthChecker()
+	healthChecker.Register(authenticationServer)
+	stopHealthChecks := healthChecker.Start()
+	defer stopHealthChecks()
+	logging.Logger.Debugln("Succesfully registered the health service to the server")
 
-	// Start the server
-	if err := authenticationServer.Serve(listener); err != nil {
-		ErrorLogger.Fatalf("Failed to expose service: \n%v", err)
+	// Start the server, and drain it when the service is stopped
+	if err := interceptors.ServeUntilStopped(authenticationServer, listener, healthChecker, drainTimeout); err != nil {
+		logging.Logger.Fatalf("Failed to expose service: \n%v", err)
 	}
+	logging.Logger.Infoln("Stopped authentication service")
 }
 
 // ________REQUIRED STRUCTURES________
 
 type Config struct {
+	/* This struct holds the authentication service's configuration. Each field is a key of the
+	configuration file, see the configuration package for the default, validate, secret and
+	reload tags */
 	Server struct {
+		Host string `yaml:"host"`
 		Port struct {
-			Myself string `yaml:"myself"`
+			Myself string `yaml:"myself" validate:"port"`
 		} `yaml:"port"`
+		TLS          authentication.TLSFiles `yaml:"tls"`
+		Certificates struct {
+			ReloadInterval int `yaml:"reloadInterval" default:"60" validate:"positive"`
+			ExpiryWarning  int `yaml:"expiryWarning" default:"30"`
+		} `yaml:"certificates"`
 		Authentication struct {
 			Jwt struct {
-				SecretKey     string `yaml:"secretKey"`
-				TokenDuration int    `yaml:"tokenDuration"`
+				SecretKey      string `yaml:"secretKey" secret:"true"`
+				TokenDuration  int    `yaml:"tokenDuration" default:"15" validate:"positive"`
+				ReloadInterval int    `yaml:"reloadInterval" default:"30" validate:"positive"`
+				Audience       string `yaml:"audience" validate:"required"`
 			} `yaml:"jwt"`
+			Exchange struct {
+				TokenDuration int `yaml:"tokenDuration" default:"120" validate:"positive"`
+			} `yaml:"exchange"`
+			MFA struct {
+				Issuer               string `yaml:"issuer" default:"mastersSandbox" validate:"required"`
+				PartialTokenDuration int    `yaml:"partialTokenDuration" default:"300" validate:"positive"`
+			} `yaml:"mfa"`
+			Policy struct {
+				File           string `yaml:"file" default:"authorisation/policy.yaml"`
+				ReloadInterval int    `yaml:"reloadInterval" default:"30" validate:"positive"`
+			} `yaml:"policy"`
 		} `yaml:"authentication"`
+		Users struct {
+			File string `yaml:"file" validate:"required"`
+		} `yaml:"users"`
+		Identity struct {
+			Providers []authentication.IdentityProviderConfig `yaml:"providers"`
+		} `yaml:"identity" reload:"true"`
+		APIKeys struct {
+			File string `yaml:"file" validate:"required"`
+		} `yaml:"apiKeys"`
+		Sessions struct {
+			File string `yaml:"file" validate:"required"`
+		} `yaml:"sessions"`
+		Login struct {
+			MaxFailures           int  `yaml:"maxFailures" default:"5" validate:"positive"`
+			Backoff               int  `yaml:"backoff" default:"1"`
+			MaxBackoff            int  `yaml:"maxBackoff" default:"60"`
+			LockoutDuration       int  `yaml:"lockoutDuration" default:"15"`
+			TrustForwardedAddress bool `yaml:"trustForwardedAddress"`
+		} `yaml:"login"`
+		Audit struct {
+			Directory  string `yaml:"directory" default:"audit" validate:"required"`
+			Retention  int    `yaml:"retention" default:"90"`
+			MaxResults int    `yaml:"maxResults" default:"500"`
+		} `yaml:"audit"`
+		Metrics struct {
+			Port string `yaml:"port" validate:"port"`
+			Push struct {
+				Enabled    bool   `yaml:"enabled"`
+				Host       string `yaml:"host" default:"localhost"`
+				Port       string `yaml:"port" default:"9091"`
+				Interval   int    `yaml:"interval" default:"15"`
+				MaxBackoff int    `yaml:"maxBackoff" default:"120"`
+			} `yaml:"push"`
+		} `yaml:"metrics"`
+		Tracing interceptors.TracingConfig `yaml:"tracing"`
+		Logging logging.Config             `yaml:"logging"`
+		Health  struct {
+			Interval int `yaml:"interval" default:"10" validate:"positive"`
+			Timeout  int `yaml:"timeout" default:"3" validate:"positive"`
+		} `yaml:"health"`
+		Shutdown struct {
+			DrainTimeout int `yaml:"drainTimeout" default:"30" validate:"positive"`
+		} `yaml:"shutdown"`
+		Configuration struct {
+			ReloadInterval int `yaml:"reloadInterval" default:"30" validate:"positive"`
+		} `yaml:"configuration"`
 	} `yaml:"server"`
 }
 
@@ -129,92 +453,1244 @@ type authServer struct {
 	serverPB.UnimplementedAuthenticationServiceServer
 }
 
+type apiKeyVerifier struct {
+	// Use this to check API keys presented to this service against the key store
+}
+
+type sessionVerifier struct {
+	// Use this to check the sessions of tokens presented to this service against the session store
+}
+
 // ________IMPLEMENT THE OFFERED SERVICES________
 
+// errProviderMismatch is returned by userRecord when an identity provider authenticates a user that belongs to another provider
+var errProviderMismatch = errors.New("user belongs to another identity provider")
+
+// sessionsManageScope lets callers list and terminate other users' sessions
+const sessionsManageScope = "sessions:manage"
+
 func (s *authServer) LoginAuth(ctx context.Context, request *serverPB.LoginAuthRequest) (*serverPB.LoginAuthResponse, error) {
-	/* This service logs the user in by checking the provided details against a user
-	database. If the user exists, a JWT is generated and returned to them. */
+	/* This service logs the user in by checking the provided details against the identity
+	providers (the user database, a directory or an htpasswd file). If the user exists, a JWT
+	is generated and returned to them. */
+
+	logging.FromContext(ctx).Infoln("Received LoginAuth service call")
+	now := time.Now()
+	username := request.GetUsername()
+	addressKey := "address:" + clientAddress(ctx)
+	usernameKey := "username:" + username // Only used for usernames that don't exist, known users keep their attempts in the store
 
-	InfoLogger.Println("Received LoginAuth service call")
-	// Find the user with the provided username, return a NotFound error if they don't exist
-	user, err := find(request.GetUsername())
+	// Find the user with the provided username, a nil user means they don't exist
+	user, err := userStore.Find(username)
 	if err != nil {
-		return nil, status.Errorf(codes.NotFound, "cannot find user: %v", err)
+		logging.FromContext(ctx).Errorln("Failed to look up user: ", err)
+		return nil, status.Errorf(codes.Internal, "could not look up user")
 	}
 
-	// Check if a user with the provided username and password combination exists, return a NotFound error if they don't
-	if user == nil {
-		return nil, status.Errorf(codes.NotFound, "the username you provided doesn't exist")
-	} else if !user.CheckPassword(request.GetPassword()) {
-		return nil, status.Errorf(codes.NotFound, "the password you provided is incorrect")
+	/* Reject the attempt outright if the address or username is backing off or locked
+	out. This happens before the password is checked, so a locked account can't be brute
+	forced, and unknown usernames are throttled exactly like known ones */
+	retryAfter := loginLimiter.TrackedRetryAfter(addressKey, now)
+	if user != nil {
+		retryAfter = maxDuration(retryAfter, loginLimiter.RetryAfter(&user.LoginAttempts, now))
+	} else {
+		retryAfter = maxDuration(retryAfter, loginLimiter.TrackedRetryAfter(usernameKey, now))
+	}
+	if retryAfter > 0 {
+		logging.FromContext(ctx).Warnf("Throttled login attempt for %q from %v", username, addressKey)
+		loginMetrics.RecordLoginFailure("throttled")
+		recordAudit(ctx, authentication.AuditLogin, username, authentication.AuditDeny, authentication.ReasonLoginThrottled)
+		return nil, authentication.LoginThrottledError(retryAfter)
 	}
 
-	// Create a jwtManager object for the user
-	jwtManager := authentication.JWTManager{
-		SecretKey:     secretKey,
-		TokenDuration: tokenDuration,
+	/* Check the username and password combination. Unknown usernames and wrong passwords
+	return the same error, so that callers can't use LoginAuth to find out which usernames
+	exist. If a provider that might know the user can't be reached, the attempt isn't counted */
+	identity, err := currentIdentityProviders().Authenticate(username, request.GetPassword())
+	if err == authentication.ErrIdentityNotFound || err == authentication.ErrInvalidCredentials {
+		logging.FromContext(ctx).Debugln("Failed login attempt")
+		recordLoginFailure(user != nil, username, addressKey, usernameKey, now)
+		recordAudit(ctx, authentication.AuditLogin, username, authentication.AuditDeny, authentication.ReasonLoginFailed)
+		return nil, authentication.LoginFailedError()
+	} else if err != nil {
+		logging.FromContext(ctx).Errorln("Failed to check credentials: ", err)
+		return nil, status.Errorf(codes.Unavailable, "could not check credentials, try again later")
+	}
+
+	// Users of other providers are kept in the user store too, so that their lockouts and second factor are tracked
+	user, err = userRecord(user, identity)
+	if err == errProviderMismatch {
+		logging.FromContext(ctx).Warnf("Refused login for %q through %q, the user belongs to another identity provider", username, identity.Provider)
+		recordLoginFailure(true, username, addressKey, usernameKey, now)
+		recordAudit(ctx, authentication.AuditLogin, username, authentication.AuditDeny, "provider mismatch: "+identity.Provider)
+		return nil, authentication.LoginFailedError()
+	} else if err != nil {
+		logging.FromContext(ctx).Errorln("Failed to save user record: ", err)
+		return nil, status.Errorf(codes.Internal, "could not save user")
+	}
+
+	/* Users who have enrolled a second factor, or whose roles require one, have to complete
+	the login with VerifyTOTP. Their failed attempts are only cleared once they have, so that
+	logging in again doesn't reset the attempts left for guessing a code */
+	if user.TOTP.Enabled || policyManager.RequiresMFA(user.Roles) {
+		logging.FromContext(ctx).Debugf("Login for %q requires a second factor", username)
+		recordAudit(ctx, authentication.AuditLogin, username, authentication.AuditAllow, "second factor required, provider "+identity.Provider)
+		return mfaChallenge(user)
+	}
+
+	// A successful login clears the user's failed attempts
+	if user.LoginAttempts.Failures > 0 {
+		err = userStore.Update(username, func(user *authentication.User) error {
+			user.LoginAttempts = authentication.LoginAttempts{}
+			return nil
+		})
+		if err != nil {
+			logging.FromContext(ctx).Errorln("Failed to reset failed login attempts: ", err)
+		}
 	}
 
-	// Generate and return a JWT for the user
-	token, err := jwtManager.GenerateManager(user)
+	// Generate and return a JWT for the user, carrying the scopes their roles are granted by the policy
+	token, scopes, err := generateAccessToken(ctx, user)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "could not generate access token")
 	}
+	recordAudit(ctx, authentication.AuditLogin, username, authentication.AuditAllow, "provider "+identity.Provider)
+	recordTokenIssued(ctx, user, "access token")
 
 	// Create and populate the response message for the request being served
 	response := &serverPB.LoginAuthResponse{
-		Permissions: user.Role,
+		Permissions: strings.Join(user.Roles, ","),
 		AccessToken: token,
+		Roles:       user.Roles,
+		Scopes:      scopes,
 	}
 
 	return response, nil
 }
 
-// ________SUPPORTING FUNCTIONS________
+func (s *authServer) VerifyTOTP(ctx context.Context, request *serverPB.VerifyTOTPRequest) (*serverPB.VerifyTOTPResponse, error) {
+	/* This service completes a login that requires a second factor. It checks the partial
+	token returned by LoginAuth along with the code from the user's authenticator app (or one
+	of their recovery codes), and returns a JWT. If the login is enrolling the user, the code
+	confirms the enrolment and the user's recovery codes are returned. Failed codes count
+	towards the same lockout as failed passwords */
 
-func DecodeConfig(configPath string) (*Config, error) {
-	// Create a new config structure
-	config := &Config{}
+	logging.FromContext(ctx).Infoln("Received VerifyTOTP service call")
+	now := time.Now()
+	addressKey := "address:" + clientAddress(ctx)
+
+	// Check the partial token, which identifies the user whose password has already been checked
+	claims, err := authentication.NewJWTManager(jwtSecret, tokenDuration).VerifyJWT(request.GetPartialToken())
+	if err != nil {
+		return nil, authentication.TokenError(err)
+	}
+	if claims.Audience != authentication.MFAAudience {
+		return nil, authentication.UnauthenticatedError(authentication.ReasonTokenInvalid, "partial token is invalid")
+	}
+	username := claims.Username
+
+	user, err := userStore.Find(username)
+	if err != nil {
+		logging.FromContext(ctx).Errorln("Failed to look up user: ", err)
+		return nil, status.Errorf(codes.Internal, "could not look up user")
+	}
+	if user == nil {
+		return nil, authentication.UnauthenticatedError(authentication.ReasonTokenInvalid, "partial token is invalid")
+	}
+
+	// Reject the attempt outright if the address or user is backing off or locked out
+	retryAfter := maxDuration(loginLimiter.TrackedRetryAfter(addressKey, now), loginLimiter.RetryAfter(&user.LoginAttempts, now))
+	if retryAfter > 0 {
+		logging.FromContext(ctx).Warnf("Throttled second factor attempt for %q from %v", username, addressKey)
+		loginMetrics.RecordLoginFailure("throttled")
+		recordAudit(ctx, authentication.AuditSecondFactor, username, authentication.AuditDeny, authentication.ReasonLoginThrottled)
+		return nil, authentication.LoginThrottledError(retryAfter)
+	}
+
+	// Check the code, a successful check clears the user's failed attempts
+	var recoveryCodes []string
+	err = userStore.Update(username, func(user *authentication.User) error {
+		var err error
+		recoveryCodes, err = checkSecondFactor(user, request.GetCode(), now)
+		if err == nil {
+			user.LoginAttempts = authentication.LoginAttempts{}
+		}
+		return err
+	})
+	if err == authentication.ErrTOTPInvalid {
+		logging.FromContext(ctx).Debugln("Failed second factor attempt")
+		recordLoginFailure(true, username, addressKey, "username:"+username, now)
+		recordAudit(ctx, authentication.AuditSecondFactor, username, authentication.AuditDeny, authentication.ReasonTOTPInvalid)
+		return nil, authentication.TOTPFailedError()
+	} else if err != nil {
+		logging.FromContext(ctx).Errorln("Failed to check second factor: ", err)
+		return nil, status.Errorf(codes.Internal, "could not check verification code")
+	}
+
+	token, scopes, err := generateAccessToken(ctx, user)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "could not generate access token")
+	}
+	reason := "totp"
+	if len(recoveryCodes) > 0 {
+		reason = "totp enrolled"
+	}
+	recordAudit(ctx, authentication.AuditSecondFactor, username, authentication.AuditAllow, reason)
+	recordTokenIssued(ctx, user, "access token")
+
+	return &serverPB.VerifyTOTPResponse{
+		Permissions:   strings.Join(user.Roles, ","),
+		AccessToken:   token,
+		Roles:         user.Roles,
+		Scopes:        scopes,
+		RecoveryCodes: recoveryCodes,
+	}, nil
+}
+
+func (s *authServer) EnrolTOTP(ctx context.Context, request *serverPB.EnrolTOTPRequest) (*serverPB.EnrolTOTPResponse, error) {
+	/* This service starts enrolling the calling user's authenticator app, returning a new
+	secret and its provisioning URI. TOTP is only enabled once ConfirmTOTP has checked a code
+	from the app, enrolling again before that replaces the secret */
+
+	logging.FromContext(ctx).Infoln("Received EnrolTOTP service call")
+
+	caller, ok := authentication.CallerFromContext(ctx)
+	if !ok || caller.Kind != authentication.CallerUser {
+		return nil, authentication.PermissionDeniedError("/authentication.AuthenticationService/EnrolTOTP")
+	}
+
+	secret, err := authentication.GenerateTOTPSecret()
+	if err != nil {
+		logging.FromContext(ctx).Errorln("Failed to generate TOTP secret: ", err)
+		return nil, status.Errorf(codes.Internal, "could not generate totp secret")
+	}
+
+	err = userStore.Update(caller.ID, func(user *authentication.User) error {
+		if user.TOTP.Enabled {
+			return status.Errorf(codes.FailedPrecondition, "totp is already enabled, ask an administrator to reset it")
+		}
+		user.TOTP.PendingSecret = secret
+		return nil
+	})
+	if status.Code(err) == codes.FailedPrecondition {
+		return nil, err
+	} else if err != nil {
+		logging.FromContext(ctx).Errorln("Failed to save TOTP secret: ", err)
+		return nil, status.Errorf(codes.Internal, "could not save totp secret")
+	}
+
+	logging.FromContext(ctx).Infof("Started TOTP enrolment for %q", caller.ID)
+	recordAudit(ctx, authentication.AuditAccount, "", authentication.AuditAllow, "totp enrolment started")
+	return &serverPB.EnrolTOTPResponse{Enrolment: enrolmentMessage(caller.ID, secret)}, nil
+}
+
+func (s *authServer) ConfirmTOTP(ctx context.Context, request *serverPB.ConfirmTOTPRequest) (*serverPB.ConfirmTOTPResponse, error) {
+	/* This service enables TOTP for the calling user once their authenticator app produces
+	a valid code for the secret returned by EnrolTOTP, and returns their recovery codes */
+
+	logging.FromContext(ctx).Infoln("Received ConfirmTOTP service call")
+
+	caller, ok := authentication.CallerFromContext(ctx)
+	if !ok || caller.Kind != authentication.CallerUser {
+		return nil, authentication.PermissionDeniedError("/authentication.AuthenticationService/ConfirmTOTP")
+	}
+
+	var recoveryCodes []string
+	err := userStore.Update(caller.ID, func(user *authentication.User) error {
+		if user.TOTP.Enabled {
+			return status.Errorf(codes.FailedPrecondition, "totp is already enabled")
+		}
+		if user.TOTP.PendingSecret == "" {
+			return status.Errorf(codes.FailedPrecondition, "totp enrolment has not been started")
+		}
+
+		var err error
+		recoveryCodes, err = checkSecondFactor(user, request.GetCode(), time.Now())
+		return err
+	})
+	if err == authentication.ErrTOTPInvalid {
+		recordAudit(ctx, authentication.AuditAccount, "", authentication.AuditDeny, authentication.ReasonTOTPInvalid)
+		return nil, authentication.TOTPFailedError()
+	} else if status.Code(err) == codes.FailedPrecondition {
+		return nil, err
+	} else if err != nil {
+		logging.FromContext(ctx).Errorln("Failed to enable TOTP: ", err)
+		return nil, status.Errorf(codes.Internal, "could not enable totp")
+	}
+	recordAudit(ctx, authentication.AuditAccount, "", authentication.AuditAllow, "totp enabled")
+
+	return &serverPB.ConfirmTOTPResponse{RecoveryCodes: recoveryCodes}, nil
+}
+
+func (s *authServer) UnlockAccount(ctx context.Context, request *serverPB.UnlockAccountRequest) (*serverPB.UnlockAccountResponse, error) {
+	/* This service clears a user's failed logins and any lockout. Access to it is
+	restricted to administrators by the authorisation policy */
+
+	logging.FromContext(ctx).Infoln("Received UnlockAccount service call")
+	username := request.GetUsername()
+
+	// Forget any attempts tracked in memory for the username, whether or not the user exists
+	loginLimiter.TrackedReset("username:" + username)
+
+	err := userStore.Update(username, func(user *authentication.User) error {
+		user.LoginAttempts = authentication.LoginAttempts{}
+		return nil
+	})
+	if err == authentication.ErrUserNotFound {
+		return nil, authentication.NewAuthError(codes.NotFound, authentication.ReasonUserNotFound, authentication.ActionNone, "user does not exist", nil)
+	} else if err != nil {
+		logging.FromContext(ctx).Errorln("Failed to unlock account: ", err)
+		return nil, status.Errorf(codes.Internal, "could not unlock account")
+	}
+
+	logging.FromContext(ctx).Infof("Unlocked account %q", username)
+	recordAudit(ctx, authentication.AuditAccount, username, authentication.AuditAllow, "account unlocked")
+	return &serverPB.UnlockAccountResponse{Username: username}, nil
+}
+
+func (s *authServer) ResetTOTP(ctx context.Context, request *serverPB.ResetTOTPRequest) (*serverPB.ResetTOTPResponse, error) {
+	/* This service removes a user's TOTP enrolment, for users who have lost their device
+	and their recovery codes. If the user's roles require a second factor, they enrol again
+	the next time they log in. Access to it is restricted to administrators by the
+	authorisation policy */
+
+	logging.FromContext(ctx).Infoln("Received ResetTOTP service call")
+	username := request.GetUsername()
+
+	err := userStore.Update(username, func(user *authentication.User) error {
+		user.TOTP = authentication.TOTP{}
+		return nil
+	})
+	if err == authentication.ErrUserNotFound {
+		return nil, authentication.NewAuthError(codes.NotFound, authentication.ReasonUserNotFound, authentication.ActionNone, "user does not exist", nil)
+	} else if err != nil {
+		logging.FromContext(ctx).Errorln("Failed to reset TOTP: ", err)
+		return nil, status.Errorf(codes.Internal, "could not reset totp")
+	}
+
+	resetBy := "unknown"
+	if caller, ok := authentication.CallerFromContext(ctx); ok {
+		resetBy = caller.ID
+	}
+	logging.FromContext(ctx).Warnf("Reset TOTP for %q for %q", username, resetBy)
+	recordAudit(ctx, authentication.AuditRevocation, username, authentication.AuditAllow, "totp reset")
+	return &serverPB.ResetTOTPResponse{Username: username}, nil
+}
+
+func (s *authServer) CreateAPIKey(ctx context.Context, request *serverPB.CreateAPIKeyRequest) (*serverPB.CreateAPIKeyResponse, error) {
+	/* This service issues a new API key granting the requested roles. A caller can't grant
+	a role they don't hold themselves (directly or through inheritance). The full key is only
+	returned here, only its hash is kept */
+
+	logging.FromContext(ctx).Infoln("Received CreateAPIKey service call")
+
+	caller, ok := authentication.CallerFromContext(ctx)
+	if !ok {
+		return nil, authentication.UnauthenticatedError(authentication.ReasonTokenMissing, "authentication token has not been provided")
+	}
+
+	policy := policyManager.Policy()
+	if request.GetName() == "" || len(request.GetRoles()) == 0 || request.GetLifetime() < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "an api key needs a name, at least one role and a non-negative lifetime")
+	}
+	held := map[string]bool{}
+	for _, role := range policy.EffectiveRoles(caller.Roles) {
+		held[role] = true
+	}
+	for _, role := range request.GetRoles() {
+		if _, ok := policy.Roles[role]; !ok {
+			return nil, status.Errorf(codes.InvalidArgument, "unknown role %q", role)
+		}
+		if !held[role] {
+			logging.FromContext(ctx).Warnf("Refused to create an API key with role %q for %q", role, caller.ID)
+			return nil, authentication.PermissionDeniedError("/authentication.AuthenticationService/CreateAPIKey")
+		}
+	}
+
+	key, apiKey, err := authentication.GenerateAPIKey(request.GetName(), request.GetRoles(), caller.ID, time.Duration(request.GetLifetime())*time.Second)
+	if err != nil {
+		logging.FromContext(ctx).Errorln("Failed to generate API key: ", err)
+		return nil, status.Errorf(codes.Internal, "could not generate api key")
+	}
+	if err := apiKeyStore.Save(apiKey); err != nil {
+		logging.FromContext(ctx).Errorln("Failed to save API key: ", err)
+		return nil, status.Errorf(codes.Internal, "could not save api key")
+	}
+
+	logging.FromContext(ctx).Infof("Created API key %v (%q) with roles %v for %q", apiKey.ID, apiKey.Name, apiKey.Roles, caller.ID)
+	recordAudit(ctx, authentication.AuditTokenIssued, "apikey:"+apiKey.ID, authentication.AuditAllow, "api key with roles "+strings.Join(apiKey.Roles, ","))
+	return &serverPB.CreateAPIKeyResponse{Key: apiKeyMessage(apiKey), Secret: key}, nil
+}
+
+func (s *authServer) ListAPIKeys(ctx context.Context, request *serverPB.ListAPIKeysRequest) (*serverPB.ListAPIKeysResponse, error) {
+	// This service lists every API key, including revoked and expired ones, along with their usage
+
+	logging.FromContext(ctx).Infoln("Received ListAPIKeys service call")
+
+	keys, err := apiKeyStore.List()
+	if err != nil {
+		logging.FromContext(ctx).Errorln("Failed to list API keys: ", err)
+		return nil, status.Errorf(codes.Internal, "could not list api keys")
+	}
+
+	response := &serverPB.ListAPIKeysResponse{}
+	for _, apiKey := range keys {
+		response.Keys = append(response.Keys, apiKeyMessage(apiKey))
+	}
+
+	return response, nil
+}
 
-	// Open the config file
-	file, err := os.Open(configPath)
+func (s *authServer) RevokeAPIKey(ctx context.Context, request *serverPB.RevokeAPIKeyRequest) (*serverPB.RevokeAPIKeyResponse, error) {
+	/* This service revokes an API key. Revoked keys are kept in the store, so that their
+	usage can still be audited */
+
+	logging.FromContext(ctx).Infoln("Received RevokeAPIKey service call")
+
+	var revoked *authentication.APIKey
+	err := apiKeyStore.Update(request.GetId(), func(apiKey *authentication.APIKey) error {
+		if apiKey.RevokedAt.IsZero() {
+			apiKey.RevokedAt = time.Now()
+		}
+		revoked = apiKey
+		return nil
+	})
+	if err == authentication.ErrAPIKeyNotFound {
+		return nil, authentication.NewAuthError(codes.NotFound, authentication.ReasonAPIKeyNotFound, authentication.ActionNone, "api key does not exist", nil)
+	} else if err != nil {
+		logging.FromContext(ctx).Errorln("Failed to revoke API key: ", err)
+		return nil, status.Errorf(codes.Internal, "could not revoke api key")
+	}
+
+	revokedBy := "unknown"
+	if caller, ok := authentication.CallerFromContext(ctx); ok {
+		revokedBy = caller.ID
+	}
+	logging.FromContext(ctx).Infof("Revoked API key %v (%q) for %q", revoked.ID, revoked.Name, revokedBy)
+	recordAudit(ctx, authentication.AuditRevocation, "apikey:"+revoked.ID, authentication.AuditAllow, "api key revoked")
+	return &serverPB.RevokeAPIKeyResponse{Key: apiKeyMessage(revoked)}, nil
+}
+
+func (s *authServer) VerifyAPIKey(ctx context.Context, request *serverPB.VerifyAPIKeyRequest) (*serverPB.VerifyAPIKeyResponse, error) {
+	/* This service checks an API key presented to another service, recording its use,
+	and returns the roles and scopes it grants. Access to it is restricted to the other
+	services (by their client certificates) by the authorisation policy */
+
+	logging.FromContext(ctx).Debugln("Received VerifyAPIKey service call")
+
+	apiKey, err := checkAPIKey(ctx, request.GetApiKey(), request.GetMethod())
 	if err != nil {
-		fmt.Println("Could not open config file")
 		return nil, err
 	}
-	defer file.Close()
 
-	// Initialise a new YAML decoder
-	decoder := yaml.NewDecoder(file)
+	return &serverPB.VerifyAPIKeyResponse{
+		Id:     apiKey.ID,
+		Name:   apiKey.Name,
+		Roles:  apiKey.Roles,
+		Scopes: policyManager.Scopes(apiKey.Roles),
+	}, nil
+}
+
+func (s *authServer) ExchangeToken(ctx context.Context, request *serverPB.ExchangeTokenRequest) (*serverPB.ExchangeTokenResponse, error) {
+	/* This service issues a short-lived token that lets the calling service call the
+	requested audience on behalf of the user (or API key) that called it. Access to it is
+	restricted to the other services (by their client certificates) by the authorisation
+	policy, and a service can only exchange tokens that were issued for it (or tokens that
+	aren't restricted to an audience) */
+
+	logging.FromContext(ctx).Debugln("Received ExchangeToken service call")
+
+	caller, ok := authentication.CallerFromContext(ctx)
+	if !ok || caller.Kind != authentication.CallerWorkload {
+		return nil, authentication.PermissionDeniedError("/authentication.AuthenticationService/ExchangeToken")
+	}
+	if request.GetAudience() == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "an audience is required")
+	}
 
-	// Start YAML decoding from file
-	if err := decoder.Decode(&config); err != nil {
-		fmt.Println("Could not decode config file: \n", err)
+	subject, err := exchangeSubject(ctx, request.GetSubjectToken(), request.GetAudience())
+	if err != nil {
 		return nil, err
 	}
+	if !subject.AcceptedBy(caller.ID) {
+		logging.FromContext(ctx).Warnf("Refused to exchange a token issued for %v for %v", subject.Audience, caller.ID)
+		return nil, authentication.UnauthenticatedError(authentication.ReasonTokenInvalid, "access token was issued for another service")
+	}
 
-	return config, nil
+	jwtManager := authentication.NewJWTManager(jwtSecret, tokenDuration)
+	token, err := jwtManager.ExchangeToken(subject, request.GetAudience(), caller.ID, exchangeTokenDuration)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "could not generate access token")
+	}
+	exchanged, err := jwtManager.VerifyJWT(token)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "could not generate access token")
+	}
+
+	logging.FromContext(ctx).Infof("Exchanged token of %q for %v, acting: %v", subject.Username, request.GetAudience(), exchanged.ActorChain())
+	auditEvent := newAuditEvent(ctx, authentication.AuditTokenIssued, authentication.AuditAllow, "exchanged for "+request.GetAudience())
+	auditEvent.User, auditEvent.Kind, auditEvent.Roles, auditEvent.Actor = subject.Username, authentication.CallerUser, subject.Roles, caller.ID
+	if strings.HasPrefix(subject.Username, "apikey:") {
+		auditEvent.Kind = authentication.CallerAPIKey
+	}
+	auditLog.Record(auditEvent)
+	return &serverPB.ExchangeTokenResponse{AccessToken: token, ExpiresAt: exchanged.ExpiresAt}, nil
 }
 
-func save(user *authentication.User) error {
-	// Still need to implement
-	return nil
+func (s *authServer) QueryAuditLog(ctx context.Context, request *serverPB.QueryAuditLogRequest) (*serverPB.QueryAuditLogResponse, error) {
+	/* This service searches the audit log of every service, returning the most recent
+	matching entries first. Access to it is restricted to administrators by the
+	authorisation policy */
+
+	logging.FromContext(ctx).Infoln("Received QueryAuditLog service call")
+
+	query := authentication.AuditQuery{
+		Service:  request.GetService(),
+		Event:    request.GetEvent(),
+		User:     request.GetUser(),
+		Method:   request.GetMethod(),
+		Decision: request.GetDecision(),
+		Limit:    int(request.GetLimit()),
+	}
+	if request.GetSince() > 0 {
+		query.Since = time.Unix(request.GetSince(), 0)
+	}
+	if request.GetUntil() > 0 {
+		query.Until = time.Unix(request.GetUntil(), 0)
+	}
+	if auditMaxResults > 0 && (query.Limit <= 0 || query.Limit > auditMaxResults) {
+		query.Limit = auditMaxResults
+	}
+
+	events, err := auditLog.Query(query)
+	if err != nil {
+		logging.FromContext(ctx).Errorln("Failed to query audit log: ", err)
+		return nil, status.Errorf(codes.Internal, "could not query audit log")
+	}
+
+	response := &serverPB.QueryAuditLogResponse{}
+	for _, event := range events {
+		response.Events = append(response.Events, auditEventMessage(event))
+	}
+
+	return response, nil
 }
 
-func find(username string) (*authentication.User, error) {
-	// Still need to implement
-	if username == "admin" {
-		user, err := authentication.CreateUser("admin", "myPassword", "admin")
+func (s *authServer) ListSessions(ctx context.Context, request *serverPB.ListSessionsRequest) (*serverPB.ListSessionsResponse, error) {
+	/* This service lists the active sessions of the calling user, or of another user for
+	callers holding the "sessions:manage" scope, most recent first */
+
+	logging.FromContext(ctx).Infoln("Received ListSessions service call")
+
+	caller, username, err := sessionOwner(ctx, request.GetUsername(), "/authentication.AuthenticationService/ListSessions")
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := sessionStore.List(username)
+	if err != nil {
+		logging.FromContext(ctx).Errorln("Failed to list sessions: ", err)
+		return nil, status.Errorf(codes.Internal, "could not list sessions")
+	}
+
+	now := time.Now()
+	response := &serverPB.ListSessionsResponse{}
+	for _, session := range sessions {
+		if session.Active(now) {
+			response.Sessions = append(response.Sessions, sessionMessage(session, caller))
+		}
+	}
+
+	return response, nil
+}
+
+func (s *authServer) TerminateSession(ctx context.Context, request *serverPB.TerminateSessionRequest) (*serverPB.TerminateSessionResponse, error) {
+	/* This service terminates one of a user's sessions, or every active session of the
+	user, so that the tokens issued for them are refused before they expire. Users can
+	terminate their own sessions, and callers holding the "sessions:manage" scope anyone's.
+	The other services cache sessions briefly, so they refuse the tokens within that time */
+
+	logging.FromContext(ctx).Infoln("Received TerminateSession service call")
+
+	caller, username, err := sessionOwner(ctx, request.GetUsername(), "/authentication.AuthenticationService/TerminateSession")
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	if request.GetSessionId() != "" {
+		// Other users' sessions are reported as missing, unless the caller can manage them
+		session, err := sessionStore.Find(request.GetSessionId())
+		if err != nil {
+			logging.FromContext(ctx).Errorln("Failed to look up session: ", err)
+			return nil, status.Errorf(codes.Internal, "could not look up session")
+		}
+		if session == nil || (session.Username != username && !canManageSessions(caller)) {
+			return nil, authentication.NewAuthError(codes.NotFound, authentication.ReasonSessionNotFound, authentication.ActionNone, "session does not exist", nil)
+		}
+		ids = append(ids, session.ID)
+	} else {
+		sessions, err := sessionStore.List(username)
 		if err != nil {
-			return nil, status.Errorf(codes.Internal, "could not create user")
+			logging.FromContext(ctx).Errorln("Failed to list sessions: ", err)
+			return nil, status.Errorf(codes.Internal, "could not list sessions")
 		}
+		now := time.Now()
+		for _, session := range sessions {
+			if session.Active(now) {
+				ids = append(ids, session.ID)
+			}
+		}
+	}
+
+	response := &serverPB.TerminateSessionResponse{}
+	for _, id := range ids {
+		var terminated *authentication.Session
+		err := sessionStore.Update(id, func(session *authentication.Session) error {
+			if session.TerminatedAt.IsZero() {
+				session.TerminatedAt, session.TerminatedBy = time.Now(), caller.ID
+			}
+			terminated = session
+			return nil
+		})
+		if err != nil {
+			logging.FromContext(ctx).Errorln("Failed to terminate session: ", err)
+			return nil, status.Errorf(codes.Internal, "could not terminate session")
+		}
+
+		logging.FromContext(ctx).Infof("Terminated session %v of %q for %q", terminated.ID, terminated.Username, caller.ID)
+		recordAudit(ctx, authentication.AuditRevocation, terminated.Username, authentication.AuditAllow, "session "+terminated.ID+" terminated")
+		response.Sessions = append(response.Sessions, sessionMessage(terminated, caller))
+	}
+
+	return response, nil
+}
+
+func (s *authServer) VerifySession(ctx context.Context, request *serverPB.VerifySessionRequest) (*serverPB.VerifySessionResponse, error) {
+	/* This service checks that the session of a token presented to another service is
+	still active, recording its use. Access to it is restricted to the other services (by
+	their client certificates) by the authorisation policy */
+
+	logging.FromContext(ctx).Debugln("Received VerifySession service call")
+
+	session, err := checkSession(request.GetSessionId())
+	if err != nil {
+		return nil, err
+	}
+
+	return &serverPB.VerifySessionResponse{Username: session.Username}, nil
+}
+
+func (verifier *apiKeyVerifier) VerifyAPIKey(ctx context.Context, key string, method string) (*authentication.Caller, error) {
+	// This function checks an API key presented to this service, returning the caller it belongs to
+	apiKey, err := checkAPIKey(ctx, key, method)
+	if err != nil {
+		return nil, err
+	}
+
+	return &authentication.Caller{
+		Kind:   authentication.CallerAPIKey,
+		ID:     apiKey.ID,
+		Roles:  apiKey.Roles,
+		Scopes: policyManager.Scopes(apiKey.Roles),
+	}, nil
+}
+
+func (verifier *sessionVerifier) VerifySession(ctx context.Context, sessionID string) error {
+	// This function checks the session of a token presented to this service
+	_, err := checkSession(sessionID)
+	return err
+}
+
+// ________SUPPORTING FUNCTIONS________
+
+func checkAPIKey(ctx context.Context, key string, method string) (*authentication.APIKey, error) {
+	/* This function checks an API key against the key store and records its use, along
+	with the method it was presented for and who presented it, for auditing */
+	apiKey, err := lookupAPIKey(ctx, key, method)
+	if err != nil {
+		return nil, err
+	}
+
+	// Record the key's use, failing to do so shouldn't stop the request
+	err = apiKeyStore.Update(apiKey.ID, func(apiKey *authentication.APIKey) error {
+		apiKey.LastUsed = time.Now()
+		apiKey.UsageCount++
+		return nil
+	})
+	if err != nil {
+		logging.FromContext(ctx).Errorln("Failed to record API key usage: ", err)
+	}
+	logging.FromContext(ctx).Infof("API key %v (%q) used for %v from %v", apiKey.ID, apiKey.Name, method, presentedBy(ctx))
+
+	return apiKey, nil
+}
+
+func lookupAPIKey(ctx context.Context, key string, method string) (*authentication.APIKey, error) {
+	// This function finds an API key in the key store and checks that it is valid, logging any rejection
+	id, secret, err := authentication.SplitAPIKey(key)
+	if err != nil {
+		return nil, authentication.APIKeyError(err)
+	}
+
+	apiKey, err := apiKeyStore.Find(id)
+	if err != nil {
+		logging.FromContext(ctx).Errorln("Failed to look up API key: ", err)
+		return nil, status.Errorf(codes.Internal, "could not look up api key")
+	}
+	if apiKey == nil {
+		logging.FromContext(ctx).Warnf("Rejected unknown API key %v for %v from %v", id, method, presentedBy(ctx))
+		return nil, authentication.APIKeyError(authentication.ErrAPIKeyInvalid)
+	}
+
+	if err := apiKey.Check(secret, time.Now()); err != nil {
+		logging.FromContext(ctx).Warnf("Rejected API key %v (%q) for %v from %v: %v", apiKey.ID, apiKey.Name, method, presentedBy(ctx), err)
+		return nil, authentication.APIKeyError(err)
+	}
+
+	return apiKey, nil
+}
+
+func userRecord(user *authentication.User, identity *authentication.Identity) (*authentication.User, error) {
+	/* This function returns the user store's record of a user that an identity provider
+	has authenticated. Users of providers other than the user store get a record (without a
+	password) the first time they log in, and their roles are refreshed from the provider
+	every time they log in. A record that belongs to another provider is never taken over */
+	if identity.Provider == authentication.LocalProviderName {
 		return user, nil
 	}
 
-	user, err := authentication.CreateUser("guest", "myPassword", "guest")
+	if user == nil {
+		user = &authentication.User{Username: identity.Username, Roles: identity.Roles, Provider: identity.Provider}
+		logging.Logger.Infof("Creating a record for %q from identity provider %q", user.Username, user.Provider)
+		return user, userStore.Save(user)
+	}
+	if user.Provider != identity.Provider {
+		return nil, errProviderMismatch
+	}
+
+	user.Roles = identity.Roles
+	err := userStore.Update(user.Username, func(record *authentication.User) error {
+		if record.Provider != identity.Provider {
+			return errProviderMismatch
+		}
+		record.Roles = identity.Roles
+		return nil
+	})
+
+	return user, err
+}
+
+func generateAccessToken(ctx context.Context, user *authentication.User) (string, []string, error) {
+	/* This function starts a session for a user who has logged in, and generates a JWT for
+	it carrying the scopes their roles are granted by the policy */
+	via := ""
+	if identity, ok := authentication.PeerIdentityFromContext(ctx); ok {
+		via = identity.CommonName
+	}
+	session, err := authentication.NewSession(user.Username, clientAddress(ctx), via, tokenDuration)
+	if err == nil {
+		err = sessionStore.Save(session)
+	}
+	if err != nil {
+		logging.FromContext(ctx).Errorln("Failed to start session: ", err)
+		return "", nil, err
+	}
+
+	jwtManager := authentication.NewJWTManager(jwtSecret, tokenDuration)
+	scopes := policyManager.Scopes(user.Roles)
+	token, err := jwtManager.GenerateManager(user, scopes, session.ID)
+	if err != nil {
+		logging.FromContext(ctx).Errorln("Failed to generate access token: ", err)
+		return "", nil, err
+	}
+
+	return token, scopes, nil
+}
+
+func mfaChallenge(user *authentication.User) (*serverPB.LoginAuthResponse, error) {
+	/* This function returns the response to a login that has to be completed with a second
+	factor. If the user's roles require one that they haven't enrolled yet, the response also
+	carries a secret for them to enrol, which VerifyTOTP confirms. The secret is kept until
+	then, so that logging in again doesn't invalidate an app that has already been set up */
+	secret := user.TOTP.PendingSecret
+	if !user.TOTP.Enabled && secret == "" {
+		generated, err := authentication.GenerateTOTPSecret()
+		if err != nil {
+			logging.Logger.Errorln("Failed to generate TOTP secret: ", err)
+			return nil, status.Errorf(codes.Internal, "could not generate totp secret")
+		}
+		err = userStore.Update(user.Username, func(user *authentication.User) error {
+			if user.TOTP.PendingSecret == "" {
+				user.TOTP.PendingSecret = generated
+			}
+			secret = user.TOTP.PendingSecret
+			return nil
+		})
+		if err != nil {
+			logging.Logger.Errorln("Failed to save TOTP secret: ", err)
+			return nil, status.Errorf(codes.Internal, "could not save totp secret")
+		}
+	}
+
+	partialToken, err := authentication.NewJWTManager(jwtSecret, tokenDuration).GeneratePartialToken(user, partialTokenDuration)
+	if err != nil {
+		logging.Logger.Errorln("Failed to generate partial token: ", err)
+		return nil, status.Errorf(codes.Internal, "could not generate partial token")
+	}
+
+	response := &serverPB.LoginAuthResponse{
+		MfaRequired:  true,
+		PartialToken: partialToken,
+	}
+	if !user.TOTP.Enabled {
+		logging.Logger.Infof("Enrolling %q in TOTP during login", user.Username)
+		response.Enrolment = enrolmentMessage(user.Username, secret)
+	}
+
+	return response, nil
+}
+
+func checkSecondFactor(user *authentication.User, code string, now time.Time) ([]string, error) {
+	/* This function checks a code against the user's TOTP enrolment, updating the user.
+	Once TOTP is enabled, one of the user's recovery codes is accepted in place of a code.
+	A code for a pending enrolment enables TOTP, and the user's new recovery codes are
+	returned. It returns ErrTOTPInvalid if the code is rejected */
+	totp := &user.TOTP
+
+	if totp.Enabled {
+		step, err := authentication.ValidateTOTP(totp.Secret, code, now, totp.LastStep)
+		if err == nil {
+			totp.LastStep = step
+			return nil, nil
+		}
+		if err == authentication.ErrTOTPInvalid && totp.UseRecoveryCode(code) {
+			logging.Logger.Warnf("Recovery code used by %q, %d remaining", user.Username, len(totp.RecoveryCodes))
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if totp.PendingSecret == "" {
+		return nil, authentication.ErrTOTPInvalid
+	}
+	step, err := authentication.ValidateTOTP(totp.PendingSecret, code, now, 0)
+	if err != nil {
+		return nil, err
+	}
+	recoveryCodes, hashes, err := authentication.GenerateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	*totp = authentication.TOTP{
+		Secret:        totp.PendingSecret,
+		Enabled:       true,
+		EnabledAt:     now,
+		RecoveryCodes: hashes,
+		LastStep:      step,
+	}
+
+	logging.Logger.Infof("Enabled TOTP for %q", user.Username)
+	return recoveryCodes, nil
+}
+
+func enrolmentMessage(username string, secret string) *serverPB.TOTPEnrolment {
+	// This function describes a TOTP secret in the form authenticator apps are set up with
+	return &serverPB.TOTPEnrolment{
+		Secret:          secret,
+		ProvisioningUri: authentication.TOTPProvisioningURI(mfaIssuer, username, secret),
+	}
+}
+
+func validateConfig(config *Config, check *authentication.ConfigCheck) {
+	/* This function checks every setting the service needs before it starts, recording the problems found in the provided check.
+	The settings with a validate tag (see the Config struct) are checked by the configuration package */
+	configuration.Validate(config, check)
+	check.CheckServerTLS("server.tls", config.Server.TLS)
+	check.RequireFile("server.authentication.policy.file", config.Server.Authentication.Policy.File, "")
+	for index, provider := range config.Server.Identity.Providers {
+		if provider.Type == "htpasswd" {
+			check.RequireFile(fmt.Sprintf("server.identity.providers[%d].file", index), provider.File, "create it with \"htpasswd -B\"")
+		}
+	}
+	if config.Server.Metrics.Push.Enabled {
+		check.RequireValue("server.metrics.push.host", config.Server.Metrics.Push.Host)
+		check.RequirePort("server.metrics.push.port", config.Server.Metrics.Push.Port)
+		check.RequirePositive("server.metrics.push.interval", config.Server.Metrics.Push.Interval)
+		check.RequirePositive("server.metrics.push.maxBackoff", config.Server.Metrics.Push.MaxBackoff)
+	}
+	config.Server.Tracing.Check("server.tracing", check)
+	check.CheckLogging("server.logging", config.Server.Logging)
+}
+
+func checkReloadedConfig(loaded interface{}) error {
+	// This (unexported) function checks a reloaded configuration in the same way as the one the service started with
+	check := authentication.NewConfigCheck("authentication service", configLoader.Path)
+	validateConfig(loaded.(*Config), check)
+	if check.Failed() {
+		return errors.New(check.Report())
+	}
+
+	return nil
+}
+
+func applyConfig(loaded interface{}) {
+	/* This (unexported) function applies the settings of a reloaded configuration that can be
+	changed while the service is running (those tagged reload:"true" in the Config struct), the
+	log level and the identity providers, with the roles they grant. Providers that can't be
+	created are reported, and the current ones are kept */
+	config := loaded.(*Config)
+	if err := logging.SetLevel(config.Server.Logging.Level); err != nil {
+		logging.Logger.Warnln("Could not change the log level: ", err)
+	}
+
+	providers, err := authentication.NewIdentityProviders(config.Server.Identity.Providers, userStore)
+	if err != nil {
+		logging.Logger.Warnln("Could not set up the reloaded identity providers, keeping the current ones: ", err)
+		return
+	}
+	identityProvidersMutex.Lock()
+	identityProviders = providers
+	identityProvidersMutex.Unlock()
+}
+
+func currentIdentityProviders() *authentication.IdentityProviders {
+	// This (unexported) function returns the identity providers that logins are currently checked against
+	identityProvidersMutex.RLock()
+	defer identityProvidersMutex.RUnlock()
+
+	return identityProviders
+}
+
+func loadTLSCredentials() (credentials.TransportCredentials, error) {
+	/* This (unexported) function loads the server's TLS certificate and private key so
+	that logins (and the passwords they carry) are encrypted, and watches them so that
+	rotated certificates are picked up without restarting the service. If configured to,
+	clients also have to present a certificate signed by the CA. It takes no inputs and
+	returns a gRPC TransportCredentials object. */
+	manager, err := authentication.NewCertificateManager("server", serverTLS)
+	if err != nil {
+		return nil, err
+	}
+
+	manager.ExpiryWarning = certificateExpiryWarning
+	manager.Watch(certificateReloadInterval)
+	serverCertificates = manager
+
+	return credentials.NewTLS(manager.ServerTLSConfig()), nil
+}
+
+func newHealthChecker() *interceptors.HealthChecker {
+	/* This (unexported) function creates the authentication service's health checker. The
+	service calls no other services, so it is ready while its certificates are valid and its
+	configuration (the policy and JWT secret) can still be loaded */
+	checker := interceptors.NewHealthChecker(healthInterval, healthTimeout)
+
+	checker.AddCheck("certificates", func(ctx context.Context) error {
+		return serverCertificates.Valid(time.Now())
+	})
+	checker.AddCheck("configuration", func(ctx context.Context) error {
+		if _, err := authentication.LoadPolicy(policyFile); err != nil {
+			return fmt.Errorf("the authorisation policy can't be loaded, the last valid one is in use: %v", err)
+		}
+		if jwtSecret.Value() == "" {
+			return fmt.Errorf("the JWT secret %v is empty", jwtSecret)
+		}
+		return nil
+	})
+
+	return checker
+}
+
+func recordLoginFailure(userExists bool, username string, addressKey string, usernameKey string, now time.Time) {
+	// This function records a failed login against the client's address and the username, counting any resulting lockouts
+	loginMetrics.RecordLoginFailure("invalid_credentials")
+
+	if loginLimiter.TrackedFailure(addressKey, now) {
+		logging.Logger.Warnf("Locked out %v after repeated login failures", addressKey)
+		loginMetrics.RecordLockout("address")
+	}
+
+	locked := false
+	if userExists {
+		err := userStore.Update(username, func(user *authentication.User) error {
+			locked = loginLimiter.RecordFailure(&user.LoginAttempts, now)
+			return nil
+		})
+		if err != nil {
+			logging.Logger.Errorln("Failed to record failed login attempt: ", err)
+		}
+	} else {
+		locked = loginLimiter.TrackedFailure(usernameKey, now)
+	}
+
+	if locked {
+		logging.Logger.Warnf("Locked out username %q after repeated login failures", username)
+		loginMetrics.RecordLockout("username")
+	}
+}
+
+func clientAddress(ctx context.Context) string {
+	/* This function returns the address of the client making a request. Calls routed
+	through the desktop gateway arrive from the gateway's address, so if configured to,
+	the client address forwarded by the gateway is used instead */
+	if trustForwardedAddress {
+		if md, ok := metadata.FromIncomingContext(ctx); ok && len(md["x-forwarded-for"]) > 0 {
+			return md["x-forwarded-for"][0]
+		}
+	}
+
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return "unknown"
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+
+	return host
+}
+
+func exchangeSubject(ctx context.Context, subjectToken string, audience string) (*authentication.UserClaims, error) {
+	/* This function returns the claims of the token being exchanged. API keys are accepted
+	too, in which case the claims describe the key (identified as "apikey:<id>") and the
+	scopes its roles are granted */
+	if subjectToken == "" {
+		return nil, authentication.UnauthenticatedError(authentication.ReasonTokenMissing, "authentication token has not been provided")
+	}
+
+	if _, _, err := authentication.SplitAPIKey(subjectToken); err == nil {
+		// The service presenting the key has already recorded its use, so it isn't recorded again here
+		apiKey, err := lookupAPIKey(ctx, subjectToken, "token exchange for "+audience)
+		if err != nil {
+			return nil, err
+		}
+
+		claims := &authentication.UserClaims{
+			Username: "apikey:" + apiKey.ID,
+			Roles:    apiKey.Roles,
+			Scopes:   policyManager.Scopes(apiKey.Roles),
+		}
+		if !apiKey.ExpiresAt.IsZero() {
+			claims.ExpiresAt = apiKey.ExpiresAt.Unix()
+		}
+		return claims, nil
+	}
+
+	claims, err := authentication.NewJWTManager(jwtSecret, tokenDuration).VerifyJWT(subjectToken)
+	if err != nil {
+		return nil, authentication.TokenError(err)
+	}
+	if claims.SessionID != "" {
+		// Tokens of terminated sessions can't be exchanged to keep calling downstream services
+		if _, err := checkSession(claims.SessionID); err != nil {
+			return nil, err
+		}
+	}
+
+	return claims, nil
+}
+
+func presentedBy(ctx context.Context) string {
+	/* This function describes who presented an API key, for the audit log. Keys presented
+	to another service arrive from that service (identified by its client certificate),
+	along with the address of the client that presented the key to it */
+	address := clientAddress(ctx)
+	if identity, ok := authentication.PeerIdentityFromContext(ctx); ok {
+		return identity.CommonName + " (client " + address + ")"
+	}
+
+	return address
+}
+
+func apiKeyMessage(apiKey *authentication.APIKey) *serverPB.APIKey {
+	// This function converts an API key from the key store into its proto message, leaving out the hashed secret
+	return &serverPB.APIKey{
+		Id:         apiKey.ID,
+		Name:       apiKey.Name,
+		Roles:      apiKey.Roles,
+		CreatedBy:  apiKey.CreatedBy,
+		CreatedAt:  unixTime(apiKey.CreatedAt),
+		ExpiresAt:  unixTime(apiKey.ExpiresAt),
+		RevokedAt:  unixTime(apiKey.RevokedAt),
+		LastUsed:   unixTime(apiKey.LastUsed),
+		UsageCount: apiKey.UsageCount,
+	}
+}
+
+func checkSession(sessionID string) (*authentication.Session, error) {
+	/* This function checks that a session is still active and records its use. Uses are
+	only written to the store once a minute, which is as precise as "last used" needs to be */
+	session, err := sessionStore.Find(sessionID)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "could not create user")
+		logging.Logger.Errorln("Failed to look up session: ", err)
+		return nil, status.Errorf(codes.Internal, "could not look up session")
+	}
+	if session == nil {
+		logging.Logger.Warnf("Refused a token of unknown session %v", sessionID)
+		return nil, authentication.SessionError(authentication.ErrSessionNotFound)
+	}
+
+	now := time.Now()
+	if err := session.Check(now); err != nil {
+		logging.Logger.Debugf("Refused a token of ended session %v of %q", session.ID, session.Username)
+		return nil, authentication.SessionError(err)
+	}
+	if now.Sub(session.LastUsed) >= time.Minute {
+		err := sessionStore.Update(sessionID, func(session *authentication.Session) error {
+			session.LastUsed = now
+			return nil
+		})
+		if err != nil {
+			logging.Logger.Errorln("Failed to record session usage: ", err)
+		}
+	}
+
+	return session, nil
+}
+
+func sessionOwner(ctx context.Context, username string, method string) (*authentication.Caller, string, error) {
+	/* This function returns the caller and the user whose sessions they asked for: their
+	own if no username is provided. Other users' sessions need the "sessions:manage" scope */
+	caller, ok := authentication.CallerFromContext(ctx)
+	if !ok {
+		return nil, "", authentication.UnauthenticatedError(authentication.ReasonTokenMissing, "authentication token has not been provided")
+	}
+
+	if username == "" || (caller.Kind == authentication.CallerUser && username == caller.ID) {
+		if caller.Kind != authentication.CallerUser {
+			return nil, "", status.Errorf(codes.InvalidArgument, "a username is required")
+		}
+		return caller, caller.ID, nil
+	}
+	if !canManageSessions(caller) {
+		logging.FromContext(ctx).Warnf("Refused %q access to the sessions of %q", caller.ID, username)
+		return nil, "", authentication.PermissionDeniedError(method)
 	}
 
-	return user, nil
+	return caller, username, nil
+}
+
+func canManageSessions(caller *authentication.Caller) bool {
+	// This function reports whether the caller may list and terminate other users' sessions
+	for _, scope := range caller.Scopes {
+		if scope == sessionsManageScope {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sessionMessage(session *authentication.Session, caller *authentication.Caller) *serverPB.Session {
+	// This function converts a session from the session store into its proto message, marking the caller's own session
+	return &serverPB.Session{
+		Id:           session.ID,
+		Username:     session.Username,
+		Client:       session.Client,
+		Via:          session.Via,
+		IssuedAt:     unixTime(session.IssuedAt),
+		ExpiresAt:    unixTime(session.ExpiresAt),
+		LastUsed:     unixTime(session.LastUsed),
+		TerminatedAt: unixTime(session.TerminatedAt),
+		TerminatedBy: session.TerminatedBy,
+		Current:      caller.Session != "" && caller.Session == session.ID,
+	}
+}
+
+func newAuditEvent(ctx context.Context, event string, decision string, reason string) authentication.AuditEvent {
+	/* This function starts an audit event for the request being served. The address of
+	the client (as forwarded by the gateway) is recorded, rather than the gateway's */
+	method, _ := grpc.Method(ctx)
+	auditEvent := authentication.AuditEventFromContext(ctx, event, method)
+	auditEvent.Peer = clientAddress(ctx)
+	auditEvent.Decision, auditEvent.Reason = decision, reason
+
+	return auditEvent
+}
+
+func recordAudit(ctx context.Context, event string, target string, decision string, reason string) {
+	/* This function records an event about the provided target (a username or API key) in
+	the audit log. The caller attached by the interceptor is recorded as the user who acted,
+	requests to public methods (logging in) are made by the target on their own behalf */
+	auditEvent := newAuditEvent(ctx, event, decision, reason)
+	if auditEvent.User == "" {
+		auditEvent.User = target
+	} else if target != auditEvent.User {
+		auditEvent.Target = target
+	}
+
+	auditLog.Record(auditEvent)
+}
+
+func recordTokenIssued(ctx context.Context, user *authentication.User, reason string) {
+	// This function records an access token issued to a user in the audit log
+	auditEvent := newAuditEvent(ctx, authentication.AuditTokenIssued, authentication.AuditAllow, reason)
+	auditEvent.User, auditEvent.Kind, auditEvent.Roles = user.Username, authentication.CallerUser, user.Roles
+
+	auditLog.Record(auditEvent)
+}
+
+func auditEventMessage(event authentication.AuditEvent) *serverPB.AuditEvent {
+	// This function converts an audit log entry into its proto message
+	return &serverPB.AuditEvent{
+		Time:      unixTime(event.Time),
+		Service:   event.Service,
+		Event:     event.Event,
+		User:      event.User,
+		Kind:      event.Kind,
+		Roles:     event.Roles,
+		Actor:     event.Actor,
+		Target:    event.Target,
+		Method:    event.Method,
+		Peer:      event.Peer,
+		Decision:  event.Decision,
+		Reason:    event.Reason,
+		RequestId: event.RequestID,
+	}
+}
+
+func unixTime(t time.Time) int64 {
+	// This function returns the Unix timestamp of the provided time, or zero if it isn't set
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+func maxDuration(a time.Duration, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func seedUsers(store authentication.UserStore) error {
+	/* This function creates the default admin and guest users if the user store is
+	empty, so that a fresh deployment can be logged into */
+	users, err := store.List()
+	if err != nil || len(users) > 0 {
+		return err
+	}
+
+	logging.Logger.Warnln("User store is empty, creating the default users. Change their passwords!")
+	defaults := []struct {
+		username string
+		role     string
+	}{
+		{"admin", "admin"},
+		{"guest", "guest"},
+	}
+	for _, defaultUser := range defaults {
+		user, err := authentication.CreateUser(defaultUser.username, "myPassword", defaultUser.role)
+		if err != nil {
+			return err
+		}
+		if err := store.Save(user); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
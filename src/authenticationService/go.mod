@@ -3,8 +3,22 @@ module github.com/nicholasbunn/mastersSandbox/src/authenticationService
 go 1.13
 
 require (
-	github.com/go-yaml/yaml v2.1.0+incompatible
 	github.com/golang/protobuf v1.5.2
+	github.com/grpc-ecosystem/go-grpc-middleware v1.3.0
 	github.com/nicholasbunn/mastersSandbox/src/authenticationStuff v0.0.0-20210609072109-f7b080e72cb4
-	google.golang.org/grpc v1.38.0
+	github.com/nicholasbunn/mastersSandbox/src/configuration v0.0.0-00010101000000-000000000000
+	github.com/nicholasbunn/mastersSandbox/src/interceptors v0.0.0-00010101000000-000000000000
+	github.com/nicholasbunn/mastersSandbox/src/logging v0.0.0-00010101000000-000000000000
+	google.golang.org/grpc v1.46.0
 )
+
+replace github.com/nicholasbunn/mastersSandbox/src/authenticationStuff => ../authenticationStuff
+
+replace github.com/nicholasbunn/mastersSandbox/src/interceptors => ../interceptors
+
+replace github.com/nicholasbunn/mastersSandbox/src/logging => ../logging
+
+replace github.com/nicholasbunn/mastersSandbox/src/configuration => ../configuration
+
+// The interceptors include this service's client, so their module requires this one in turn
+replace github.com/nicholasbunn/mastersSandbox/src/authenticationService => ./
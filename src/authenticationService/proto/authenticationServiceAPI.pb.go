@@ -68,11 +68,16 @@ func (m *LoginAuthRequest) GetPassword() string {
 }
 
 type LoginAuthResponse struct {
-	Permissions          string   `protobuf:"bytes,1,opt,name=permissions,proto3" json:"permissions,omitempty"`
-	AccessToken          string   `protobuf:"bytes,2,opt,name=access_token,json=accessToken,proto3" json:"access_token,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	Permissions          string         `protobuf:"bytes,1,opt,name=permissions,proto3" json:"permissions,omitempty"`
+	AccessToken          string         `protobuf:"bytes,2,opt,name=access_token,json=accessToken,proto3" json:"access_token,omitempty"`
+	Roles                []string       `protobuf:"bytes,3,rep,name=roles,proto3" json:"roles,omitempty"`
+	Scopes               []string       `protobuf:"bytes,4,rep,name=scopes,proto3" json:"scopes,omitempty"`
+	MfaRequired          bool           `protobuf:"varint,5,opt,name=mfa_required,json=mfaRequired,proto3" json:"mfa_required,omitempty"`
+	PartialToken         string         `protobuf:"bytes,6,opt,name=partial_token,json=partialToken,proto3" json:"partial_token,omitempty"`
+	Enrolment            *TOTPEnrolment `protobuf:"bytes,7,opt,name=enrolment,proto3" json:"enrolment,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
 }
 
 func (m *LoginAuthResponse) Reset()         { *m = LoginAuthResponse{} }
@@ -114,9 +119,1729 @@ func (m *LoginAuthResponse) GetAccessToken() string {
 	return ""
 }
 
+func (m *LoginAuthResponse) GetRoles() []string {
+	if m != nil {
+		return m.Roles
+	}
+	return nil
+}
+
+func (m *LoginAuthResponse) GetScopes() []string {
+	if m != nil {
+		return m.Scopes
+	}
+	return nil
+}
+
+func (m *LoginAuthResponse) GetMfaRequired() bool {
+	if m != nil {
+		return m.MfaRequired
+	}
+	return false
+}
+
+func (m *LoginAuthResponse) GetPartialToken() string {
+	if m != nil {
+		return m.PartialToken
+	}
+	return ""
+}
+
+func (m *LoginAuthResponse) GetEnrolment() *TOTPEnrolment {
+	if m != nil {
+		return m.Enrolment
+	}
+	return nil
+}
+
+// Second factor (TOTP, RFC 6238)
+type TOTPEnrolment struct {
+	Secret               string   `protobuf:"bytes,1,opt,name=secret,proto3" json:"secret,omitempty"`
+	ProvisioningUri      string   `protobuf:"bytes,2,opt,name=provisioning_uri,json=provisioningUri,proto3" json:"provisioning_uri,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TOTPEnrolment) Reset()         { *m = TOTPEnrolment{} }
+func (m *TOTPEnrolment) String() string { return proto.CompactTextString(m) }
+func (*TOTPEnrolment) ProtoMessage()    {}
+func (*TOTPEnrolment) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6991cbd76a21bcaf, []int{2}
+}
+
+func (m *TOTPEnrolment) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TOTPEnrolment.Unmarshal(m, b)
+}
+func (m *TOTPEnrolment) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TOTPEnrolment.Marshal(b, m, deterministic)
+}
+func (m *TOTPEnrolment) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TOTPEnrolment.Merge(m, src)
+}
+func (m *TOTPEnrolment) XXX_Size() int {
+	return xxx_messageInfo_TOTPEnrolment.Size(m)
+}
+func (m *TOTPEnrolment) XXX_DiscardUnknown() {
+	xxx_messageInfo_TOTPEnrolment.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TOTPEnrolment proto.InternalMessageInfo
+
+func (m *TOTPEnrolment) GetSecret() string {
+	if m != nil {
+		return m.Secret
+	}
+	return ""
+}
+
+func (m *TOTPEnrolment) GetProvisioningUri() string {
+	if m != nil {
+		return m.ProvisioningUri
+	}
+	return ""
+}
+
+type VerifyTOTPRequest struct {
+	PartialToken         string   `protobuf:"bytes,1,opt,name=partial_token,json=partialToken,proto3" json:"partial_token,omitempty"`
+	Code                 string   `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *VerifyTOTPRequest) Reset()         { *m = VerifyTOTPRequest{} }
+func (m *VerifyTOTPRequest) String() string { return proto.CompactTextString(m) }
+func (*VerifyTOTPRequest) ProtoMessage()    {}
+func (*VerifyTOTPRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6991cbd76a21bcaf, []int{3}
+}
+
+func (m *VerifyTOTPRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_VerifyTOTPRequest.Unmarshal(m, b)
+}
+func (m *VerifyTOTPRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_VerifyTOTPRequest.Marshal(b, m, deterministic)
+}
+func (m *VerifyTOTPRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_VerifyTOTPRequest.Merge(m, src)
+}
+func (m *VerifyTOTPRequest) XXX_Size() int {
+	return xxx_messageInfo_VerifyTOTPRequest.Size(m)
+}
+func (m *VerifyTOTPRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_VerifyTOTPRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_VerifyTOTPRequest proto.InternalMessageInfo
+
+func (m *VerifyTOTPRequest) GetPartialToken() string {
+	if m != nil {
+		return m.PartialToken
+	}
+	return ""
+}
+
+func (m *VerifyTOTPRequest) GetCode() string {
+	if m != nil {
+		return m.Code
+	}
+	return ""
+}
+
+type VerifyTOTPResponse struct {
+	Permissions          string   `protobuf:"bytes,1,opt,name=permissions,proto3" json:"permissions,omitempty"`
+	AccessToken          string   `protobuf:"bytes,2,opt,name=access_token,json=accessToken,proto3" json:"access_token,omitempty"`
+	Roles                []string `protobuf:"bytes,3,rep,name=roles,proto3" json:"roles,omitempty"`
+	Scopes               []string `protobuf:"bytes,4,rep,name=scopes,proto3" json:"scopes,omitempty"`
+	RecoveryCodes        []string `protobuf:"bytes,5,rep,name=recovery_codes,json=recoveryCodes,proto3" json:"recovery_codes,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *VerifyTOTPResponse) Reset()         { *m = VerifyTOTPResponse{} }
+func (m *VerifyTOTPResponse) String() string { return proto.CompactTextString(m) }
+func (*VerifyTOTPResponse) ProtoMessage()    {}
+func (*VerifyTOTPResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6991cbd76a21bcaf, []int{4}
+}
+
+func (m *VerifyTOTPResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_VerifyTOTPResponse.Unmarshal(m, b)
+}
+func (m *VerifyTOTPResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_VerifyTOTPResponse.Marshal(b, m, deterministic)
+}
+func (m *VerifyTOTPResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_VerifyTOTPResponse.Merge(m, src)
+}
+func (m *VerifyTOTPResponse) XXX_Size() int {
+	return xxx_messageInfo_VerifyTOTPResponse.Size(m)
+}
+func (m *VerifyTOTPResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_VerifyTOTPResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_VerifyTOTPResponse proto.InternalMessageInfo
+
+func (m *VerifyTOTPResponse) GetPermissions() string {
+	if m != nil {
+		return m.Permissions
+	}
+	return ""
+}
+
+func (m *VerifyTOTPResponse) GetAccessToken() string {
+	if m != nil {
+		return m.AccessToken
+	}
+	return ""
+}
+
+func (m *VerifyTOTPResponse) GetRoles() []string {
+	if m != nil {
+		return m.Roles
+	}
+	return nil
+}
+
+func (m *VerifyTOTPResponse) GetScopes() []string {
+	if m != nil {
+		return m.Scopes
+	}
+	return nil
+}
+
+func (m *VerifyTOTPResponse) GetRecoveryCodes() []string {
+	if m != nil {
+		return m.RecoveryCodes
+	}
+	return nil
+}
+
+type EnrolTOTPRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *EnrolTOTPRequest) Reset()         { *m = EnrolTOTPRequest{} }
+func (m *EnrolTOTPRequest) String() string { return proto.CompactTextString(m) }
+func (*EnrolTOTPRequest) ProtoMessage()    {}
+func (*EnrolTOTPRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6991cbd76a21bcaf, []int{5}
+}
+
+func (m *EnrolTOTPRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EnrolTOTPRequest.Unmarshal(m, b)
+}
+func (m *EnrolTOTPRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EnrolTOTPRequest.Marshal(b, m, deterministic)
+}
+func (m *EnrolTOTPRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EnrolTOTPRequest.Merge(m, src)
+}
+func (m *EnrolTOTPRequest) XXX_Size() int {
+	return xxx_messageInfo_EnrolTOTPRequest.Size(m)
+}
+func (m *EnrolTOTPRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_EnrolTOTPRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EnrolTOTPRequest proto.InternalMessageInfo
+
+type EnrolTOTPResponse struct {
+	Enrolment            *TOTPEnrolment `protobuf:"bytes,1,opt,name=enrolment,proto3" json:"enrolment,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *EnrolTOTPResponse) Reset()         { *m = EnrolTOTPResponse{} }
+func (m *EnrolTOTPResponse) String() string { return proto.CompactTextString(m) }
+func (*EnrolTOTPResponse) ProtoMessage()    {}
+func (*EnrolTOTPResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6991cbd76a21bcaf, []int{6}
+}
+
+func (m *EnrolTOTPResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EnrolTOTPResponse.Unmarshal(m, b)
+}
+func (m *EnrolTOTPResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EnrolTOTPResponse.Marshal(b, m, deterministic)
+}
+func (m *EnrolTOTPResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EnrolTOTPResponse.Merge(m, src)
+}
+func (m *EnrolTOTPResponse) XXX_Size() int {
+	return xxx_messageInfo_EnrolTOTPResponse.Size(m)
+}
+func (m *EnrolTOTPResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_EnrolTOTPResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EnrolTOTPResponse proto.InternalMessageInfo
+
+func (m *EnrolTOTPResponse) GetEnrolment() *TOTPEnrolment {
+	if m != nil {
+		return m.Enrolment
+	}
+	return nil
+}
+
+type ConfirmTOTPRequest struct {
+	Code                 string   `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ConfirmTOTPRequest) Reset()         { *m = ConfirmTOTPRequest{} }
+func (m *ConfirmTOTPRequest) String() string { return proto.CompactTextString(m) }
+func (*ConfirmTOTPRequest) ProtoMessage()    {}
+func (*ConfirmTOTPRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6991cbd76a21bcaf, []int{7}
+}
+
+func (m *ConfirmTOTPRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ConfirmTOTPRequest.Unmarshal(m, b)
+}
+func (m *ConfirmTOTPRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ConfirmTOTPRequest.Marshal(b, m, deterministic)
+}
+func (m *ConfirmTOTPRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ConfirmTOTPRequest.Merge(m, src)
+}
+func (m *ConfirmTOTPRequest) XXX_Size() int {
+	return xxx_messageInfo_ConfirmTOTPRequest.Size(m)
+}
+func (m *ConfirmTOTPRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ConfirmTOTPRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ConfirmTOTPRequest proto.InternalMessageInfo
+
+func (m *ConfirmTOTPRequest) GetCode() string {
+	if m != nil {
+		return m.Code
+	}
+	return ""
+}
+
+type ConfirmTOTPResponse struct {
+	RecoveryCodes        []string `protobuf:"bytes,1,rep,name=recovery_codes,json=recoveryCodes,proto3" json:"recovery_codes,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ConfirmTOTPResponse) Reset()         { *m = ConfirmTOTPResponse{} }
+func (m *ConfirmTOTPResponse) String() string { return proto.CompactTextString(m) }
+func (*ConfirmTOTPResponse) ProtoMessage()    {}
+func (*ConfirmTOTPResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6991cbd76a21bcaf, []int{8}
+}
+
+func (m *ConfirmTOTPResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ConfirmTOTPResponse.Unmarshal(m, b)
+}
+func (m *ConfirmTOTPResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ConfirmTOTPResponse.Marshal(b, m, deterministic)
+}
+func (m *ConfirmTOTPResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ConfirmTOTPResponse.Merge(m, src)
+}
+func (m *ConfirmTOTPResponse) XXX_Size() int {
+	return xxx_messageInfo_ConfirmTOTPResponse.Size(m)
+}
+func (m *ConfirmTOTPResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ConfirmTOTPResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ConfirmTOTPResponse proto.InternalMessageInfo
+
+func (m *ConfirmTOTPResponse) GetRecoveryCodes() []string {
+	if m != nil {
+		return m.RecoveryCodes
+	}
+	return nil
+}
+
+type ResetTOTPRequest struct {
+	Username             string   `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ResetTOTPRequest) Reset()         { *m = ResetTOTPRequest{} }
+func (m *ResetTOTPRequest) String() string { return proto.CompactTextString(m) }
+func (*ResetTOTPRequest) ProtoMessage()    {}
+func (*ResetTOTPRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6991cbd76a21bcaf, []int{9}
+}
+
+func (m *ResetTOTPRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ResetTOTPRequest.Unmarshal(m, b)
+}
+func (m *ResetTOTPRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ResetTOTPRequest.Marshal(b, m, deterministic)
+}
+func (m *ResetTOTPRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ResetTOTPRequest.Merge(m, src)
+}
+func (m *ResetTOTPRequest) XXX_Size() int {
+	return xxx_messageInfo_ResetTOTPRequest.Size(m)
+}
+func (m *ResetTOTPRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ResetTOTPRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ResetTOTPRequest proto.InternalMessageInfo
+
+func (m *ResetTOTPRequest) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
+}
+
+type ResetTOTPResponse struct {
+	Username             string   `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ResetTOTPResponse) Reset()         { *m = ResetTOTPResponse{} }
+func (m *ResetTOTPResponse) String() string { return proto.CompactTextString(m) }
+func (*ResetTOTPResponse) ProtoMessage()    {}
+func (*ResetTOTPResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6991cbd76a21bcaf, []int{10}
+}
+
+func (m *ResetTOTPResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ResetTOTPResponse.Unmarshal(m, b)
+}
+func (m *ResetTOTPResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ResetTOTPResponse.Marshal(b, m, deterministic)
+}
+func (m *ResetTOTPResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ResetTOTPResponse.Merge(m, src)
+}
+func (m *ResetTOTPResponse) XXX_Size() int {
+	return xxx_messageInfo_ResetTOTPResponse.Size(m)
+}
+func (m *ResetTOTPResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ResetTOTPResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ResetTOTPResponse proto.InternalMessageInfo
+
+func (m *ResetTOTPResponse) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
+}
+
+type UnlockAccountRequest struct {
+	Username             string   `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *UnlockAccountRequest) Reset()         { *m = UnlockAccountRequest{} }
+func (m *UnlockAccountRequest) String() string { return proto.CompactTextString(m) }
+func (*UnlockAccountRequest) ProtoMessage()    {}
+func (*UnlockAccountRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6991cbd76a21bcaf, []int{11}
+}
+
+func (m *UnlockAccountRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UnlockAccountRequest.Unmarshal(m, b)
+}
+func (m *UnlockAccountRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UnlockAccountRequest.Marshal(b, m, deterministic)
+}
+func (m *UnlockAccountRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UnlockAccountRequest.Merge(m, src)
+}
+func (m *UnlockAccountRequest) XXX_Size() int {
+	return xxx_messageInfo_UnlockAccountRequest.Size(m)
+}
+func (m *UnlockAccountRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_UnlockAccountRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UnlockAccountRequest proto.InternalMessageInfo
+
+func (m *UnlockAccountRequest) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
+}
+
+type UnlockAccountResponse struct {
+	Username             string   `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *UnlockAccountResponse) Reset()         { *m = UnlockAccountResponse{} }
+func (m *UnlockAccountResponse) String() string { return proto.CompactTextString(m) }
+func (*UnlockAccountResponse) ProtoMessage()    {}
+func (*UnlockAccountResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6991cbd76a21bcaf, []int{12}
+}
+
+func (m *UnlockAccountResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UnlockAccountResponse.Unmarshal(m, b)
+}
+func (m *UnlockAccountResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UnlockAccountResponse.Marshal(b, m, deterministic)
+}
+func (m *UnlockAccountResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UnlockAccountResponse.Merge(m, src)
+}
+func (m *UnlockAccountResponse) XXX_Size() int {
+	return xxx_messageInfo_UnlockAccountResponse.Size(m)
+}
+func (m *UnlockAccountResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_UnlockAccountResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UnlockAccountResponse proto.InternalMessageInfo
+
+func (m *UnlockAccountResponse) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
+}
+
+// API keys for scripts and scheduled jobs. Times are Unix timestamps (in seconds), zero if unset
+type APIKey struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name                 string   `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Roles                []string `protobuf:"bytes,3,rep,name=roles,proto3" json:"roles,omitempty"`
+	CreatedBy            string   `protobuf:"bytes,4,opt,name=created_by,json=createdBy,proto3" json:"created_by,omitempty"`
+	CreatedAt            int64    `protobuf:"varint,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	ExpiresAt            int64    `protobuf:"varint,6,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	RevokedAt            int64    `protobuf:"varint,7,opt,name=revoked_at,json=revokedAt,proto3" json:"revoked_at,omitempty"`
+	LastUsed             int64    `protobuf:"varint,8,opt,name=last_used,json=lastUsed,proto3" json:"last_used,omitempty"`
+	UsageCount           int64    `protobuf:"varint,9,opt,name=usage_count,json=usageCount,proto3" json:"usage_count,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *APIKey) Reset()         { *m = APIKey{} }
+func (m *APIKey) String() string { return proto.CompactTextString(m) }
+func (*APIKey) ProtoMessage()    {}
+func (*APIKey) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6991cbd76a21bcaf, []int{13}
+}
+
+func (m *APIKey) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_APIKey.Unmarshal(m, b)
+}
+func (m *APIKey) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_APIKey.Marshal(b, m, deterministic)
+}
+func (m *APIKey) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_APIKey.Merge(m, src)
+}
+func (m *APIKey) XXX_Size() int {
+	return xxx_messageInfo_APIKey.Size(m)
+}
+func (m *APIKey) XXX_DiscardUnknown() {
+	xxx_messageInfo_APIKey.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_APIKey proto.InternalMessageInfo
+
+func (m *APIKey) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *APIKey) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *APIKey) GetRoles() []string {
+	if m != nil {
+		return m.Roles
+	}
+	return nil
+}
+
+func (m *APIKey) GetCreatedBy() string {
+	if m != nil {
+		return m.CreatedBy
+	}
+	return ""
+}
+
+func (m *APIKey) GetCreatedAt() int64 {
+	if m != nil {
+		return m.CreatedAt
+	}
+	return 0
+}
+
+func (m *APIKey) GetExpiresAt() int64 {
+	if m != nil {
+		return m.ExpiresAt
+	}
+	return 0
+}
+
+func (m *APIKey) GetRevokedAt() int64 {
+	if m != nil {
+		return m.RevokedAt
+	}
+	return 0
+}
+
+func (m *APIKey) GetLastUsed() int64 {
+	if m != nil {
+		return m.LastUsed
+	}
+	return 0
+}
+
+func (m *APIKey) GetUsageCount() int64 {
+	if m != nil {
+		return m.UsageCount
+	}
+	return 0
+}
+
+type CreateAPIKeyRequest struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Roles                []string `protobuf:"bytes,2,rep,name=roles,proto3" json:"roles,omitempty"`
+	Lifetime             int64    `protobuf:"varint,3,opt,name=lifetime,proto3" json:"lifetime,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CreateAPIKeyRequest) Reset()         { *m = CreateAPIKeyRequest{} }
+func (m *CreateAPIKeyRequest) String() string { return proto.CompactTextString(m) }
+func (*CreateAPIKeyRequest) ProtoMessage()    {}
+func (*CreateAPIKeyRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6991cbd76a21bcaf, []int{14}
+}
+
+func (m *CreateAPIKeyRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CreateAPIKeyRequest.Unmarshal(m, b)
+}
+func (m *CreateAPIKeyRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CreateAPIKeyRequest.Marshal(b, m, deterministic)
+}
+func (m *CreateAPIKeyRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CreateAPIKeyRequest.Merge(m, src)
+}
+func (m *CreateAPIKeyRequest) XXX_Size() int {
+	return xxx_messageInfo_CreateAPIKeyRequest.Size(m)
+}
+func (m *CreateAPIKeyRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CreateAPIKeyRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CreateAPIKeyRequest proto.InternalMessageInfo
+
+func (m *CreateAPIKeyRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *CreateAPIKeyRequest) GetRoles() []string {
+	if m != nil {
+		return m.Roles
+	}
+	return nil
+}
+
+func (m *CreateAPIKeyRequest) GetLifetime() int64 {
+	if m != nil {
+		return m.Lifetime
+	}
+	return 0
+}
+
+type CreateAPIKeyResponse struct {
+	Key                  *APIKey  `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Secret               string   `protobuf:"bytes,2,opt,name=secret,proto3" json:"secret,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CreateAPIKeyResponse) Reset()         { *m = CreateAPIKeyResponse{} }
+func (m *CreateAPIKeyResponse) String() string { return proto.CompactTextString(m) }
+func (*CreateAPIKeyResponse) ProtoMessage()    {}
+func (*CreateAPIKeyResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6991cbd76a21bcaf, []int{15}
+}
+
+func (m *CreateAPIKeyResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CreateAPIKeyResponse.Unmarshal(m, b)
+}
+func (m *CreateAPIKeyResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CreateAPIKeyResponse.Marshal(b, m, deterministic)
+}
+func (m *CreateAPIKeyResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CreateAPIKeyResponse.Merge(m, src)
+}
+func (m *CreateAPIKeyResponse) XXX_Size() int {
+	return xxx_messageInfo_CreateAPIKeyResponse.Size(m)
+}
+func (m *CreateAPIKeyResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_CreateAPIKeyResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CreateAPIKeyResponse proto.InternalMessageInfo
+
+func (m *CreateAPIKeyResponse) GetKey() *APIKey {
+	if m != nil {
+		return m.Key
+	}
+	return nil
+}
+
+func (m *CreateAPIKeyResponse) GetSecret() string {
+	if m != nil {
+		return m.Secret
+	}
+	return ""
+}
+
+type ListAPIKeysRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListAPIKeysRequest) Reset()         { *m = ListAPIKeysRequest{} }
+func (m *ListAPIKeysRequest) String() string { return proto.CompactTextString(m) }
+func (*ListAPIKeysRequest) ProtoMessage()    {}
+func (*ListAPIKeysRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6991cbd76a21bcaf, []int{16}
+}
+
+func (m *ListAPIKeysRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListAPIKeysRequest.Unmarshal(m, b)
+}
+func (m *ListAPIKeysRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListAPIKeysRequest.Marshal(b, m, deterministic)
+}
+func (m *ListAPIKeysRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListAPIKeysRequest.Merge(m, src)
+}
+func (m *ListAPIKeysRequest) XXX_Size() int {
+	return xxx_messageInfo_ListAPIKeysRequest.Size(m)
+}
+func (m *ListAPIKeysRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListAPIKeysRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListAPIKeysRequest proto.InternalMessageInfo
+
+type ListAPIKeysResponse struct {
+	Keys                 []*APIKey `protobuf:"bytes,1,rep,name=keys,proto3" json:"keys,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *ListAPIKeysResponse) Reset()         { *m = ListAPIKeysResponse{} }
+func (m *ListAPIKeysResponse) String() string { return proto.CompactTextString(m) }
+func (*ListAPIKeysResponse) ProtoMessage()    {}
+func (*ListAPIKeysResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6991cbd76a21bcaf, []int{17}
+}
+
+func (m *ListAPIKeysResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListAPIKeysResponse.Unmarshal(m, b)
+}
+func (m *ListAPIKeysResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListAPIKeysResponse.Marshal(b, m, deterministic)
+}
+func (m *ListAPIKeysResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListAPIKeysResponse.Merge(m, src)
+}
+func (m *ListAPIKeysResponse) XXX_Size() int {
+	return xxx_messageInfo_ListAPIKeysResponse.Size(m)
+}
+func (m *ListAPIKeysResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListAPIKeysResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListAPIKeysResponse proto.InternalMessageInfo
+
+func (m *ListAPIKeysResponse) GetKeys() []*APIKey {
+	if m != nil {
+		return m.Keys
+	}
+	return nil
+}
+
+type RevokeAPIKeyRequest struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RevokeAPIKeyRequest) Reset()         { *m = RevokeAPIKeyRequest{} }
+func (m *RevokeAPIKeyRequest) String() string { return proto.CompactTextString(m) }
+func (*RevokeAPIKeyRequest) ProtoMessage()    {}
+func (*RevokeAPIKeyRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6991cbd76a21bcaf, []int{18}
+}
+
+func (m *RevokeAPIKeyRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RevokeAPIKeyRequest.Unmarshal(m, b)
+}
+func (m *RevokeAPIKeyRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RevokeAPIKeyRequest.Marshal(b, m, deterministic)
+}
+func (m *RevokeAPIKeyRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RevokeAPIKeyRequest.Merge(m, src)
+}
+func (m *RevokeAPIKeyRequest) XXX_Size() int {
+	return xxx_messageInfo_RevokeAPIKeyRequest.Size(m)
+}
+func (m *RevokeAPIKeyRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RevokeAPIKeyRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RevokeAPIKeyRequest proto.InternalMessageInfo
+
+func (m *RevokeAPIKeyRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+type RevokeAPIKeyResponse struct {
+	Key                  *APIKey  `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RevokeAPIKeyResponse) Reset()         { *m = RevokeAPIKeyResponse{} }
+func (m *RevokeAPIKeyResponse) String() string { return proto.CompactTextString(m) }
+func (*RevokeAPIKeyResponse) ProtoMessage()    {}
+func (*RevokeAPIKeyResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6991cbd76a21bcaf, []int{19}
+}
+
+func (m *RevokeAPIKeyResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RevokeAPIKeyResponse.Unmarshal(m, b)
+}
+func (m *RevokeAPIKeyResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RevokeAPIKeyResponse.Marshal(b, m, deterministic)
+}
+func (m *RevokeAPIKeyResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RevokeAPIKeyResponse.Merge(m, src)
+}
+func (m *RevokeAPIKeyResponse) XXX_Size() int {
+	return xxx_messageInfo_RevokeAPIKeyResponse.Size(m)
+}
+func (m *RevokeAPIKeyResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_RevokeAPIKeyResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RevokeAPIKeyResponse proto.InternalMessageInfo
+
+func (m *RevokeAPIKeyResponse) GetKey() *APIKey {
+	if m != nil {
+		return m.Key
+	}
+	return nil
+}
+
+type VerifyAPIKeyRequest struct {
+	ApiKey               string   `protobuf:"bytes,1,opt,name=api_key,json=apiKey,proto3" json:"api_key,omitempty"`
+	Method               string   `protobuf:"bytes,2,opt,name=method,proto3" json:"method,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *VerifyAPIKeyRequest) Reset()         { *m = VerifyAPIKeyRequest{} }
+func (m *VerifyAPIKeyRequest) String() string { return proto.CompactTextString(m) }
+func (*VerifyAPIKeyRequest) ProtoMessage()    {}
+func (*VerifyAPIKeyRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6991cbd76a21bcaf, []int{20}
+}
+
+func (m *VerifyAPIKeyRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_VerifyAPIKeyRequest.Unmarshal(m, b)
+}
+func (m *VerifyAPIKeyRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_VerifyAPIKeyRequest.Marshal(b, m, deterministic)
+}
+func (m *VerifyAPIKeyRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_VerifyAPIKeyRequest.Merge(m, src)
+}
+func (m *VerifyAPIKeyRequest) XXX_Size() int {
+	return xxx_messageInfo_VerifyAPIKeyRequest.Size(m)
+}
+func (m *VerifyAPIKeyRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_VerifyAPIKeyRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_VerifyAPIKeyRequest proto.InternalMessageInfo
+
+func (m *VerifyAPIKeyRequest) GetApiKey() string {
+	if m != nil {
+		return m.ApiKey
+	}
+	return ""
+}
+
+func (m *VerifyAPIKeyRequest) GetMethod() string {
+	if m != nil {
+		return m.Method
+	}
+	return ""
+}
+
+type VerifyAPIKeyResponse struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name                 string   `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Roles                []string `protobuf:"bytes,3,rep,name=roles,proto3" json:"roles,omitempty"`
+	Scopes               []string `protobuf:"bytes,4,rep,name=scopes,proto3" json:"scopes,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *VerifyAPIKeyResponse) Reset()         { *m = VerifyAPIKeyResponse{} }
+func (m *VerifyAPIKeyResponse) String() string { return proto.CompactTextString(m) }
+func (*VerifyAPIKeyResponse) ProtoMessage()    {}
+func (*VerifyAPIKeyResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6991cbd76a21bcaf, []int{21}
+}
+
+func (m *VerifyAPIKeyResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_VerifyAPIKeyResponse.Unmarshal(m, b)
+}
+func (m *VerifyAPIKeyResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_VerifyAPIKeyResponse.Marshal(b, m, deterministic)
+}
+func (m *VerifyAPIKeyResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_VerifyAPIKeyResponse.Merge(m, src)
+}
+func (m *VerifyAPIKeyResponse) XXX_Size() int {
+	return xxx_messageInfo_VerifyAPIKeyResponse.Size(m)
+}
+func (m *VerifyAPIKeyResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_VerifyAPIKeyResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_VerifyAPIKeyResponse proto.InternalMessageInfo
+
+func (m *VerifyAPIKeyResponse) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *VerifyAPIKeyResponse) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *VerifyAPIKeyResponse) GetRoles() []string {
+	if m != nil {
+		return m.Roles
+	}
+	return nil
+}
+
+func (m *VerifyAPIKeyResponse) GetScopes() []string {
+	if m != nil {
+		return m.Scopes
+	}
+	return nil
+}
+
+// Token exchange, lets a service call another service on behalf of the user (or API key) that called it
+type ExchangeTokenRequest struct {
+	SubjectToken         string   `protobuf:"bytes,1,opt,name=subject_token,json=subjectToken,proto3" json:"subject_token,omitempty"`
+	Audience             string   `protobuf:"bytes,2,opt,name=audience,proto3" json:"audience,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ExchangeTokenRequest) Reset()         { *m = ExchangeTokenRequest{} }
+func (m *ExchangeTokenRequest) String() string { return proto.CompactTextString(m) }
+func (*ExchangeTokenRequest) ProtoMessage()    {}
+func (*ExchangeTokenRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6991cbd76a21bcaf, []int{22}
+}
+
+func (m *ExchangeTokenRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ExchangeTokenRequest.Unmarshal(m, b)
+}
+func (m *ExchangeTokenRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ExchangeTokenRequest.Marshal(b, m, deterministic)
+}
+func (m *ExchangeTokenRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ExchangeTokenRequest.Merge(m, src)
+}
+func (m *ExchangeTokenRequest) XXX_Size() int {
+	return xxx_messageInfo_ExchangeTokenRequest.Size(m)
+}
+func (m *ExchangeTokenRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ExchangeTokenRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ExchangeTokenRequest proto.InternalMessageInfo
+
+func (m *ExchangeTokenRequest) GetSubjectToken() string {
+	if m != nil {
+		return m.SubjectToken
+	}
+	return ""
+}
+
+func (m *ExchangeTokenRequest) GetAudience() string {
+	if m != nil {
+		return m.Audience
+	}
+	return ""
+}
+
+type ExchangeTokenResponse struct {
+	AccessToken          string   `protobuf:"bytes,1,opt,name=access_token,json=accessToken,proto3" json:"access_token,omitempty"`
+	ExpiresAt            int64    `protobuf:"varint,2,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ExchangeTokenResponse) Reset()         { *m = ExchangeTokenResponse{} }
+func (m *ExchangeTokenResponse) String() string { return proto.CompactTextString(m) }
+func (*ExchangeTokenResponse) ProtoMessage()    {}
+func (*ExchangeTokenResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6991cbd76a21bcaf, []int{23}
+}
+
+func (m *ExchangeTokenResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ExchangeTokenResponse.Unmarshal(m, b)
+}
+func (m *ExchangeTokenResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ExchangeTokenResponse.Marshal(b, m, deterministic)
+}
+func (m *ExchangeTokenResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ExchangeTokenResponse.Merge(m, src)
+}
+func (m *ExchangeTokenResponse) XXX_Size() int {
+	return xxx_messageInfo_ExchangeTokenResponse.Size(m)
+}
+func (m *ExchangeTokenResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ExchangeTokenResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ExchangeTokenResponse proto.InternalMessageInfo
+
+func (m *ExchangeTokenResponse) GetAccessToken() string {
+	if m != nil {
+		return m.AccessToken
+	}
+	return ""
+}
+
+func (m *ExchangeTokenResponse) GetExpiresAt() int64 {
+	if m != nil {
+		return m.ExpiresAt
+	}
+	return 0
+}
+
+// Audit log. Times are Unix timestamps (in seconds), zero if unset
+type AuditEvent struct {
+	Time                 int64    `protobuf:"varint,1,opt,name=time,proto3" json:"time,omitempty"`
+	Service              string   `protobuf:"bytes,2,opt,name=service,proto3" json:"service,omitempty"`
+	Event                string   `protobuf:"bytes,3,opt,name=event,proto3" json:"event,omitempty"`
+	User                 string   `protobuf:"bytes,4,opt,name=user,proto3" json:"user,omitempty"`
+	Kind                 string   `protobuf:"bytes,5,opt,name=kind,proto3" json:"kind,omitempty"`
+	Roles                []string `protobuf:"bytes,6,rep,name=roles,proto3" json:"roles,omitempty"`
+	Actor                string   `protobuf:"bytes,7,opt,name=actor,proto3" json:"actor,omitempty"`
+	Method               string   `protobuf:"bytes,8,opt,name=method,proto3" json:"method,omitempty"`
+	Peer                 string   `protobuf:"bytes,9,opt,name=peer,proto3" json:"peer,omitempty"`
+	Decision             string   `protobuf:"bytes,10,opt,name=decision,proto3" json:"decision,omitempty"`
+	Reason               string   `protobuf:"bytes,11,opt,name=reason,proto3" json:"reason,omitempty"`
+	RequestId            string   `protobuf:"bytes,12,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	Target               string   `protobuf:"bytes,13,opt,name=target,proto3" json:"target,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AuditEvent) Reset()         { *m = AuditEvent{} }
+func (m *AuditEvent) String() string { return proto.CompactTextString(m) }
+func (*AuditEvent) ProtoMessage()    {}
+func (*AuditEvent) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6991cbd76a21bcaf, []int{24}
+}
+
+func (m *AuditEvent) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AuditEvent.Unmarshal(m, b)
+}
+func (m *AuditEvent) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AuditEvent.Marshal(b, m, deterministic)
+}
+func (m *AuditEvent) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AuditEvent.Merge(m, src)
+}
+func (m *AuditEvent) XXX_Size() int {
+	return xxx_messageInfo_AuditEvent.Size(m)
+}
+func (m *AuditEvent) XXX_DiscardUnknown() {
+	xxx_messageInfo_AuditEvent.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AuditEvent proto.InternalMessageInfo
+
+func (m *AuditEvent) GetTime() int64 {
+	if m != nil {
+		return m.Time
+	}
+	return 0
+}
+
+func (m *AuditEvent) GetService() string {
+	if m != nil {
+		return m.Service
+	}
+	return ""
+}
+
+func (m *AuditEvent) GetEvent() string {
+	if m != nil {
+		return m.Event
+	}
+	return ""
+}
+
+func (m *AuditEvent) GetUser() string {
+	if m != nil {
+		return m.User
+	}
+	return ""
+}
+
+func (m *AuditEvent) GetKind() string {
+	if m != nil {
+		return m.Kind
+	}
+	return ""
+}
+
+func (m *AuditEvent) GetRoles() []string {
+	if m != nil {
+		return m.Roles
+	}
+	return nil
+}
+
+func (m *AuditEvent) GetActor() string {
+	if m != nil {
+		return m.Actor
+	}
+	return ""
+}
+
+func (m *AuditEvent) GetMethod() string {
+	if m != nil {
+		return m.Method
+	}
+	return ""
+}
+
+func (m *AuditEvent) GetPeer() string {
+	if m != nil {
+		return m.Peer
+	}
+	return ""
+}
+
+func (m *AuditEvent) GetDecision() string {
+	if m != nil {
+		return m.Decision
+	}
+	return ""
+}
+
+func (m *AuditEvent) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+func (m *AuditEvent) GetRequestId() string {
+	if m != nil {
+		return m.RequestId
+	}
+	return ""
+}
+
+func (m *AuditEvent) GetTarget() string {
+	if m != nil {
+		return m.Target
+	}
+	return ""
+}
+
+type QueryAuditLogRequest struct {
+	Since                int64    `protobuf:"varint,1,opt,name=since,proto3" json:"since,omitempty"`
+	Until                int64    `protobuf:"varint,2,opt,name=until,proto3" json:"until,omitempty"`
+	Service              string   `protobuf:"bytes,3,opt,name=service,proto3" json:"service,omitempty"`
+	Event                string   `protobuf:"bytes,4,opt,name=event,proto3" json:"event,omitempty"`
+	User                 string   `protobuf:"bytes,5,opt,name=user,proto3" json:"user,omitempty"`
+	Method               string   `protobuf:"bytes,6,opt,name=method,proto3" json:"method,omitempty"`
+	Decision             string   `protobuf:"bytes,7,opt,name=decision,proto3" json:"decision,omitempty"`
+	Limit                int32    `protobuf:"varint,8,opt,name=limit,proto3" json:"limit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *QueryAuditLogRequest) Reset()         { *m = QueryAuditLogRequest{} }
+func (m *QueryAuditLogRequest) String() string { return proto.CompactTextString(m) }
+func (*QueryAuditLogRequest) ProtoMessage()    {}
+func (*QueryAuditLogRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6991cbd76a21bcaf, []int{25}
+}
+
+func (m *QueryAuditLogRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_QueryAuditLogRequest.Unmarshal(m, b)
+}
+func (m *QueryAuditLogRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_QueryAuditLogRequest.Marshal(b, m, deterministic)
+}
+func (m *QueryAuditLogRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_QueryAuditLogRequest.Merge(m, src)
+}
+func (m *QueryAuditLogRequest) XXX_Size() int {
+	return xxx_messageInfo_QueryAuditLogRequest.Size(m)
+}
+func (m *QueryAuditLogRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_QueryAuditLogRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_QueryAuditLogRequest proto.InternalMessageInfo
+
+func (m *QueryAuditLogRequest) GetSince() int64 {
+	if m != nil {
+		return m.Since
+	}
+	return 0
+}
+
+func (m *QueryAuditLogRequest) GetUntil() int64 {
+	if m != nil {
+		return m.Until
+	}
+	return 0
+}
+
+func (m *QueryAuditLogRequest) GetService() string {
+	if m != nil {
+		return m.Service
+	}
+	return ""
+}
+
+func (m *QueryAuditLogRequest) GetEvent() string {
+	if m != nil {
+		return m.Event
+	}
+	return ""
+}
+
+func (m *QueryAuditLogRequest) GetUser() string {
+	if m != nil {
+		return m.User
+	}
+	return ""
+}
+
+func (m *QueryAuditLogRequest) GetMethod() string {
+	if m != nil {
+		return m.Method
+	}
+	return ""
+}
+
+func (m *QueryAuditLogRequest) GetDecision() string {
+	if m != nil {
+		return m.Decision
+	}
+	return ""
+}
+
+func (m *QueryAuditLogRequest) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+type QueryAuditLogResponse struct {
+	Events               []*AuditEvent `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *QueryAuditLogResponse) Reset()         { *m = QueryAuditLogResponse{} }
+func (m *QueryAuditLogResponse) String() string { return proto.CompactTextString(m) }
+func (*QueryAuditLogResponse) ProtoMessage()    {}
+func (*QueryAuditLogResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6991cbd76a21bcaf, []int{26}
+}
+
+func (m *QueryAuditLogResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_QueryAuditLogResponse.Unmarshal(m, b)
+}
+func (m *QueryAuditLogResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_QueryAuditLogResponse.Marshal(b, m, deterministic)
+}
+func (m *QueryAuditLogResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_QueryAuditLogResponse.Merge(m, src)
+}
+func (m *QueryAuditLogResponse) XXX_Size() int {
+	return xxx_messageInfo_QueryAuditLogResponse.Size(m)
+}
+func (m *QueryAuditLogResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_QueryAuditLogResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_QueryAuditLogResponse proto.InternalMessageInfo
+
+func (m *QueryAuditLogResponse) GetEvents() []*AuditEvent {
+	if m != nil {
+		return m.Events
+	}
+	return nil
+}
+
+// Sessions, one per login. Times are Unix timestamps (in seconds), zero if unset
+type Session struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Username             string   `protobuf:"bytes,2,opt,name=username,proto3" json:"username,omitempty"`
+	Client               string   `protobuf:"bytes,3,opt,name=client,proto3" json:"client,omitempty"`
+	Via                  string   `protobuf:"bytes,4,opt,name=via,proto3" json:"via,omitempty"`
+	IssuedAt             int64    `protobuf:"varint,5,opt,name=issued_at,json=issuedAt,proto3" json:"issued_at,omitempty"`
+	ExpiresAt            int64    `protobuf:"varint,6,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	LastUsed             int64    `protobuf:"varint,7,opt,name=last_used,json=lastUsed,proto3" json:"last_used,omitempty"`
+	TerminatedAt         int64    `protobuf:"varint,8,opt,name=terminated_at,json=terminatedAt,proto3" json:"terminated_at,omitempty"`
+	TerminatedBy         string   `protobuf:"bytes,9,opt,name=terminated_by,json=terminatedBy,proto3" json:"terminated_by,omitempty"`
+	Current              bool     `protobuf:"varint,10,opt,name=current,proto3" json:"current,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Session) Reset()         { *m = Session{} }
+func (m *Session) String() string { return proto.CompactTextString(m) }
+func (*Session) ProtoMessage()    {}
+func (*Session) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6991cbd76a21bcaf, []int{27}
+}
+
+func (m *Session) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Session.Unmarshal(m, b)
+}
+func (m *Session) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Session.Marshal(b, m, deterministic)
+}
+func (m *Session) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Session.Merge(m, src)
+}
+func (m *Session) XXX_Size() int {
+	return xxx_messageInfo_Session.Size(m)
+}
+func (m *Session) XXX_DiscardUnknown() {
+	xxx_messageInfo_Session.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Session proto.InternalMessageInfo
+
+func (m *Session) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Session) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
+}
+
+func (m *Session) GetClient() string {
+	if m != nil {
+		return m.Client
+	}
+	return ""
+}
+
+func (m *Session) GetVia() string {
+	if m != nil {
+		return m.Via
+	}
+	return ""
+}
+
+func (m *Session) GetIssuedAt() int64 {
+	if m != nil {
+		return m.IssuedAt
+	}
+	return 0
+}
+
+func (m *Session) GetExpiresAt() int64 {
+	if m != nil {
+		return m.ExpiresAt
+	}
+	return 0
+}
+
+func (m *Session) GetLastUsed() int64 {
+	if m != nil {
+		return m.LastUsed
+	}
+	return 0
+}
+
+func (m *Session) GetTerminatedAt() int64 {
+	if m != nil {
+		return m.TerminatedAt
+	}
+	return 0
+}
+
+func (m *Session) GetTerminatedBy() string {
+	if m != nil {
+		return m.TerminatedBy
+	}
+	return ""
+}
+
+func (m *Session) GetCurrent() bool {
+	if m != nil {
+		return m.Current
+	}
+	return false
+}
+
+type ListSessionsRequest struct {
+	Username             string   `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListSessionsRequest) Reset()         { *m = ListSessionsRequest{} }
+func (m *ListSessionsRequest) String() string { return proto.CompactTextString(m) }
+func (*ListSessionsRequest) ProtoMessage()    {}
+func (*ListSessionsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6991cbd76a21bcaf, []int{28}
+}
+
+func (m *ListSessionsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListSessionsRequest.Unmarshal(m, b)
+}
+func (m *ListSessionsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListSessionsRequest.Marshal(b, m, deterministic)
+}
+func (m *ListSessionsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListSessionsRequest.Merge(m, src)
+}
+func (m *ListSessionsRequest) XXX_Size() int {
+	return xxx_messageInfo_ListSessionsRequest.Size(m)
+}
+func (m *ListSessionsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListSessionsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListSessionsRequest proto.InternalMessageInfo
+
+func (m *ListSessionsRequest) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
+}
+
+type ListSessionsResponse struct {
+	Sessions             []*Session `protobuf:"bytes,1,rep,name=sessions,proto3" json:"sessions,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
+func (m *ListSessionsResponse) Reset()         { *m = ListSessionsResponse{} }
+func (m *ListSessionsResponse) String() string { return proto.CompactTextString(m) }
+func (*ListSessionsResponse) ProtoMessage()    {}
+func (*ListSessionsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6991cbd76a21bcaf, []int{29}
+}
+
+func (m *ListSessionsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListSessionsResponse.Unmarshal(m, b)
+}
+func (m *ListSessionsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListSessionsResponse.Marshal(b, m, deterministic)
+}
+func (m *ListSessionsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListSessionsResponse.Merge(m, src)
+}
+func (m *ListSessionsResponse) XXX_Size() int {
+	return xxx_messageInfo_ListSessionsResponse.Size(m)
+}
+func (m *ListSessionsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListSessionsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListSessionsResponse proto.InternalMessageInfo
+
+func (m *ListSessionsResponse) GetSessions() []*Session {
+	if m != nil {
+		return m.Sessions
+	}
+	return nil
+}
+
+type TerminateSessionRequest struct {
+	SessionId            string   `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Username             string   `protobuf:"bytes,2,opt,name=username,proto3" json:"username,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TerminateSessionRequest) Reset()         { *m = TerminateSessionRequest{} }
+func (m *TerminateSessionRequest) String() string { return proto.CompactTextString(m) }
+func (*TerminateSessionRequest) ProtoMessage()    {}
+func (*TerminateSessionRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6991cbd76a21bcaf, []int{30}
+}
+
+func (m *TerminateSessionRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TerminateSessionRequest.Unmarshal(m, b)
+}
+func (m *TerminateSessionRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TerminateSessionRequest.Marshal(b, m, deterministic)
+}
+func (m *TerminateSessionRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TerminateSessionRequest.Merge(m, src)
+}
+func (m *TerminateSessionRequest) XXX_Size() int {
+	return xxx_messageInfo_TerminateSessionRequest.Size(m)
+}
+func (m *TerminateSessionRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_TerminateSessionRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TerminateSessionRequest proto.InternalMessageInfo
+
+func (m *TerminateSessionRequest) GetSessionId() string {
+	if m != nil {
+		return m.SessionId
+	}
+	return ""
+}
+
+func (m *TerminateSessionRequest) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
+}
+
+type TerminateSessionResponse struct {
+	Sessions             []*Session `protobuf:"bytes,1,rep,name=sessions,proto3" json:"sessions,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
+func (m *TerminateSessionResponse) Reset()         { *m = TerminateSessionResponse{} }
+func (m *TerminateSessionResponse) String() string { return proto.CompactTextString(m) }
+func (*TerminateSessionResponse) ProtoMessage()    {}
+func (*TerminateSessionResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6991cbd76a21bcaf, []int{31}
+}
+
+func (m *TerminateSessionResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TerminateSessionResponse.Unmarshal(m, b)
+}
+func (m *TerminateSessionResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TerminateSessionResponse.Marshal(b, m, deterministic)
+}
+func (m *TerminateSessionResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TerminateSessionResponse.Merge(m, src)
+}
+func (m *TerminateSessionResponse) XXX_Size() int {
+	return xxx_messageInfo_TerminateSessionResponse.Size(m)
+}
+func (m *TerminateSessionResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_TerminateSessionResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TerminateSessionResponse proto.InternalMessageInfo
+
+func (m *TerminateSessionResponse) GetSessions() []*Session {
+	if m != nil {
+		return m.Sessions
+	}
+	return nil
+}
+
+type VerifySessionRequest struct {
+	SessionId            string   `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *VerifySessionRequest) Reset()         { *m = VerifySessionRequest{} }
+func (m *VerifySessionRequest) String() string { return proto.CompactTextString(m) }
+func (*VerifySessionRequest) ProtoMessage()    {}
+func (*VerifySessionRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6991cbd76a21bcaf, []int{32}
+}
+
+func (m *VerifySessionRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_VerifySessionRequest.Unmarshal(m, b)
+}
+func (m *VerifySessionRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_VerifySessionRequest.Marshal(b, m, deterministic)
+}
+func (m *VerifySessionRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_VerifySessionRequest.Merge(m, src)
+}
+func (m *VerifySessionRequest) XXX_Size() int {
+	return xxx_messageInfo_VerifySessionRequest.Size(m)
+}
+func (m *VerifySessionRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_VerifySessionRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_VerifySessionRequest proto.InternalMessageInfo
+
+func (m *VerifySessionRequest) GetSessionId() string {
+	if m != nil {
+		return m.SessionId
+	}
+	return ""
+}
+
+type VerifySessionResponse struct {
+	Username             string   `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *VerifySessionResponse) Reset()         { *m = VerifySessionResponse{} }
+func (m *VerifySessionResponse) String() string { return proto.CompactTextString(m) }
+func (*VerifySessionResponse) ProtoMessage()    {}
+func (*VerifySessionResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6991cbd76a21bcaf, []int{33}
+}
+
+func (m *VerifySessionResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_VerifySessionResponse.Unmarshal(m, b)
+}
+func (m *VerifySessionResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_VerifySessionResponse.Marshal(b, m, deterministic)
+}
+func (m *VerifySessionResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_VerifySessionResponse.Merge(m, src)
+}
+func (m *VerifySessionResponse) XXX_Size() int {
+	return xxx_messageInfo_VerifySessionResponse.Size(m)
+}
+func (m *VerifySessionResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_VerifySessionResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_VerifySessionResponse proto.InternalMessageInfo
+
+func (m *VerifySessionResponse) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
+}
+
 func init() {
-	proto.RegisterType((*LoginAuthRequest)(nil), "LoginAuthRequest")
-	proto.RegisterType((*LoginAuthResponse)(nil), "LoginAuthResponse")
+	proto.RegisterType((*LoginAuthRequest)(nil), "authentication.LoginAuthRequest")
+	proto.RegisterType((*LoginAuthResponse)(nil), "authentication.LoginAuthResponse")
+	proto.RegisterType((*TOTPEnrolment)(nil), "authentication.TOTPEnrolment")
+	proto.RegisterType((*VerifyTOTPRequest)(nil), "authentication.VerifyTOTPRequest")
+	proto.RegisterType((*VerifyTOTPResponse)(nil), "authentication.VerifyTOTPResponse")
+	proto.RegisterType((*EnrolTOTPRequest)(nil), "authentication.EnrolTOTPRequest")
+	proto.RegisterType((*EnrolTOTPResponse)(nil), "authentication.EnrolTOTPResponse")
+	proto.RegisterType((*ConfirmTOTPRequest)(nil), "authentication.ConfirmTOTPRequest")
+	proto.RegisterType((*ConfirmTOTPResponse)(nil), "authentication.ConfirmTOTPResponse")
+	proto.RegisterType((*ResetTOTPRequest)(nil), "authentication.ResetTOTPRequest")
+	proto.RegisterType((*ResetTOTPResponse)(nil), "authentication.ResetTOTPResponse")
+	proto.RegisterType((*UnlockAccountRequest)(nil), "authentication.UnlockAccountRequest")
+	proto.RegisterType((*UnlockAccountResponse)(nil), "authentication.UnlockAccountResponse")
+	proto.RegisterType((*APIKey)(nil), "authentication.APIKey")
+	proto.RegisterType((*CreateAPIKeyRequest)(nil), "authentication.CreateAPIKeyRequest")
+	proto.RegisterType((*CreateAPIKeyResponse)(nil), "authentication.CreateAPIKeyResponse")
+	proto.RegisterType((*ListAPIKeysRequest)(nil), "authentication.ListAPIKeysRequest")
+	proto.RegisterType((*ListAPIKeysResponse)(nil), "authentication.ListAPIKeysResponse")
+	proto.RegisterType((*RevokeAPIKeyRequest)(nil), "authentication.RevokeAPIKeyRequest")
+	proto.RegisterType((*RevokeAPIKeyResponse)(nil), "authentication.RevokeAPIKeyResponse")
+	proto.RegisterType((*VerifyAPIKeyRequest)(nil), "authentication.VerifyAPIKeyRequest")
+	proto.RegisterType((*VerifyAPIKeyResponse)(nil), "authentication.VerifyAPIKeyResponse")
+	proto.RegisterType((*ExchangeTokenRequest)(nil), "authentication.ExchangeTokenRequest")
+	proto.RegisterType((*ExchangeTokenResponse)(nil), "authentication.ExchangeTokenResponse")
+	proto.RegisterType((*AuditEvent)(nil), "authentication.AuditEvent")
+	proto.RegisterType((*QueryAuditLogRequest)(nil), "authentication.QueryAuditLogRequest")
+	proto.RegisterType((*QueryAuditLogResponse)(nil), "authentication.QueryAuditLogResponse")
+	proto.RegisterType((*Session)(nil), "authentication.Session")
+	proto.RegisterType((*ListSessionsRequest)(nil), "authentication.ListSessionsRequest")
+	proto.RegisterType((*ListSessionsResponse)(nil), "authentication.ListSessionsResponse")
+	proto.RegisterType((*TerminateSessionRequest)(nil), "authentication.TerminateSessionRequest")
+	proto.RegisterType((*TerminateSessionResponse)(nil), "authentication.TerminateSessionResponse")
+	proto.RegisterType((*VerifySessionRequest)(nil), "authentication.VerifySessionRequest")
+	proto.RegisterType((*VerifySessionResponse)(nil), "authentication.VerifySessionResponse")
 }
 
 func init() {
@@ -124,19 +1849,96 @@ func init() {
 }
 
 var fileDescriptor_6991cbd76a21bcaf = []byte{
-	// 215 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0xb2, 0x4a, 0x2c, 0x2d, 0xc9,
-	0x48, 0xcd, 0x2b, 0xc9, 0x4c, 0x4e, 0x2c, 0xc9, 0xcc, 0xcf, 0x0b, 0x4e, 0x2d, 0x2a, 0xcb, 0x4c,
-	0x4e, 0xd5, 0x2f, 0x28, 0xca, 0x2f, 0xc9, 0xd7, 0xc7, 0x2a, 0xe7, 0x18, 0xe0, 0xa9, 0x07, 0x96,
-	0x56, 0xf2, 0xe2, 0x12, 0xf0, 0xc9, 0x4f, 0xcf, 0xcc, 0x73, 0x2c, 0x2d, 0xc9, 0x08, 0x4a, 0x2d,
-	0x2c, 0x4d, 0x2d, 0x2e, 0x11, 0x92, 0xe2, 0xe2, 0x28, 0x2d, 0x4e, 0x2d, 0xca, 0x4b, 0xcc, 0x4d,
-	0x95, 0x60, 0x54, 0x60, 0xd4, 0xe0, 0x0c, 0x82, 0xf3, 0x41, 0x72, 0x05, 0x89, 0xc5, 0xc5, 0xe5,
-	0xf9, 0x45, 0x29, 0x12, 0x4c, 0x10, 0x39, 0x18, 0x5f, 0x29, 0x82, 0x4b, 0x10, 0xc9, 0xac, 0xe2,
-	0x82, 0xfc, 0xbc, 0xe2, 0x54, 0x21, 0x05, 0x2e, 0xee, 0x82, 0xd4, 0xa2, 0xdc, 0xcc, 0xe2, 0xe2,
-	0xcc, 0xfc, 0xbc, 0x62, 0xa8, 0x79, 0xc8, 0x42, 0x42, 0x8a, 0x5c, 0x3c, 0x89, 0xc9, 0xc9, 0xa9,
-	0xc5, 0xc5, 0xf1, 0x25, 0xf9, 0xd9, 0xa9, 0x79, 0x50, 0x63, 0xb9, 0x21, 0x62, 0x21, 0x20, 0x21,
-	0x23, 0x5f, 0x2e, 0x51, 0x47, 0x6c, 0xfe, 0x10, 0x32, 0xe1, 0xe2, 0x84, 0x5b, 0x29, 0x24, 0xa8,
-	0x87, 0xee, 0x15, 0x29, 0x21, 0x3d, 0x0c, 0x17, 0x29, 0x31, 0x38, 0x19, 0x47, 0x19, 0xe2, 0x09,
-	0x32, 0x6b, 0xac, 0x72, 0x49, 0x6c, 0x60, 0x49, 0x63, 0x40, 0x00, 0x00, 0x00, 0xff, 0xff, 0xd2,
-	0x72, 0x28, 0x30, 0x6e, 0x01, 0x00, 0x00,
+	// 1445 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xc4, 0x58, 0xef, 0x72, 0xdb, 0x44,
+	0x10, 0xc7, 0x76, 0xfc, 0x47, 0xeb, 0xa4, 0x24, 0x17, 0xa7, 0xd5, 0x88, 0xe9, 0x90, 0x28, 0xc9,
+	0x60, 0xf8, 0x90, 0x4e, 0x93, 0xe1, 0x0b, 0xe5, 0x03, 0x6e, 0xa6, 0xcc, 0x84, 0x64, 0xa6, 0x41,
+	0x4d, 0xa0, 0xd0, 0x19, 0x3c, 0x8a, 0x74, 0x71, 0x8e, 0xd8, 0x92, 0x7b, 0x77, 0x0a, 0xf5, 0x33,
+	0xf0, 0x0a, 0x3c, 0x03, 0x2f, 0xc0, 0x4b, 0xf0, 0x3e, 0xf0, 0x81, 0xb9, 0x3f, 0x52, 0x4e, 0x92,
+	0x23, 0x37, 0xf0, 0x81, 0x6f, 0xda, 0xdd, 0xdf, 0xed, 0xed, 0xfd, 0x76, 0x6f, 0x6f, 0x6d, 0xf8,
+	0xc2, 0x4f, 0xf8, 0x15, 0x8e, 0x38, 0x09, 0x7c, 0x4e, 0xe2, 0xe8, 0x15, 0xa6, 0x37, 0x24, 0xc0,
+	0x4f, 0xa6, 0x34, 0xe6, 0xf1, 0x93, 0xb9, 0xb6, 0xc1, 0xe9, 0xd1, 0x9e, 0x34, 0xa3, 0x07, 0x79,
+	0xbb, 0xfb, 0x0d, 0xac, 0x9e, 0xc4, 0x23, 0x12, 0x0d, 0x12, 0x7e, 0xe5, 0xe1, 0xb7, 0x09, 0x66,
+	0x1c, 0x39, 0xd0, 0x49, 0x18, 0xa6, 0x91, 0x3f, 0xc1, 0x76, 0x6d, 0xb3, 0xd6, 0xb7, 0xbc, 0x4c,
+	0x16, 0xb6, 0xa9, 0xcf, 0xd8, 0x2f, 0x31, 0x0d, 0xed, 0xba, 0xb2, 0xa5, 0xb2, 0xfb, 0x6b, 0x1d,
+	0xd6, 0x0c, 0x67, 0x6c, 0x1a, 0x47, 0x0c, 0xa3, 0x4d, 0xe8, 0x4e, 0x31, 0x9d, 0x10, 0xc6, 0x48,
+	0x1c, 0x31, 0xed, 0xd0, 0x54, 0xa1, 0x2d, 0x58, 0xf6, 0x83, 0x00, 0x33, 0x36, 0xe4, 0xf1, 0x35,
+	0x8e, 0xb4, 0xdf, 0xae, 0xd2, 0x9d, 0x09, 0x15, 0xea, 0x41, 0x93, 0xc6, 0x63, 0xcc, 0xec, 0xc6,
+	0x66, 0xa3, 0x6f, 0x79, 0x4a, 0x40, 0x0f, 0xa1, 0xc5, 0x82, 0x78, 0x8a, 0x99, 0xbd, 0x24, 0xd5,
+	0x5a, 0x12, 0x0e, 0x27, 0x97, 0xfe, 0x90, 0xe2, 0xb7, 0x09, 0xa1, 0x38, 0xb4, 0x9b, 0x9b, 0xb5,
+	0x7e, 0xc7, 0xeb, 0x4e, 0x2e, 0x7d, 0x4f, 0xab, 0xd0, 0x36, 0xac, 0x4c, 0x7d, 0xca, 0x89, 0x3f,
+	0xd6, 0x9b, 0xb6, 0xe4, 0xa6, 0xcb, 0x5a, 0xa9, 0x76, 0x7d, 0x06, 0x16, 0x8e, 0x68, 0x3c, 0x9e,
+	0xe0, 0x88, 0xdb, 0xed, 0xcd, 0x5a, 0xbf, 0xbb, 0xff, 0x78, 0x2f, 0x4f, 0xe0, 0xde, 0xd9, 0xcb,
+	0xb3, 0xd3, 0x17, 0x29, 0xc8, 0xbb, 0xc5, 0xbb, 0x1e, 0xac, 0xe4, 0x6c, 0x32, 0x5a, 0x1c, 0x50,
+	0xcc, 0x35, 0x07, 0x5a, 0x42, 0x9f, 0xc2, 0xea, 0x94, 0xc6, 0x37, 0x44, 0x90, 0x41, 0xa2, 0xd1,
+	0x30, 0xa1, 0x44, 0x53, 0xf0, 0xa1, 0xa9, 0x3f, 0xa7, 0xc4, 0x3d, 0x81, 0xb5, 0xef, 0x30, 0x25,
+	0x97, 0x33, 0xe1, 0x39, 0x4d, 0x57, 0xe9, 0x28, 0xb5, 0x39, 0x47, 0x41, 0xb0, 0x14, 0xc4, 0x21,
+	0xd6, 0x8e, 0xe5, 0xb7, 0xfb, 0x7b, 0x0d, 0x90, 0xe9, 0xee, 0xff, 0x4b, 0xd8, 0x2e, 0x3c, 0xa0,
+	0x38, 0x88, 0x6f, 0x30, 0x9d, 0x0d, 0x45, 0x68, 0xcc, 0x6e, 0x4a, 0xfb, 0x4a, 0xaa, 0x3d, 0x14,
+	0x4a, 0x17, 0xc1, 0xaa, 0xa4, 0xd3, 0x38, 0xbd, 0x7b, 0x0a, 0x6b, 0x86, 0x4e, 0x1f, 0x21, 0x97,
+	0xb8, 0xda, 0x3d, 0x13, 0xd7, 0x07, 0x74, 0x18, 0x47, 0x97, 0x84, 0x4e, 0x4c, 0x96, 0x53, 0x02,
+	0x6b, 0x06, 0x81, 0x5f, 0xc2, 0x7a, 0x0e, 0xa9, 0x77, 0x2f, 0x9f, 0xa6, 0x36, 0xef, 0x34, 0x7b,
+	0xb0, 0xea, 0x61, 0x86, 0xb9, 0xb9, 0x4b, 0xc5, 0xd5, 0x73, 0x9f, 0xc0, 0x9a, 0x81, 0xd7, 0x7b,
+	0x55, 0x2d, 0xd8, 0x87, 0xde, 0x79, 0x34, 0x8e, 0x83, 0xeb, 0x41, 0x10, 0xc4, 0x49, 0xc4, 0xdf,
+	0x67, 0x93, 0x03, 0xd8, 0x28, 0xac, 0x79, 0x8f, 0x8d, 0xfe, 0xae, 0x41, 0x6b, 0x70, 0x7a, 0x74,
+	0x8c, 0x67, 0xe8, 0x01, 0xd4, 0x49, 0xa8, 0x01, 0x75, 0x12, 0x0a, 0xda, 0xe4, 0x12, 0x5d, 0x77,
+	0xe2, 0xfb, 0x8e, 0xda, 0x78, 0x0c, 0x10, 0x50, 0xec, 0x73, 0x1c, 0x0e, 0x2f, 0x66, 0xf6, 0x92,
+	0xc4, 0x5b, 0x5a, 0xf3, 0x7c, 0x66, 0x9a, 0x7d, 0x2e, 0x6f, 0x74, 0x23, 0x33, 0x0f, 0xb8, 0x30,
+	0xe3, 0x77, 0x53, 0x42, 0x31, 0x13, 0xe6, 0x96, 0x32, 0x6b, 0x8d, 0x32, 0x53, 0x7c, 0x13, 0x5f,
+	0xab, 0xd5, 0x6d, 0x65, 0xd6, 0x9a, 0x01, 0x47, 0x1f, 0x81, 0x35, 0xf6, 0x19, 0x1f, 0x26, 0x0c,
+	0x87, 0x76, 0x47, 0x5a, 0x3b, 0x42, 0x71, 0xce, 0x70, 0x88, 0x3e, 0x86, 0x6e, 0xc2, 0xfc, 0x11,
+	0x1e, 0x4a, 0x42, 0x6c, 0x4b, 0x9a, 0x41, 0xaa, 0x0e, 0x85, 0xc6, 0x7d, 0x03, 0xeb, 0x87, 0x32,
+	0x10, 0xc5, 0x81, 0x51, 0x31, 0x06, 0x5b, 0x85, 0xa3, 0xd7, 0xcd, 0xa3, 0x3b, 0xd0, 0x19, 0x93,
+	0x4b, 0xcc, 0xc9, 0x04, 0xdb, 0x0d, 0xbd, 0xbb, 0x96, 0xdd, 0xd7, 0xd0, 0xcb, 0x3b, 0xd7, 0xf9,
+	0xe8, 0x43, 0xe3, 0x1a, 0xcf, 0x74, 0x71, 0x3f, 0x2c, 0x16, 0xb7, 0x06, 0x0b, 0x88, 0xd1, 0x77,
+	0xea, 0x66, 0xdf, 0x71, 0x7b, 0x80, 0x4e, 0x08, 0xe3, 0x0a, 0xca, 0xd2, 0xfb, 0x34, 0x80, 0xf5,
+	0x9c, 0x56, 0x6f, 0xf7, 0x19, 0x2c, 0x5d, 0xe3, 0x99, 0xaa, 0xe4, 0xbb, 0xf7, 0x93, 0x18, 0x77,
+	0x17, 0xd6, 0x3d, 0x49, 0x6d, 0x9e, 0x8f, 0x42, 0x69, 0xb8, 0x5f, 0x41, 0x2f, 0x0f, 0xbb, 0xef,
+	0xc9, 0xdc, 0xaf, 0x61, 0x5d, 0xf5, 0xaf, 0xfc, 0x46, 0x8f, 0xa0, 0xed, 0x4f, 0xc9, 0x30, 0x75,
+	0x62, 0x79, 0x2d, 0x7f, 0x4a, 0x8e, 0x15, 0x13, 0x13, 0xcc, 0xaf, 0xe2, 0xf4, 0xe9, 0xd2, 0x92,
+	0x7b, 0x05, 0xbd, 0xbc, 0x1f, 0x1d, 0xc9, 0xbf, 0x2f, 0xe6, 0x3b, 0x1a, 0x9d, 0xfb, 0x3d, 0xf4,
+	0x5e, 0xbc, 0x0b, 0xae, 0xfc, 0x68, 0x84, 0x65, 0x9f, 0x34, 0x7a, 0x38, 0x4b, 0x2e, 0x7e, 0xc6,
+	0x01, 0xcf, 0xf7, 0x70, 0xad, 0x94, 0x58, 0x51, 0x26, 0x7e, 0x12, 0x12, 0x1c, 0x05, 0x69, 0x08,
+	0x99, 0xec, 0xfe, 0x00, 0x1b, 0x05, 0xc7, 0xfa, 0x0c, 0xc5, 0x5e, 0x5d, 0x2b, 0xf7, 0xea, 0xfc,
+	0xdd, 0xa9, 0x17, 0xee, 0x8e, 0xfb, 0x47, 0x1d, 0x60, 0x90, 0x84, 0x84, 0xbf, 0xb8, 0xc1, 0x91,
+	0x2c, 0x6b, 0x59, 0xa8, 0x35, 0x89, 0x93, 0xdf, 0xc8, 0x86, 0x36, 0x53, 0x93, 0x86, 0x0e, 0x2c,
+	0x15, 0x05, 0x3d, 0x58, 0x2c, 0x93, 0x75, 0x6d, 0x79, 0x4d, 0x9c, 0xfa, 0x10, 0xcd, 0x43, 0xdf,
+	0x72, 0xf9, 0x2d, 0x74, 0xd7, 0x24, 0x52, 0x8f, 0xb5, 0xe5, 0xc9, 0xef, 0x5b, 0x72, 0x5b, 0x26,
+	0xb9, 0x3d, 0x68, 0xfa, 0x01, 0x8f, 0xa9, 0xbc, 0xc7, 0x96, 0xa7, 0x04, 0x23, 0xb9, 0x1d, 0x33,
+	0xb9, 0xc2, 0xef, 0x14, 0x63, 0x2a, 0xef, 0xad, 0xe5, 0xc9, 0x6f, 0xc1, 0x64, 0x88, 0x03, 0xf9,
+	0xb2, 0xda, 0xa0, 0x98, 0x4c, 0x65, 0xe1, 0x87, 0x62, 0x9f, 0xc5, 0x91, 0xdd, 0x55, 0x7e, 0x94,
+	0xa4, 0x5a, 0x88, 0xcc, 0xd6, 0x90, 0x84, 0xf6, 0xb2, 0xb4, 0x59, 0x5a, 0x73, 0x14, 0x8a, 0x65,
+	0xdc, 0xa7, 0x23, 0xcc, 0xed, 0x15, 0xb5, 0x4c, 0x49, 0xee, 0x9f, 0x35, 0xe8, 0x7d, 0x9b, 0x60,
+	0x3a, 0x93, 0x14, 0x9e, 0xc4, 0xa3, 0x34, 0xe5, 0x3d, 0x68, 0x32, 0x12, 0x05, 0x29, 0x91, 0x4a,
+	0x10, 0xda, 0x24, 0xe2, 0x64, 0xac, 0xd3, 0xa0, 0x04, 0x93, 0xdf, 0xc6, 0x1d, 0xfc, 0x2e, 0xcd,
+	0xe3, 0xb7, 0x69, 0xf0, 0x7b, 0xcb, 0x4f, 0x2b, 0xc7, 0x8f, 0xc9, 0x45, 0xbb, 0xc0, 0x45, 0x0f,
+	0x9a, 0x63, 0x32, 0x21, 0x5c, 0x52, 0xda, 0xf4, 0x94, 0xe0, 0x1e, 0xc3, 0x46, 0xe1, 0x44, 0xba,
+	0xd6, 0xf6, 0xa1, 0x25, 0xf7, 0x4f, 0xdb, 0x84, 0x53, 0xba, 0xbc, 0x59, 0x19, 0x79, 0x1a, 0xe9,
+	0xfe, 0x56, 0x87, 0xf6, 0x2b, 0x2c, 0x07, 0x8b, 0xd2, 0x7d, 0x33, 0xdf, 0x9c, 0x7a, 0x61, 0x10,
+	0x7d, 0x08, 0xad, 0x60, 0x4c, 0x6e, 0x2b, 0x4b, 0x4b, 0x68, 0x15, 0x1a, 0x37, 0xc4, 0xd7, 0x74,
+	0x88, 0x4f, 0xd1, 0xdc, 0x09, 0x63, 0x89, 0xf9, 0x70, 0x74, 0x94, 0x62, 0xf1, 0xbb, 0x91, 0x7b,
+	0x18, 0xda, 0x85, 0x87, 0x61, 0x1b, 0x56, 0xb8, 0x98, 0x8a, 0xa2, 0xf4, 0x55, 0x52, 0x2f, 0xc7,
+	0xf2, 0xad, 0x72, 0xc0, 0x0b, 0xa0, 0x8b, 0x99, 0xae, 0x43, 0x03, 0xf4, 0x7c, 0x26, 0xf2, 0x1b,
+	0x24, 0x94, 0x8a, 0xd3, 0x80, 0x9c, 0x55, 0x53, 0xd1, 0x7d, 0xaa, 0xda, 0xb1, 0x66, 0x88, 0xbd,
+	0xcf, 0x13, 0x7e, 0x0c, 0xbd, 0xfc, 0x12, 0x9d, 0x9d, 0x03, 0xe8, 0x30, 0x9c, 0x0d, 0x75, 0x22,
+	0x3f, 0x8f, 0x8a, 0xf9, 0xd1, 0x6b, 0xbc, 0x0c, 0xe8, 0x9e, 0xc1, 0xa3, 0xb3, 0x34, 0xd2, 0xd4,
+	0xaa, 0x63, 0x78, 0x0c, 0xa0, 0x61, 0xc3, 0x2c, 0x6b, 0x96, 0xd6, 0x1c, 0x55, 0x26, 0xcf, 0x7d,
+	0x09, 0x76, 0xd9, 0xeb, 0x7f, 0x09, 0xf3, 0xf3, 0xb4, 0x83, 0xdf, 0x2b, 0x46, 0x31, 0xed, 0x14,
+	0x96, 0x2d, 0x9e, 0x76, 0xf6, 0xff, 0x02, 0xd8, 0x18, 0xcc, 0xfb, 0x95, 0x85, 0x3c, 0xb0, 0xb2,
+	0xdf, 0x3f, 0x68, 0xb3, 0x18, 0x75, 0xf1, 0x77, 0x96, 0xb3, 0x55, 0x81, 0x50, 0x71, 0xb8, 0x1f,
+	0xa0, 0x73, 0x80, 0xdb, 0x19, 0x1d, 0x95, 0x96, 0x94, 0x7e, 0x0e, 0x38, 0x6e, 0x15, 0x24, 0x73,
+	0xeb, 0x81, 0x95, 0x8d, 0xcd, 0xe5, 0x50, 0x8b, 0x53, 0xb6, 0xb3, 0x55, 0x81, 0xc8, 0x7c, 0xbe,
+	0x86, 0xae, 0x31, 0x0e, 0xa3, 0x52, 0x20, 0xe5, 0xa9, 0xda, 0xd9, 0xae, 0xc4, 0x64, 0x9e, 0x7f,
+	0x82, 0x95, 0xdc, 0x54, 0x8a, 0x76, 0x8a, 0xeb, 0xe6, 0x0d, 0xba, 0xce, 0xee, 0x02, 0x94, 0xc9,
+	0x46, 0x36, 0x5a, 0x97, 0xd9, 0x28, 0x4e, 0xe9, 0xce, 0x56, 0x05, 0x22, 0xf3, 0xf9, 0x06, 0x96,
+	0xcd, 0xc1, 0x0d, 0x95, 0x8f, 0x5a, 0x9e, 0x19, 0x9d, 0x9d, 0x6a, 0x90, 0x49, 0xb5, 0x31, 0xa5,
+	0x95, 0xa9, 0x2e, 0x0f, 0x76, 0xce, 0x76, 0x25, 0xc6, 0x0c, 0xdb, 0x9c, 0xca, 0xca, 0x61, 0xcf,
+	0x19, 0xed, 0x9c, 0x9d, 0x6a, 0x90, 0xe9, 0xdc, 0x1c, 0xb4, 0xca, 0xce, 0xe7, 0x8c, 0x73, 0xce,
+	0x4e, 0x35, 0xc8, 0x2c, 0x92, 0xdc, 0x08, 0x54, 0x2e, 0x92, 0x79, 0xa3, 0x97, 0xb3, 0xbb, 0x00,
+	0x65, 0xfa, 0xcf, 0x3d, 0x7b, 0x65, 0xff, 0xf3, 0xde, 0x79, 0x67, 0x77, 0x01, 0xca, 0x24, 0xc7,
+	0xec, 0xdb, 0x68, 0x6e, 0xc2, 0x0a, 0x0f, 0x81, 0xb3, 0x53, 0x0d, 0xca, 0x9c, 0x8f, 0x60, 0xb5,
+	0xd8, 0x71, 0xd1, 0x27, 0xc5, 0xb5, 0x77, 0x74, 0x7a, 0xa7, 0xbf, 0x18, 0x68, 0xb2, 0x94, 0x6b,
+	0xa9, 0xe8, 0x8e, 0xf4, 0x15, 0xb6, 0xd8, 0x5d, 0x80, 0x4a, 0xfd, 0x3f, 0x3f, 0xf8, 0xf1, 0x69,
+	0xc5, 0xdf, 0x5f, 0xcf, 0xe6, 0xda, 0x2e, 0x5a, 0xd2, 0x78, 0xf0, 0xcf, 0x00, 0x8d, 0x87, 0x75,
+	0xea, 0x3a, 0x13, 0x00, 0x00,
 }
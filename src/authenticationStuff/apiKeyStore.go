@@ -0,0 +1,173 @@
+package authentication
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+type APIKeyStore interface {
+	/* This interface describes a persistent store of API keys. Find returns a nil key
+	(and no error) if the key doesn't exist */
+	Find(id string) (*APIKey, error)
+	Save(apiKey *APIKey) error
+	Update(id string, update func(apiKey *APIKey) error) error // Atomically read, modify and save a key
+	List() ([]*APIKey, error)
+}
+
+type FileAPIKeyStore struct {
+	/* This struct is an APIKeyStore that keeps its keys in memory and persists them to a
+	JSON file on every change */
+	path  string
+	mutex sync.Mutex
+	keys  map[string]*APIKey
+}
+
+func NewFileAPIKeyStore(path string) (*FileAPIKeyStore, error) {
+	/* This function opens the key store at the provided path. If the file doesn't exist
+	yet, an empty store is created and will be written on the first save */
+	store := &FileAPIKeyStore{
+		path: path,
+		keys: map[string]*APIKey{},
+	}
+
+	contents, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("could not read api key store: %v", err)
+	}
+
+	var keys []*APIKey
+	if err := json.Unmarshal(contents, &keys); err != nil {
+		return nil, fmt.Errorf("could not decode api key store: %v", err)
+	}
+	for _, apiKey := range keys {
+		store.keys[apiKey.ID] = apiKey
+	}
+
+	return store, nil
+}
+
+func (store *FileAPIKeyStore) Find(id string) (*APIKey, error) {
+	// This function returns a copy of the key with the provided ID, or nil if it doesn't exist
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	apiKey, ok := store.keys[id]
+	if !ok {
+		return nil, nil
+	}
+
+	return apiKey.copy(), nil
+}
+
+func (store *FileAPIKeyStore) Save(apiKey *APIKey) error {
+	// This function adds or replaces a key and persists the store
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	previous, existed := store.keys[apiKey.ID]
+	store.keys[apiKey.ID] = apiKey.copy()
+	if err := store.persist(); err != nil {
+		// Roll back so that memory and disk don't disagree
+		if existed {
+			store.keys[apiKey.ID] = previous
+		} else {
+			delete(store.keys, apiKey.ID)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (store *FileAPIKeyStore) Update(id string, update func(apiKey *APIKey) error) error {
+	/* This function applies the provided update to a key and persists the result. The
+	store is locked for the duration, so concurrent updates to the same key can't be lost */
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	previous, ok := store.keys[id]
+	if !ok {
+		return ErrAPIKeyNotFound
+	}
+
+	updated := previous.copy()
+	if err := update(updated); err != nil {
+		return err
+	}
+
+	store.keys[id] = updated
+	if err := store.persist(); err != nil {
+		store.keys[id] = previous
+		return err
+	}
+
+	return nil
+}
+
+func (store *FileAPIKeyStore) List() ([]*APIKey, error) {
+	// This function returns a copy of every key in the store, oldest first
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	keys := make([]*APIKey, 0, len(store.keys))
+	for _, apiKey := range store.keys {
+		keys = append(keys, apiKey.copy())
+	}
+	sortAPIKeys(keys)
+
+	return keys, nil
+}
+
+func (store *FileAPIKeyStore) persist() error {
+	/* This (unexported) function writes the store to disk. The file is written next to
+	the store and renamed over it, so a crash never leaves a half-written store behind.
+	The caller must hold the store's mutex */
+	keys := make([]*APIKey, 0, len(store.keys))
+	for _, apiKey := range store.keys {
+		keys = append(keys, apiKey)
+	}
+	sortAPIKeys(keys)
+
+	contents, err := json.MarshalIndent(keys, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not encode api key store: %v", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(store.path), 0700); err != nil {
+		return fmt.Errorf("could not create api key store directory: %v", err)
+	}
+	temporaryPath := store.path + ".tmp"
+	if err := ioutil.WriteFile(temporaryPath, contents, 0600); err != nil {
+		return fmt.Errorf("could not write api key store: %v", err)
+	}
+	if err := os.Rename(temporaryPath, store.path); err != nil {
+		return fmt.Errorf("could not replace api key store: %v", err)
+	}
+
+	return nil
+}
+
+func (apiKey *APIKey) copy() *APIKey {
+	// This (unexported) function returns a deep copy of the key, so that callers can't modify the store's copy
+	duplicate := *apiKey
+	duplicate.Roles = append([]string(nil), apiKey.Roles...)
+
+	return &duplicate
+}
+
+func sortAPIKeys(keys []*APIKey) {
+	// This (unexported) function orders keys by creation time, breaking ties by ID
+	sort.Slice(keys, func(i, j int) bool {
+		if !keys[i].CreatedAt.Equal(keys[j].CreatedAt) {
+			return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+		}
+		return keys[i].ID < keys[j].ID
+	})
+}
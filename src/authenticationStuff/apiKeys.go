@@ -0,0 +1,130 @@
+package authentication
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+/* API keys let scripts and scheduled jobs call the services without a user's password.
+A key looks like "msk_<id>.<secret>": the ID identifies the key in the store and the
+secret is only ever shown once, when the key is created. Only a SHA-256 hash of the secret
+is stored. Unlike passwords the secrets are long and random, so a slow hash isn't needed
+to protect them, and verifying them on every request stays cheap */
+
+const apiKeyPrefix = "msk_"
+
+var (
+	// ErrAPIKeyNotFound is returned by APIKeyStore.Update when the key doesn't exist
+	ErrAPIKeyNotFound = errors.New("api key not found")
+	// ErrAPIKeyInvalid is returned when a key is malformed, unknown, revoked or doesn't match its hash
+	ErrAPIKeyInvalid = errors.New("api key is invalid")
+	// ErrAPIKeyExpired is returned when an otherwise valid key has expired
+	ErrAPIKeyExpired = errors.New("api key has expired")
+)
+
+type APIKey struct {
+	/* This struct describes an API key as it is kept in the key store. A zero ExpiresAt
+	means the key never expires, and a zero RevokedAt means it hasn't been revoked */
+	ID           string
+	Name         string // A description of what the key is used for
+	HashedSecret string
+	Roles        []string
+	CreatedBy    string
+	CreatedAt    time.Time
+	ExpiresAt    time.Time
+	RevokedAt    time.Time
+	LastUsed     time.Time
+	UsageCount   int64
+}
+
+type APIKeyVerifier interface {
+	/* This interface describes something that can check an API key presented for the
+	provided method, returning the caller it belongs to. Failures are returned as gRPC
+	status errors that can be passed straight back to the client */
+	VerifyAPIKey(ctx context.Context, key string, method string) (*Caller, error)
+}
+
+func GenerateAPIKey(name string, roles []string, createdBy string, lifetime time.Duration) (string, *APIKey, error) {
+	/* This function creates a new API key granting the provided roles. It returns the
+	full key, which has to be handed to the user as it can't be recovered later, along with
+	the record to be kept in the key store. A lifetime of zero creates a key that never
+	expires */
+	id, err := randomBytes(8)
+	if err != nil {
+		return "", nil, err
+	}
+	secretBytes, err := randomBytes(32)
+	if err != nil {
+		return "", nil, err
+	}
+	secret := base64.RawURLEncoding.EncodeToString(secretBytes)
+
+	now := time.Now()
+	apiKey := &APIKey{
+		ID:           hex.EncodeToString(id),
+		Name:         name,
+		HashedSecret: hashSecret(secret),
+		Roles:        append([]string{}, roles...),
+		CreatedBy:    createdBy,
+		CreatedAt:    now,
+	}
+	if lifetime > 0 {
+		apiKey.ExpiresAt = now.Add(lifetime)
+	}
+
+	return apiKeyPrefix + apiKey.ID + "." + secret, apiKey, nil
+}
+
+func SplitAPIKey(key string) (string, string, error) {
+	// This function splits a full API key into its ID and secret
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return "", "", ErrAPIKeyInvalid
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(key, apiKeyPrefix), ".", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", ErrAPIKeyInvalid
+	}
+
+	return parts[0], parts[1], nil
+}
+
+func (apiKey *APIKey) Check(secret string, now time.Time) error {
+	/* This function checks the provided secret against the key's hash, and that the key
+	is neither revoked nor expired */
+	if subtle.ConstantTimeCompare([]byte(hashSecret(secret)), []byte(apiKey.HashedSecret)) != 1 {
+		return ErrAPIKeyInvalid
+	}
+	if !apiKey.RevokedAt.IsZero() {
+		return ErrAPIKeyInvalid
+	}
+	if !apiKey.ExpiresAt.IsZero() && now.After(apiKey.ExpiresAt) {
+		return ErrAPIKeyExpired
+	}
+
+	return nil
+}
+
+func hashSecret(secret string) string {
+	// This (unexported) function returns the hex-encoded SHA-256 hash of an API key's secret
+	hash := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(hash[:])
+}
+
+func randomBytes(length int) ([]byte, error) {
+	// This (unexported) function returns the provided number of cryptographically random bytes
+	bytes := make([]byte, length)
+	if _, err := rand.Read(bytes); err != nil {
+		return nil, fmt.Errorf("could not generate random bytes: %v", err)
+	}
+
+	return bytes, nil
+}
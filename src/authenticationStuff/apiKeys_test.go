@@ -0,0 +1,93 @@
+package authentication
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAPIKeys(t *testing.T) {
+	key, apiKey, err := GenerateAPIKey("nightly report", []string{"analyst"}, "admin", time.Hour)
+	if err != nil {
+		t.Fatal("Failed to generate API key: ", err)
+	}
+	id, secret, err := SplitAPIKey(key)
+	if err != nil || id != apiKey.ID {
+		t.Fatal("Failed to split a generated key: ", err)
+	}
+	now := time.Now()
+
+	var Tests = []struct {
+		name           string
+		secret         string
+		modify         func(apiKey *APIKey)
+		expectedOutput error
+	}{
+		{"The generated secret is accepted", secret, func(apiKey *APIKey) {}, nil},
+		{"Wrong secrets are rejected", secret + "x", func(apiKey *APIKey) {}, ErrAPIKeyInvalid},
+		{"Revoked keys are rejected", secret, func(apiKey *APIKey) { apiKey.RevokedAt = now }, ErrAPIKeyInvalid},
+		{"Expired keys are rejected", secret, func(apiKey *APIKey) { apiKey.ExpiresAt = now.Add(-time.Second) }, ErrAPIKeyExpired},
+		{"Keys without an expiry never expire", secret, func(apiKey *APIKey) { apiKey.ExpiresAt = time.Time{} }, nil},
+	}
+
+	for _, test := range Tests {
+		t.Run(test.name, func(t *testing.T) {
+			modified := apiKey.copy()
+			test.modify(modified)
+			if output := modified.Check(test.secret, now); output != test.expectedOutput {
+				t.Error("Check failed.\n Expected ", test.expectedOutput, ", received ", output)
+			}
+		})
+	}
+
+	t.Run("Malformed keys are rejected", func(t *testing.T) {
+		for _, malformed := range []string{"", apiKey.ID, "msk_" + apiKey.ID, "msk_." + secret, "other_" + apiKey.ID + "." + secret} {
+			if _, _, err := SplitAPIKey(malformed); err != ErrAPIKeyInvalid {
+				t.Error("Expected ", malformed, " to be rejected")
+			}
+		}
+	})
+}
+
+func TestFileAPIKeyStore(t *testing.T) {
+	storePath := filepath.Join(t.TempDir(), "keys", "apiKeys.json")
+	store, err := NewFileAPIKeyStore(storePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, apiKey, err := GenerateAPIKey("nightly report", []string{"analyst"}, "admin", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(apiKey); err != nil {
+		t.Fatal("Failed to save API key: ", err)
+	}
+	if err := store.Update(apiKey.ID, func(apiKey *APIKey) error {
+		apiKey.UsageCount++
+		return nil
+	}); err != nil {
+		t.Fatal("Failed to update API key: ", err)
+	}
+
+	// Reopen the store to make sure the changes were persisted
+	reopened, err := NewFileAPIKeyStore(storePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, err := reopened.Find(apiKey.ID)
+	if err != nil || stored == nil {
+		t.Fatal("Failed to find the saved API key: ", err)
+	}
+	if stored.UsageCount != 1 || !reflect.DeepEqual(stored.Roles, apiKey.Roles) || stored.HashedSecret != apiKey.HashedSecret {
+		t.Error("The stored API key does not match the saved one: ", stored)
+	}
+
+	if missing, err := reopened.Find("missing"); missing != nil || err != nil {
+		t.Error("Expected no key and no error for an unknown ID")
+	}
+	if err := reopened.Update("missing", func(apiKey *APIKey) error { return nil }); err != ErrAPIKeyNotFound {
+		t.Error("Expected ErrAPIKeyNotFound, received ", err)
+	}
+}
@@ -0,0 +1,330 @@
+package authentication
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nicholasbunn/mastersSandbox/src/logging"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+/* The audit log records who did what: every authorisation decision made by the services'
+interceptors, and every login, token issued and credential revoked by the authentication
+service. Each service appends one JSON object per line to its own file in a directory
+shared by the services, starting a new file every day (UTC) so that old entries can be
+removed once they are older than the retention period. Entries are never modified */
+
+// Audit events
+const (
+	AuditAuthorisation = "authorisation" // A request was allowed or denied by a service's authentication interceptor
+	AuditLogin         = "login"         // A password (first) login step succeeded or failed
+	AuditSecondFactor  = "second_factor" // A TOTP or recovery code was accepted or refused
+	AuditTokenIssued   = "token_issued"  // An access token or API key was issued
+	AuditRevocation    = "revocation"    // An API key or second factor was revoked
+	AuditAccount       = "account"       // An account was unlocked, or enrolled a second factor
+)
+
+// Audit decisions
+const (
+	AuditAllow = "allow"
+	AuditDeny  = "deny"
+)
+
+const auditDateLayout = "2006-01-02"
+
+type AuditEvent struct {
+	/* This struct describes a single audit log entry. User is whoever acted (the caller, or
+	the user logging in), as a username, API key ID or workload name. Target is the user or
+	API key that was acted on, if it isn't the user themselves */
+	Time      time.Time `json:"time"`
+	Service   string    `json:"service"`
+	Event     string    `json:"event"`
+	User      string    `json:"user,omitempty"`
+	Kind      string    `json:"kind,omitempty"` // CallerUser, CallerAPIKey or CallerWorkload
+	Roles     []string  `json:"roles,omitempty"`
+	Actor     string    `json:"actor,omitempty"` // The service that presented the user's exchanged token
+	Target    string    `json:"target,omitempty"`
+	Method    string    `json:"method,omitempty"`
+	Peer      string    `json:"peer,omitempty"`
+	Decision  string    `json:"decision"`
+	Reason    string    `json:"reason,omitempty"`
+	RequestID string    `json:"requestId,omitempty"`
+}
+
+type AuditQuery struct {
+	/* This struct describes which audit log entries to return. Empty fields match every
+	entry, User matches entries by or about the user, and Method may contain wildcards
+	(see path.Match) */
+	Since    time.Time
+	Until    time.Time
+	Service  string
+	Event    string
+	User     string
+	Method   string
+	Decision string
+	Limit    int // The maximum number of entries returned, the most recent ones are kept
+}
+
+type AuditLog struct {
+	/* This struct appends a service's audit events to its daily files in the audit
+	directory, and removes files that are older than the retention period. A nil AuditLog
+	discards every event, for services that don't keep an audit log */
+	directory string
+	service   string
+	retention time.Duration // Zero keeps every file
+
+	mutex sync.Mutex
+	file  *os.File
+	day   string
+}
+
+func NewAuditLog(directory string, service string, retention time.Duration) (*AuditLog, error) {
+	// This function opens the provided service's audit log in the provided directory, creating the directory if needed
+	if directory == "" || service == "" {
+		return nil, fmt.Errorf("an audit directory and service name are required")
+	}
+	if err := os.MkdirAll(directory, 0750); err != nil {
+		return nil, fmt.Errorf("could not create audit directory: %v", err)
+	}
+
+	auditLog := &AuditLog{directory: directory, service: service, retention: retention}
+	auditLog.mutex.Lock()
+	defer auditLog.mutex.Unlock()
+	if err := auditLog.open(time.Now()); err != nil {
+		return nil, err
+	}
+
+	return auditLog, nil
+}
+
+func (auditLog *AuditLog) Record(event AuditEvent) {
+	/* This function appends an event to the audit log, filling in its time and service.
+	Failing to write an entry is logged but never fails the request being audited */
+	if auditLog == nil {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	event.Time = event.Time.UTC()
+	event.Service = auditLog.service
+
+	line, err := json.Marshal(event)
+	if err != nil {
+		logging.Logger.Errorf("Could not encode audit event: %v", err)
+		return
+	}
+	line = append(line, '\n')
+
+	auditLog.mutex.Lock()
+	defer auditLog.mutex.Unlock()
+	if day := event.Time.Format(auditDateLayout); day != auditLog.day {
+		if err := auditLog.open(event.Time); err != nil {
+			logging.Logger.Errorf("Could not open audit log: %v", err)
+			return
+		}
+	}
+	if _, err := auditLog.file.Write(line); err != nil {
+		logging.Logger.Errorf("Could not write audit event: %v", err)
+	}
+}
+
+func (auditLog *AuditLog) Query(query AuditQuery) ([]AuditEvent, error) {
+	/* This function returns the entries matching the provided query from every service's
+	files in the audit directory, most recent first */
+	if auditLog == nil {
+		return nil, fmt.Errorf("audit log is not enabled")
+	}
+
+	files, err := filepath.Glob(filepath.Join(auditLog.directory, "*.jsonl"))
+	if err != nil {
+		return nil, fmt.Errorf("could not list audit files: %v", err)
+	}
+
+	var events []AuditEvent
+	for _, file := range files {
+		service, day, ok := parseAuditFileName(file)
+		if !ok || (query.Service != "" && service != query.Service) {
+			continue
+		}
+		// Skip files that can't hold entries in the requested period
+		if !query.Since.IsZero() && day.Add(24*time.Hour).Before(query.Since) {
+			continue
+		}
+		if !query.Until.IsZero() && day.After(query.Until) {
+			continue
+		}
+
+		matching, err := readAuditFile(file, query)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, matching...)
+	}
+
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Time.After(events[j].Time) })
+	if query.Limit > 0 && len(events) > query.Limit {
+		events = events[:query.Limit]
+	}
+
+	return events, nil
+}
+
+func (auditLog *AuditLog) Close() error {
+	// This function closes the audit log's current file, later events reopen it
+	if auditLog == nil {
+		return nil
+	}
+
+	auditLog.mutex.Lock()
+	defer auditLog.mutex.Unlock()
+	if auditLog.file == nil {
+		return nil
+	}
+	err := auditLog.file.Close()
+	auditLog.file = nil
+	auditLog.day = ""
+
+	return err
+}
+
+func (auditLog *AuditLog) open(now time.Time) error {
+	/* This (unexported) function switches to the file for the day of the provided time,
+	and removes the service's files that have passed the retention period. The caller
+	holds the mutex */
+	day := now.UTC().Format(auditDateLayout)
+	name := filepath.Join(auditLog.directory, auditLog.service+"-"+day+".jsonl")
+	file, err := os.OpenFile(name, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0640)
+	if err != nil {
+		return fmt.Errorf("could not open audit file: %v", err)
+	}
+
+	if auditLog.file != nil {
+		auditLog.file.Close()
+	}
+	auditLog.file = file
+	auditLog.day = day
+
+	auditLog.prune(now)
+	return nil
+}
+
+func (auditLog *AuditLog) prune(now time.Time) {
+	// This (unexported) function removes the service's files whose day ended longer ago than the retention period
+	if auditLog.retention <= 0 {
+		return
+	}
+
+	files, err := filepath.Glob(filepath.Join(auditLog.directory, auditLog.service+"-*.jsonl"))
+	if err != nil {
+		return
+	}
+	for _, file := range files {
+		service, day, ok := parseAuditFileName(file)
+		if !ok || service != auditLog.service {
+			continue
+		}
+		if now.Sub(day.Add(24*time.Hour)) > auditLog.retention {
+			if err := os.Remove(file); err != nil {
+				logging.Logger.Warnf("Could not remove expired audit file %v: %v", file, err)
+			}
+		}
+	}
+}
+
+func AuditEventFromContext(ctx context.Context, event string, method string) AuditEvent {
+	/* This function starts an audit event for a request, filling in the caller attached
+	by the authentication interceptor (if any), the address the request came from and the
+	request ID sent along with it (if any) */
+	auditEvent := AuditEvent{Event: event, Method: method}
+
+	if caller, ok := CallerFromContext(ctx); ok {
+		auditEvent.User = caller.ID
+		auditEvent.Kind = caller.Kind
+		auditEvent.Roles = caller.Roles
+		auditEvent.Actor = caller.Actor
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		auditEvent.Peer = p.Addr.String()
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok && len(md["x-request-id"]) > 0 {
+		auditEvent.RequestID = md["x-request-id"][0]
+	}
+
+	return auditEvent
+}
+
+func readAuditFile(name string, query AuditQuery) ([]AuditEvent, error) {
+	/* This (unexported) function returns the entries in the provided file that match the
+	query. Lines that can't be decoded (such as a line cut short by a crash) are skipped */
+	file, err := os.Open(name)
+	if os.IsNotExist(err) {
+		return nil, nil // Removed by another service's retention since it was listed
+	} else if err != nil {
+		return nil, fmt.Errorf("could not read audit file: %v", err)
+	}
+	defer file.Close()
+
+	var events []AuditEvent
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var event AuditEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			continue
+		}
+		if query.matches(event) {
+			events = append(events, event)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("could not read audit file: %v", err)
+	}
+
+	return events, nil
+}
+
+func (query AuditQuery) matches(event AuditEvent) bool {
+	// This (unexported) function reports whether an entry matches the query
+	if !query.Since.IsZero() && event.Time.Before(query.Since) {
+		return false
+	}
+	if !query.Until.IsZero() && event.Time.After(query.Until) {
+		return false
+	}
+	if query.Method != "" {
+		if matched, _ := path.Match(query.Method, event.Method); !matched {
+			return false
+		}
+	}
+
+	return (query.Service == "" || query.Service == event.Service) &&
+		(query.Event == "" || query.Event == event.Event) &&
+		(query.User == "" || query.User == event.User || query.User == event.Target) &&
+		(query.Decision == "" || query.Decision == event.Decision)
+}
+
+func parseAuditFileName(name string) (service string, day time.Time, ok bool) {
+	// This (unexported) function splits an audit file name ("service-2006-01-02.jsonl") into its service and day
+	base := strings.TrimSuffix(filepath.Base(name), ".jsonl")
+	if len(base) < len(auditDateLayout)+2 || base[len(base)-len(auditDateLayout)-1] != '-' {
+		return "", time.Time{}, false
+	}
+
+	day, err := time.Parse(auditDateLayout, base[len(base)-len(auditDateLayout):])
+	if err != nil {
+		return "", time.Time{}, false
+	}
+
+	return base[:len(base)-len(auditDateLayout)-1], day, true
+}
@@ -0,0 +1,131 @@
+package authentication
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAuditLog(t *testing.T) {
+	directory, err := ioutil.TempDir("", "audit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(directory)
+
+	gateway, err := NewAuditLog(directory, "desktopgateway", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer gateway.Close()
+	authService, err := NewAuditLog(directory, "authenticationservice", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer authService.Close()
+
+	now := time.Now().UTC()
+	authService.Record(AuditEvent{Time: now.Add(-3 * time.Minute), Event: AuditLogin, User: "analyst", Decision: AuditAllow})
+	gateway.Record(AuditEvent{Time: now.Add(-2 * time.Minute), Event: AuditAuthorisation, User: "analyst", Method: "/PowerEstimationServices/PowerEstimator", Decision: AuditAllow})
+	gateway.Record(AuditEvent{Time: now.Add(-time.Minute), Event: AuditAuthorisation, User: "guest", Method: "/PowerEstimationServices/PowerEstimationSP", Decision: AuditDeny, Reason: ReasonPermissionDenied})
+	authService.Record(AuditEvent{Time: now.Add(-30 * time.Second), Event: AuditAccount, User: "admin", Target: "guest", Decision: AuditAllow})
+
+	var Tests = []struct {
+		name     string
+		query    AuditQuery
+		expected []string // Users of the expected entries, most recent first
+	}{
+		{"Every service's entries are returned, most recent first", AuditQuery{}, []string{"admin", "guest", "analyst", "analyst"}},
+		{"Entries are filtered by service", AuditQuery{Service: "authenticationservice"}, []string{"admin", "analyst"}},
+		{"Entries are filtered by user and decision", AuditQuery{User: "guest", Decision: AuditDeny}, []string{"guest"}},
+		{"Entries about a user match the user", AuditQuery{User: "guest"}, []string{"admin", "guest"}},
+		{"Methods can contain wildcards", AuditQuery{Method: "/PowerEstimationServices/*"}, []string{"guest", "analyst"}},
+		{"Entries are filtered by time", AuditQuery{Since: now.Add(-150 * time.Second), Until: now.Add(-90 * time.Second)}, []string{"analyst"}},
+		{"The limit keeps the most recent entries", AuditQuery{Limit: 2}, []string{"admin", "guest"}},
+	}
+
+	for _, test := range Tests {
+		t.Run(test.name, func(t *testing.T) {
+			events, err := gateway.Query(test.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var users []string
+			for _, event := range events {
+				users = append(users, event.User)
+			}
+			if len(users) != len(test.expected) {
+				t.Fatal("Expected entries for ", test.expected, ", received ", users)
+			}
+			for index := range users {
+				if users[index] != test.expected[index] {
+					t.Error("Expected entries for ", test.expected, ", received ", users)
+				}
+			}
+		})
+	}
+
+	t.Run("Entries record the service that wrote them", func(t *testing.T) {
+		events, _ := gateway.Query(AuditQuery{Event: AuditLogin})
+		if len(events) != 1 || events[0].Service != "authenticationservice" {
+			t.Error("Expected the login to be recorded by the authentication service, received ", events)
+		}
+	})
+
+	t.Run("Malformed lines are skipped", func(t *testing.T) {
+		file, err := os.OpenFile(filepath.Join(directory, "desktopgateway-"+now.Format(auditDateLayout)+".jsonl"), os.O_APPEND|os.O_WRONLY, 0640)
+		if err != nil {
+			t.Fatal(err)
+		}
+		file.WriteString("{\"time\": \"2021-06-01T1")
+		file.Close()
+
+		if events, err := gateway.Query(AuditQuery{Service: "desktopgateway"}); err != nil || len(events) != 2 {
+			t.Error("Expected the two complete entries, received ", events, err)
+		}
+	})
+}
+
+func TestAuditLogRetention(t *testing.T) {
+	directory, err := ioutil.TempDir("", "audit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(directory)
+
+	now := time.Now().UTC()
+	expired := filepath.Join(directory, "desktopgateway-"+now.AddDate(0, 0, -10).Format(auditDateLayout)+".jsonl")
+	kept := filepath.Join(directory, "desktopgateway-"+now.AddDate(0, 0, -2).Format(auditDateLayout)+".jsonl")
+	otherService := filepath.Join(directory, "authenticationservice-"+now.AddDate(0, 0, -10).Format(auditDateLayout)+".jsonl")
+	for _, file := range []string{expired, kept, otherService} {
+		if err := ioutil.WriteFile(file, nil, 0640); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	auditLog, err := NewAuditLog(directory, "desktopgateway", 7*24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer auditLog.Close()
+
+	if _, err := os.Stat(expired); !os.IsNotExist(err) {
+		t.Error("Files older than the retention period should be removed")
+	}
+	if _, err := os.Stat(kept); err != nil {
+		t.Error("Files within the retention period should be kept")
+	}
+	if _, err := os.Stat(otherService); err != nil {
+		t.Error("Other services' files are left to their own retention")
+	}
+}
+
+func TestNilAuditLog(t *testing.T) {
+	var auditLog *AuditLog
+	auditLog.Record(AuditEvent{Event: AuditLogin}) // Should not panic
+	if _, err := auditLog.Query(AuditQuery{}); err == nil {
+		t.Error("Querying a disabled audit log should fail")
+	}
+}
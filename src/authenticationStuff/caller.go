@@ -0,0 +1,35 @@
+package authentication
+
+import "context"
+
+// The kinds of caller an authorised request can come from
+const (
+	CallerUser     = "user"     // A user, authenticated by their access token
+	CallerAPIKey   = "apikey"   // A script or scheduled job, authenticated by an API key
+	CallerWorkload = "workload" // Another service, authenticated by its client certificate
+)
+
+type Caller struct {
+	/* This struct describes who made an authorised request. The authentication
+	interceptor attaches it to the request's context so that services can tell who they
+	are serving */
+	Kind    string   // CallerUser, CallerAPIKey or CallerWorkload
+	ID      string   // The username, API key ID or workload name
+	Roles   []string // The roles the caller holds (empty for workloads)
+	Scopes  []string // The scopes the caller holds (empty for workloads)
+	Actor   string   // The service that presented a user's exchanged token on their behalf, if any
+	Session string   // The session the user's token belongs to, if any
+}
+
+type callerKey struct{}
+
+func ContextWithCaller(ctx context.Context, caller *Caller) context.Context {
+	// This function returns a copy of the context carrying the provided caller
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+func CallerFromContext(ctx context.Context) (*Caller, bool) {
+	// This function returns the caller attached to the context by the authentication interceptor, if any
+	caller, ok := ctx.Value(callerKey{}).(*Caller)
+	return caller, ok
+}
@@ -0,0 +1,257 @@
+package authentication
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+/* A development certificate authority issues the certificates the services use to
+encrypt and authenticate their connections. The same code is used by the certify command
+and by tests that need throwaway certificates */
+
+// Certificate usages, a certificate can be issued for either or both
+const (
+	ServerCertificate = 1 << iota // Presented by a server to its callers
+	ClientCertificate             // Presented by a client to the servers it calls
+)
+
+type CertificateAuthority struct {
+	// This struct holds a CA's certificate and the private key used to sign the certificates it issues
+	Certificate *x509.Certificate
+	Key         *ecdsa.PrivateKey
+}
+
+type CertificateRequest struct {
+	/* This struct describes a certificate to be issued. The common name identifies the
+	workload (see PeerIdentity) and the DNS names and IP addresses are the hosts the
+	certificate is valid for */
+	CommonName  string
+	DNSNames    []string
+	IPAddresses []net.IP
+	Usage       int           // ServerCertificate, ClientCertificate or both
+	Lifetime    time.Duration // How long the certificate is valid for
+}
+
+type IssuedCertificate struct {
+	// This struct holds a certificate issued by a CA along with its private key
+	Certificate *x509.Certificate
+	Key         *ecdsa.PrivateKey
+}
+
+func NewCertificateAuthority(commonName string, lifetime time.Duration) (*CertificateAuthority, error) {
+	// This function creates a self-signed CA that is valid for the provided lifetime
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("could not generate CA key: %v", err)
+	}
+
+	template, err := certificateTemplate(commonName, lifetime)
+	if err != nil {
+		return nil, err
+	}
+	template.IsCA = true
+	template.BasicConstraintsValid = true
+	template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign
+
+	certificate, err := createCertificate(template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CertificateAuthority{Certificate: certificate, Key: key}, nil
+}
+
+func ReadCertificateAuthority(certificateFile string, keyFile string) (*CertificateAuthority, error) {
+	// This function loads a CA's certificate and private key from PEM files
+	certificate, err := ReadCertificate(certificateFile)
+	if err != nil {
+		return nil, err
+	}
+	if !certificate.IsCA {
+		return nil, fmt.Errorf("%v is not a CA certificate", certificateFile)
+	}
+
+	key, err := readPrivateKey(keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CertificateAuthority{Certificate: certificate, Key: key}, nil
+}
+
+func (authority *CertificateAuthority) Issue(request CertificateRequest) (*IssuedCertificate, error) {
+	// This function issues a certificate, signed by the CA, as described by the provided request
+	if request.Usage&(ServerCertificate|ClientCertificate) == 0 {
+		return nil, fmt.Errorf("certificate for %v has no usage", request.CommonName)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("could not generate key for %v: %v", request.CommonName, err)
+	}
+
+	template, err := certificateTemplate(request.CommonName, request.Lifetime)
+	if err != nil {
+		return nil, err
+	}
+	template.DNSNames = request.DNSNames
+	template.IPAddresses = request.IPAddresses
+	template.KeyUsage = x509.KeyUsageDigitalSignature
+	if request.Usage&ServerCertificate != 0 {
+		template.ExtKeyUsage = append(template.ExtKeyUsage, x509.ExtKeyUsageServerAuth)
+	}
+	if request.Usage&ClientCertificate != 0 {
+		template.ExtKeyUsage = append(template.ExtKeyUsage, x509.ExtKeyUsageClientAuth)
+	}
+
+	// A certificate can't outlive the CA that issued it
+	if template.NotAfter.After(authority.Certificate.NotAfter) {
+		template.NotAfter = authority.Certificate.NotAfter
+	}
+
+	certificate, err := createCertificate(template, authority.Certificate, &key.PublicKey, authority.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &IssuedCertificate{Certificate: certificate, Key: key}, nil
+}
+
+func (authority *CertificateAuthority) Verify(certificate *x509.Certificate, usage x509.ExtKeyUsage) error {
+	// This function checks that the provided certificate was issued by the CA for the provided usage, and is still valid
+	roots := x509.NewCertPool()
+	roots.AddCert(authority.Certificate)
+
+	_, err := certificate.Verify(x509.VerifyOptions{
+		Roots:     roots,
+		KeyUsages: []x509.ExtKeyUsage{usage},
+	})
+
+	return err
+}
+
+func (authority *CertificateAuthority) Write(certificateFile string, keyFile string) error {
+	// This function writes the CA's certificate and private key to PEM files
+	return writeKeyPair(authority.Certificate, authority.Key, certificateFile, keyFile)
+}
+
+func (issued *IssuedCertificate) Write(certificateFile string, keyFile string) error {
+	// This function writes the certificate and its private key to PEM files
+	return writeKeyPair(issued.Certificate, issued.Key, certificateFile, keyFile)
+}
+
+func ReadCertificate(certificateFile string) (*x509.Certificate, error) {
+	// This function loads the first certificate in a PEM file
+	contents, err := ioutil.ReadFile(certificateFile)
+	if err != nil {
+		return nil, fmt.Errorf("could not read certificate: %v", err)
+	}
+
+	block, _ := pem.Decode(contents)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("%v does not contain a PEM certificate", certificateFile)
+	}
+
+	return x509.ParseCertificate(block.Bytes)
+}
+
+func certificateTemplate(commonName string, lifetime time.Duration) (*x509.Certificate, error) {
+	// This (unexported) function returns a certificate template with a random serial number and the provided validity
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("could not generate serial number: %v", err)
+	}
+
+	now := time.Now()
+	return &x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject: pkix.Name{
+			Country:            []string{"AQ"},
+			Province:           []string{"Queen Maud Land"},
+			Locality:           []string{"Vesleskarvet"},
+			Organization:       []string{"SANAP"},
+			OrganizationalUnit: []string{"Ship"},
+			CommonName:         commonName,
+		},
+		NotBefore: now.Add(-5 * time.Minute), // Allow for clock skew between containers
+		NotAfter:  now.Add(lifetime),
+	}, nil
+}
+
+func createCertificate(template *x509.Certificate, parent *x509.Certificate, publicKey *ecdsa.PublicKey, signer *ecdsa.PrivateKey) (*x509.Certificate, error) {
+	// This (unexported) function signs the template with the parent's key and parses the result
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, publicKey, signer)
+	if err != nil {
+		return nil, fmt.Errorf("could not create certificate for %v: %v", template.Subject.CommonName, err)
+	}
+
+	return x509.ParseCertificate(der)
+}
+
+func readPrivateKey(keyFile string) (*ecdsa.PrivateKey, error) {
+	// This (unexported) function loads an ECDSA private key from a PEM file
+	contents, err := ioutil.ReadFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("could not read private key: %v", err)
+	}
+
+	block, _ := pem.Decode(contents)
+	if block == nil {
+		return nil, fmt.Errorf("%v does not contain a PEM private key", keyFile)
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse private key from %v: %v", keyFile, err)
+	}
+	ecdsaKey, ok := key.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%v does not contain an ECDSA private key", keyFile)
+	}
+
+	return ecdsaKey, nil
+}
+
+func writeKeyPair(certificate *x509.Certificate, key *ecdsa.PrivateKey, certificateFile string, keyFile string) error {
+	/* This (unexported) function writes a certificate and its private key to PEM files.
+	Each file is replaced atomically, and the key is written first, so a service watching
+	the files never reads a half-written file (see CertificateManager) */
+	keyBytes, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return fmt.Errorf("could not encode private key: %v", err)
+	}
+
+	if err := writeFileAtomically(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyBytes}), 0600); err != nil {
+		return err
+	}
+
+	return writeFileAtomically(certificateFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate.Raw}), 0644)
+}
+
+func writeFileAtomically(path string, contents []byte, permissions os.FileMode) error {
+	// This (unexported) function writes the file next to its destination and renames it over the destination
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("could not create directory for %v: %v", path, err)
+	}
+
+	temporaryPath := path + ".tmp"
+	if err := ioutil.WriteFile(temporaryPath, contents, permissions); err != nil {
+		return fmt.Errorf("could not write %v: %v", path, err)
+	}
+	if err := os.Rename(temporaryPath, path); err != nil {
+		return fmt.Errorf("could not replace %v: %v", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package authentication
+
+import (
+	"crypto/ecdsa"
+	"crypto/x509"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestAuthority(t *testing.T) *CertificateAuthority {
+	// This helper creates a throwaway CA that is valid for an hour
+	authority, err := NewCertificateAuthority("Test CA", time.Hour)
+	if err != nil {
+		t.Fatal("Failed to create CA: ", err)
+	}
+
+	return authority
+}
+
+func issueTestCertificate(t *testing.T, authority *CertificateAuthority, request CertificateRequest) *IssuedCertificate {
+	// This helper issues a certificate that is valid for an hour
+	request.Lifetime = time.Hour
+	issued, err := authority.Issue(request)
+	if err != nil {
+		t.Fatal("Failed to issue certificate: ", err)
+	}
+
+	return issued
+}
+
+func writeTestCertificate(t *testing.T, directory string, name string, certificate *x509.Certificate, key *ecdsa.PrivateKey) (string, string) {
+	// This helper writes a certificate and its key to PEM files and returns their paths
+	certificatePath := filepath.Join(directory, name+"-cert.pem")
+	keyPath := filepath.Join(directory, name+"-key.pem")
+	if err := writeKeyPair(certificate, key, certificatePath, keyPath); err != nil {
+		t.Fatal("Failed to write certificate: ", err)
+	}
+
+	return certificatePath, keyPath
+}
+
+func TestCertificateAuthority(t *testing.T) {
+	directory := t.TempDir()
+	authority := newTestAuthority(t)
+
+	issued := issueTestCertificate(t, authority, CertificateRequest{
+		CommonName:  "desktopgateway",
+		DNSNames:    []string{"desktopgateway", "localhost"},
+		IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+		Usage:       ServerCertificate | ClientCertificate,
+	})
+
+	var Tests = []struct {
+		name           string
+		usage          x509.ExtKeyUsage
+		expectedOutput bool
+	}{
+		{"Server certificates verify for server authentication", x509.ExtKeyUsageServerAuth, true},
+		{"Client certificates verify for client authentication", x509.ExtKeyUsageClientAuth, true},
+		{"Certificates don't verify for other usages", x509.ExtKeyUsageCodeSigning, false},
+	}
+
+	for _, test := range Tests {
+		t.Run(test.name, func(t *testing.T) {
+			output := authority.Verify(issued.Certificate, test.usage) == nil
+			if output != test.expectedOutput {
+				t.Error("Verify failed for usage ", test.usage, ".\n Expected ", test.expectedOutput, ", received ", output)
+			}
+		})
+	}
+
+	t.Run("Certificates from another CA don't verify", func(t *testing.T) {
+		if err := newTestAuthority(t).Verify(issued.Certificate, x509.ExtKeyUsageServerAuth); err == nil {
+			t.Error("Expected a certificate from another CA to be rejected")
+		}
+	})
+
+	t.Run("Certificates can't outlive their CA", func(t *testing.T) {
+		longLived, err := authority.Issue(CertificateRequest{CommonName: "longLived", Usage: ClientCertificate, Lifetime: 24 * time.Hour})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if longLived.Certificate.NotAfter.After(authority.Certificate.NotAfter) {
+			t.Error("Certificate expires after its CA")
+		}
+	})
+
+	t.Run("Written CAs can be read back", func(t *testing.T) {
+		certificatePath, keyPath := writeTestCertificate(t, directory, "ca", authority.Certificate, authority.Key)
+		read, err := ReadCertificateAuthority(certificatePath, keyPath)
+		if err != nil {
+			t.Fatal("Failed to read CA: ", err)
+		}
+		if !read.Certificate.Equal(authority.Certificate) || !read.Key.Equal(authority.Key) {
+			t.Error("CA read back does not match the CA that was written")
+		}
+	})
+}
@@ -0,0 +1,308 @@
+package authentication
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/nicholasbunn/mastersSandbox/src/logging"
+	prometheus "github.com/prometheus/client_golang/prometheus"
+)
+
+/* Certificates on the ship are rotated without restarting the services. A certificate
+manager holds one side's certificate, key and CA, reloads them when the files change and
+hands them out per handshake, so new connections use the new certificates while existing
+connections are left alone */
+
+// certificateExpiryDays records the days left until each loaded certificate expires
+var certificateExpiryDays = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "certificate_expiry_days",
+		Help: "The number of days until the certificate expires",
+	}, []string{"certificate", "kind"})
+
+func CertificateMetrics() prometheus.Collector {
+	// This function returns the expiry gauge, so that a service can register it on its own metrics registry
+	return certificateExpiryDays
+}
+
+type CertificateManager struct {
+	/* This struct holds the active certificate, key and CA of one side of a TLS
+	connection and reloads them whenever their files change on disk */
+	ExpiryWarning time.Duration // How long before a certificate expires to start logging warnings
+
+	name         string
+	files        TLSFiles
+	mutex        sync.RWMutex
+	certificate  *tls.Certificate
+	leaf         *x509.Certificate
+	caPool       *x509.CertPool
+	caExpiry     time.Time
+	modTimes     map[string]time.Time
+	lastWarnings map[string]time.Time
+}
+
+func NewCertificateManager(name string, files TLSFiles) (*CertificateManager, error) {
+	/* This function loads the certificate, key and CA described by the provided files and
+	returns a manager for them. The name distinguishes the manager's certificates ("server",
+	"client") in logs and metrics */
+	manager := &CertificateManager{
+		ExpiryWarning: 30 * 24 * time.Hour,
+		name:          name,
+		files:         files,
+		lastWarnings:  map[string]time.Time{},
+	}
+	if err := manager.Reload(); err != nil {
+		return nil, err
+	}
+
+	return manager, nil
+}
+
+func (manager *CertificateManager) Reload() error {
+	/* This function re-reads the certificate, key and CA files. The active certificates
+	are only replaced if every file is valid (and the key matches the certificate), so a
+	rotation that is still in progress never leaves the service without certificates */
+	modTimes := map[string]time.Time{}
+	for _, file := range []string{manager.files.Certificate, manager.files.Key, manager.files.CA} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return fmt.Errorf("could not read %v certificate files: %v", manager.name, err)
+		}
+		modTimes[file] = info.ModTime()
+	}
+
+	certificate, err := tls.LoadX509KeyPair(manager.files.Certificate, manager.files.Key)
+	if err != nil {
+		return fmt.Errorf("could not load %v certificate: %v", manager.name, err)
+	}
+	leaf, err := x509.ParseCertificate(certificate.Certificate[0])
+	if err != nil {
+		return fmt.Errorf("could not parse %v certificate: %v", manager.name, err)
+	}
+
+	caPool, caExpiry, err := loadTrustedCertificates(manager.files.CA)
+	if err != nil {
+		return err
+	}
+
+	manager.mutex.Lock()
+	manager.certificate = &certificate
+	manager.leaf = leaf
+	manager.caPool = caPool
+	manager.caExpiry = caExpiry
+	manager.modTimes = modTimes
+	manager.mutex.Unlock()
+
+	manager.checkExpiry(time.Now())
+
+	return nil
+}
+
+func (manager *CertificateManager) Watch(interval time.Duration) (stop func()) {
+	/* This function polls the certificate, key and CA files every interval and reloads
+	them when any of them has been modified. Expiry is checked (and the expiry gauge
+	updated) on every poll. It returns a function that stops the watcher */
+	done := make(chan struct{})
+	ticker := time.NewTicker(interval)
+
+	manager.mutex.RLock()
+	lastSeen := map[string]time.Time{}
+	for file, modTime := range manager.modTimes {
+		lastSeen[file] = modTime
+	}
+	manager.mutex.RUnlock()
+
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				changed := false
+				for file, modTime := range lastSeen {
+					info, err := os.Stat(file)
+					if err != nil {
+						continue // The file may be mid-rotation, try again on the next poll
+					}
+					if !info.ModTime().Equal(modTime) {
+						lastSeen[file] = info.ModTime() // Only attempt each edit once, a bad file is reported a single time
+						changed = true
+					}
+				}
+
+				if changed {
+					if err := manager.Reload(); err != nil {
+						logging.Logger.Warnf("Could not reload %v certificates, keeping the current ones: %v", manager.name, err)
+					} else {
+						logging.Logger.Infof("Reloaded %v certificates", manager.name)
+					}
+				} else {
+					manager.checkExpiry(time.Now())
+				}
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
+
+func (manager *CertificateManager) Certificate() *x509.Certificate {
+	// This function returns the currently active (leaf) certificate
+	manager.mutex.RLock()
+	defer manager.mutex.RUnlock()
+
+	return manager.leaf
+}
+
+func (manager *CertificateManager) Valid(now time.Time) error {
+	/* This function returns an error if the active certificate or CA isn't valid at the
+	provided time, in which case handshakes with the service fail. Services report it in
+	their readiness */
+	manager.mutex.RLock()
+	defer manager.mutex.RUnlock()
+
+	switch {
+	case now.Before(manager.leaf.NotBefore):
+		return fmt.Errorf("the %v certificate isn't valid until %v", manager.name, manager.leaf.NotBefore.Format(time.RFC3339))
+	case now.After(manager.leaf.NotAfter):
+		return fmt.Errorf("the %v certificate expired on %v", manager.name, manager.leaf.NotAfter.Format(time.RFC3339))
+	case now.After(manager.caExpiry):
+		return fmt.Errorf("the %v CA certificate expired on %v", manager.name, manager.caExpiry.Format(time.RFC3339))
+	}
+
+	return nil
+}
+
+func (manager *CertificateManager) ServerTLSConfig() *tls.Config {
+	/* This function returns a TLS configuration for a server that picks up the active
+	certificate and CA for every new handshake. If the manager's files require it, callers
+	have to present a client certificate signed by the CA */
+	clientAuth := tls.VerifyClientCertIfGiven
+	if manager.files.RequireClientCertificate {
+		clientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			manager.mutex.RLock()
+			defer manager.mutex.RUnlock()
+
+			return &tls.Config{
+				Certificates: []tls.Certificate{*manager.certificate},
+				ClientCAs:    manager.caPool,
+				ClientAuth:   clientAuth,
+				MinVersion:   tls.VersionTLS12,
+				NextProtos:   []string{"h2"}, // gRPC runs over HTTP/2
+			}, nil
+		},
+	}
+}
+
+func (manager *CertificateManager) ClientTLSConfig() *tls.Config {
+	/* This function returns a TLS configuration for a client that verifies servers against
+	the active CA and presents the active certificate, both as they are at the time of each
+	handshake. Long-lived connections therefore trust a rotated CA when they reconnect */
+	return &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: true, // The built-in verification is fixed to RootCAs, servers are verified by VerifyConnection instead
+		VerifyConnection: func(state tls.ConnectionState) error {
+			manager.mutex.RLock()
+			caPool := manager.caPool
+			manager.mutex.RUnlock()
+
+			return verifyServer(state, caPool)
+		},
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			manager.mutex.RLock()
+			defer manager.mutex.RUnlock()
+
+			return manager.certificate, nil
+		},
+	}
+}
+
+func (manager *CertificateManager) checkExpiry(now time.Time) {
+	/* This (unexported) function updates the expiry gauge and logs a warning (at most
+	once a day per certificate) if the certificate or CA expires within ExpiryWarning */
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+
+	expiries := map[string]time.Time{"leaf": manager.leaf.NotAfter, "ca": manager.caExpiry}
+	for kind, expiry := range expiries {
+		remaining := expiry.Sub(now)
+		certificateExpiryDays.WithLabelValues(manager.name, kind).Set(remaining.Hours() / 24)
+
+		if remaining > manager.ExpiryWarning || now.Sub(manager.lastWarnings[kind]) < 24*time.Hour {
+			continue
+		}
+		manager.lastWarnings[kind] = now
+
+		if remaining <= 0 {
+			logging.Logger.Warnf("The %v %v certificate expired on %v", manager.name, kind, expiry.Format(time.RFC3339))
+		} else {
+			logging.Logger.Warnf("The %v %v certificate expires in %.1f days (%v)", manager.name, kind, remaining.Hours()/24, expiry.Format(time.RFC3339))
+		}
+	}
+}
+
+func verifyServer(state tls.ConnectionState, caPool *x509.CertPool) error {
+	/* This (unexported) function verifies the certificate chain presented by a server
+	against the provided CA, and checks that it was issued for the name the client dialled,
+	as the built-in verification would */
+	if len(state.PeerCertificates) == 0 {
+		return fmt.Errorf("the server did not present a certificate")
+	}
+	if state.ServerName == "" {
+		return fmt.Errorf("the server's name is required to verify its certificate")
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, certificate := range state.PeerCertificates[1:] {
+		intermediates.AddCert(certificate)
+	}
+	_, err := state.PeerCertificates[0].Verify(x509.VerifyOptions{
+		Roots:         caPool,
+		Intermediates: intermediates,
+		DNSName:       state.ServerName,
+	})
+
+	return err
+}
+
+func loadTrustedCertificates(caFile string) (*x509.CertPool, time.Time, error) {
+	/* This (unexported) function loads the CA certificate(s) in the provided file into a
+	certificate pool, and returns the earliest time at which one of them expires */
+	pemCA, err := ioutil.ReadFile(caFile)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("could not read CA certificate: %v", err)
+	}
+
+	certificatePool := x509.NewCertPool()
+	var expiry time.Time
+	for block, rest := pem.Decode(pemCA); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, time.Time{}, fmt.Errorf("could not parse CA certificate from %v: %v", caFile, err)
+		}
+		certificatePool.AddCert(certificate)
+		if expiry.IsZero() || certificate.NotAfter.Before(expiry) {
+			expiry = certificate.NotAfter
+		}
+	}
+	if expiry.IsZero() {
+		return nil, time.Time{}, fmt.Errorf("failed to add the CA's certificate from %v", caFile)
+	}
+
+	return certificatePool, expiry, nil
+}
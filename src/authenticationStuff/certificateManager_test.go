@@ -0,0 +1,134 @@
+package authentication
+
+import (
+	"crypto/tls"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCertificateManager(t *testing.T) {
+	directory := t.TempDir()
+
+	authority := newTestAuthority(t)
+	original := issueTestCertificate(t, authority, CertificateRequest{CommonName: "original", DNSNames: []string{"localhost"}, Usage: ServerCertificate})
+
+	caPath, _ := writeTestCertificate(t, directory, "ca", authority.Certificate, authority.Key)
+	certificatePath, keyPath := writeTestCertificate(t, directory, "server", original.Certificate, original.Key)
+
+	manager, err := NewCertificateManager("test", TLSFiles{Certificate: certificatePath, Key: keyPath, CA: caPath, RequireClientCertificate: true})
+	if err != nil {
+		t.Fatal("Failed to load certificates: ", err)
+	}
+
+	t.Run("Expiry is exported in days", func(t *testing.T) {
+		// The test certificates are valid for an hour
+		days := testutil.ToFloat64(certificateExpiryDays.WithLabelValues("test", "leaf"))
+		if days <= 0 || days > 1.0/24 {
+			t.Error("Unexpected days to expiry: ", days)
+		}
+	})
+
+	t.Run("Servers pick up the active certificate per handshake", func(t *testing.T) {
+		config, err := manager.ServerTLSConfig().GetConfigForClient(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(config.Certificates) != 1 || config.ClientCAs == nil {
+			t.Error("Server configuration is missing the certificate or CA")
+		}
+	})
+
+	t.Run("Certificates are only valid until they expire", func(t *testing.T) {
+		if err := manager.Valid(time.Now()); err != nil {
+			t.Error("Expected the certificates to be valid, received ", err)
+		}
+		if err := manager.Valid(time.Now().Add(2 * time.Hour)); err == nil || !strings.Contains(err.Error(), "expired") {
+			t.Error("Expected the certificates to have expired, received ", err)
+		}
+	})
+
+	stop := manager.Watch(10 * time.Millisecond)
+	defer stop()
+
+	waitFor := func(commonName string) {
+		deadline := time.Now().Add(2 * time.Second)
+		for manager.Certificate().Subject.CommonName != commonName {
+			if time.Now().After(deadline) {
+				t.Fatal("Expected the active certificate to be ", commonName, ", but it is ", manager.Certificate().Subject.CommonName)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	touch := func(paths ...string) {
+		future := time.Now().Add(time.Minute)
+		for _, path := range paths {
+			if err := os.Chtimes(path, future, future); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	t.Run("Rotated certificates are reloaded", func(t *testing.T) {
+		rotated := issueTestCertificate(t, authority, CertificateRequest{CommonName: "rotated", DNSNames: []string{"localhost"}, Usage: ServerCertificate})
+		writeTestCertificate(t, directory, "server", rotated.Certificate, rotated.Key)
+		touch(certificatePath, keyPath)
+
+		waitFor("rotated")
+	})
+
+	t.Run("Invalid files keep the current certificate", func(t *testing.T) {
+		if err := ioutil.WriteFile(certificatePath, []byte("not a certificate"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		touch(certificatePath)
+		time.Sleep(100 * time.Millisecond)
+
+		waitFor("rotated")
+	})
+}
+
+func TestClientCARotation(t *testing.T) {
+	directory := t.TempDir()
+
+	original, rotated := newTestAuthority(t), newTestAuthority(t)
+	serverConfig := func(authority *CertificateAuthority, name string) *tls.Config {
+		issued := issueTestCertificate(t, authority, CertificateRequest{CommonName: name, DNSNames: []string{name}, Usage: ServerCertificate})
+		return &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{issued.Certificate.Raw}, PrivateKey: issued.Key}}}
+	}
+
+	client := issueTestCertificate(t, original, CertificateRequest{CommonName: "desktopgateway", Usage: ClientCertificate})
+	caPath, _ := writeTestCertificate(t, directory, "ca", original.Certificate, original.Key)
+	certificatePath, keyPath := writeTestCertificate(t, directory, "client", client.Certificate, client.Key)
+
+	manager, err := NewCertificateManager("client", TLSFiles{Certificate: certificatePath, Key: keyPath, CA: caPath})
+	if err != nil {
+		t.Fatal("Failed to load certificates: ", err)
+	}
+
+	// The configuration is created once, as it is for a long-lived connection
+	clientConfig := manager.ClientTLSConfig()
+	clientConfig.ServerName = "localhost"
+
+	if _, err := handshake(t, serverConfig(original, "localhost"), clientConfig); err != nil {
+		t.Error("Expected a server signed by the CA to be trusted, received ", err)
+	}
+	if _, err := handshake(t, serverConfig(original, "otherhost"), clientConfig); err == nil {
+		t.Error("Expected a server with a certificate for another name to be refused")
+	}
+	if _, err := handshake(t, serverConfig(rotated, "localhost"), clientConfig); err == nil {
+		t.Error("Expected a server signed by another CA to be refused")
+	}
+
+	writeTestCertificate(t, directory, "ca", rotated.Certificate, rotated.Key)
+	if err := manager.Reload(); err != nil {
+		t.Fatal("Failed to reload certificates: ", err)
+	}
+	if _, err := handshake(t, serverConfig(rotated, "localhost"), clientConfig); err != nil {
+		t.Error("Expected a server signed by the rotated CA to be trusted, received ", err)
+	}
+}
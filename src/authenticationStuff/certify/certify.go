@@ -0,0 +1,252 @@
+package main
+
+/* certify creates a development CA and issues a server and a client certificate for every
+service in docker-compose.yaml. Run again, it only reissues certificates that are missing,
+invalid, about to expire or issued by another CA, so it can be used to rotate certificates
+in place (the services pick up the new files without restarting).
+
+The generated layout is:
+
+	<output>/ca-cert.pem, ca-key.pem
+	<output>/<service>/server-cert.pem, server-key.pem, client-cert.pem, client-key.pem
+	<output>/<client>/client-cert.pem, client-key.pem (for each of -clients)
+*/
+
+import (
+	// Native packages
+	"crypto/tls"
+	"crypto/x509"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	// Required packages
+	"github.com/go-yaml/yaml"
+
+	// Personal packages
+	authentication "github.com/nicholasbunn/mastersSandbox/src/authenticationStuff"
+)
+
+type Options struct {
+	// This struct holds the command line options of the command
+	ComposeFile string        // The docker-compose file listing the services
+	Output      string        // The directory the certificates are written to
+	Exclude     []string      // Services that don't need certificates
+	Clients     []string      // Additional client-only identities (e.g. the frontend)
+	CALifetime  time.Duration // How long a new CA is valid for
+	Lifetime    time.Duration // How long new service certificates are valid for
+	RenewBefore time.Duration // Certificates expiring within this duration are reissued
+	RotateCA    bool          // Replace the CA (and therefore every certificate) even if it is still valid
+	Force       bool          // Reissue every service certificate even if it is still valid
+}
+
+type ComposeFile struct {
+	Services map[string]struct {
+		Build         interface{} `yaml:"build"`
+		Hostname      string      `yaml:"hostname"`
+		ContainerName string      `yaml:"container_name"`
+	} `yaml:"services"`
+}
+
+type workload struct {
+	// This struct describes the certificates to be issued for one service or client
+	name      string
+	hostnames []string
+	usages    []int
+}
+
+func main() {
+	options := Options{}
+	var exclude, clients string
+	flag.StringVar(&options.ComposeFile, "compose", "docker-compose.yaml", "The docker-compose file listing the services")
+	flag.StringVar(&options.Output, "output", "certification", "The directory the certificates are written to")
+	flag.StringVar(&exclude, "exclude", "envoyproxy,prometheus,otelcollector", "Comma-separated services that don't need certificates")
+	flag.StringVar(&clients, "clients", "frontend", "Comma-separated client-only identities to issue client certificates for")
+	flag.DurationVar(&options.CALifetime, "ca-lifetime", 365*24*time.Hour, "How long a new CA is valid for")
+	flag.DurationVar(&options.Lifetime, "lifetime", 90*24*time.Hour, "How long new service certificates are valid for")
+	flag.DurationVar(&options.RenewBefore, "renew-before", 30*24*time.Hour, "Reissue certificates that expire within this duration")
+	flag.BoolVar(&options.RotateCA, "rotate-ca", false, "Replace the CA, and every certificate it issued, even if it is still valid")
+	flag.BoolVar(&options.Force, "force", false, "Reissue every service certificate even if it is still valid")
+	flag.Parse()
+
+	options.Exclude = splitList(exclude)
+	options.Clients = splitList(clients)
+
+	if err := Run(options); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func Run(options Options) error {
+	/* This function loads (or creates) the CA and issues the certificates described by the
+	provided options, keeping any certificate that is still valid */
+	workloads, err := workloadsFromCompose(options.ComposeFile, options.Exclude)
+	if err != nil {
+		return err
+	}
+	for _, client := range options.Clients {
+		workloads = append(workloads, workload{name: client, hostnames: []string{client}, usages: []int{authentication.ClientCertificate}})
+	}
+
+	authority, err := loadAuthority(options)
+	if err != nil {
+		return err
+	}
+
+	for _, service := range workloads {
+		for _, usage := range service.usages {
+			if err := issue(options, authority, service, usage); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func workloadsFromCompose(composeFile string, exclude []string) ([]workload, error) {
+	/* This function returns a workload for every service that docker-compose builds from
+	this repository, except the excluded ones. Each service is reachable by its service
+	name (and its hostname or container name, if set) as well as on localhost */
+	contents, err := ioutil.ReadFile(composeFile)
+	if err != nil {
+		return nil, fmt.Errorf("could not read compose file: %v", err)
+	}
+
+	compose := ComposeFile{}
+	if err := yaml.Unmarshal(contents, &compose); err != nil {
+		return nil, fmt.Errorf("could not decode compose file: %v", err)
+	}
+
+	excluded := map[string]bool{}
+	for _, name := range exclude {
+		excluded[name] = true
+	}
+
+	workloads := []workload{}
+	for name, service := range compose.Services {
+		if service.Build == nil || excluded[name] {
+			continue // Third-party images bring their own certificates, if any
+		}
+
+		hostnames := []string{name}
+		for _, alias := range []string{service.Hostname, service.ContainerName} {
+			if alias != "" && alias != name {
+				hostnames = append(hostnames, alias)
+			}
+		}
+		hostnames = append(hostnames, "localhost")
+
+		workloads = append(workloads, workload{
+			name:      name,
+			hostnames: hostnames,
+			usages:    []int{authentication.ServerCertificate, authentication.ClientCertificate},
+		})
+	}
+	sort.Slice(workloads, func(i, j int) bool { return workloads[i].name < workloads[j].name })
+
+	return workloads, nil
+}
+
+func loadAuthority(options Options) (*authentication.CertificateAuthority, error) {
+	// This function loads the existing CA, or creates a new one if there is none (or it has to be rotated)
+	certificateFile := filepath.Join(options.Output, "ca-cert.pem")
+	keyFile := filepath.Join(options.Output, "ca-key.pem")
+
+	if !options.RotateCA {
+		authority, err := authentication.ReadCertificateAuthority(certificateFile, keyFile)
+		if err == nil && time.Until(authority.Certificate.NotAfter) > options.RenewBefore {
+			fmt.Printf("Kept CA (expires %v)\n", authority.Certificate.NotAfter.Format(time.RFC3339))
+			return authority, nil
+		}
+	}
+
+	authority, err := authentication.NewCertificateAuthority("mastersSandbox development CA", options.CALifetime)
+	if err != nil {
+		return nil, err
+	}
+	if err := authority.Write(certificateFile, keyFile); err != nil {
+		return nil, err
+	}
+	fmt.Printf("Created CA (expires %v)\n", authority.Certificate.NotAfter.Format(time.RFC3339))
+
+	return authority, nil
+}
+
+func issue(options Options, authority *authentication.CertificateAuthority, service workload, usage int) error {
+	// This function issues a server or client certificate for the service, unless its current one is still valid
+	kind, extendedUsage := "server", x509.ExtKeyUsageServerAuth
+	if usage == authentication.ClientCertificate {
+		kind, extendedUsage = "client", x509.ExtKeyUsageClientAuth
+	}
+	certificateFile := filepath.Join(options.Output, service.name, kind+"-cert.pem")
+	keyFile := filepath.Join(options.Output, service.name, kind+"-key.pem")
+
+	reason := "forced"
+	if !options.Force {
+		reason = renewalReason(options, authority, certificateFile, keyFile, extendedUsage, service.hostnames)
+		if reason == "" {
+			fmt.Printf("Kept %v certificate for %v\n", kind, service.name)
+			return nil
+		}
+	}
+
+	issued, err := authority.Issue(authentication.CertificateRequest{
+		CommonName:  service.name,
+		DNSNames:    service.hostnames,
+		IPAddresses: []net.IP{net.ParseIP("127.0.0.1"), net.ParseIP("::1")},
+		Usage:       usage,
+		Lifetime:    options.Lifetime,
+	})
+	if err != nil {
+		return err
+	}
+	if err := issued.Write(certificateFile, keyFile); err != nil {
+		return err
+	}
+	fmt.Printf("Issued %v certificate for %v, %v (expires %v)\n", kind, service.name, reason, issued.Certificate.NotAfter.Format(time.RFC3339))
+
+	return nil
+}
+
+func renewalReason(options Options, authority *authentication.CertificateAuthority, certificateFile string, keyFile string, usage x509.ExtKeyUsage, hostnames []string) string {
+	// This function returns why the certificate in the provided files has to be reissued, or an empty string if it doesn't
+	certificate, err := authentication.ReadCertificate(certificateFile)
+	if err != nil {
+		return "missing"
+	}
+	if _, err := tls.LoadX509KeyPair(certificateFile, keyFile); err != nil {
+		return "key missing or mismatched"
+	}
+	if err := authority.Verify(certificate, usage); err != nil {
+		return "not issued by the current CA"
+	}
+	if time.Until(certificate.NotAfter) < options.RenewBefore {
+		return "about to expire"
+	}
+	for _, hostname := range hostnames {
+		if certificate.VerifyHostname(hostname) != nil {
+			return "missing hostname " + hostname
+		}
+	}
+
+	return ""
+}
+
+func splitList(list string) []string {
+	// This function splits a comma-separated list, ignoring empty entries
+	items := []string{}
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"crypto/x509"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	authentication "github.com/nicholasbunn/mastersSandbox/src/authenticationStuff"
+)
+
+const testCompose = `
+services:
+    desktopgateway:
+        build:
+            context: .
+        hostname: gateway
+    prometheus:
+        build:
+            context: .
+    pushgateway:
+        image: prom/pushgateway
+`
+
+func TestRun(t *testing.T) {
+	directory := t.TempDir()
+	composeFile := filepath.Join(directory, "docker-compose.yaml")
+	if err := ioutil.WriteFile(composeFile, []byte(testCompose), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	options := Options{
+		ComposeFile: composeFile,
+		Output:      filepath.Join(directory, "certification"),
+		Exclude:     []string{"prometheus"},
+		Clients:     []string{"frontend"},
+		CALifetime:  24 * time.Hour,
+		Lifetime:    2 * time.Hour,
+		RenewBefore: time.Hour,
+	}
+
+	serial := func(path ...string) string {
+		// This helper returns the serial number of the certificate at the provided path in the output directory
+		certificate, err := authentication.ReadCertificate(filepath.Join(append([]string{options.Output}, path...)...))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return certificate.SerialNumber.String()
+	}
+
+	if err := Run(options); err != nil {
+		t.Fatal("Failed to issue certificates: ", err)
+	}
+
+	t.Run("Certificates are issued for built services and clients", func(t *testing.T) {
+		authority, err := authentication.ReadCertificateAuthority(filepath.Join(options.Output, "ca-cert.pem"), filepath.Join(options.Output, "ca-key.pem"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		certificate, err := authentication.ReadCertificate(filepath.Join(options.Output, "desktopgateway", "server-cert.pem"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := authority.Verify(certificate, x509.ExtKeyUsageServerAuth); err != nil {
+			t.Error("Server certificate was not issued by the CA: ", err)
+		}
+		for _, hostname := range []string{"desktopgateway", "gateway", "localhost", "127.0.0.1"} {
+			if err := certificate.VerifyHostname(hostname); err != nil {
+				t.Error("Server certificate is not valid for ", hostname)
+			}
+		}
+
+		for _, unexpected := range []string{"prometheus", "pushgateway", filepath.Join("frontend", "server-cert.pem")} {
+			if _, err := os.Stat(filepath.Join(options.Output, unexpected)); err == nil {
+				t.Error("Unexpected certificates in ", unexpected)
+			}
+		}
+		serial("frontend", "client-cert.pem")
+	})
+
+	t.Run("Valid certificates are kept", func(t *testing.T) {
+		before := serial("desktopgateway", "client-cert.pem")
+		if err := Run(options); err != nil {
+			t.Fatal(err)
+		}
+		if serial("desktopgateway", "client-cert.pem") != before {
+			t.Error("A valid certificate was reissued")
+		}
+	})
+
+	t.Run("Certificates about to expire are reissued", func(t *testing.T) {
+		before := serial("desktopgateway", "client-cert.pem")
+		renewing := options
+		renewing.RenewBefore = 3 * time.Hour // Longer than the certificates' lifetime, but shorter than the CA's
+		if err := Run(renewing); err != nil {
+			t.Fatal(err)
+		}
+		if serial("desktopgateway", "client-cert.pem") == before {
+			t.Error("A certificate about to expire was kept")
+		}
+	})
+
+	t.Run("Rotating the CA reissues every certificate", func(t *testing.T) {
+		beforeCA, before := serial("ca-cert.pem"), serial("frontend", "client-cert.pem")
+		rotating := options
+		rotating.RotateCA = true
+		if err := Run(rotating); err != nil {
+			t.Fatal(err)
+		}
+		if serial("ca-cert.pem") == beforeCA || serial("frontend", "client-cert.pem") == before {
+			t.Error("Rotating the CA did not reissue the certificates")
+		}
+	})
+}
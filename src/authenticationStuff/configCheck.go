@@ -0,0 +1,265 @@
+package authentication
+
+import (
+	"crypto/tls"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/nicholasbunn/mastersSandbox/src/logging"
+	"github.com/sirupsen/logrus"
+)
+
+// DevModeVariable names the environment variable that relaxes the security checks made at startup, for local development
+const DevModeVariable = "MASTERS_DEV_MODE"
+
+// MinSecretLength is the shortest JWT secret (in bytes) accepted outside of dev mode
+const MinSecretLength = 32
+
+// Secrets that have shipped in configuration files or are commonly used as placeholders
+var weakSecrets = map[string]bool{"secret": true, "changeme": true, "password": true, "mysecret": true, "jwtsecret": true}
+
+type configProblem struct {
+	setting  string
+	message  string
+	insecure bool // Insecure settings still let the service run, so they are only warnings in dev mode
+}
+
+type ConfigCheck struct {
+	/* This struct collects every problem found in a service's configuration, so that
+	they are reported together when the service starts instead of one at a time (or as a
+	panic). Problems that make the service unable to run (missing files, empty ports) are
+	always fatal. Problems that only make it insecure (a weak JWT secret, callers that
+	aren't asked for a certificate) are reported as warnings in dev mode */
+	Service string // The service being checked, as named in the report
+	Path    string // The configuration file being checked
+	DevMode bool
+
+	problems []configProblem
+}
+
+func NewConfigCheck(service string, path string) *ConfigCheck {
+	// This function starts checking the provided service's configuration file, in dev mode if DevModeVariable is set to true
+	devMode, _ := strconv.ParseBool(os.Getenv(DevModeVariable))
+	return &ConfigCheck{Service: service, Path: path, DevMode: devMode}
+}
+
+func (check *ConfigCheck) Problem(setting string, format string, args ...interface{}) {
+	// This function records a problem that prevents the service from running
+	check.problems = append(check.problems, configProblem{setting: setting, message: fmt.Sprintf(format, args...)})
+}
+
+func (check *ConfigCheck) Insecure(setting string, format string, args ...interface{}) {
+	// This function records a problem that leaves the service insecure, which is only a warning in dev mode
+	check.problems = append(check.problems, configProblem{setting: setting, message: fmt.Sprintf(format, args...), insecure: true})
+}
+
+func (check *ConfigCheck) RequireValue(setting string, value string) {
+	// This function records a problem if the provided setting is empty
+	if strings.TrimSpace(value) == "" {
+		check.Problem(setting, "is not set")
+	}
+}
+
+func (check *ConfigCheck) RequirePositive(setting string, value int) {
+	// This function records a problem if the provided setting (a count or duration) isn't positive
+	if value <= 0 {
+		check.Problem(setting, "has to be greater than zero, found %d", value)
+	}
+}
+
+func (check *ConfigCheck) RequirePort(setting string, port string) {
+	// This function records a problem if the provided setting isn't a TCP port number
+	if port == "" {
+		check.Problem(setting, "is not set, the service has no port to use")
+		return
+	}
+	if number, err := strconv.Atoi(port); err != nil || number < 1 || number > 65535 {
+		check.Problem(setting, "%q is not a port number (1-65535)", port)
+	}
+}
+
+func (check *ConfigCheck) RequireFile(setting string, path string, hint string) {
+	/* This function records a problem if the provided setting doesn't name an existing
+	file. The hint tells the reader how to create the file, and may be empty */
+	if path == "" {
+		check.Problem(setting, "is not set")
+		return
+	}
+
+	info, err := os.Stat(path)
+	message := ""
+	if os.IsNotExist(err) {
+		message = fmt.Sprintf("file %q does not exist (relative to %v)", path, workingDirectory())
+	} else if err != nil {
+		message = fmt.Sprintf("file %q can't be read: %v", path, err)
+	} else if info.IsDir() {
+		message = fmt.Sprintf("%q is a directory, not a file", path)
+	} else {
+		return
+	}
+	if hint != "" {
+		message += ", " + hint
+	}
+	check.Problem(setting, "%s", message)
+}
+
+func (check *ConfigCheck) CheckSecret(setting string, secret string) {
+	// This function records the provided JWT signing secret as insecure if it is empty, a well-known value or too short
+	switch {
+	case secret == "":
+		check.Problem(setting, "is not set, tokens can't be signed")
+	case weakSecrets[strings.ToLower(secret)]:
+		check.Insecure(setting, "is a well-known value, anyone can forge tokens with it. Set a random secret of at least %d characters (e.g. run \"make secrets\") and use it in every service", MinSecretLength)
+	case len(secret) < MinSecretLength:
+		check.Insecure(setting, "is only %d characters long, use a random secret of at least %d characters", len(secret), MinSecretLength)
+	}
+}
+
+func (check *ConfigCheck) CheckSecretReference(setting string, reference string) *Secret {
+	/* This function resolves the provided secret reference (see Secret) and checks the
+	secret it names. Secrets written out in the configuration file are insecure, since
+	they end up committed alongside it. It returns nil if the reference can't be resolved */
+	if reference == "" {
+		check.Problem(setting, "is not set, tokens can't be signed")
+		return nil
+	}
+
+	secret, err := NewSecret(reference)
+	if err != nil && strings.HasPrefix(reference, "secret:") {
+		check.Problem(setting, "%v, run \"make secrets\" to create one for local development", err)
+		return nil
+	} else if err != nil {
+		check.Problem(setting, "%v", err)
+		return nil
+	}
+	if secret.Literal() {
+		check.Insecure(setting, "is written in the configuration file, refer to it with \"${env:NAME}\", \"file:/path\" or \"secret:name\" (a Docker secret) instead")
+	}
+	check.CheckSecret(setting, secret.Value())
+
+	return secret
+}
+
+func (check *ConfigCheck) CheckServerTLS(setting string, files TLSFiles) {
+	/* This function checks a server's TLS files: the certificate and key have to exist and
+	match, and the CA has to exist so that callers' certificates can be verified. Not
+	requiring client certificates is insecure, since callers can't be identified */
+	check.checkKeyPair(setting, files)
+	check.RequireFile(setting+".ca", files.CA, "run \"make certify\" to create the development CA")
+	if !files.RequireClientCertificate {
+		check.Insecure(setting+".requireClientCertificate", "is false, callers without a client certificate are accepted")
+	}
+}
+
+func (check *ConfigCheck) CheckClientTLS(setting string, files TLSFiles) {
+	// This function checks a client's TLS files: the CA verifies the servers, the certificate and key identify the client to them
+	check.checkKeyPair(setting, files)
+	check.RequireFile(setting+".ca", files.CA, "run \"make certify\" to create the development CA")
+}
+
+func (check *ConfigCheck) CheckLogging(setting string, config logging.Config) {
+	// This function records the problems with the provided logging settings, so that the logger can be set up with them
+	if _, err := logrus.ParseLevel(config.Level); err != nil {
+		check.Problem(setting+".level", "%q is not a level, use \"debug\", \"info\", \"warning\" or \"error\"", config.Level)
+	}
+	if config.Format != logging.FormatJSON && config.Format != logging.FormatLogfmt {
+		check.Problem(setting+".format", "%q is not a format, use %q or %q", config.Format, logging.FormatJSON, logging.FormatLogfmt)
+	}
+	switch config.Output {
+	case logging.OutputStdout:
+	case logging.OutputFile:
+		check.RequireValue(setting+".file", config.File)
+		check.RequirePositive(setting+".maxSize", config.MaxSize)
+	default:
+		check.Problem(setting+".output", "%q is not an output, use %q or %q", config.Output, logging.OutputStdout, logging.OutputFile)
+	}
+}
+
+func (check *ConfigCheck) Failed() bool {
+	// This function reports whether any problem found should stop the service from starting
+	for _, problem := range check.problems {
+		if !problem.insecure || !check.DevMode {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (check *ConfigCheck) Report() string {
+	/* This function describes every problem found, one per line, or returns an empty
+	string if there are none. In dev mode, insecure settings are listed as warnings */
+	if len(check.problems) == 0 {
+		return ""
+	}
+
+	var fatal, warnings []string
+	for _, problem := range check.problems {
+		line := "  - " + problem.setting + ": " + problem.message
+		if problem.insecure && check.DevMode {
+			warnings = append(warnings, line)
+		} else {
+			fatal = append(fatal, line)
+		}
+	}
+
+	var report strings.Builder
+	if len(fatal) > 0 {
+		fmt.Fprintf(&report, "The %v can't start, its configuration (%v) has %d problem(s):\n%v\n", check.Service, check.Path, len(fatal), strings.Join(fatal, "\n"))
+		if !check.DevMode && check.hasInsecure() {
+			fmt.Fprintf(&report, "Set %v=true to run with insecure settings during local development\n", DevModeVariable)
+		}
+	}
+	if len(warnings) > 0 {
+		fmt.Fprintf(&report, "The %v is running in dev mode (%v) with insecure settings:\n%v\n", check.Service, DevModeVariable, strings.Join(warnings, "\n"))
+	}
+
+	return strings.TrimSuffix(report.String(), "\n")
+}
+
+func (check *ConfigCheck) Enforce() {
+	/* This function prints the report (if there is anything to report) to standard error,
+	and exits if the service shouldn't start. It is called once every setting is checked */
+	if report := check.Report(); report != "" {
+		fmt.Fprintln(os.Stderr, report)
+	}
+	if check.Failed() {
+		os.Exit(1)
+	}
+}
+
+func (check *ConfigCheck) checkKeyPair(setting string, files TLSFiles) {
+	// This (unexported) function checks that a certificate and its key exist and belong together
+	problems := len(check.problems)
+	check.RequireFile(setting+".certificate", files.Certificate, "run \"make certify\" to issue the service certificates")
+	check.RequireFile(setting+".key", files.Key, "run \"make certify\" to issue the service certificates")
+	if len(check.problems) > problems {
+		return
+	}
+
+	if _, err := tls.LoadX509KeyPair(files.Certificate, files.Key); err != nil {
+		check.Problem(setting, "the certificate and key can't be loaded: %v", err)
+	}
+}
+
+func (check *ConfigCheck) hasInsecure() bool {
+	for _, problem := range check.problems {
+		if problem.insecure {
+			return true
+		}
+	}
+
+	return false
+}
+
+func workingDirectory() string {
+	// This (unexported) function returns the working directory that relative paths are resolved against
+	directory, err := os.Getwd()
+	if err != nil {
+		return "the working directory"
+	}
+
+	return directory
+}
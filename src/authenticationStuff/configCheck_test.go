@@ -0,0 +1,95 @@
+package authentication
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/nicholasbunn/mastersSandbox/src/logging"
+)
+
+func TestConfigCheck(t *testing.T) {
+	directory := t.TempDir()
+	authority := newTestAuthority(t)
+	server := issueTestCertificate(t, authority, CertificateRequest{CommonName: "desktopgateway", Usage: ServerCertificate})
+	client := issueTestCertificate(t, authority, CertificateRequest{CommonName: "frontend", Usage: ClientCertificate})
+	caPath, _ := writeTestCertificate(t, directory, "ca", authority.Certificate, authority.Key)
+	serverCertificate, serverKey := writeTestCertificate(t, directory, "server", server.Certificate, server.Key)
+	_, clientKey := writeTestCertificate(t, directory, "client", client.Certificate, client.Key)
+
+	secureTLS := TLSFiles{Certificate: serverCertificate, Key: serverKey, CA: caPath, RequireClientCertificate: true}
+	strongSecret := strings.Repeat("x", MinSecretLength)
+
+	var Tests = []struct {
+		name           string
+		devMode        bool
+		check          func(check *ConfigCheck)
+		expectedFailed bool
+		expectedReport []string // Settings (or messages) the report should mention
+	}{
+		{"A complete, secure configuration passes", false, func(check *ConfigCheck) {
+			check.RequirePort("server.port.myself", "50201")
+			check.CheckSecret("server.authentication.jwt.secretKey", strongSecret)
+			check.CheckServerTLS("server.tls", secureTLS)
+		}, false, nil},
+		{"Every problem is reported at once", false, func(check *ConfigCheck) {
+			check.RequirePort("server.port.myself", "")
+			check.RequirePort("client.port.authenticationService", "5040l")
+			check.CheckSecret("server.authentication.jwt.secretKey", "secret")
+			check.RequireFile("server.authentication.policy.file", directory+"/missing.yaml", "")
+			check.CheckServerTLS("server.tls", TLSFiles{Certificate: directory + "/missing-cert.pem", Key: serverKey, CA: caPath, RequireClientCertificate: true})
+		}, true, []string{"server.port.myself", "client.port.authenticationService", "secretKey", "policy.file", "server.tls.certificate", "make certify", "5 problem(s)", DevModeVariable}},
+		{"Short secrets are insecure", false, func(check *ConfigCheck) {
+			check.CheckSecret("server.authentication.jwt.secretKey", "tooShort")
+		}, true, []string{"only 8 characters"}},
+		{"Mismatched certificates and keys are refused", false, func(check *ConfigCheck) {
+			check.CheckServerTLS("server.tls", TLSFiles{Certificate: serverCertificate, Key: clientKey, CA: caPath, RequireClientCertificate: true})
+		}, true, []string{"can't be loaded"}},
+		{"Dev mode accepts insecure settings with a warning", true, func(check *ConfigCheck) {
+			check.CheckSecret("server.authentication.jwt.secretKey", "secret")
+			check.CheckServerTLS("server.tls", TLSFiles{Certificate: serverCertificate, Key: serverKey, CA: caPath})
+		}, false, []string{"dev mode", "secretKey", "requireClientCertificate"}},
+		{"Dev mode doesn't accept missing files", true, func(check *ConfigCheck) {
+			check.CheckClientTLS("client.tls", TLSFiles{Certificate: serverCertificate, Key: serverKey, CA: directory + "/missing-ca.pem"})
+		}, true, []string{"client.tls.ca"}},
+		{"Empty secrets can't be used in dev mode either", true, func(check *ConfigCheck) {
+			check.CheckSecret("server.authentication.jwt.secretKey", "")
+		}, true, []string{"not set"}},
+		{"Secrets written in the configuration file are insecure", false, func(check *ConfigCheck) {
+			check.CheckSecretReference("server.authentication.jwt.secretKey", strongSecret)
+		}, true, []string{"written in the configuration file"}},
+		{"Unresolved secret references can't be used in dev mode either", true, func(check *ConfigCheck) {
+			check.CheckSecretReference("server.authentication.jwt.secretKey", "file:"+directory+"/missing-secret")
+		}, true, []string{"can't be read"}},
+		{"Referenced secrets are checked too", true, func(check *ConfigCheck) {
+			ioutil.WriteFile(directory+"/weak-secret", []byte("changeme\n"), 0600)
+			check.CheckSecretReference("server.authentication.jwt.secretKey", "file:"+directory+"/weak-secret")
+		}, false, []string{"well-known value"}},
+		{"Complete logging settings pass", false, func(check *ConfigCheck) {
+			check.CheckLogging("server.logging", logging.Config{Level: "info", Format: logging.FormatJSON, Output: logging.OutputFile, File: "program logs/service.log", MaxSize: 10})
+		}, false, nil},
+		{"Every logging problem is reported", true, func(check *ConfigCheck) {
+			check.CheckLogging("server.logging", logging.Config{Level: "verbose", Format: "xml", Output: logging.OutputFile})
+		}, true, []string{"server.logging.level", "server.logging.format", "server.logging.file", "server.logging.maxSize"}},
+	}
+
+	for _, test := range Tests {
+		t.Run(test.name, func(t *testing.T) {
+			check := &ConfigCheck{Service: "desktop gateway", Path: "configuration.yaml", DevMode: test.devMode}
+			test.check(check)
+
+			if failed := check.Failed(); failed != test.expectedFailed {
+				t.Error("Expected failed to be ", test.expectedFailed, ", received ", failed, ": ", check.Report())
+			}
+			report := check.Report()
+			if len(test.expectedReport) == 0 && report != "" {
+				t.Error("Expected an empty report, received: ", report)
+			}
+			for _, expected := range test.expectedReport {
+				if !strings.Contains(report, expected) {
+					t.Errorf("Expected the report to mention %q, received:\n%v", expected, report)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package authentication
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+/* Authentication and authorisation errors follow one model across the services:
+missing or invalid credentials return codes.Unauthenticated, a valid caller without the
+required roles returns codes.PermissionDenied, and every error carries an ErrorInfo
+detail whose Reason and "action" metadata tell the frontend how to respond */
+
+const (
+	// ErrorDomain identifies errors raised by this system in ErrorInfo details
+	ErrorDomain = "mastersSandbox.authentication"
+
+	// Reasons, as reported in ErrorInfo details
+	ReasonMetadataMissing  = "METADATA_MISSING"
+	ReasonTokenMissing     = "TOKEN_MISSING"
+	ReasonTokenInvalid     = "TOKEN_INVALID"
+	ReasonTokenExpired     = "TOKEN_EXPIRED"
+	ReasonPermissionDenied = "PERMISSION_DENIED"
+	ReasonLoginFailed      = "LOGIN_FAILED"
+	ReasonLoginThrottled   = "LOGIN_THROTTLED"
+	ReasonUserNotFound     = "USER_NOT_FOUND"
+	ReasonAPIKeyInvalid    = "API_KEY_INVALID"
+	ReasonAPIKeyExpired    = "API_KEY_EXPIRED"
+	ReasonAPIKeyNotFound   = "API_KEY_NOT_FOUND"
+	ReasonTOTPInvalid      = "TOTP_INVALID"
+	ReasonSessionEnded     = "SESSION_ENDED"
+	ReasonSessionNotFound  = "SESSION_NOT_FOUND"
+
+	// Actions the frontend can take, as reported in the "action" metadata of ErrorInfo details
+	ActionLogin = "login" // The user should (re-)enter their credentials
+	ActionRetry = "retry" // The request can be retried as is
+	ActionNone  = "none"  // Nothing the user can do will make the request succeed
+)
+
+// ErrTokenExpired is returned by VerifyJWT when an otherwise valid token has expired
+var ErrTokenExpired = errors.New("token has expired")
+
+func NewAuthError(code codes.Code, reason string, action string, message string, metadata map[string]string) error {
+	/* This function creates a gRPC status error with the provided code and message, and
+	attaches an ErrorInfo detail describing the reason and the action the caller should take */
+	errorMetadata := map[string]string{"action": action}
+	for key, value := range metadata {
+		errorMetadata[key] = value
+	}
+
+	errorStatus, err := status.New(code, message).WithDetails(&errdetails.ErrorInfo{
+		Reason:   reason,
+		Domain:   ErrorDomain,
+		Metadata: errorMetadata,
+	})
+	if err != nil {
+		// Attaching details only fails if the detail can't be marshalled, fall back to a plain status
+		return status.Error(code, message)
+	}
+
+	return errorStatus.Err()
+}
+
+func UnauthenticatedError(reason string, message string) error {
+	// This function returns an error for a request with missing or invalid credentials, prompting a (re-)login
+	return NewAuthError(codes.Unauthenticated, reason, ActionLogin, message, nil)
+}
+
+func PermissionDeniedError(method string) error {
+	// This function returns an error for an authenticated caller who may not invoke the provided method
+	return NewAuthError(codes.PermissionDenied, ReasonPermissionDenied, ActionNone, "user does not have permission to access this RPC", map[string]string{"method": method})
+}
+
+func LoginFailedError() error {
+	/* This function returns the single error used for every failed login, so that the
+	response doesn't reveal whether the username or the password was wrong */
+	return NewAuthError(codes.Unauthenticated, ReasonLoginFailed, ActionRetry, "invalid username or password", nil)
+}
+
+func TOTPFailedError() error {
+	/* This function returns the error used when a TOTP (or recovery) code is rejected. The
+	user can try again with the next code, until the partial token from their login expires */
+	return NewAuthError(codes.Unauthenticated, ReasonTOTPInvalid, ActionRetry, "invalid verification code", nil)
+}
+
+func LoginThrottledError(retryAfter time.Duration) error {
+	/* This function returns the error used when a login is rejected because of earlier
+	failures. Like LoginFailedError, it doesn't reveal whether the username or the address
+	was throttled, or whether the username exists */
+	seconds := int64((retryAfter + time.Second - 1) / time.Second) // Round up so that retrying after this long succeeds
+	message := "too many failed login attempts, try again later"
+
+	errorStatus, err := status.New(codes.ResourceExhausted, message).WithDetails(
+		&errdetails.ErrorInfo{
+			Reason:   ReasonLoginThrottled,
+			Domain:   ErrorDomain,
+			Metadata: map[string]string{"action": ActionRetry, "retryAfter": strconv.FormatInt(seconds, 10)},
+		},
+		&errdetails.RetryInfo{
+			RetryDelay: ptypes.DurationProto(time.Duration(seconds) * time.Second),
+		},
+	)
+	if err != nil {
+		return status.Error(codes.ResourceExhausted, message)
+	}
+
+	return errorStatus.Err()
+}
+
+func TokenError(err error) error {
+	// This function converts an error returned by VerifyJWT into an Unauthenticated error
+	if err == ErrTokenExpired {
+		return UnauthenticatedError(ReasonTokenExpired, "access token has expired")
+	}
+
+	return UnauthenticatedError(ReasonTokenInvalid, "access token is invalid")
+}
+
+func SessionError(err error) error {
+	/* This function converts an error returned by Session.Check into an Unauthenticated error.
+	Sessions that can't be found were never issued (or ended long ago), so their tokens are invalid */
+	if err == ErrSessionNotFound {
+		return UnauthenticatedError(ReasonTokenInvalid, "access token is invalid")
+	}
+
+	return UnauthenticatedError(ReasonSessionEnded, "session has ended, log in again")
+}
+
+func APIKeyError(err error) error {
+	/* This function converts an error returned by APIKey.Check into an Unauthenticated
+	error. Keys belong to scripts rather than users, so there is no point prompting a login */
+	if err == ErrAPIKeyExpired {
+		return NewAuthError(codes.Unauthenticated, ReasonAPIKeyExpired, ActionNone, "api key has expired", nil)
+	}
+
+	return NewAuthError(codes.Unauthenticated, ReasonAPIKeyInvalid, ActionNone, "api key is invalid", nil)
+}
+
+func ErrorInfoFromError(err error) *errdetails.ErrorInfo {
+	// This function extracts the ErrorInfo detail from a gRPC status error, returning nil if there is none
+	errorStatus, ok := status.FromError(err)
+	if !ok {
+		return nil
+	}
+
+	for _, detail := range errorStatus.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			return info
+		}
+	}
+
+	return nil
+}
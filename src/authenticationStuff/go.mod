@@ -4,6 +4,16 @@ go 1.13
 
 require (
 	github.com/dgrijalva/jwt-go v3.2.0+incompatible
+	github.com/go-asn1-ber/asn1-ber v1.5.1
+	github.com/go-ldap/ldap/v3 v3.3.0
+	github.com/go-yaml/yaml v2.1.0+incompatible
+	github.com/golang/protobuf v1.4.3
+	github.com/nicholasbunn/mastersSandbox/src/logging v0.0.0-00010101000000-000000000000
+	github.com/prometheus/client_golang v1.11.0
+	github.com/sirupsen/logrus v1.8.1
 	golang.org/x/crypto v0.0.0-20210513164829-c07d793c2f9a
+	google.golang.org/genproto v0.0.0-20200526211855-cb27e3aa2013
 	google.golang.org/grpc v1.38.0
 )
+
+replace github.com/nicholasbunn/mastersSandbox/src/logging => ../logging
@@ -0,0 +1,130 @@
+package authentication
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+type htpasswdEntry struct {
+	hashedPassword string
+	roles          []string
+}
+
+type HtpasswdProvider struct {
+	/* This struct is an IdentityProvider that checks passwords against a static
+	htpasswd-style file. Each line holds "username:bcrypt hash", optionally followed by
+	":role,role" to override the provider's default roles. Only bcrypt hashes (as written
+	by htpasswd -B) are accepted. The file is re-read whenever it changes on disk */
+	name         string
+	path         string
+	defaultRoles []string
+
+	mutex   sync.Mutex
+	entries map[string]htpasswdEntry
+	modTime time.Time
+}
+
+func NewHtpasswdProvider(name string, path string, defaultRoles []string) (*HtpasswdProvider, error) {
+	// This function loads the htpasswd file at the provided path and returns a provider for it
+	if path == "" {
+		return nil, fmt.Errorf("no htpasswd file configured")
+	}
+
+	provider := &HtpasswdProvider{name: name, path: path, defaultRoles: defaultRoles}
+	if err := provider.reload(); err != nil {
+		return nil, err
+	}
+
+	return provider, nil
+}
+
+func (provider *HtpasswdProvider) Name() string {
+	return provider.name
+}
+
+func (provider *HtpasswdProvider) Authenticate(username string, password string) (*Identity, error) {
+	// This function checks the provided credentials against the htpasswd file
+	if err := provider.reload(); err != nil {
+		return nil, err
+	}
+
+	provider.mutex.Lock()
+	entry, ok := provider.entries[username]
+	provider.mutex.Unlock()
+	if !ok {
+		return nil, ErrIdentityNotFound
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(entry.hashedPassword), []byte(password)) != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	return &Identity{Username: username, Roles: entry.roles}, nil
+}
+
+func (provider *HtpasswdProvider) reload() error {
+	/* This (unexported) function re-reads the htpasswd file if it has been modified since
+	it was last read. A file that can't be parsed is reported and the previous entries kept */
+	info, err := os.Stat(provider.path)
+	if err != nil {
+		return fmt.Errorf("could not read htpasswd file: %v", err)
+	}
+
+	provider.mutex.Lock()
+	defer provider.mutex.Unlock()
+	if provider.entries != nil && info.ModTime().Equal(provider.modTime) {
+		return nil
+	}
+
+	entries, err := parseHtpasswd(provider.path, provider.defaultRoles)
+	if err != nil {
+		return err
+	}
+	provider.entries = entries
+	provider.modTime = info.ModTime()
+
+	return nil
+}
+
+func parseHtpasswd(path string, defaultRoles []string) (map[string]htpasswdEntry, error) {
+	// This (unexported) function parses an htpasswd file, skipping blank lines and comments
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read htpasswd file: %v", err)
+	}
+	defer file.Close()
+
+	entries := map[string]htpasswdEntry{}
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Split(line, ":")
+		if len(fields) < 2 || len(fields) > 3 || fields[0] == "" {
+			return nil, fmt.Errorf("htpasswd file line %d is malformed", lineNumber)
+		}
+		if _, err := bcrypt.Cost([]byte(fields[1])); err != nil {
+			return nil, fmt.Errorf("htpasswd file line %d does not hold a bcrypt hash", lineNumber)
+		}
+
+		entry := htpasswdEntry{hashedPassword: fields[1], roles: defaultRoles}
+		if len(fields) == 3 && fields[2] != "" {
+			entry.roles = strings.Split(fields[2], ",")
+		}
+		entries[fields[0]] = entry
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("could not read htpasswd file: %v", err)
+	}
+
+	return entries, nil
+}
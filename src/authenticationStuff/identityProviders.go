@@ -0,0 +1,149 @@
+package authentication
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/nicholasbunn/mastersSandbox/src/logging"
+)
+
+// Errors returned by identity providers
+var (
+	ErrIdentityNotFound   = errors.New("identity provider does not know the user")
+	ErrInvalidCredentials = errors.New("invalid username or password")
+)
+
+// LocalProviderName is the name of the provider backed by the user store, users created before providers existed belong to it
+const LocalProviderName = "local"
+
+type Identity struct {
+	/* This struct describes a user whose credentials were checked by an identity
+	provider, and the roles the provider grants them */
+	Username string
+	Roles    []string
+	Provider string // The name of the provider that checked the credentials
+}
+
+type IdentityProvider interface {
+	/* This interface describes a backend that checks usernames and passwords. Authenticate
+	returns ErrIdentityNotFound if the provider doesn't know the user (so that the next
+	provider can be asked), ErrInvalidCredentials if it knows the user but the password is
+	wrong, and any other error if it couldn't check the credentials at all */
+	Name() string
+	Authenticate(username string, password string) (*Identity, error)
+}
+
+type IdentityProviderConfig struct {
+	/* This struct describes an identity provider in a service's configuration file.
+	Type is "local", "htpasswd" or "ldap", and Name defaults to the type */
+	Type         string     `yaml:"type"`
+	Name         string     `yaml:"name"`
+	File         string     `yaml:"file"`         // The htpasswd file
+	DefaultRoles []string   `yaml:"defaultRoles"` // Roles for htpasswd entries that don't list any, and for directory users in no mapped group
+	LDAP         LDAPConfig `yaml:"ldap"`
+}
+
+type IdentityProviders struct {
+	/* This struct checks credentials against a list of identity providers in order. The
+	first provider that knows the user decides whether the password is right, providers that
+	can't be reached are skipped so that the next one can be tried */
+	providers []IdentityProvider
+}
+
+func NewIdentityProviders(configs []IdentityProviderConfig, store UserStore) (*IdentityProviders, error) {
+	/* This function creates the identity providers described by the provided configs, in
+	order. Without any configs, only the user store is used */
+	if len(configs) == 0 {
+		configs = []IdentityProviderConfig{{Type: LocalProviderName}}
+	}
+
+	chain := &IdentityProviders{}
+	names := map[string]bool{}
+	for index, config := range configs {
+		name := config.Name
+		if name == "" {
+			name = config.Type
+		}
+		if names[name] {
+			return nil, fmt.Errorf("identity provider %d has the same name as an earlier provider: %q", index, name)
+		}
+		names[name] = true
+
+		var provider IdentityProvider
+		var err error
+		switch config.Type {
+		case LocalProviderName:
+			provider = &LocalProvider{Store: store}
+		case "htpasswd":
+			provider, err = NewHtpasswdProvider(name, config.File, config.DefaultRoles)
+		case "ldap":
+			provider, err = NewLDAPProvider(name, config.LDAP, config.DefaultRoles)
+		default:
+			err = fmt.Errorf("unknown type %q", config.Type)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not create identity provider %q: %v", name, err)
+		}
+		chain.providers = append(chain.providers, provider)
+	}
+
+	return chain, nil
+}
+
+func (chain *IdentityProviders) Authenticate(username string, password string) (*Identity, error) {
+	/* This function checks the provided credentials against each provider in turn. It
+	returns ErrIdentityNotFound if no provider knows the user and ErrInvalidCredentials if
+	the password is wrong. If a provider couldn't be reached and no later provider knows
+	the user, that provider's error is returned instead, since the user may well exist */
+	var unavailable error
+	for _, provider := range chain.providers {
+		identity, err := provider.Authenticate(username, password)
+		switch err {
+		case nil:
+			identity.Provider = provider.Name()
+			return identity, nil
+		case ErrIdentityNotFound:
+			continue
+		case ErrInvalidCredentials:
+			return nil, err
+		default:
+			logging.Logger.Warnf("Identity provider %q is unavailable, trying the next one: %v", provider.Name(), err)
+			if unavailable == nil {
+				unavailable = fmt.Errorf("identity provider %q is unavailable: %v", provider.Name(), err)
+			}
+		}
+	}
+
+	if unavailable != nil {
+		return nil, unavailable
+	}
+	return nil, ErrIdentityNotFound
+}
+
+type LocalProvider struct {
+	/* This struct is an IdentityProvider that checks passwords against the users in the
+	user store. Records that the store keeps for users of other providers are ignored */
+	Store UserStore
+}
+
+func (provider *LocalProvider) Name() string {
+	return LocalProviderName
+}
+
+func (provider *LocalProvider) Authenticate(username string, password string) (*Identity, error) {
+	// This function checks the provided credentials against the user store
+	user, err := provider.Store.Find(username)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || !user.IsLocal() {
+		CheckCredentials(nil, password) // Take as long as checking a wrong password would
+		return nil, ErrIdentityNotFound
+	}
+
+	if !user.CheckPassword(password) {
+		return nil, ErrInvalidCredentials
+	}
+
+	return &Identity{Username: user.Username, Roles: user.Roles}, nil
+}
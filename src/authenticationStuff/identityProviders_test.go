@@ -0,0 +1,115 @@
+package authentication
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func writeHtpasswd(t *testing.T, lines ...string) string {
+	// This helper writes an htpasswd file to a temporary directory and returns its path
+	htpasswdPath := filepath.Join(t.TempDir(), "htpasswd")
+	if err := ioutil.WriteFile(htpasswdPath, []byte(strings.Join(lines, "\n")), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return htpasswdPath
+}
+
+func htpasswdHash(t *testing.T, password string) string {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(hashedPassword)
+}
+
+func TestIdentityProviders(t *testing.T) {
+	// The user store holds a local user, and the record of a directory user (which has no password)
+	store, err := NewFileUserStore(filepath.Join(t.TempDir(), "users.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	local, err := CreateUser("alice", "localPassword", "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, user := range []*User{local, {Username: "bob", Roles: []string{"analyst"}, Provider: "directory"}} {
+		if err := store.Save(user); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	htpasswdPath := writeHtpasswd(t,
+		"# Lab accounts",
+		"alice:"+htpasswdHash(t, "htpasswdPassword"),
+		"bob:"+htpasswdHash(t, "bobPassword"),
+		"carol:"+strings.Replace(htpasswdHash(t, "carolPassword"), "$2a$", "$2y$", 1)+":analyst,guest",
+	)
+	chain, err := NewIdentityProviders([]IdentityProviderConfig{
+		{Type: "ldap", Name: "unreachable", LDAP: testLDAPConfig("ldap://127.0.0.1:1")},
+		{Type: "local"},
+		{Type: "htpasswd", File: htpasswdPath, DefaultRoles: []string{"guest"}},
+	}, store)
+	if err != nil {
+		t.Fatal("Failed to create identity providers: ", err)
+	}
+
+	var Tests = []struct {
+		name             string
+		username         string
+		password         string
+		expectedProvider string
+		expectedRoles    []string
+		expectedError    error
+	}{
+		{"Unreachable providers are skipped", "alice", "localPassword", "local", []string{"admin"}, nil},
+		{"The first provider that knows a user decides", "alice", "htpasswdPassword", "", nil, ErrInvalidCredentials},
+		{"Records of other providers' users are ignored by the user store", "bob", "bobPassword", "htpasswd", []string{"guest"}, nil},
+		{"Htpasswd entries can list roles and use $2y$ hashes", "carol", "carolPassword", "htpasswd", []string{"analyst", "guest"}, nil},
+		{"Wrong htpasswd passwords are rejected", "carol", "localPassword", "", nil, ErrInvalidCredentials},
+	}
+
+	for _, test := range Tests {
+		t.Run(test.name, func(t *testing.T) {
+			identity, err := chain.Authenticate(test.username, test.password)
+			if err != test.expectedError {
+				t.Fatal("Expected error ", test.expectedError, ", received ", err)
+			}
+			if err == nil && (identity.Provider != test.expectedProvider || !reflect.DeepEqual(identity.Roles, test.expectedRoles)) {
+				t.Error("Expected ", test.expectedProvider, " to grant ", test.expectedRoles, ", received ", identity)
+			}
+		})
+	}
+
+	t.Run("Users nobody knows are reported as unavailable while a provider is unreachable", func(t *testing.T) {
+		if _, err := chain.Authenticate("dave", "password"); err == nil || err == ErrIdentityNotFound || err == ErrInvalidCredentials {
+			t.Error("Expected the unreachable provider's error, received ", err)
+		}
+	})
+
+	t.Run("Users nobody knows are reported as not found", func(t *testing.T) {
+		localOnly, err := NewIdentityProviders(nil, store)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := localOnly.Authenticate("dave", "password"); err != ErrIdentityNotFound {
+			t.Error("Expected ErrIdentityNotFound, received ", err)
+		}
+	})
+
+	t.Run("Invalid provider lists are rejected", func(t *testing.T) {
+		for _, configs := range [][]IdentityProviderConfig{
+			{{Type: "kerberos"}},
+			{{Type: "local"}, {Type: "local"}},
+			{{Type: "htpasswd", File: writeHtpasswd(t, "alice:not a hash")}},
+		} {
+			if _, err := NewIdentityProviders(configs, store); err == nil {
+				t.Error("Expected ", configs, " to be rejected")
+			}
+		}
+	})
+}
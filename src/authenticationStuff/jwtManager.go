@@ -7,54 +7,144 @@ import (
 	"github.com/dgrijalva/jwt-go"
 )
 
+// MFAAudience is the audience of partial tokens, no service uses it so they are refused everywhere as access tokens
+const MFAAudience = "mfa"
+
 type JWTManager struct {
 	/* This struct is a JSON web token (JWT) manager, it
-	describes the info of the JWT */
-	SecretKey     string
+	describes the info of the JWT. Tokens signed before the secret
+	was rotated are accepted until they would have expired */
+	Secret        *Secret
 	TokenDuration time.Duration
 }
 
 type UserClaims struct {
 	/* This is a custom JWT claim that describes the information
-	that a JWT will contain about the user */
+	that a JWT will contain about the user. Tokens issued by a token
+	exchange also carry an audience (the only service that accepts them)
+	and the service acting on the user's behalf. Tokens issued for a login
+	carry its session, and so do the tokens exchanged for them */
 	jwt.StandardClaims
-	Username string `json:"username"`
-	Role     string `json:"role"`
+	Username  string      `json:"username"`
+	Roles     []string    `json:"roles"`
+	Scopes    []string    `json:"scopes"`
+	SessionID string      `json:"sid,omitempty"`
+	Actor     *ActorClaim `json:"act,omitempty"`
+}
+
+type ActorClaim struct {
+	/* This struct describes the service acting on a user's behalf (see RFC 8693). If that
+	service was itself acting on behalf of another service, the chain is nested in Actor */
+	Subject string      `json:"sub"`
+	Actor   *ActorClaim `json:"act,omitempty"`
 }
 
-func NewJWTManager(sercretKey string, tokenDuration time.Duration) *JWTManager {
+func NewJWTManager(secret *Secret, tokenDuration time.Duration) *JWTManager {
 	// This function returns a new JWT manager
-	return &JWTManager{sercretKey, tokenDuration}
+	return &JWTManager{secret, tokenDuration}
 }
 
-func (manager *JWTManager) GenerateManager(user *User) (string, error) {
-	// This function generates and returns a signed JWT
+func (manager *JWTManager) GenerateManager(user *User, scopes []string, sessionID string) (string, error) {
+	// This function generates and returns a signed JWT carrying the user's roles, the scopes derived from them and the session it belongs to
 	claims := UserClaims{
 		StandardClaims: jwt.StandardClaims{
 			ExpiresAt: time.Now().Add(manager.TokenDuration).Unix(),
 		},
-		Username: user.Username,
-		Role:     user.Role,
+		Username:  user.Username,
+		Roles:     user.Roles,
+		Scopes:    scopes,
+		SessionID: sessionID,
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims) // Consider using something a bit stronger for production
-	return token.SignedString([]byte(manager.SecretKey))
+	return token.SignedString([]byte(manager.Secret.Value()))
+}
+
+func (manager *JWTManager) GeneratePartialToken(user *User, lifetime time.Duration) (string, error) {
+	/* This function generates the token returned by the first step of a login that needs a
+	second factor. It only identifies the user, carries no roles or scopes, and is restricted
+	to MFAAudience so that it can't be used in place of an access token */
+	now := time.Now()
+	claims := UserClaims{
+		StandardClaims: jwt.StandardClaims{
+			Audience:  MFAAudience,
+			ExpiresAt: now.Add(lifetime).Unix(),
+			IssuedAt:  now.Unix(),
+		},
+		Username: user.Username,
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(manager.Secret.Value()))
+}
+
+func (manager *JWTManager) ExchangeToken(subject *UserClaims, audience string, actor string, lifetime time.Duration) (string, error) {
+	/* This function generates a token that lets the provided service (the actor) call the
+	audience on behalf of the subject. The token carries the subject's roles and scopes, is
+	only accepted by the audience, and expires after the provided lifetime or when the
+	subject's own token does, whichever is sooner */
+	now := time.Now()
+	expiresAt := now.Add(lifetime).Unix()
+	if subject.ExpiresAt != 0 && subject.ExpiresAt < expiresAt {
+		expiresAt = subject.ExpiresAt
+	}
+
+	claims := UserClaims{
+		StandardClaims: jwt.StandardClaims{
+			Audience:  audience,
+			ExpiresAt: expiresAt,
+			IssuedAt:  now.Unix(),
+		},
+		Username:  subject.Username,
+		Roles:     subject.Roles,
+		Scopes:    subject.Scopes,
+		SessionID: subject.SessionID,
+		Actor:     &ActorClaim{Subject: actor, Actor: subject.Actor},
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(manager.Secret.Value()))
+}
+
+func (claims *UserClaims) ActorChain() []string {
+	// This function lists the services acting on the user's behalf, starting with the most recent one
+	chain := []string{}
+	for actor := claims.Actor; actor != nil; actor = actor.Actor {
+		chain = append(chain, actor.Subject)
+	}
+
+	return chain
+}
+
+func (claims *UserClaims) AcceptedBy(audience string) bool {
+	// This function reports whether the token may be presented to the provided service, tokens without an audience are accepted by every service
+	return claims.Audience == "" || claims.Audience == audience
 }
 
 func (manager *JWTManager) VerifyJWT(accessToken string) (*UserClaims, error) {
 	// This function verifies the provided JWT
-	token, err := jwt.ParseWithClaims(
-		accessToken,
-		&UserClaims{},
-		func(token *jwt.Token) (interface{}, error) {
-			_, ok := token.Method.(*jwt.SigningMethodHMAC)
-			if !ok {
-				return nil, fmt.Errorf("unexpected token signing method")
-			}
-			return []byte(manager.SecretKey), nil
-		},
-	)
+	var token *jwt.Token
+	var err error
+	for _, secret := range manager.Secret.Values(manager.TokenDuration) {
+		token, err = jwt.ParseWithClaims(
+			accessToken,
+			&UserClaims{},
+			func(token *jwt.Token) (interface{}, error) {
+				_, ok := token.Method.(*jwt.SigningMethodHMAC)
+				if !ok {
+					return nil, fmt.Errorf("unexpected token signing method")
+				}
+				return []byte(secret), nil
+			},
+		)
+		// Only a bad signature is worth retrying with the previous secret
+		if validationError, ok := err.(*jwt.ValidationError); !ok || validationError.Errors&jwt.ValidationErrorSignatureInvalid == 0 {
+			break
+		}
+	}
 
 	if err != nil {
+		// Report expiry separately so that callers can prompt the user to log in again
+		if validationError, ok := err.(*jwt.ValidationError); ok && validationError.Errors&jwt.ValidationErrorExpired != 0 {
+			return nil, ErrTokenExpired
+		}
 		return nil, fmt.Errorf("invalid token: %v", err)
 	}
 
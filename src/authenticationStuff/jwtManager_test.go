@@ -0,0 +1,95 @@
+package authentication
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestExchangeToken(t *testing.T) {
+	manager := NewJWTManager(StaticSecret("secret"), 15*time.Minute)
+	user := &User{Username: "analyst", Roles: []string{"analyst"}}
+
+	userToken, err := manager.GenerateManager(user, []string{"estimation:run"}, "session")
+	if err != nil {
+		t.Fatal(err)
+	}
+	subject, err := manager.VerifyJWT(userToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Exchange the user's token at the gateway, then exchange the result again at the aggregator
+	gatewayToken, err := manager.ExchangeToken(subject, "powerestimationsp", "desktopgateway", time.Minute)
+	if err != nil {
+		t.Fatal("Failed to exchange token: ", err)
+	}
+	gatewayClaims, err := manager.VerifyJWT(gatewayToken)
+	if err != nil {
+		t.Fatal("Failed to verify exchanged token: ", err)
+	}
+	aggregatorToken, err := manager.ExchangeToken(gatewayClaims, "estimateservice", "powerestimationsp", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aggregatorClaims, err := manager.VerifyJWT(aggregatorToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Exchanged tokens keep the user's identity", func(t *testing.T) {
+		if aggregatorClaims.Username != user.Username || !reflect.DeepEqual(aggregatorClaims.Roles, user.Roles) || !reflect.DeepEqual(aggregatorClaims.Scopes, subject.Scopes) {
+			t.Error("Exchanged token does not carry the user's identity: ", aggregatorClaims)
+		}
+	})
+
+	t.Run("Exchanged tokens record every service in the chain", func(t *testing.T) {
+		expected := []string{"powerestimationsp", "desktopgateway"}
+		if chain := aggregatorClaims.ActorChain(); !reflect.DeepEqual(chain, expected) {
+			t.Error("Expected actor chain ", expected, ", received ", chain)
+		}
+	})
+
+	t.Run("Exchanged tokens are only accepted by their audience", func(t *testing.T) {
+		if !gatewayClaims.AcceptedBy("powerestimationsp") || gatewayClaims.AcceptedBy("estimateservice") {
+			t.Error("Audience restriction was not applied")
+		}
+		if !subject.AcceptedBy("powerestimationsp") {
+			t.Error("Tokens without an audience should be accepted by every service")
+		}
+	})
+
+	t.Run("Exchanged tokens belong to the user's session", func(t *testing.T) {
+		if aggregatorClaims.SessionID != "session" {
+			t.Error("Expected the exchanged token to carry the session, received ", aggregatorClaims.SessionID)
+		}
+	})
+
+	t.Run("Exchanged tokens never outlive their subject", func(t *testing.T) {
+		if aggregatorClaims.ExpiresAt > gatewayClaims.ExpiresAt {
+			t.Error("Exchanged token expires after the token it was exchanged for")
+		}
+	})
+}
+
+func TestGeneratePartialToken(t *testing.T) {
+	manager := NewJWTManager(StaticSecret("secret"), 15*time.Minute)
+
+	token, err := manager.GeneratePartialToken(&User{Username: "admin", Roles: []string{"admin"}}, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := manager.VerifyJWT(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if claims.Username != "admin" || len(claims.Roles) > 0 || len(claims.Scopes) > 0 {
+		t.Error("Partial tokens should identify the user without granting anything: ", claims)
+	}
+	for _, service := range []string{"authenticationservice", "desktopgateway", ""} {
+		if claims.AcceptedBy(service) {
+			t.Error("Partial tokens should not be accepted as access tokens by ", service)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package authentication
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+type LDAPConfig struct {
+	/* This struct describes a directory to authenticate users against. Users are found by
+	searching UserBase with UserFilter (the username replaces %s), and their password is
+	checked by binding as them. Their groups are found by searching GroupBase with GroupFilter
+	(the user's DN replaces %s), and mapped to roles with RoleMapping, keyed by the group's
+	GroupAttribute or its DN. Searches are made as BindDN, or anonymously if it isn't set */
+	URL            string              `yaml:"url"`      // ldap://host:389 or ldaps://host:636
+	StartTLS       bool                `yaml:"startTLS"` // Upgrade ldap:// connections to TLS before sending any credentials
+	CA             string              `yaml:"ca"`       // CA used to verify the directory's certificate, the system pool is used if empty
+	BindDN         string              `yaml:"bindDN"`
+	BindPassword   string              `yaml:"bindPassword" secret:"true"`
+	UserBase       string              `yaml:"userBase"`
+	UserFilter     string              `yaml:"userFilter"`
+	GroupBase      string              `yaml:"groupBase"`
+	GroupFilter    string              `yaml:"groupFilter"`
+	GroupAttribute string              `yaml:"groupAttribute"`
+	RoleMapping    map[string][]string `yaml:"roleMapping"`
+	Timeout        int                 `yaml:"timeout"` // Duration (in seconds) to wait for the directory
+}
+
+type LDAPProvider struct {
+	/* This struct is an IdentityProvider that checks passwords by binding to an LDAP
+	directory, and grants roles based on the user's groups */
+	name         string
+	config       LDAPConfig
+	defaultRoles []string
+	tlsConfig    *tls.Config
+	timeout      time.Duration
+}
+
+func NewLDAPProvider(name string, config LDAPConfig, defaultRoles []string) (*LDAPProvider, error) {
+	// This function checks the provided directory settings and returns a provider for the directory
+	address, err := url.Parse(config.URL)
+	if err != nil || (address.Scheme != "ldap" && address.Scheme != "ldaps") || address.Host == "" {
+		return nil, fmt.Errorf("invalid directory url %q", config.URL)
+	}
+	if config.UserBase == "" || !strings.Contains(config.UserFilter, "%s") {
+		return nil, fmt.Errorf("a user base and a user filter containing %%s are required")
+	}
+	if config.GroupFilter != "" && !strings.Contains(config.GroupFilter, "%s") {
+		return nil, fmt.Errorf("the group filter has to contain %%s")
+	}
+	if config.GroupAttribute == "" {
+		config.GroupAttribute = "cn"
+	}
+
+	provider := &LDAPProvider{
+		name:         name,
+		config:       config,
+		defaultRoles: defaultRoles,
+		timeout:      time.Duration(config.Timeout) * time.Second,
+	}
+	if provider.timeout <= 0 {
+		provider.timeout = 5 * time.Second
+	}
+
+	host, _, err := net.SplitHostPort(address.Host)
+	if err != nil {
+		host = address.Host
+	}
+	provider.tlsConfig = &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}
+	if config.CA != "" {
+		pemCA, err := ioutil.ReadFile(config.CA)
+		if err != nil {
+			return nil, fmt.Errorf("could not read directory CA: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pemCA) {
+			return nil, fmt.Errorf("could not parse directory CA")
+		}
+		provider.tlsConfig.RootCAs = pool
+	}
+
+	return provider, nil
+}
+
+func (provider *LDAPProvider) Name() string {
+	return provider.name
+}
+
+func (provider *LDAPProvider) Authenticate(username string, password string) (*Identity, error) {
+	/* This function finds the user in the directory, checks their password by binding as
+	them, and maps their groups to roles */
+	if password == "" {
+		// An empty password would make a simple bind unauthenticated, which directories accept for any DN
+		return nil, ErrInvalidCredentials
+	}
+
+	conn, err := provider.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	// Find the user's DN
+	if err := provider.bindService(conn); err != nil {
+		return nil, err
+	}
+	users, err := conn.Search(ldap.NewSearchRequest(
+		provider.config.UserBase, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 2, int(provider.timeout/time.Second), false,
+		fmt.Sprintf(provider.config.UserFilter, ldap.EscapeFilter(username)),
+		[]string{"dn"},
+		nil,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("could not search for user: %v", err)
+	}
+	if len(users.Entries) == 0 {
+		return nil, ErrIdentityNotFound
+	} else if len(users.Entries) > 1 {
+		return nil, fmt.Errorf("user filter matched more than one entry for %q", username)
+	}
+	userDN := users.Entries[0].DN
+
+	// Check the password
+	if err := conn.Bind(userDN, password); ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+		return nil, ErrInvalidCredentials
+	} else if err != nil {
+		return nil, fmt.Errorf("could not bind as user: %v", err)
+	}
+
+	roles, err := provider.roles(conn, userDN)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Identity{Username: username, Roles: roles}, nil
+}
+
+func (provider *LDAPProvider) connect() (*ldap.Conn, error) {
+	// This (unexported) function connects to the directory, upgrading the connection to TLS if configured to
+	conn, err := ldap.DialURL(
+		provider.config.URL,
+		ldap.DialWithDialer(&net.Dialer{Timeout: provider.timeout}),
+		ldap.DialWithTLSConfig(provider.tlsConfig),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to directory: %v", err)
+	}
+	conn.SetTimeout(provider.timeout)
+
+	if provider.config.StartTLS && strings.HasPrefix(provider.config.URL, "ldap://") {
+		if err := conn.StartTLS(provider.tlsConfig); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("could not start tls with directory: %v", err)
+		}
+	}
+
+	return conn, nil
+}
+
+func (provider *LDAPProvider) bindService(conn *ldap.Conn) error {
+	// This (unexported) function binds as the search account, or anonymously if there isn't one
+	var err error
+	if provider.config.BindDN == "" {
+		err = conn.UnauthenticatedBind("")
+	} else {
+		err = conn.Bind(provider.config.BindDN, provider.config.BindPassword)
+	}
+	if err != nil {
+		return fmt.Errorf("could not bind to directory for searching: %v", err)
+	}
+
+	return nil
+}
+
+func (provider *LDAPProvider) roles(conn *ldap.Conn, userDN string) ([]string, error) {
+	/* This (unexported) function maps the groups the user is a member of to roles. Users
+	in no mapped group get the provider's default roles */
+	if provider.config.GroupFilter == "" {
+		return provider.defaultRoles, nil
+	}
+
+	// Search as the search account again, the user might not be allowed to read groups
+	if err := provider.bindService(conn); err != nil {
+		return nil, err
+	}
+	groups, err := conn.Search(ldap.NewSearchRequest(
+		provider.config.GroupBase, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, int(provider.timeout/time.Second), false,
+		fmt.Sprintf(provider.config.GroupFilter, ldap.EscapeFilter(userDN)),
+		[]string{provider.config.GroupAttribute},
+		nil,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("could not search for groups: %v", err)
+	}
+
+	resolved := map[string]bool{}
+	for _, group := range groups.Entries {
+		keys := append([]string{group.DN}, group.GetAttributeValues(provider.config.GroupAttribute)...)
+		for _, key := range keys {
+			for _, role := range provider.config.RoleMapping[key] {
+				resolved[role] = true
+			}
+		}
+	}
+	if len(resolved) == 0 {
+		return provider.defaultRoles, nil
+	}
+
+	return sortedKeys(resolved), nil
+}
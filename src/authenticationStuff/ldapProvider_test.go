@@ -0,0 +1,243 @@
+package authentication
+
+import (
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+)
+
+type fakeDirectoryEntry struct {
+	dn         string
+	password   string // Entries without a password can't be bound as
+	attributes map[string][]string
+}
+
+type fakeDirectory struct {
+	/* This struct is a minimal in-process LDAP server for testing the LDAP provider. It
+	supports simple binds and searches with equality, presence, and, or filters */
+	entries []fakeDirectoryEntry
+}
+
+func startFakeDirectory(t *testing.T, entries []fakeDirectoryEntry) string {
+	// This helper starts a fake directory holding the provided entries and returns its URL
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	directory := &fakeDirectory{entries: entries}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go directory.serve(conn)
+		}
+	}()
+
+	return "ldap://" + listener.Addr().String()
+}
+
+func (directory *fakeDirectory) serve(conn net.Conn) {
+	defer conn.Close()
+
+	for {
+		request, err := ber.ReadPacket(conn)
+		if err != nil || len(request.Children) < 2 {
+			return
+		}
+		messageID, _ := request.Children[0].Value.(int64)
+		operation := request.Children[1]
+
+		switch operation.Tag {
+		case ldap.ApplicationBindRequest:
+			name := operation.Children[1].Data.String()
+			password := operation.Children[2].Data.String()
+			resultCode := uint16(ldap.LDAPResultInvalidCredentials)
+			if entry := directory.find(name); (name == "" && password == "") || (entry != nil && entry.password != "" && entry.password == password) {
+				resultCode = ldap.LDAPResultSuccess
+			}
+			conn.Write(fakeMessage(messageID, fakeResult(ldap.ApplicationBindResponse, resultCode)))
+
+		case ldap.ApplicationSearchRequest:
+			base := strings.ToLower(operation.Children[0].Data.String())
+			filter := operation.Children[6]
+			for _, entry := range directory.entries {
+				if strings.HasSuffix(strings.ToLower(entry.dn), base) && fakeMatches(entry, filter) {
+					conn.Write(fakeMessage(messageID, fakeSearchEntry(entry)))
+				}
+			}
+			conn.Write(fakeMessage(messageID, fakeResult(ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess)))
+
+		default:
+			return
+		}
+	}
+}
+
+func (directory *fakeDirectory) find(dn string) *fakeDirectoryEntry {
+	for index := range directory.entries {
+		if strings.EqualFold(directory.entries[index].dn, dn) {
+			return &directory.entries[index]
+		}
+	}
+	return nil
+}
+
+func fakeMatches(entry fakeDirectoryEntry, filter *ber.Packet) bool {
+	// This helper evaluates the subset of search filters that the fake directory supports
+	switch filter.Tag {
+	case ldap.FilterAnd:
+		for _, child := range filter.Children {
+			if !fakeMatches(entry, child) {
+				return false
+			}
+		}
+		return true
+	case ldap.FilterOr:
+		for _, child := range filter.Children {
+			if fakeMatches(entry, child) {
+				return true
+			}
+		}
+	case ldap.FilterEqualityMatch:
+		for _, value := range entry.attributes[strings.ToLower(filter.Children[0].Data.String())] {
+			if strings.EqualFold(value, filter.Children[1].Data.String()) {
+				return true
+			}
+		}
+	case ldap.FilterPresent:
+		return len(entry.attributes[strings.ToLower(filter.Data.String())]) > 0
+	}
+
+	return false
+}
+
+func fakeMessage(messageID int64, operation *ber.Packet) []byte {
+	envelope := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+	envelope.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, messageID, "Message ID"))
+	envelope.AppendChild(operation)
+	return envelope.Bytes()
+}
+
+func fakeResult(operation ber.Tag, resultCode uint16) *ber.Packet {
+	result := ber.Encode(ber.ClassApplication, ber.TypeConstructed, operation, nil, "Result")
+	result.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, int64(resultCode), "Result Code"))
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Matched DN"))
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Diagnostic Message"))
+	return result
+}
+
+func fakeSearchEntry(entry fakeDirectoryEntry) *ber.Packet {
+	result := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "Search Result Entry")
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, entry.dn, "DN"))
+	attributes := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attributes")
+	for name, values := range entry.attributes {
+		attribute := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attribute")
+		attribute.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "Type"))
+		set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "Values")
+		for _, value := range values {
+			set.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, "Value"))
+		}
+		attribute.AppendChild(set)
+		attributes.AppendChild(attribute)
+	}
+	result.AppendChild(attributes)
+	return result
+}
+
+var testDirectory = []fakeDirectoryEntry{
+	{dn: "cn=reader,dc=example,dc=org", password: "readerPassword", attributes: map[string][]string{"cn": {"reader"}}},
+	{dn: "uid=alice,ou=people,dc=example,dc=org", password: "alicePassword", attributes: map[string][]string{"uid": {"alice"}}},
+	{dn: "uid=bob,ou=people,dc=example,dc=org", password: "bobPassword", attributes: map[string][]string{"uid": {"bob"}}},
+	{dn: "cn=estimators,ou=groups,dc=example,dc=org", attributes: map[string][]string{
+		"cn":     {"estimators"},
+		"member": {"uid=alice,ou=people,dc=example,dc=org"},
+	}},
+	{dn: "cn=lab,ou=groups,dc=example,dc=org", attributes: map[string][]string{
+		"cn":     {"lab"},
+		"member": {"uid=alice,ou=people,dc=example,dc=org"},
+	}},
+}
+
+func testLDAPConfig(url string) LDAPConfig {
+	return LDAPConfig{
+		URL:          url,
+		BindDN:       "cn=reader,dc=example,dc=org",
+		BindPassword: "readerPassword",
+		UserBase:     "ou=people,dc=example,dc=org",
+		UserFilter:   "(uid=%s)",
+		GroupBase:    "ou=groups,dc=example,dc=org",
+		GroupFilter:  "(member=%s)",
+		RoleMapping: map[string][]string{
+			"estimators":                         {"analyst"},
+			"cn=lab,ou=groups,dc=example,dc=org": {"guest"},
+		},
+		Timeout: 2,
+	}
+}
+
+func TestLDAPProvider(t *testing.T) {
+	url := startFakeDirectory(t, testDirectory)
+	provider, err := NewLDAPProvider("directory", testLDAPConfig(url), []string{"guest"})
+	if err != nil {
+		t.Fatal("Failed to create LDAP provider: ", err)
+	}
+
+	var Tests = []struct {
+		name          string
+		username      string
+		password      string
+		expectedRoles []string
+		expectedError error
+	}{
+		{"Groups are mapped to roles by name and by DN", "alice", "alicePassword", []string{"analyst", "guest"}, nil},
+		{"Users in no mapped group get the default roles", "bob", "bobPassword", []string{"guest"}, nil},
+		{"Wrong passwords are rejected", "alice", "bobPassword", nil, ErrInvalidCredentials},
+		{"Empty passwords are rejected", "alice", "", nil, ErrInvalidCredentials},
+		{"Unknown users are passed on", "carol", "carolPassword", nil, ErrIdentityNotFound},
+		{"Usernames can't inject filters", "*", "alicePassword", nil, ErrIdentityNotFound},
+	}
+
+	for _, test := range Tests {
+		t.Run(test.name, func(t *testing.T) {
+			identity, err := provider.Authenticate(test.username, test.password)
+			if err != test.expectedError {
+				t.Fatal("Expected error ", test.expectedError, ", received ", err)
+			}
+			if err == nil && (identity.Username != test.username || !reflect.DeepEqual(identity.Roles, test.expectedRoles)) {
+				t.Error("Expected roles ", test.expectedRoles, ", received ", identity.Roles)
+			}
+		})
+	}
+
+	t.Run("A wrong search account makes the directory unavailable", func(t *testing.T) {
+		config := testLDAPConfig(url)
+		config.BindPassword = "wrong"
+		misconfigured, err := NewLDAPProvider("directory", config, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := misconfigured.Authenticate("alice", "alicePassword"); err == nil || err == ErrInvalidCredentials || err == ErrIdentityNotFound {
+			t.Error("Expected the directory to be reported as unavailable, received ", err)
+		}
+	})
+
+	t.Run("Invalid settings are rejected", func(t *testing.T) {
+		config := testLDAPConfig("http://" + strings.TrimPrefix(url, "ldap://"))
+		if _, err := NewLDAPProvider("directory", config, nil); err == nil {
+			t.Error("Expected a non-LDAP url to be rejected")
+		}
+		config = testLDAPConfig(url)
+		config.UserFilter = "(uid=alice)"
+		if _, err := NewLDAPProvider("directory", config, nil); err == nil {
+			t.Error("Expected a user filter without a placeholder to be rejected")
+		}
+	})
+}
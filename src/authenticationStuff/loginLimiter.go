@@ -0,0 +1,133 @@
+package authentication
+
+import (
+	"sync"
+	"time"
+)
+
+type LoginAttempts struct {
+	/* This struct records the failed login attempts made for a username or from an
+	address */
+	Failures    int
+	LastFailure time.Time
+	LockedUntil time.Time
+}
+
+type LoginLimiter struct {
+	/* This struct throttles failed logins. Each failure doubles the time that has to
+	pass before the next attempt is accepted (starting at Backoff and capped at
+	MaxBackoff), and MaxFailures consecutive failures lock the username or address out
+	for LockoutDuration. Attempts for known users are kept on the User, so that they are
+	persisted by the user store; attempts per address and for unknown usernames are kept
+	in memory by the limiter */
+	MaxFailures     int
+	Backoff         time.Duration
+	MaxBackoff      time.Duration
+	LockoutDuration time.Duration
+
+	mutex    sync.Mutex
+	trackers map[string]*LoginAttempts
+}
+
+func (attempts *LoginAttempts) Locked(now time.Time) bool {
+	// This function reports whether the attempts have triggered a lockout that is still active
+	return now.Before(attempts.LockedUntil)
+}
+
+func (limiter *LoginLimiter) RetryAfter(attempts *LoginAttempts, now time.Time) time.Duration {
+	// This function returns how long the caller has to wait before another attempt is accepted
+	if attempts.Locked(now) {
+		return attempts.LockedUntil.Sub(now)
+	}
+	if attempts.Failures == 0 || !attempts.LockedUntil.IsZero() {
+		// No failures, or a lockout that has since expired
+		return 0
+	}
+
+	if wait := attempts.LastFailure.Add(limiter.backoff(attempts.Failures)).Sub(now); wait > 0 {
+		return wait
+	}
+
+	return 0
+}
+
+func (limiter *LoginLimiter) RecordFailure(attempts *LoginAttempts, now time.Time) (locked bool) {
+	// This function records a failed attempt, returning true if the failure triggered a lockout
+	if !attempts.LockedUntil.IsZero() && !attempts.Locked(now) {
+		// The previous lockout has expired, so start counting afresh
+		*attempts = LoginAttempts{}
+	}
+
+	attempts.Failures++
+	attempts.LastFailure = now
+	if attempts.Failures >= limiter.MaxFailures {
+		attempts.LockedUntil = now.Add(limiter.LockoutDuration)
+		return true
+	}
+
+	return false
+}
+
+func (limiter *LoginLimiter) TrackedRetryAfter(key string, now time.Time) time.Duration {
+	// This function is RetryAfter for attempts tracked in memory by the limiter, such as per-address attempts
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	attempts, ok := limiter.trackers[key]
+	if !ok {
+		return 0
+	}
+
+	return limiter.RetryAfter(attempts, now)
+}
+
+func (limiter *LoginLimiter) TrackedFailure(key string, now time.Time) (locked bool) {
+	// This function is RecordFailure for attempts tracked in memory by the limiter
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	if limiter.trackers == nil {
+		limiter.trackers = map[string]*LoginAttempts{}
+	}
+	limiter.prune(now)
+
+	attempts, ok := limiter.trackers[key]
+	if !ok {
+		attempts = &LoginAttempts{}
+		limiter.trackers[key] = attempts
+	}
+
+	return limiter.RecordFailure(attempts, now)
+}
+
+func (limiter *LoginLimiter) TrackedReset(key string) {
+	// This function forgets the attempts tracked in memory under the provided key
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	delete(limiter.trackers, key)
+}
+
+func (limiter *LoginLimiter) backoff(failures int) time.Duration {
+	// This (unexported) function returns the delay enforced after the provided number of consecutive failures
+	delay := limiter.Backoff
+	for i := 1; i < failures && delay < limiter.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > limiter.MaxBackoff {
+		delay = limiter.MaxBackoff
+	}
+
+	return delay
+}
+
+func (limiter *LoginLimiter) prune(now time.Time) {
+	/* This (unexported) function drops in-memory records that no longer affect any
+	attempt, so that the tracker doesn't grow without bound. The caller must hold the
+	limiter's mutex */
+	for key, attempts := range limiter.trackers {
+		if limiter.RetryAfter(attempts, now) == 0 && now.Sub(attempts.LastFailure) > limiter.LockoutDuration+limiter.MaxBackoff {
+			delete(limiter.trackers, key)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package authentication
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoginLimiter(t *testing.T) {
+	limiter := &LoginLimiter{
+		MaxFailures:     3,
+		Backoff:         time.Second,
+		MaxBackoff:      4 * time.Second,
+		LockoutDuration: time.Minute,
+	}
+	start := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("Backoff doubles with each failure", func(t *testing.T) {
+		attempts := &LoginAttempts{}
+		limiter.RecordFailure(attempts, start)
+		if wait := limiter.RetryAfter(attempts, start); wait != time.Second {
+			t.Error("Expected a 1s backoff after one failure, received ", wait)
+		}
+		limiter.RecordFailure(attempts, start.Add(time.Second))
+		if wait := limiter.RetryAfter(attempts, start.Add(time.Second)); wait != 2*time.Second {
+			t.Error("Expected a 2s backoff after two failures, received ", wait)
+		}
+	})
+
+	t.Run("Repeated failures lock out until the lockout expires", func(t *testing.T) {
+		attempts := &LoginAttempts{}
+		locked := false
+		for i := 0; i < limiter.MaxFailures; i++ {
+			locked = limiter.RecordFailure(attempts, start)
+		}
+		if !locked || limiter.RetryAfter(attempts, start) != time.Minute {
+			t.Error("Expected a lockout after ", limiter.MaxFailures, " failures")
+		}
+		if wait := limiter.RetryAfter(attempts, start.Add(time.Minute)); wait != 0 {
+			t.Error("Expected the lockout to expire, received ", wait)
+		}
+		if limiter.RecordFailure(attempts, start.Add(time.Minute)); attempts.Failures != 1 {
+			t.Error("Expected failures to be counted afresh after a lockout, received ", attempts.Failures)
+		}
+	})
+
+	t.Run("Tracked attempts are independent per key", func(t *testing.T) {
+		limiter.TrackedFailure("address:10.0.0.1", start)
+		if limiter.TrackedRetryAfter("address:10.0.0.1", start) == 0 || limiter.TrackedRetryAfter("address:10.0.0.2", start) != 0 {
+			t.Error("Expected only the failing address to be throttled")
+		}
+		limiter.TrackedReset("address:10.0.0.1")
+		if limiter.TrackedRetryAfter("address:10.0.0.1", start) != 0 {
+			t.Error("Expected reset to clear the tracked attempts")
+		}
+	})
+}
@@ -0,0 +1,364 @@
+package authentication
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-yaml/yaml"
+	"github.com/nicholasbunn/mastersSandbox/src/logging"
+)
+
+type RoleDefinition struct {
+	/* This struct describes a role in the policy file. A role grants its own scopes
+	as well as every role (and therefore every scope) that it inherits. Users holding a
+	role that requires MFA have to log in with a second factor (TOTP), and so do users
+	holding a role that inherits it */
+	Inherits   []string `yaml:"inherits"`
+	Scopes     []string `yaml:"scopes"`
+	RequireMFA bool     `yaml:"requireMFA"`
+}
+
+type PolicyRule struct {
+	/* This struct describes a single authorisation rule. Methods are full gRPC method
+	names ("/Service/Method") and may contain wildcards (see path.Match). A rule is
+	satisfied if the caller holds any of the listed roles (directly or through
+	inheritance) and all of the listed scopes. Identities list the workloads (names on
+	their client certificates, which may also contain wildcards) that may call the
+	methods on their own behalf, without presenting a user's token */
+	Methods    []string `yaml:"methods"`
+	Public     bool     `yaml:"public"`
+	Roles      []string `yaml:"roles"`
+	Scopes     []string `yaml:"scopes"`
+	Identities []string `yaml:"identities"`
+}
+
+type Policy struct {
+	/* This struct describes a declarative authorisation policy, as loaded from a policy
+	file. Rules are evaluated in order and the first rule with a matching method pattern
+	is applied. Methods that match no rule are denied if DefaultDeny is set, and are
+	publicly accessible otherwise */
+	DefaultDeny bool                      `yaml:"defaultDeny"`
+	Roles       map[string]RoleDefinition `yaml:"roles"`
+	Rules       []PolicyRule              `yaml:"rules"`
+
+	effectiveRoles map[string][]string // Each role mapped to itself and every role it inherits
+}
+
+type PolicyManager struct {
+	/* This struct holds the active policy and reloads it whenever the policy
+	file changes on disk */
+	path    string
+	mutex   sync.RWMutex
+	policy  *Policy
+	modTime time.Time
+}
+
+func LoadPolicy(policyPath string) (*Policy, error) {
+	// This function reads, parses and validates the policy file at the provided path
+	contents, err := ioutil.ReadFile(policyPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not read policy file: %v", err)
+	}
+
+	policy := &Policy{}
+	if err := yaml.Unmarshal(contents, policy); err != nil {
+		return nil, fmt.Errorf("could not decode policy file: %v", err)
+	}
+
+	if err := policy.compile(); err != nil {
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+func (policy *Policy) compile() error {
+	/* This (unexported) function validates the policy and resolves role inheritance,
+	rejecting unknown roles, malformed patterns and inheritance cycles */
+
+	policy.effectiveRoles = map[string][]string{}
+	for role := range policy.Roles {
+		resolved := map[string]bool{}
+		if err := policy.resolveRole(role, resolved, map[string]bool{}); err != nil {
+			return err
+		}
+		policy.effectiveRoles[role] = sortedKeys(resolved)
+	}
+
+	for index, rule := range policy.Rules {
+		if len(rule.Methods) == 0 {
+			return fmt.Errorf("policy rule %d does not list any methods", index)
+		}
+		for _, pattern := range rule.Methods {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("policy rule %d has a malformed method pattern %q: %v", index, pattern, err)
+			}
+		}
+		for _, pattern := range rule.Identities {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("policy rule %d has a malformed identity pattern %q: %v", index, pattern, err)
+			}
+		}
+		for _, role := range rule.Roles {
+			if _, ok := policy.Roles[role]; !ok {
+				return fmt.Errorf("policy rule %d references unknown role %q", index, role)
+			}
+		}
+		if rule.Public && (len(rule.Roles) > 0 || len(rule.Scopes) > 0 || len(rule.Identities) > 0) {
+			return fmt.Errorf("policy rule %d is public but also lists roles, scopes or identities", index)
+		}
+	}
+
+	return nil
+}
+
+func (policy *Policy) resolveRole(role string, resolved map[string]bool, visiting map[string]bool) error {
+	// This (unexported) function walks the inheritance tree of a role, collecting every role it grants
+	definition, ok := policy.Roles[role]
+	if !ok {
+		return fmt.Errorf("policy references unknown role %q", role)
+	}
+	if visiting[role] {
+		return fmt.Errorf("policy role %q inherits from itself", role)
+	}
+
+	visiting[role] = true
+	resolved[role] = true
+	for _, parent := range definition.Inherits {
+		if err := policy.resolveRole(parent, resolved, visiting); err != nil {
+			return err
+		}
+	}
+	visiting[role] = false
+
+	return nil
+}
+
+func (policy *Policy) match(method string) *PolicyRule {
+	// This (unexported) function returns the first rule whose method patterns match the provided method
+	for index := range policy.Rules {
+		for _, pattern := range policy.Rules[index].Methods {
+			if matched, _ := path.Match(pattern, method); matched {
+				return &policy.Rules[index]
+			}
+		}
+	}
+
+	return nil
+}
+
+func (policy *Policy) EffectiveRoles(roles []string) []string {
+	// This function expands the provided roles to include every role they inherit
+	resolved := map[string]bool{}
+	for _, role := range roles {
+		for _, effective := range policy.effectiveRoles[role] {
+			resolved[effective] = true
+		}
+	}
+
+	return sortedKeys(resolved)
+}
+
+func (policy *Policy) Scopes(roles []string) []string {
+	// This function returns the scopes granted by the provided roles, including inherited ones
+	scopes := map[string]bool{}
+	for _, role := range policy.EffectiveRoles(roles) {
+		for _, scope := range policy.Roles[role].Scopes {
+			scopes[scope] = true
+		}
+	}
+
+	return sortedKeys(scopes)
+}
+
+func (policy *Policy) RequiresMFA(roles []string) bool {
+	// This function reports whether any of the provided roles (or a role they inherit) requires a second factor
+	for _, role := range policy.EffectiveRoles(roles) {
+		if policy.Roles[role].RequireMFA {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (policy *Policy) RequiresAuthentication(method string) bool {
+	/* This function reports whether a caller needs to present credentials for the
+	provided method */
+	rule := policy.match(method)
+	if rule == nil {
+		return policy.DefaultDeny
+	}
+
+	return !rule.Public
+}
+
+func (policy *Policy) Authorise(method string, roles []string, scopes []string) bool {
+	/* This function reports whether a caller holding the provided roles and scopes
+	may invoke the provided method */
+	rule := policy.match(method)
+	if rule == nil {
+		return !policy.DefaultDeny
+	}
+	if rule.Public {
+		return true
+	}
+
+	held := map[string]bool{}
+	for _, scope := range scopes {
+		held[scope] = true
+	}
+	for _, scope := range rule.Scopes {
+		if !held[scope] {
+			return false
+		}
+	}
+
+	if len(rule.Roles) == 0 {
+		// A rule that only lists identities is reserved for workloads, not for users or API keys
+		return len(rule.Scopes) > 0 || len(rule.Identities) == 0
+	}
+	for _, role := range policy.EffectiveRoles(roles) {
+		for _, required := range rule.Roles {
+			if role == required {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (policy *Policy) AuthoriseIdentity(method string, names []string) bool {
+	/* This function reports whether a workload, identified by the names on its verified
+	client certificate, may invoke the provided method without a user's token */
+	rule := policy.match(method)
+	if rule == nil {
+		return false
+	}
+
+	for _, pattern := range rule.Identities {
+		for _, name := range names {
+			if matched, _ := path.Match(pattern, name); matched {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func NewPolicyManager(policyPath string) (*PolicyManager, error) {
+	// This function loads the policy at the provided path and returns a manager for it
+	manager := &PolicyManager{path: policyPath}
+	if err := manager.Reload(); err != nil {
+		return nil, err
+	}
+
+	return manager, nil
+}
+
+func (manager *PolicyManager) Policy() *Policy {
+	// This function returns the currently active policy
+	manager.mutex.RLock()
+	defer manager.mutex.RUnlock()
+
+	return manager.policy
+}
+
+func (manager *PolicyManager) Reload() error {
+	/* This function re-reads the policy file. The active policy is only replaced if the
+	new file is valid, so a bad edit never leaves the service without a policy */
+	info, err := os.Stat(manager.path)
+	if err != nil {
+		return fmt.Errorf("could not read policy file: %v", err)
+	}
+
+	policy, err := LoadPolicy(manager.path)
+	if err != nil {
+		return err
+	}
+
+	manager.mutex.Lock()
+	manager.policy = policy
+	manager.modTime = info.ModTime()
+	manager.mutex.Unlock()
+
+	return nil
+}
+
+func (manager *PolicyManager) Watch(interval time.Duration) (stop func()) {
+	/* This function polls the policy file every interval and reloads it when it has
+	been modified. It returns a function that stops the watcher */
+	done := make(chan struct{})
+	ticker := time.NewTicker(interval)
+
+	manager.mutex.RLock()
+	lastSeen := manager.modTime
+	manager.mutex.RUnlock()
+
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				info, err := os.Stat(manager.path)
+				if err != nil {
+					logging.Logger.Warnln("Could not stat policy file, keeping the current policy: ", err)
+					continue
+				}
+
+				if info.ModTime().Equal(lastSeen) {
+					continue
+				}
+				lastSeen = info.ModTime() // Only attempt each edit once, a bad file is reported a single time
+
+				if err := manager.Reload(); err != nil {
+					logging.Logger.Warnln("Could not reload policy file, keeping the current policy: ", err)
+				} else {
+					logging.Logger.Infoln("Reloaded policy file ", manager.path)
+				}
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
+
+func (manager *PolicyManager) RequiresAuthentication(method string) bool {
+	return manager.Policy().RequiresAuthentication(method)
+}
+
+func (manager *PolicyManager) Authorise(method string, roles []string, scopes []string) bool {
+	return manager.Policy().Authorise(method, roles, scopes)
+}
+
+func (manager *PolicyManager) AuthoriseIdentity(method string, names []string) bool {
+	return manager.Policy().AuthoriseIdentity(method, names)
+}
+
+func (manager *PolicyManager) Scopes(roles []string) []string {
+	return manager.Policy().Scopes(roles)
+}
+
+func (manager *PolicyManager) RequiresMFA(roles []string) bool {
+	return manager.Policy().RequiresMFA(roles)
+}
+
+func sortedKeys(set map[string]bool) []string {
+	// This (unexported) function returns the keys of a set in a deterministic order
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,165 @@
+package authentication
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const testPolicy = `
+defaultDeny: true
+roles:
+  guest:
+    scopes: ["estimation:read"]
+  analyst:
+    inherits: ["guest"]
+    scopes: ["estimation:run"]
+    requireMFA: true
+  admin:
+    inherits: ["analyst"]
+    scopes: ["evaluation:run"]
+rules:
+  - methods: ["/LoginService/Login"]
+    public: true
+  - methods: ["/Package/Evaluate"]
+    roles: ["admin"]
+    scopes: ["evaluation:run"]
+  - methods: ["/Package/Fetch"]
+    identities: ["desktopgateway", "*.workers"]
+  - methods: ["/Package/*"]
+    roles: ["analyst"]
+`
+
+func writePolicy(t *testing.T, contents string) string {
+	// This helper writes a policy to a temporary file and returns its path
+	policyPath := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := ioutil.WriteFile(policyPath, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return policyPath
+}
+
+func TestPolicyAuthorise(t *testing.T) {
+	policy, err := LoadPolicy(writePolicy(t, testPolicy))
+	if err != nil {
+		t.Fatal("Failed to load test policy: ", err)
+	}
+
+	var Tests = []struct {
+		name           string
+		method         string
+		roles          []string
+		expectedOutput bool
+	}{
+		{"Public methods are always allowed", "/LoginService/Login", nil, true},
+		{"Wildcard rules match every method in a service", "/Package/Estimate", []string{"analyst"}, true},
+		{"Inherited roles satisfy a rule", "/Package/Estimate", []string{"admin"}, true},
+		{"Roles lower in the hierarchy are refused", "/Package/Estimate", []string{"guest"}, false},
+		{"The first matching rule takes precedence", "/Package/Evaluate", []string{"analyst"}, false},
+		{"Any one of multiple roles is sufficient", "/Package/Evaluate", []string{"guest", "admin"}, true},
+		{"Unmatched methods are denied by default", "/Unknown/Method", []string{"admin"}, false},
+		{"Rules that only list identities refuse users", "/Package/Fetch", []string{"admin"}, false},
+	}
+
+	for _, test := range Tests {
+		t.Run(test.name, func(t *testing.T) {
+			output := policy.Authorise(test.method, test.roles, policy.Scopes(test.roles))
+			if output != test.expectedOutput {
+				t.Error("Authorise failed for ", test.method, " with roles ", test.roles, ".\n Expected ", test.expectedOutput, ", received ", output)
+			}
+		})
+	}
+
+	var IdentityTests = []struct {
+		name           string
+		method         string
+		names          []string
+		expectedOutput bool
+	}{
+		{"Listed workloads are allowed", "/Package/Fetch", []string{"localhost", "desktopgateway"}, true},
+		{"Identity patterns may contain wildcards", "/Package/Fetch", []string{"estimator.workers"}, true},
+		{"Unlisted workloads are refused", "/Package/Fetch", []string{"frontend"}, false},
+		{"Rules without identities refuse every workload", "/Package/Estimate", []string{"desktopgateway"}, false},
+		{"Unmatched methods refuse every workload", "/Unknown/Method", []string{"desktopgateway"}, false},
+	}
+
+	for _, test := range IdentityTests {
+		t.Run(test.name, func(t *testing.T) {
+			output := policy.AuthoriseIdentity(test.method, test.names)
+			if output != test.expectedOutput {
+				t.Error("AuthoriseIdentity failed for ", test.method, " with names ", test.names, ".\n Expected ", test.expectedOutput, ", received ", output)
+			}
+		})
+	}
+
+	t.Run("Scopes are derived from inherited roles", func(t *testing.T) {
+		expected := []string{"estimation:read", "estimation:run", "evaluation:run"}
+		if scopes := policy.Scopes([]string{"admin"}); !reflect.DeepEqual(scopes, expected) {
+			t.Error("Expected ", expected, ", received ", scopes)
+		}
+	})
+
+	t.Run("MFA requirements are inherited", func(t *testing.T) {
+		if !policy.RequiresMFA([]string{"admin"}) || !policy.RequiresMFA([]string{"guest", "analyst"}) || policy.RequiresMFA([]string{"guest"}) {
+			t.Error("RequiresMFA did not follow role inheritance")
+		}
+	})
+
+	t.Run("Authentication requirements follow the rules", func(t *testing.T) {
+		if policy.RequiresAuthentication("/LoginService/Login") || !policy.RequiresAuthentication("/Unknown/Method") {
+			t.Error("RequiresAuthentication did not respect public rules and default-deny")
+		}
+	})
+}
+
+func TestPolicyValidation(t *testing.T) {
+	var Tests = []struct {
+		name     string
+		contents string
+	}{
+		{"Inheritance cycles are rejected", "roles:\n  a:\n    inherits: [b]\n  b:\n    inherits: [a]\n"},
+		{"Unknown roles in rules are rejected", "roles:\n  a: {}\nrules:\n  - methods: [\"/A/*\"]\n    roles: [b]\n"},
+		{"Malformed patterns are rejected", "rules:\n  - methods: [\"/A/[\"]\n    public: true\n"},
+		{"Public rules may not list identities", "rules:\n  - methods: [\"/A/*\"]\n    public: true\n    identities: [a]\n"},
+	}
+
+	for _, test := range Tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := LoadPolicy(writePolicy(t, test.contents)); err == nil {
+				t.Error("Expected policy to be rejected")
+			}
+		})
+	}
+}
+
+func TestPolicyManagerReload(t *testing.T) {
+	policyPath := writePolicy(t, testPolicy)
+	manager, err := NewPolicyManager(policyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stop := manager.Watch(10 * time.Millisecond)
+	defer stop()
+
+	// Open the default-deny switch and make sure the change is picked up without restarting
+	updated := []byte("defaultDeny: false\n")
+	if err := ioutil.WriteFile(policyPath, updated, 0644); err != nil {
+		t.Fatal(err)
+	}
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(policyPath, future, future); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for manager.RequiresAuthentication("/Unknown/Method") {
+		if time.Now().After(deadline) {
+			t.Fatal("Policy was not reloaded after the file changed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
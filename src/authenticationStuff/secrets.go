@@ -0,0 +1,195 @@
+package authentication
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nicholasbunn/mastersSandbox/src/logging"
+)
+
+// SecretsDirectoryVariable names the environment variable that overrides where "secret:" references are read from
+const SecretsDirectoryVariable = "MASTERS_SECRETS_DIRECTORY"
+
+// DefaultSecretsDirectory is where Docker mounts the secrets granted to a container
+const DefaultSecretsDirectory = "/run/secrets"
+
+type Secret struct {
+	/* This struct holds a secret (such as the JWT signing key) resolved from a reference in
+	a configuration file, so that the value itself never has to be committed. References
+	take one of the following forms:
+		${env:NAME}		the value of the environment variable NAME
+		file:/path		the contents of the file at path
+		secret:name		the Docker secret name (a file in SecretsDirectory())
+	Anything else is used as the secret itself. Secrets read from files are re-read when the
+	file changes, and the value they replaced is kept for a grace period so that tokens
+	signed just before a rotation are still accepted */
+	reference string
+	path      string // The file the secret is read from, empty for environment variables and literals
+
+	mutex     sync.RWMutex
+	value     string
+	previous  string
+	rotatedAt time.Time
+	modTime   time.Time
+}
+
+func NewSecret(reference string) (*Secret, error) {
+	// This function resolves the provided reference and returns the secret it names
+	secret := &Secret{reference: reference}
+	switch {
+	case strings.HasPrefix(reference, "${env:") && strings.HasSuffix(reference, "}"):
+		name := strings.TrimSuffix(strings.TrimPrefix(reference, "${env:"), "}")
+		value, ok := os.LookupEnv(name)
+		if !ok || value == "" {
+			return nil, fmt.Errorf("refers to the environment variable %v, which is not set", name)
+		}
+		secret.value = value
+		return secret, nil
+	case strings.HasPrefix(reference, "file:"):
+		secret.path = strings.TrimPrefix(reference, "file:")
+	case strings.HasPrefix(reference, "secret:"):
+		secret.path = filepath.Join(SecretsDirectory(), strings.TrimPrefix(reference, "secret:"))
+	default:
+		secret.value = reference
+		return secret, nil
+	}
+
+	if err := secret.Reload(); err != nil {
+		return nil, err
+	}
+
+	return secret, nil
+}
+
+func StaticSecret(value string) *Secret {
+	// This function returns a secret that always has the provided value, for tests and tools
+	return &Secret{reference: value, value: value}
+}
+
+func SecretsDirectory() string {
+	// This function returns the directory that "secret:" references are read from
+	if directory := os.Getenv(SecretsDirectoryVariable); directory != "" {
+		return directory
+	}
+
+	return DefaultSecretsDirectory
+}
+
+func (secret *Secret) Value() string {
+	// This function returns the current value of the secret
+	secret.mutex.RLock()
+	defer secret.mutex.RUnlock()
+
+	return secret.value
+}
+
+func (secret *Secret) Values(grace time.Duration) []string {
+	/* This function returns the current value of the secret, followed by the value it
+	replaced if the secret was rotated less than grace ago. Tokens are signed with the
+	first value and may be verified with either */
+	secret.mutex.RLock()
+	defer secret.mutex.RUnlock()
+
+	if secret.previous != "" && time.Since(secret.rotatedAt) < grace {
+		return []string{secret.value, secret.previous}
+	}
+
+	return []string{secret.value}
+}
+
+func (secret *Secret) Literal() bool {
+	// This function reports whether the secret was written out in full instead of being referenced
+	return secret.path == "" && !strings.HasPrefix(secret.reference, "${env:")
+}
+
+func (secret *Secret) String() string {
+	// This function describes the secret by its reference, so that printing it never reveals the value
+	if secret.Literal() {
+		return "[redacted]"
+	}
+
+	return secret.reference
+}
+
+func (secret *Secret) Reload() error {
+	/* This function re-reads a secret held in a file. Surrounding whitespace (such as the
+	trailing newline left by most editors) is ignored, and an empty file is refused so
+	that a half-written rotation never leaves the service without a secret */
+	if secret.path == "" {
+		return nil
+	}
+
+	info, err := os.Stat(secret.path)
+	if err != nil {
+		return fmt.Errorf("refers to a file that can't be read: %v", err)
+	}
+	contents, err := ioutil.ReadFile(secret.path)
+	if err != nil {
+		return fmt.Errorf("refers to a file that can't be read: %v", err)
+	}
+	value := strings.TrimSpace(string(contents))
+	if value == "" {
+		return fmt.Errorf("refers to %q, which is empty", secret.path)
+	}
+
+	secret.mutex.Lock()
+	defer secret.mutex.Unlock()
+	if secret.value != "" && secret.value != value {
+		secret.previous = secret.value
+		secret.rotatedAt = time.Now()
+	}
+	secret.value = value
+	secret.modTime = info.ModTime()
+
+	return nil
+}
+
+func (secret *Secret) Watch(interval time.Duration) (stop func()) {
+	/* This function polls the file holding the secret every interval and reloads it when it
+	has been modified. Secrets that aren't held in files never change, so nothing is watched.
+	It returns a function that stops the watcher */
+	if secret.path == "" {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	ticker := time.NewTicker(interval)
+
+	secret.mutex.RLock()
+	lastSeen := secret.modTime
+	secret.mutex.RUnlock()
+
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				info, err := os.Stat(secret.path)
+				if err != nil {
+					logging.Logger.Warnln("Could not stat secret ", secret, ", keeping the current value: ", err)
+					continue
+				}
+
+				if info.ModTime().Equal(lastSeen) {
+					continue
+				}
+				lastSeen = info.ModTime()
+
+				if err := secret.Reload(); err != nil {
+					logging.Logger.Warnln("Could not reload secret ", secret, ", keeping the current value: ", err)
+				} else {
+					logging.Logger.Infoln("Reloaded secret ", secret)
+				}
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
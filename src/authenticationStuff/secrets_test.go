@@ -0,0 +1,117 @@
+package authentication
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewSecret(t *testing.T) {
+	directory := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(directory, "jwt_secret"), []byte("fromTheDockerSecret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(directory, "empty"), []byte("\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("MASTERS_TEST_JWT_SECRET", "fromTheEnvironment")
+	defer os.Unsetenv("MASTERS_TEST_JWT_SECRET")
+	os.Setenv(SecretsDirectoryVariable, directory)
+	defer os.Unsetenv(SecretsDirectoryVariable)
+
+	var Tests = []struct {
+		name            string
+		reference       string
+		expectedValue   string
+		expectedLiteral bool
+		expectedError   bool
+	}{
+		{"Environment variables are resolved", "${env:MASTERS_TEST_JWT_SECRET}", "fromTheEnvironment", false, false},
+		{"Unset environment variables are refused", "${env:MASTERS_TEST_UNSET}", "", false, true},
+		{"Files are read without their trailing newline", "file:" + filepath.Join(directory, "jwt_secret"), "fromTheDockerSecret", false, false},
+		{"Docker secrets are read from the secrets directory", "secret:jwt_secret", "fromTheDockerSecret", false, false},
+		{"Missing files are refused", "secret:missing", "", false, true},
+		{"Empty files are refused", "secret:empty", "", false, true},
+		{"Anything else is the secret itself", "notAReference", "notAReference", true, false},
+	}
+
+	for _, test := range Tests {
+		t.Run(test.name, func(t *testing.T) {
+			secret, err := NewSecret(test.reference)
+			if (err != nil) != test.expectedError {
+				t.Fatal("Expected error ", test.expectedError, ", received ", err)
+			}
+			if err != nil {
+				return
+			}
+			if secret.Value() != test.expectedValue || secret.Literal() != test.expectedLiteral {
+				t.Errorf("Expected %q (literal %v), received %q (literal %v)", test.expectedValue, test.expectedLiteral, secret.Value(), secret.Literal())
+			}
+			if printed := fmt.Sprint(secret); strings.Contains(printed, test.expectedValue) && test.expectedLiteral {
+				t.Error("Printing a secret should not reveal it, printed ", printed)
+			}
+		})
+	}
+}
+
+func TestSecretRotation(t *testing.T) {
+	secretPath := filepath.Join(t.TempDir(), "jwt_secret")
+	if err := ioutil.WriteFile(secretPath, []byte("theOriginalSecret"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	secret, err := NewSecret("file:" + secretPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stop := secret.Watch(10 * time.Millisecond)
+	defer stop()
+
+	manager := NewJWTManager(secret, 15*time.Minute)
+	oldToken, err := manager.GenerateManager(&User{Username: "analyst"}, nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Rotate the secret and make sure the change is picked up without restarting
+	if err := ioutil.WriteFile(secretPath, []byte("theRotatedSecret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(secretPath, future, future); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for secret.Value() != "theRotatedSecret" {
+		if time.Now().After(deadline) {
+			t.Fatal("Secret was not reloaded after the file changed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Run("New tokens are signed with the rotated secret", func(t *testing.T) {
+		token, _ := manager.GenerateManager(&User{Username: "analyst"}, nil, "")
+		if _, err := NewJWTManager(StaticSecret("theRotatedSecret"), time.Minute).VerifyJWT(token); err != nil {
+			t.Error("Expected the token to be signed with the rotated secret: ", err)
+		}
+	})
+
+	t.Run("Tokens signed before the rotation are accepted until they expire", func(t *testing.T) {
+		if _, err := manager.VerifyJWT(oldToken); err != nil {
+			t.Error("Expected the token to be accepted during the grace period: ", err)
+		}
+		if _, err := NewJWTManager(secret, 0).VerifyJWT(oldToken); err == nil {
+			t.Error("Expected the token to be refused once the grace period is over")
+		}
+	})
+
+	t.Run("An empty file keeps the current secret", func(t *testing.T) {
+		ioutil.WriteFile(secretPath, nil, 0600)
+		if err := secret.Reload(); err == nil || secret.Value() != "theRotatedSecret" {
+			t.Error("Expected the rotated secret to be kept, received ", err)
+		}
+	})
+}
@@ -0,0 +1,184 @@
+package authentication
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// SessionRetention is how long sessions are kept in the store after they end, so that recently terminated sessions can still be looked up
+const SessionRetention = 24 * time.Hour
+
+type SessionStore interface {
+	/* This interface describes a persistent store of sessions. Find returns a nil session
+	(and no error) if the session doesn't exist */
+	Find(id string) (*Session, error)
+	Save(session *Session) error
+	Update(id string, update func(session *Session) error) error // Atomically read, modify and save a session
+	List(username string) ([]*Session, error)                    // Every session of the user, or of every user if empty
+}
+
+type FileSessionStore struct {
+	/* This struct is a SessionStore that keeps its sessions in memory and persists them
+	to a JSON file on every change. Sessions that ended more than SessionRetention ago are
+	dropped whenever the store is written */
+	path     string
+	mutex    sync.Mutex
+	sessions map[string]*Session
+}
+
+func NewFileSessionStore(path string) (*FileSessionStore, error) {
+	/* This function opens the session store at the provided path. If the file doesn't
+	exist yet, an empty store is created and will be written on the first save */
+	store := &FileSessionStore{
+		path:     path,
+		sessions: map[string]*Session{},
+	}
+
+	contents, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("could not read session store: %v", err)
+	}
+
+	var sessions []*Session
+	if err := json.Unmarshal(contents, &sessions); err != nil {
+		return nil, fmt.Errorf("could not decode session store: %v", err)
+	}
+	for _, session := range sessions {
+		store.sessions[session.ID] = session
+	}
+
+	return store, nil
+}
+
+func (store *FileSessionStore) Find(id string) (*Session, error) {
+	// This function returns a copy of the session with the provided ID, or nil if it doesn't exist
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	session, ok := store.sessions[id]
+	if !ok {
+		return nil, nil
+	}
+
+	duplicate := *session
+	return &duplicate, nil
+}
+
+func (store *FileSessionStore) Save(session *Session) error {
+	// This function adds or replaces a session and persists the store
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	previous, existed := store.sessions[session.ID]
+	duplicate := *session
+	store.sessions[session.ID] = &duplicate
+	if err := store.persist(); err != nil {
+		// Roll back so that memory and disk don't disagree
+		if existed {
+			store.sessions[session.ID] = previous
+		} else {
+			delete(store.sessions, session.ID)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (store *FileSessionStore) Update(id string, update func(session *Session) error) error {
+	/* This function applies the provided update to a session and persists the result. The
+	store is locked for the duration, so concurrent updates to the same session can't be lost */
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	previous, ok := store.sessions[id]
+	if !ok {
+		return ErrSessionNotFound
+	}
+
+	updated := *previous
+	if err := update(&updated); err != nil {
+		return err
+	}
+
+	store.sessions[id] = &updated
+	if err := store.persist(); err != nil {
+		store.sessions[id] = previous
+		return err
+	}
+
+	return nil
+}
+
+func (store *FileSessionStore) List(username string) ([]*Session, error) {
+	// This function returns a copy of every session of the provided user (or of every user if empty), most recent first
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	sessions := []*Session{}
+	for _, session := range store.sessions {
+		if username == "" || session.Username == username {
+			duplicate := *session
+			sessions = append(sessions, &duplicate)
+		}
+	}
+	sortSessions(sessions)
+
+	return sessions, nil
+}
+
+func (store *FileSessionStore) persist() error {
+	/* This (unexported) function drops sessions that ended more than SessionRetention ago
+	and writes the store to disk. The file is written next to the store and renamed over it,
+	so a crash never leaves a half-written store behind. The caller must hold the store's mutex */
+	cutoff := time.Now().Add(-SessionRetention)
+	sessions := make([]*Session, 0, len(store.sessions))
+	for id, session := range store.sessions {
+		ended := session.ExpiresAt
+		if !session.TerminatedAt.IsZero() && session.TerminatedAt.Before(ended) {
+			ended = session.TerminatedAt
+		}
+		if ended.Before(cutoff) {
+			delete(store.sessions, id)
+			continue
+		}
+		sessions = append(sessions, session)
+	}
+	sortSessions(sessions)
+
+	contents, err := json.MarshalIndent(sessions, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not encode session store: %v", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(store.path), 0700); err != nil {
+		return fmt.Errorf("could not create session store directory: %v", err)
+	}
+	temporaryPath := store.path + ".tmp"
+	if err := ioutil.WriteFile(temporaryPath, contents, 0600); err != nil {
+		return fmt.Errorf("could not write session store: %v", err)
+	}
+	if err := os.Rename(temporaryPath, store.path); err != nil {
+		return fmt.Errorf("could not replace session store: %v", err)
+	}
+
+	return nil
+}
+
+func sortSessions(sessions []*Session) {
+	// This (unexported) function orders sessions from the most recently issued, breaking ties by ID
+	sort.Slice(sessions, func(i, j int) bool {
+		if !sessions[i].IssuedAt.Equal(sessions[j].IssuedAt) {
+			return sessions[i].IssuedAt.After(sessions[j].IssuedAt)
+		}
+		return sessions[i].ID < sessions[j].ID
+	})
+}
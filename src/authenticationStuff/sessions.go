@@ -0,0 +1,124 @@
+package authentication
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+)
+
+/* A session is created every time a user logs in, and every token issued to them for that
+login (including the tokens exchanged for it further down the call chain) carries the
+session's ID. Terminating a session makes the services refuse its tokens before they
+expire, for example when a shared workstation has been left logged in. The authentication
+service keeps the sessions, the other services ask it whether a session is still active
+and cache the answer briefly */
+
+var (
+	// ErrSessionNotFound is returned when a session doesn't exist (or has already been pruned from the store)
+	ErrSessionNotFound = errors.New("session not found")
+	// ErrSessionTerminated is returned when a session has been terminated, or has expired
+	ErrSessionTerminated = errors.New("session has been terminated")
+)
+
+type Session struct {
+	/* This struct describes a login as it is kept in the session store. A zero
+	TerminatedAt means the session hasn't been terminated */
+	ID           string
+	Username     string
+	Client       string // The address of the client that logged in
+	Via          string // The service that relayed the login (by its client certificate), if any
+	IssuedAt     time.Time
+	ExpiresAt    time.Time // When the session's access token expires
+	LastUsed     time.Time
+	TerminatedAt time.Time
+	TerminatedBy string
+}
+
+type SessionVerifier interface {
+	/* This interface describes something that can check that the session a token belongs
+	to is still active. Failures are returned as gRPC status errors that can be passed
+	straight back to the client */
+	VerifySession(ctx context.Context, sessionID string) error
+}
+
+func NewSession(username string, client string, via string, lifetime time.Duration) (*Session, error) {
+	// This function creates a new session for a user who has just logged in
+	id, err := randomBytes(16)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return &Session{
+		ID:        hex.EncodeToString(id),
+		Username:  username,
+		Client:    client,
+		Via:       via,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(lifetime),
+		LastUsed:  now,
+	}, nil
+}
+
+func (session *Session) Active(now time.Time) bool {
+	// This function reports whether the session has neither been terminated nor expired
+	return session.TerminatedAt.IsZero() && now.Before(session.ExpiresAt)
+}
+
+func (session *Session) Check(now time.Time) error {
+	// This function returns ErrSessionTerminated if the session is no longer active
+	if !session.Active(now) {
+		return ErrSessionTerminated
+	}
+
+	return nil
+}
+
+type SessionCache struct {
+	/* This struct is a SessionVerifier that remembers the sessions it has seen to be
+	active for TTL, so that the authentication service isn't asked on every request. A
+	terminated session is therefore refused at most TTL after it was terminated. Refusals
+	(and failures to check) are never cached */
+	TTL    time.Duration
+	Verify func(ctx context.Context, sessionID string) error // Asks the authentication service
+
+	mutex    sync.Mutex
+	verified map[string]time.Time // Session IDs mapped to when they were last seen to be active
+}
+
+func NewSessionCache(ttl time.Duration, verify func(ctx context.Context, sessionID string) error) *SessionCache {
+	// This function returns a cache that checks sessions with the provided function
+	return &SessionCache{TTL: ttl, Verify: verify, verified: map[string]time.Time{}}
+}
+
+func (cache *SessionCache) VerifySession(ctx context.Context, sessionID string) error {
+	// This function checks the provided session, asking Verify unless it was seen to be active less than TTL ago
+	now := time.Now()
+	cache.mutex.Lock()
+	verifiedAt, ok := cache.verified[sessionID]
+	cache.mutex.Unlock()
+	if ok && now.Sub(verifiedAt) < cache.TTL {
+		return nil
+	}
+
+	if err := cache.Verify(ctx, sessionID); err != nil {
+		cache.mutex.Lock()
+		delete(cache.verified, sessionID)
+		cache.mutex.Unlock()
+		return err
+	}
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	for id, verifiedAt := range cache.verified {
+		// Forget sessions that haven't been seen for a while, so that the cache doesn't grow forever
+		if now.Sub(verifiedAt) >= cache.TTL {
+			delete(cache.verified, id)
+		}
+	}
+	cache.verified[sessionID] = now
+
+	return nil
+}
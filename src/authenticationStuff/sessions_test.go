@@ -0,0 +1,124 @@
+package authentication
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileSessionStore(t *testing.T) {
+	storePath := filepath.Join(t.TempDir(), "sessions.json")
+	store, err := NewFileSessionStore(storePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bridge, _ := NewSession("analyst", "10.0.0.7", "desktopgateway", 15*time.Minute)
+	laptop, _ := NewSession("analyst", "10.0.0.8", "desktopgateway", 15*time.Minute)
+	laptop.IssuedAt = bridge.IssuedAt.Add(time.Second)
+	admin, _ := NewSession("admin", "10.0.0.9", "desktopgateway", 15*time.Minute)
+	old, _ := NewSession("analyst", "10.0.0.7", "desktopgateway", time.Minute)
+	old.IssuedAt, old.ExpiresAt = time.Now().Add(-2*SessionRetention), time.Now().Add(-2*SessionRetention+time.Minute)
+	for _, session := range []*Session{bridge, laptop, admin, old} {
+		if err := store.Save(session); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("Sessions are listed per user, most recent first", func(t *testing.T) {
+		sessions, _ := store.List("analyst")
+		if len(sessions) != 2 || sessions[0].ID != laptop.ID || sessions[1].ID != bridge.ID {
+			t.Error("Expected the laptop and bridge sessions, received ", sessions)
+		}
+		if all, _ := store.List(""); len(all) != 3 {
+			t.Error("Expected every user's sessions, received ", all)
+		}
+	})
+
+	t.Run("Sessions that ended long ago are pruned", func(t *testing.T) {
+		if session, _ := store.Find(old.ID); session != nil {
+			t.Error("Expected the old session to be pruned")
+		}
+	})
+
+	t.Run("Terminated sessions are persisted", func(t *testing.T) {
+		err := store.Update(bridge.ID, func(session *Session) error {
+			session.TerminatedAt, session.TerminatedBy = time.Now(), "admin"
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		reopened, err := NewFileSessionStore(storePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		session, _ := reopened.Find(bridge.ID)
+		if session == nil || session.Check(time.Now()) != ErrSessionTerminated || session.TerminatedBy != "admin" {
+			t.Error("Expected the terminated session to be refused after reopening the store, received ", session)
+		}
+		if session, _ := reopened.Find(laptop.ID); session == nil || session.Check(time.Now()) != nil {
+			t.Error("Expected the other session to still be active, received ", session)
+		}
+	})
+
+	t.Run("Expired sessions are no longer active", func(t *testing.T) {
+		if laptop.Active(laptop.ExpiresAt.Add(time.Second)) {
+			t.Error("Expected the session to end when its token expires")
+		}
+	})
+
+	t.Run("Updating an unknown session fails", func(t *testing.T) {
+		if err := store.Update("missing", func(*Session) error { return nil }); err != ErrSessionNotFound {
+			t.Error("Expected ErrSessionNotFound, received ", err)
+		}
+	})
+}
+
+func TestSessionCache(t *testing.T) {
+	calls := 0
+	terminated := map[string]bool{}
+	cache := NewSessionCache(time.Hour, func(ctx context.Context, sessionID string) error {
+		calls++
+		if terminated[sessionID] {
+			return errors.New("terminated")
+		}
+		return nil
+	})
+	ctx := context.Background()
+
+	t.Run("Active sessions are only checked once per TTL", func(t *testing.T) {
+		for attempt := 0; attempt < 3; attempt++ {
+			if err := cache.VerifySession(ctx, "active"); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if calls != 1 {
+			t.Error("Expected a single check, made ", calls)
+		}
+	})
+
+	t.Run("Refusals are never cached", func(t *testing.T) {
+		terminated["ended"] = true
+		calls = 0
+		for attempt := 0; attempt < 2; attempt++ {
+			if err := cache.VerifySession(ctx, "ended"); err == nil {
+				t.Error("Expected the terminated session to be refused")
+			}
+		}
+		if calls != 2 {
+			t.Error("Expected every attempt to be checked, made ", calls)
+		}
+	})
+
+	t.Run("Sessions are checked again once the TTL has passed", func(t *testing.T) {
+		cache.TTL = 0
+		terminated["active"] = true
+		if err := cache.VerifySession(ctx, "active"); err == nil {
+			t.Error("Expected the session to be checked again and refused")
+		}
+	})
+}
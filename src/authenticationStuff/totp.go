@@ -0,0 +1,166 @@
+package authentication
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+/* Time-based one-time passwords (TOTP) follow RFC 6238 with the parameters every
+authenticator app supports by default: HMAC-SHA1, 6 digit codes and a 30 second period */
+
+const (
+	TOTPDigits = 6
+	TOTPPeriod = 30 * time.Second
+
+	totpSecretSize        = 20 // Size (in bytes) of generated secrets, as recommended by RFC 4226
+	totpSkew              = 1  // Number of periods either side of the current one in which a code is still accepted, to allow for clock drift
+	recoveryCodeSize      = 5  // Size (in bytes) of generated recovery codes, formatted as 10 hexadecimal characters
+	RecoveryCodeCount     = 10 // Number of recovery codes issued when TOTP is enabled
+	recoveryCodeSeparator = "-"
+)
+
+// ErrTOTPInvalid is returned when a TOTP or recovery code is wrong, has already been used, or TOTP isn't set up
+var ErrTOTPInvalid = errors.New("invalid verification code")
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+type TOTP struct {
+	/* This struct describes a user's TOTP enrolment. The secret has to be kept in the
+	clear (the server computes codes from it), recovery codes are only kept as hashes.
+	A pending secret has been handed to the user but hasn't been confirmed with a code yet */
+	Secret        string
+	Enabled       bool
+	EnabledAt     time.Time
+	PendingSecret string
+	RecoveryCodes []string // Hex-encoded SHA-256 hashes of the unused recovery codes
+	LastStep      int64    // The period of the last accepted code, so that a code can't be replayed
+}
+
+func GenerateTOTPSecret() (string, error) {
+	// This function generates a random TOTP secret, base32-encoded (without padding) as authenticator apps expect
+	secret := make([]byte, totpSecretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("could not generate totp secret: %v", err)
+	}
+
+	return totpEncoding.EncodeToString(secret), nil
+}
+
+func TOTPProvisioningURI(issuer string, account string, secret string) string {
+	/* This function returns the otpauth:// URI that authenticator apps read from a QR code
+	to enrol the provided account. The URI is returned as text, the client renders the QR code */
+	label := url.PathEscape(issuer) + ":" + url.PathEscape(account)
+	parameters := url.Values{}
+	parameters.Set("secret", secret)
+	parameters.Set("issuer", issuer)
+	parameters.Set("algorithm", "SHA1")
+	parameters.Set("digits", fmt.Sprint(TOTPDigits))
+	parameters.Set("period", fmt.Sprint(int(TOTPPeriod/time.Second)))
+
+	return "otpauth://totp/" + label + "?" + parameters.Encode()
+}
+
+func TOTPStep(now time.Time) int64 {
+	// This function returns the period (counter) that the provided time falls in
+	return now.Unix() / int64(TOTPPeriod/time.Second)
+}
+
+func TOTPCode(secret string, step int64) (string, error) {
+	// This function computes the code for the provided period (the HOTP value of RFC 4226, truncated to TOTPDigits)
+	key, err := totpEncoding.DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+	if err != nil {
+		return "", fmt.Errorf("invalid totp secret: %v", err)
+	}
+
+	counter := make([]byte, 8)
+	binary.BigEndian.PutUint64(counter, uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	modulus := uint32(1)
+	for i := 0; i < TOTPDigits; i++ {
+		modulus *= 10
+	}
+
+	return fmt.Sprintf("%0*d", TOTPDigits, value%modulus), nil
+}
+
+func ValidateTOTP(secret string, code string, now time.Time, lastStep int64) (int64, error) {
+	/* This function checks a code against the periods around the provided time. It returns
+	the period the code belongs to, which the caller stores as the new last step. Codes from
+	the last accepted period (or earlier) are refused, so every code can only be used once */
+	code = strings.TrimSpace(code)
+	if len(code) != TOTPDigits {
+		return 0, ErrTOTPInvalid
+	}
+
+	current := TOTPStep(now)
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		expected, err := TOTPCode(secret, step)
+		if err != nil {
+			return 0, err
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			if step <= lastStep {
+				return 0, ErrTOTPInvalid
+			}
+			return step, nil
+		}
+	}
+
+	return 0, ErrTOTPInvalid
+}
+
+func GenerateRecoveryCodes() (codes []string, hashes []string, err error) {
+	/* This function generates a set of single-use recovery codes, for logging in without
+	the authenticator. It returns the codes, to show to the user once, and their hashes to store */
+	for i := 0; i < RecoveryCodeCount; i++ {
+		raw := make([]byte, recoveryCodeSize)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, nil, fmt.Errorf("could not generate recovery codes: %v", err)
+		}
+		encoded := hex.EncodeToString(raw)
+		code := encoded[:len(encoded)/2] + recoveryCodeSeparator + encoded[len(encoded)/2:]
+
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+
+	return codes, hashes, nil
+}
+
+func (totp *TOTP) UseRecoveryCode(code string) bool {
+	// This function reports whether the provided recovery code is one of the unused codes, and removes it if it is
+	hashed := hashRecoveryCode(code)
+	for index, stored := range totp.RecoveryCodes {
+		if subtle.ConstantTimeCompare([]byte(stored), []byte(hashed)) == 1 {
+			totp.RecoveryCodes = append(totp.RecoveryCodes[:index:index], totp.RecoveryCodes[index+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+func hashRecoveryCode(code string) string {
+	// This (unexported) function hashes a recovery code, ignoring case, whitespace and separators
+	normalised := strings.ToLower(strings.Join(strings.Fields(code), ""))
+	normalised = strings.ReplaceAll(normalised, recoveryCodeSeparator, "")
+	sum := sha256.Sum256([]byte(normalised))
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,103 @@
+package authentication
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTOTPCode(t *testing.T) {
+	// Test vectors from RFC 6238 (appendix B, SHA1), truncated to 6 digits
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+	var Tests = []struct {
+		time           int64
+		expectedOutput string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, test := range Tests {
+		output, err := TOTPCode(secret, TOTPStep(time.Unix(test.time, 0)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if output != test.expectedOutput {
+			t.Error("TOTPCode failed for ", test.time, ".\n Expected ", test.expectedOutput, ", received ", output)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	step := TOTPStep(now)
+	code := func(step int64) string {
+		code, err := TOTPCode(secret, step)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return code
+	}
+
+	var Tests = []struct {
+		name           string
+		code           string
+		lastStep       int64
+		expectedOutput error
+	}{
+		{"The current code is accepted", code(step), 0, nil},
+		{"Codes from the neighbouring periods are accepted", code(step - 1), 0, nil},
+		{"Older codes are refused", code(step - 2), 0, ErrTOTPInvalid},
+		{"Codes can't be replayed", code(step), step, ErrTOTPInvalid},
+		{"Codes of the wrong length are refused", code(step)[1:], 0, ErrTOTPInvalid},
+	}
+
+	for _, test := range Tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, output := ValidateTOTP(secret, test.code, now, test.lastStep); output != test.expectedOutput {
+				t.Error("ValidateTOTP failed.\n Expected ", test.expectedOutput, ", received ", output)
+			}
+		})
+	}
+
+	t.Run("The provisioning URI carries the secret and issuer", func(t *testing.T) {
+		uri := TOTPProvisioningURI("mastersSandbox", "admin", secret)
+		if !strings.HasPrefix(uri, "otpauth://totp/mastersSandbox:admin?") || !strings.Contains(uri, "secret="+secret) || !strings.Contains(uri, "issuer=mastersSandbox") {
+			t.Error("Unexpected provisioning URI: ", uri)
+		}
+	})
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, hashes, err := GenerateRecoveryCodes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != RecoveryCodeCount || len(hashes) != RecoveryCodeCount {
+		t.Fatal("Expected ", RecoveryCodeCount, " recovery codes, received ", len(codes))
+	}
+	totp := &TOTP{RecoveryCodes: hashes}
+
+	if !totp.UseRecoveryCode(" " + strings.ToUpper(codes[3]) + " ") {
+		t.Error("Recovery codes should be accepted regardless of case and whitespace")
+	}
+	if totp.UseRecoveryCode(codes[3]) {
+		t.Error("Recovery codes should only be accepted once")
+	}
+	if totp.UseRecoveryCode("0000000000") {
+		t.Error("Unknown recovery codes should be refused")
+	}
+	if len(totp.RecoveryCodes) != RecoveryCodeCount-1 {
+		t.Error("Using a recovery code should remove only that code")
+	}
+}
@@ -0,0 +1,75 @@
+package authentication
+
+import (
+	"context"
+	"crypto/x509"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+/* Servers and clients use separate TLS configurations: a server presents its own
+certificate and verifies callers against the CA, while a client verifies the server
+against the CA and presents its own certificate when asked. The verified certificate of
+the caller identifies the workload (service) making the call */
+
+type TLSFiles struct {
+	/* This struct describes the files making up one side's TLS configuration, as
+	loaded from a service's configuration file */
+	Certificate              string `yaml:"certificate"`              // Path of the certificate presented to the other side
+	Key                      string `yaml:"key"`                      // Path of the private key belonging to the certificate
+	CA                       string `yaml:"ca"`                       // Path of the certificate of the CA used to verify the other side
+	RequireClientCertificate bool   `yaml:"requireClientCertificate"` // Servers only, whether callers have to present a client certificate
+}
+
+type PeerIdentity struct {
+	/* This struct describes the workload identity carried by a caller's verified
+	client certificate */
+	Subject    string   // Distinguished name of the certificate subject
+	CommonName string   // Common name of the certificate subject
+	DNSNames   []string // DNS subject alternative names
+	URIs       []string // URI subject alternative names (e.g. SPIFFE IDs)
+}
+
+func PeerIdentityFromContext(ctx context.Context) (*PeerIdentity, bool) {
+	/* This function returns the identity of the caller of an incoming request, as
+	described by its client certificate. Only certificates that were verified against the
+	CA during the handshake are considered, so the identity can be trusted */
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.AuthInfo == nil {
+		return nil, false
+	}
+
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return nil, false
+	}
+
+	return identityFromCertificate(tlsInfo.State.VerifiedChains[0][0]), true
+}
+
+func (identity *PeerIdentity) Names() []string {
+	// This function returns every name the caller's certificate was issued for, starting with its common name
+	names := []string{}
+	if identity.CommonName != "" {
+		names = append(names, identity.CommonName)
+	}
+	names = append(names, identity.DNSNames...)
+	names = append(names, identity.URIs...)
+
+	return names
+}
+
+func identityFromCertificate(certificate *x509.Certificate) *PeerIdentity {
+	// This (unexported) function extracts the workload identity from a certificate
+	identity := &PeerIdentity{
+		Subject:    certificate.Subject.String(),
+		CommonName: certificate.Subject.CommonName,
+		DNSNames:   append([]string{}, certificate.DNSNames...),
+	}
+	for _, uri := range certificate.URIs {
+		identity.URIs = append(identity.URIs, uri.String())
+	}
+
+	return identity
+}
@@ -0,0 +1,89 @@
+package authentication
+
+import (
+	"context"
+	"crypto/tls"
+	"io/ioutil"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+func handshake(t *testing.T, serverConfig *tls.Config, clientConfig *tls.Config) (tls.ConnectionState, error) {
+	// This helper performs a TLS handshake over an in-memory connection and returns the server's view of it
+	serverConnection, clientConnection := net.Pipe()
+	defer serverConnection.Close()
+	defer clientConnection.Close()
+
+	server := tls.Server(serverConnection, serverConfig)
+	client := tls.Client(clientConnection, clientConfig)
+
+	clientErr := make(chan error, 1)
+	go func() {
+		clientErr <- client.Handshake()
+		// Read until the server closes the pipe, so that the server's alerts are delivered
+		ioutil.ReadAll(client)
+	}()
+
+	err := server.Handshake()
+	serverConnection.Close()
+	<-clientErr
+
+	return server.ConnectionState(), err
+}
+
+func TestMutualTLS(t *testing.T) {
+	directory := t.TempDir()
+
+	authority := newTestAuthority(t)
+	server := issueTestCertificate(t, authority, CertificateRequest{CommonName: "localhost", DNSNames: []string{"localhost"}, Usage: ServerCertificate})
+	client := issueTestCertificate(t, authority, CertificateRequest{CommonName: "desktopgateway", DNSNames: []string{"desktopgateway"}, Usage: ClientCertificate})
+
+	caPath, _ := writeTestCertificate(t, directory, "ca", authority.Certificate, authority.Key)
+	serverCertificate, serverKey := writeTestCertificate(t, directory, "server", server.Certificate, server.Key)
+	clientCertificate, clientKey := writeTestCertificate(t, directory, "client", client.Certificate, client.Key)
+
+	serverManager, err := NewCertificateManager("server", TLSFiles{Certificate: serverCertificate, Key: serverKey, CA: caPath, RequireClientCertificate: true})
+	if err != nil {
+		t.Fatal("Failed to load server certificates: ", err)
+	}
+	clientManager, err := NewCertificateManager("client", TLSFiles{Certificate: clientCertificate, Key: clientKey, CA: caPath})
+	if err != nil {
+		t.Fatal("Failed to load client certificates: ", err)
+	}
+	serverConfig := serverManager.ServerTLSConfig()
+	clientConfig := clientManager.ClientTLSConfig()
+	clientConfig.ServerName = "localhost"
+
+	t.Run("Verified client certificates identify the caller", func(t *testing.T) {
+		state, err := handshake(t, serverConfig, clientConfig)
+		if err != nil {
+			t.Fatal("Handshake failed: ", err)
+		}
+
+		ctx := peer.NewContext(context.Background(), &peer.Peer{AuthInfo: credentials.TLSInfo{State: state}})
+		identity, ok := PeerIdentityFromContext(ctx)
+		if !ok {
+			t.Fatal("Expected the caller's identity to be available")
+		}
+		if identity.CommonName != "desktopgateway" || len(identity.Names()) != 2 {
+			t.Error("Unexpected identity: ", identity)
+		}
+	})
+
+	t.Run("Callers without a client certificate are refused", func(t *testing.T) {
+		anonymousConfig := clientConfig.Clone()
+		anonymousConfig.GetClientCertificate = nil
+		if _, err := handshake(t, serverConfig, anonymousConfig); err == nil {
+			t.Error("Expected the handshake to fail without a client certificate")
+		}
+	})
+
+	t.Run("Requests without TLS carry no identity", func(t *testing.T) {
+		if _, ok := PeerIdentityFromContext(context.Background()); ok {
+			t.Error("Expected no identity without a peer")
+		}
+	})
+}
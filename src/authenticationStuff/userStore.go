@@ -0,0 +1,168 @@
+package authentication
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// ErrUserNotFound is returned by UserStore.Update when the user doesn't exist
+var ErrUserNotFound = errors.New("user not found")
+
+type UserStore interface {
+	/* This interface describes a persistent store of users. Find returns a nil user
+	(and no error) if the user doesn't exist */
+	Find(username string) (*User, error)
+	Save(user *User) error
+	Update(username string, update func(user *User) error) error // Atomically read, modify and save a user
+	List() ([]*User, error)
+}
+
+type FileUserStore struct {
+	/* This struct is a UserStore that keeps its users in memory and persists them to a
+	JSON file on every change */
+	path  string
+	mutex sync.Mutex
+	users map[string]*User
+}
+
+func NewFileUserStore(path string) (*FileUserStore, error) {
+	/* This function opens the user store at the provided path. If the file doesn't exist
+	yet, an empty store is created and will be written on the first save */
+	store := &FileUserStore{
+		path:  path,
+		users: map[string]*User{},
+	}
+
+	contents, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("could not read user store: %v", err)
+	}
+
+	var users []*User
+	if err := json.Unmarshal(contents, &users); err != nil {
+		return nil, fmt.Errorf("could not decode user store: %v", err)
+	}
+	for _, user := range users {
+		store.users[user.Username] = user
+	}
+
+	return store, nil
+}
+
+func (store *FileUserStore) Find(username string) (*User, error) {
+	// This function returns a copy of the user with the provided username, or nil if they don't exist
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	user, ok := store.users[username]
+	if !ok {
+		return nil, nil
+	}
+
+	return user.copy(), nil
+}
+
+func (store *FileUserStore) Save(user *User) error {
+	// This function adds or replaces a user and persists the store
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	previous, existed := store.users[user.Username]
+	store.users[user.Username] = user.copy()
+	if err := store.persist(); err != nil {
+		// Roll back so that memory and disk don't disagree
+		if existed {
+			store.users[user.Username] = previous
+		} else {
+			delete(store.users, user.Username)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (store *FileUserStore) Update(username string, update func(user *User) error) error {
+	/* This function applies the provided update to a user and persists the result. The
+	store is locked for the duration, so concurrent updates to the same user can't be lost */
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	previous, ok := store.users[username]
+	if !ok {
+		return ErrUserNotFound
+	}
+
+	updated := previous.copy()
+	if err := update(updated); err != nil {
+		return err
+	}
+
+	store.users[username] = updated
+	if err := store.persist(); err != nil {
+		store.users[username] = previous
+		return err
+	}
+
+	return nil
+}
+
+func (store *FileUserStore) List() ([]*User, error) {
+	// This function returns a copy of every user in the store, ordered by username
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	users := make([]*User, 0, len(store.users))
+	for _, user := range store.users {
+		users = append(users, user.copy())
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].Username < users[j].Username })
+
+	return users, nil
+}
+
+func (store *FileUserStore) persist() error {
+	/* This (unexported) function writes the store to disk. The file is written next to
+	the store and renamed over it, so a crash never leaves a half-written store behind.
+	The caller must hold the store's mutex */
+	users := make([]*User, 0, len(store.users))
+	for _, user := range store.users {
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].Username < users[j].Username })
+
+	contents, err := json.MarshalIndent(users, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not encode user store: %v", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(store.path), 0700); err != nil {
+		return fmt.Errorf("could not create user store directory: %v", err)
+	}
+	temporaryPath := store.path + ".tmp"
+	if err := ioutil.WriteFile(temporaryPath, contents, 0600); err != nil {
+		return fmt.Errorf("could not write user store: %v", err)
+	}
+	if err := os.Rename(temporaryPath, store.path); err != nil {
+		return fmt.Errorf("could not replace user store: %v", err)
+	}
+
+	return nil
+}
+
+func (user *User) copy() *User {
+	// This (unexported) function returns a deep copy of the user, so that callers can't modify the store's copy
+	duplicate := *user
+	duplicate.Roles = append([]string(nil), user.Roles...)
+	duplicate.TOTP.RecoveryCodes = append([]string(nil), user.TOTP.RecoveryCodes...)
+
+	return &duplicate
+}
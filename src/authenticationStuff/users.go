@@ -1,20 +1,33 @@
 package authentication
 
 import (
+	"sync"
+
 	"golang.org/x/crypto/bcrypt"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
+var (
+	// A hash that no password matches, used to keep failed logins for unknown users as slow as any other
+	dummyHash     []byte
+	dummyHashOnce sync.Once
+)
+
 type User struct {
 	/* This struct describes the user, as their info will
-	be stored in the DB */
+	be stored in the DB. Users of other identity providers are
+	kept too (without a password), so that their lockouts and
+	second factor are tracked like everyone else's */
 	Username       string
 	HashedPassword string
-	Role           string
+	Roles          []string
+	Provider       string        // The identity provider the user belongs to, empty for the user store itself
+	LoginAttempts  LoginAttempts // Failed login tracking, persisted so that lockouts survive restarts
+	TOTP           TOTP          // Second factor enrolment, optional unless one of the user's roles requires it
 }
 
-func CreateUser(username string, password string, role string) (*User, error) {
+func CreateUser(username string, password string, roles ...string) (*User, error) {
 	// This function creates and returns a new user object
 
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
@@ -25,12 +38,17 @@ func CreateUser(username string, password string, role string) (*User, error) {
 	user := &User{
 		Username:       username,
 		HashedPassword: string(hashedPassword),
-		Role:           role,
+		Roles:          roles,
 	}
 
 	return user, nil
 }
 
+func (user *User) IsLocal() bool {
+	// This function reports whether the user's password is kept in the user store, rather than by another identity provider
+	return user.Provider == "" || user.Provider == LocalProviderName
+}
+
 func (user *User) CheckPassword(password string) bool {
 	/* This function checks whether the password provided for the user is the same
 	as the password stored for that user */
@@ -38,3 +56,18 @@ func (user *User) CheckPassword(password string) bool {
 
 	return err == nil
 }
+
+func CheckCredentials(user *User, password string) bool {
+	/* This function checks the provided password against the user's stored password. If
+	the user doesn't exist, the password is checked against a dummy hash instead so that
+	rejecting an unknown username takes as long as rejecting a wrong password */
+	if user == nil {
+		dummyHashOnce.Do(func() {
+			dummyHash, _ = bcrypt.GenerateFromPassword([]byte("not a real password"), bcrypt.DefaultCost)
+		})
+		bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		return false
+	}
+
+	return user.CheckPassword(password)
+}
@@ -0,0 +1,164 @@
+package configuration
+
+import (
+	// Native packages
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+
+	// Required packages
+	"github.com/go-yaml/yaml"
+)
+
+/* This package loads the configuration of every Go service. A service describes its settings with
+a struct whose fields carry yaml tags (the keys of its configuration file), and optionally:
+	- default:"value", the value used when the key is missing from the file
+	- validate:"required", "positive" or "port", checked (with the service's ConfigCheck) by Validate
+	- secret:"true", for values that are redacted when the configuration is printed
+	- reload:"true", for settings that can be changed while the service is running (see Watch)
+A configuration is loaded in three layers: the defaults, then the file (in which keys that the
+service doesn't know are refused, so that typos aren't silently ignored), then the environment.
+Every key can be overridden with an environment variable named after the service's prefix and
+the key's path, for example POWERESTIMATIONSP_CLIENT_HOST_FETCH for client.host.fetch. Values
+that aren't strings are written as YAML, so lists and maps can be overridden too. The file is
+src/<service>/configuration.yaml by default, and can be changed with the --config flag. Running a
+service with --print-config prints the configuration it would run with and exits */
+
+const (
+	// ConfigFlag is the command line flag that sets the path of the configuration file
+	ConfigFlag = "config"
+
+	// PrintFlag is the command line flag that prints the effective configuration and exits
+	PrintFlag = "print-config"
+
+	// redacted replaces the values of secret settings when the configuration is printed
+	redacted = "[redacted]"
+)
+
+type Loader struct {
+	/* This struct loads a service's configuration from its file and environment, and
+	watches the file for changes (see Watch) */
+	Service        string // The name of the service, as used in messages
+	Path           string // The path of the configuration file
+	EnvPrefix      string // The prefix of the environment variables that override the file's keys
+	PrintRequested bool   // Whether the service was asked (with --print-config) to print its configuration and exit
+
+	Overridden []string // The keys set by environment variables when the configuration was last loaded
+
+	lookupEnv func(key string) (string, bool)
+}
+
+func NewLoader(service string, defaultPath string, envPrefix string) *Loader {
+	/* This function creates a loader for the provided service, reading the configuration
+	file's path (and whether to print the configuration) from the command line. Arguments
+	the loader doesn't know are left for the service (or the test runner) */
+	path, print := ParseArgs(os.Args[1:], defaultPath)
+
+	return &Loader{
+		Service:        service,
+		Path:           path,
+		EnvPrefix:      envPrefix,
+		PrintRequested: print,
+		lookupEnv:      os.LookupEnv,
+	}
+}
+
+func ParseArgs(args []string, defaultPath string) (path string, print bool) {
+	/* This function returns the configuration file's path set with --config (or the provided
+	default), and whether --print-config was passed. Both "--config path" and "--config=path"
+	are accepted, with one or two dashes */
+	path = defaultPath
+	for i := 0; i < len(args); i++ {
+		name := strings.TrimLeft(args[i], "-")
+		if name == args[i] {
+			continue // Not a flag
+		}
+
+		switch {
+		case name == PrintFlag || name == PrintFlag+"=true":
+			print = true
+		case strings.HasPrefix(name, ConfigFlag+"="):
+			path = strings.TrimPrefix(name, ConfigFlag+"=")
+		case name == ConfigFlag && i+1 < len(args):
+			path = args[i+1]
+			i++
+		}
+	}
+
+	return path, print
+}
+
+func (loader *Loader) Load(config interface{}) error {
+	/* This function loads the configuration into the provided struct (a pointer): the defaults
+	first, then the file and then the environment variables. It returns an error if the file
+	can't be read, holds keys the service doesn't know, or a value (from either the file or an
+	environment variable) doesn't fit its setting */
+	if err := applyDefaults(config); err != nil {
+		return err
+	}
+
+	contents, err := ioutil.ReadFile(loader.Path)
+	if err != nil {
+		return fmt.Errorf("could not read %v: %v", loader.Path, err)
+	}
+	if err := yaml.UnmarshalStrict(contents, config); err != nil {
+		return fmt.Errorf("could not decode %v: %v", loader.Path, err)
+	}
+
+	overridden, err := applyEnvironment(config, loader.EnvPrefix, loader.lookupEnv)
+	loader.Overridden = overridden
+
+	return err
+}
+
+func (loader *Loader) Print(output io.Writer, config interface{}) error {
+	/* This function writes the provided configuration to the provided output as YAML, with
+	its secrets redacted, noting where it was loaded from and which keys were overridden */
+	contents, err := yaml.Marshal(redact(reflect.ValueOf(config)))
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(output, "# Effective configuration of the %v, loaded from %v (secrets are redacted)\n", loader.Service, loader.Path)
+	for _, key := range loader.Overridden {
+		fmt.Fprintf(output, "# %v is set by %v\n", key, EnvironmentVariable(loader.EnvPrefix, key))
+	}
+	_, err = output.Write(contents)
+
+	return err
+}
+
+func EnvironmentVariable(prefix string, key string) string {
+	/* This function returns the name of the environment variable that overrides the provided
+	key ("client.host.fetch"), the prefix followed by the key's parts in upper snake case
+	("PREFIX_CLIENT_HOST_FETCH") */
+	parts := []string{prefix}
+	for _, part := range strings.Split(key, ".") {
+		parts = append(parts, upperSnakeCase(part))
+	}
+
+	return strings.Join(parts, "_")
+}
+
+// ________SUPPORTING FUNCTIONS________
+
+func upperSnakeCase(name string) string {
+	/* This (unexported) function converts a camel case key ("requireClientCertificate") to
+	upper snake case ("REQUIRE_CLIENT_CERTIFICATE"). Runs of capitals stay together, so
+	"powerEstimationSP" becomes "POWER_ESTIMATION_SP" */
+	var converted strings.Builder
+	for i, character := range name {
+		if i > 0 && character >= 'A' && character <= 'Z' {
+			previous := name[i-1]
+			if (previous >= 'a' && previous <= 'z') || (previous >= '0' && previous <= '9') {
+				converted.WriteByte('_')
+			}
+		}
+		converted.WriteRune(character)
+	}
+
+	return strings.ToUpper(converted.String())
+}
@@ -0,0 +1,328 @@
+package configuration
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+type testConfig struct {
+	Server struct {
+		Port struct {
+			Myself string `yaml:"myself" validate:"port"`
+		} `yaml:"port"`
+		SecretKey string `yaml:"secretKey" secret:"true"`
+		Logging   struct {
+			Level string `yaml:"level" default:"info"`
+			File  string `yaml:"file" default:"service.log"`
+		} `yaml:"logging" reload:"true"`
+		Health struct {
+			Interval int `yaml:"interval" default:"10" validate:"positive"`
+		} `yaml:"health"`
+	} `yaml:"server"`
+	Client struct {
+		Host struct {
+			Fetch string `yaml:"fetch" default:"localhost" validate:"required"`
+		} `yaml:"host"`
+		Timeout struct {
+			Call int `yaml:"call" reload:"true"`
+		} `yaml:"timeout"`
+		Methods map[string]bool `yaml:"methods"`
+	} `yaml:"client"`
+	Providers []struct {
+		Type     string `yaml:"type"`
+		Password string `yaml:"password" secret:"true"`
+	} `yaml:"providers"`
+}
+
+const testFile = `
+server:
+  port:
+    myself: "50101"
+  secretKey: "supersecret"
+  logging:
+    level: "warning"
+client:
+  timeout:
+    call: 15
+providers:
+  - type: "ldap"
+    password: "bindpassword"
+`
+
+func testLoader(t *testing.T, contents string, environment map[string]string) *Loader {
+	// This function writes the provided configuration file, and returns a loader for it that reads the provided environment
+	path := filepath.Join(t.TempDir(), "configuration.yaml")
+	if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return &Loader{Service: "test service", Path: path, EnvPrefix: "TESTSERVICE", lookupEnv: func(key string) (string, bool) {
+		value, ok := environment[key]
+		return value, ok
+	}}
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("Defaults, the file and the environment are layered", func(t *testing.T) {
+		loader := testLoader(t, testFile, map[string]string{
+			"TESTSERVICE_CLIENT_HOST_FETCH":      "fetchdataservice",
+			"TESTSERVICE_SERVER_HEALTH_INTERVAL": "30",
+			"TESTSERVICE_CLIENT_METHODS":         "{/Package/Method: true}",
+		})
+		config := &testConfig{}
+		if err := loader.Load(config); err != nil {
+			t.Fatal("Expected the configuration to load, received ", err)
+		}
+
+		if config.Server.Logging.File != "service.log" || config.Server.Logging.Level != "warning" {
+			t.Error("Expected the file to override only the defaults it sets, received ", config.Server.Logging)
+		}
+		if config.Client.Host.Fetch != "fetchdataservice" || config.Server.Health.Interval != 30 || !config.Client.Methods["/Package/Method"] {
+			t.Error("Expected the environment to override the file and defaults, received ", config.Client, config.Server.Health)
+		}
+		if strings.Join(loader.Overridden, " ") != "server.health.interval client.host.fetch client.methods" {
+			t.Error("Expected the overridden keys to be recorded, received ", loader.Overridden)
+		}
+	})
+
+	var Tests = []struct {
+		name        string
+		contents    string
+		environment map[string]string
+		expectedErr string
+	}{
+		{"Unknown keys are refused", "client:\n  timeuot:\n    call: 5\n", nil, "timeuot"},
+		{"Values of the wrong type are refused", "server:\n  health:\n    interval: often\n", nil, "often"},
+		{"Invalid environment variables are reported", testFile, map[string]string{"TESTSERVICE_CLIENT_TIMEOUT_CALL": "soon"}, "TESTSERVICE_CLIENT_TIMEOUT_CALL"},
+	}
+	for _, test := range Tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := testLoader(t, test.contents, test.environment).Load(&testConfig{})
+			if err == nil || !strings.Contains(err.Error(), test.expectedErr) {
+				t.Errorf("Expected an error mentioning %q, received %v", test.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestParseArgs(t *testing.T) {
+	var Tests = []struct {
+		args          []string
+		expectedPath  string
+		expectedPrint bool
+	}{
+		{nil, "default.yaml", false},
+		{[]string{"--config", "other.yaml"}, "other.yaml", false},
+		{[]string{"-config=other.yaml", "--print-config"}, "other.yaml", true},
+		{[]string{"-test.v", "-test.run", "TestLoad"}, "default.yaml", false},
+	}
+
+	for _, test := range Tests {
+		path, print := ParseArgs(test.args, "default.yaml")
+		if path != test.expectedPath || print != test.expectedPrint {
+			t.Errorf("Expected %v to give %q and %v, received %q and %v", test.args, test.expectedPath, test.expectedPrint, path, print)
+		}
+	}
+}
+
+func TestEnvironmentVariable(t *testing.T) {
+	for key, expected := range map[string]string{
+		"server.tls.requireClientCertificate":                "PREFIX_SERVER_TLS_REQUIRE_CLIENT_CERTIFICATE",
+		"client.authenticatedMethods.name.powerEstimationSP": "PREFIX_CLIENT_AUTHENTICATED_METHODS_NAME_POWER_ESTIMATION_SP",
+		"server.identity.providers":                          "PREFIX_SERVER_IDENTITY_PROVIDERS",
+	} {
+		if variable := EnvironmentVariable("PREFIX", key); variable != expected {
+			t.Errorf("Expected %v to be overridden by %v, received %v", key, expected, variable)
+		}
+	}
+}
+
+func TestPrint(t *testing.T) {
+	loader := testLoader(t, testFile, map[string]string{"TESTSERVICE_CLIENT_HOST_FETCH": "fetchdataservice"})
+	config := &testConfig{}
+	if err := loader.Load(config); err != nil {
+		t.Fatal(err)
+	}
+
+	output := &bytes.Buffer{}
+	if err := loader.Print(output, config); err != nil {
+		t.Fatal(err)
+	}
+	printed := output.String()
+	for _, secret := range []string{"supersecret", "bindpassword"} {
+		if strings.Contains(printed, secret) {
+			t.Errorf("Expected %q to be redacted, received:\n%v", secret, printed)
+		}
+	}
+	for _, expected := range []string{"secretKey: '[redacted]'", "password: '[redacted]'", "level: warning", "fetch: fetchdataservice", "TESTSERVICE_CLIENT_HOST_FETCH"} {
+		if !strings.Contains(printed, expected) {
+			t.Errorf("Expected the printed configuration to contain %q, received:\n%v", expected, printed)
+		}
+	}
+	if strings.Index(printed, "server:") > strings.Index(printed, "client:") {
+		t.Error("Expected the keys to be printed in the order they are declared")
+	}
+}
+
+type recordingChecker struct {
+	// This struct records the settings it is asked to check
+	checked []string
+}
+
+func (checker *recordingChecker) RequireValue(setting string, value string) {
+	checker.checked = append(checker.checked, "value "+setting+"="+value)
+}
+func (checker *recordingChecker) RequirePositive(setting string, value int) {
+	checker.checked = append(checker.checked, "positive "+setting)
+}
+func (checker *recordingChecker) RequirePort(setting string, port string) {
+	checker.checked = append(checker.checked, "port "+setting+"="+port)
+}
+
+func TestValidate(t *testing.T) {
+	config := &testConfig{}
+	if err := testLoader(t, testFile, nil).Load(config); err != nil {
+		t.Fatal(err)
+	}
+
+	checker := &recordingChecker{}
+	Validate(config, checker)
+	expected := "port server.port.myself=50101, positive server.health.interval, value client.host.fetch=localhost"
+	if checked := strings.Join(checker.checked, ", "); checked != expected {
+		t.Errorf("Expected the checks %q, received %q", expected, checked)
+	}
+}
+
+func TestChanges(t *testing.T) {
+	current, loaded := &testConfig{}, &testConfig{}
+	current.Server.Logging.Level, loaded.Server.Logging.Level = "info", "debug"
+	current.Client.Timeout.Call, loaded.Client.Timeout.Call = 15, 30
+	current.Server.Port.Myself, loaded.Server.Port.Myself = "50101", "50102"
+
+	reloadable, fixed := Changes(current, loaded)
+	if strings.Join(reloadable, " ") != "server.logging.level client.timeout.call" || strings.Join(fixed, " ") != "server.port.myself" {
+		t.Errorf("Expected the changes to be split by whether they can be reloaded, received %v and %v", reloadable, fixed)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	loader := testLoader(t, testFile, nil)
+	current := &testConfig{}
+	if err := loader.Load(current); err != nil {
+		t.Fatal(err)
+	}
+
+	applied := make(chan *testConfig, 1)
+	var invalid int32 // Set (atomically, the watcher reads it) while reloaded configurations should be refused
+	stop := loader.Watch(current, 10*time.Millisecond, func(config interface{}) error {
+		if atomic.LoadInt32(&invalid) == 1 {
+			return errors.New("invalid")
+		}
+		return nil
+	}, func(config interface{}) {
+		applied <- config.(*testConfig)
+	})
+	defer stop()
+
+	rewrite := func(contents string) {
+		if err := ioutil.WriteFile(loader.Path, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+		future := time.Now().Add(time.Minute)
+		os.Chtimes(loader.Path, future, future)
+	}
+	expectApplied := func(level string) {
+		select {
+		case config := <-applied:
+			if config.Server.Logging.Level != level {
+				t.Errorf("Expected the level %v to be applied, received %v", level, config.Server.Logging.Level)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("Expected the reloaded configuration to be applied")
+		}
+	}
+	expectNothingApplied := func() {
+		select {
+		case config := <-applied:
+			t.Error("Expected nothing to be applied, received ", config.Server.Logging)
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+
+	t.Run("Changed files are reloaded", func(t *testing.T) {
+		rewrite(strings.Replace(testFile, `level: "warning"`, `level: "debug"`, 1))
+		expectApplied("debug")
+	})
+
+	t.Run("Invalid configurations are ignored", func(t *testing.T) {
+		atomic.StoreInt32(&invalid, 1)
+		rewrite(strings.Replace(testFile, `level: "warning"`, `level: "error"`, 1))
+		expectNothingApplied()
+		atomic.StoreInt32(&invalid, 0)
+	})
+
+	t.Run("SIGHUP reloads the file", func(t *testing.T) {
+		if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+			t.Fatal(err)
+		}
+		expectApplied("error")
+	})
+
+	t.Run("Settings that can't be reloaded aren't applied", func(t *testing.T) {
+		rewrite(strings.Replace(strings.Replace(testFile, `level: "warning"`, `level: "error"`, 1), "50101", "50102", 1))
+		expectNothingApplied()
+	})
+}
+
+func TestReloadKeepsFixedSettings(t *testing.T) {
+	loader := testLoader(t, testFile, nil)
+	current := &testConfig{}
+	if err := loader.Load(current); err != nil {
+		t.Fatal(err)
+	}
+	var applied *testConfig
+	validate := func(config interface{}) error { return nil }
+	apply := func(config interface{}) { applied = config.(*testConfig) }
+
+	rewrite := func(port string, level string) {
+		contents := strings.Replace(strings.Replace(testFile, "50101", port, 1), `level: "warning"`, `level: "`+level+`"`, 1)
+		if err := ioutil.WriteFile(loader.Path, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rewrite("50102", "debug")
+	running := loader.reload(current, validate, apply).(*testConfig)
+	if applied == nil || applied.Server.Logging.Level != "debug" || applied.Server.Port.Myself != "50101" {
+		t.Fatal("Expected only the reloadable settings to be applied, received ", applied)
+	}
+	if running.Server.Port.Myself != "50101" {
+		t.Error("Expected the running configuration to keep the port in use, received ", running.Server.Port.Myself)
+	}
+
+	// Reverting the port is not a change to the configuration the service is running with
+	rewrite("50101", "debug")
+	reverted := &testConfig{}
+	if err := loader.Load(reverted); err != nil {
+		t.Fatal(err)
+	}
+	if _, fixed := Changes(running, reverted); len(fixed) != 0 {
+		t.Error("Expected no settings that need a restart to have changed, received ", fixed)
+	}
+}
+
+func TestDuration(t *testing.T) {
+	var duration Duration
+	duration.Set(15 * time.Second)
+	if duration.Get() != 15*time.Second {
+		t.Error("Expected the duration to be 15s, received ", duration.Get())
+	}
+}
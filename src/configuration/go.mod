@@ -0,0 +1,10 @@
+module github.com/nicholasbunn/mastersSandbox/src/configuration
+
+go 1.13
+
+require (
+	github.com/go-yaml/yaml v2.1.0+incompatible
+	github.com/nicholasbunn/mastersSandbox/src/logging v0.0.0-00010101000000-000000000000
+)
+
+replace github.com/nicholasbunn/mastersSandbox/src/logging => ../logging
@@ -0,0 +1,127 @@
+package configuration
+
+import (
+	// Native packages
+	"os"
+	"os/signal"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	// Personal packages
+	"github.com/nicholasbunn/mastersSandbox/src/logging"
+)
+
+/* A running service reloads its configuration when it receives SIGHUP (docker kill -s HUP), or
+when its configuration file changes. Only the settings tagged reload:"true" are applied: the
+reloaded configuration is checked in the same way as when the service started, and handed to the
+service to apply if it is valid. Changes to any other setting are logged, and are applied the
+next time the service is started. Settings that are read while calls are served are held in
+values that can be changed safely while they are read, such as Duration */
+
+type Duration struct {
+	// This struct holds a duration that can be changed while it is being read, for settings that are reloaded
+	nanoseconds int64
+}
+
+func (duration *Duration) Get() time.Duration {
+	// This function returns the duration
+	return time.Duration(atomic.LoadInt64(&duration.nanoseconds))
+}
+
+func (duration *Duration) Set(value time.Duration) {
+	// This function changes the duration
+	atomic.StoreInt64(&duration.nanoseconds, int64(value))
+}
+
+func (loader *Loader) Watch(current interface{}, interval time.Duration, validate func(config interface{}) error, apply func(config interface{})) (stop func()) {
+	/* This function reloads the configuration whenever the service receives SIGHUP, or the
+	configuration file has changed (it is checked every interval). The provided configuration
+	is the one the service is running with. A reloaded configuration that can't be loaded, or
+	that validate returns an error for, is ignored. Otherwise, if any reloadable setting
+	changed, it is passed to apply, which should only apply the reloadable settings. It
+	returns a function that stops the watcher */
+	done := make(chan struct{})
+	ticker := time.NewTicker(interval)
+	hangups := make(chan os.Signal, 1)
+	signal.Notify(hangups, syscall.SIGHUP)
+	lastModified := modificationTime(loader.Path)
+
+	go func() {
+		defer ticker.Stop()
+		defer signal.Stop(hangups)
+		for {
+			select {
+			case <-done:
+				return
+			case <-hangups:
+				logging.Logger.Infof("Received SIGHUP, reloading %v", loader.Path)
+				lastModified = modificationTime(loader.Path)
+				current = loader.reload(current, validate, apply)
+			case <-ticker.C:
+				if modified := modificationTime(loader.Path); !modified.Equal(lastModified) {
+					lastModified = modified
+					logging.Logger.Infof("%v has changed, reloading it", loader.Path)
+					current = loader.reload(current, validate, apply)
+				}
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
+
+// ________SUPPORTING FUNCTIONS________
+
+func (loader *Loader) reload(current interface{}, validate func(config interface{}) error, apply func(config interface{})) interface{} {
+	/* This (unexported) function loads the configuration again, applies its reloadable
+	settings if they changed, and returns the configuration the service is running with. The
+	settings that can't be reloaded keep their current values, so that changes to them are
+	compared against the values in use (and reported again until the service is restarted) */
+	loaded := reflect.New(reflect.TypeOf(current).Elem()).Interface()
+	if err := loader.Load(loaded); err != nil {
+		logging.Logger.Warnf("Could not reload the configuration, keeping the current one: %v", err)
+		return current
+	}
+	if err := validate(loaded); err != nil {
+		logging.Logger.Warnf("The reloaded configuration is invalid, keeping the current one: %v", err)
+		return current
+	}
+
+	reloadable, fixed := Changes(current, loaded)
+	if len(fixed) > 0 {
+		logging.Logger.Warnf("Restart the %v to apply the changes to %v, they can't be changed while it is running", loader.Service, strings.Join(fixed, ", "))
+		keepFixed(current, loaded)
+	}
+	if len(reloadable) == 0 {
+		logging.Logger.Infoln("No settings that can be reloaded have changed")
+		return loaded
+	}
+
+	apply(loaded)
+	logging.Logger.Infof("Reloaded the configuration, applying the changes to %v", strings.Join(reloadable, ", "))
+
+	return loaded
+}
+
+func keepFixed(current interface{}, loaded interface{}) {
+	// This (unexported) function copies the settings that can't be reloaded from the current configuration into the loaded one
+	currentSettings := settings(current)
+	for i, setting := range settings(loaded) {
+		if !setting.reloadable {
+			setting.value.Set(currentSettings[i].value)
+		}
+	}
+}
+
+func modificationTime(path string) time.Time {
+	// This (unexported) function returns the time the file at the provided path was last modified, or the zero time if it can't be read
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return info.ModTime()
+}
@@ -0,0 +1,217 @@
+package configuration
+
+import (
+	// Native packages
+	"fmt"
+	"reflect"
+	"strings"
+
+	// Required packages
+	"github.com/go-yaml/yaml"
+)
+
+/* The settings of a configuration are found by walking its struct: every field is a key (named by
+its yaml tag), fields that are structs hold further keys, and every other field is a setting.
+The tags on the fields describe each setting's default, validation, secrecy and whether it can be
+reloaded, and a reload tag on a struct applies to every setting in it */
+
+type Checker interface {
+	/* This interface describes what Validate reports problems to, it is implemented by the
+	services' authentication.ConfigCheck */
+	RequireValue(setting string, value string)
+	RequirePositive(setting string, value int)
+	RequirePort(setting string, port string)
+}
+
+type setting struct {
+	// This (unexported) struct describes a setting found while walking a configuration
+	key        string // The key's full path ("server.port.myself")
+	field      reflect.StructField
+	value      reflect.Value // The setting's (settable) value
+	reloadable bool          // Whether the setting, or a struct it is in, is tagged reload:"true"
+}
+
+func Validate(config interface{}, check Checker) {
+	/* This function checks every setting of the provided configuration that has a validate
+	tag, reporting the problems to the provided checker. Checks that depend on other settings
+	(or files) are left to the service */
+	for _, setting := range settings(config) {
+		for _, rule := range strings.Split(setting.field.Tag.Get("validate"), ",") {
+			switch rule {
+			case "required":
+				check.RequireValue(setting.key, fmt.Sprint(setting.value.Interface()))
+			case "positive":
+				check.RequirePositive(setting.key, int(setting.value.Int()))
+			case "port":
+				check.RequirePort(setting.key, setting.value.String())
+			}
+		}
+	}
+}
+
+func Changes(current interface{}, loaded interface{}) (reloadable []string, fixed []string) {
+	/* This function compares two configurations of the same service, and returns the keys
+	that differ, split into those that can be applied while the service is running and those
+	that need a restart */
+	loadedSettings := settings(loaded)
+	for i, setting := range settings(current) {
+		if reflect.DeepEqual(setting.value.Interface(), loadedSettings[i].value.Interface()) {
+			continue
+		}
+		if setting.reloadable {
+			reloadable = append(reloadable, setting.key)
+		} else {
+			fixed = append(fixed, setting.key)
+		}
+	}
+
+	return reloadable, fixed
+}
+
+// ________SUPPORTING FUNCTIONS________
+
+func settings(config interface{}) []setting {
+	// This (unexported) function returns every setting of the provided configuration (a pointer to a struct), in the order they are declared
+	var found []setting
+	walk(reflect.ValueOf(config).Elem(), "", false, &found)
+
+	return found
+}
+
+func walk(value reflect.Value, prefix string, reloadable bool, found *[]setting) {
+	// This (unexported) function adds the settings of the provided struct to found
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		name := keyName(field)
+		if field.PkgPath != "" || name == "-" {
+			continue // Unexported and ignored fields aren't settings
+		}
+
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+		fieldReloadable := reloadable || field.Tag.Get("reload") == "true"
+
+		if field.Type.Kind() == reflect.Struct {
+			walk(value.Field(i), key, fieldReloadable, found)
+			continue
+		}
+		*found = append(*found, setting{key: key, field: field, value: value.Field(i), reloadable: fieldReloadable})
+	}
+}
+
+func keyName(field reflect.StructField) string {
+	// This (unexported) function returns the key of a field, its yaml tag or (as yaml does) its lowercased name
+	name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+	if name == "" {
+		name = strings.ToLower(field.Name)
+	}
+
+	return name
+}
+
+func setValue(value reflect.Value, text string) error {
+	/* This (unexported) function sets a setting from text, as a string for string settings
+	and as YAML for every other kind (numbers, booleans, lists and maps) */
+	if value.Kind() == reflect.String {
+		value.SetString(text)
+		return nil
+	}
+
+	parsed := reflect.New(value.Type())
+	if err := yaml.UnmarshalStrict([]byte(text), parsed.Interface()); err != nil {
+		return err
+	}
+	value.Set(parsed.Elem())
+
+	return nil
+}
+
+func applyDefaults(config interface{}) error {
+	// This (unexported) function sets every setting of the provided configuration that has a default tag to its default
+	for _, setting := range settings(config) {
+		defaultValue, ok := setting.field.Tag.Lookup("default")
+		if !ok {
+			continue
+		}
+		if err := setValue(setting.value, defaultValue); err != nil {
+			return fmt.Errorf("the default of %v (%q) is invalid: %v", setting.key, defaultValue, err)
+		}
+	}
+
+	return nil
+}
+
+func applyEnvironment(config interface{}, prefix string, lookupEnv func(string) (string, bool)) ([]string, error) {
+	/* This (unexported) function sets every setting of the provided configuration that has an
+	environment variable set for it, and returns the keys that were set. Every variable with an
+	invalid value is reported in the returned error */
+	var overridden, problems []string
+	for _, setting := range settings(config) {
+		variable := EnvironmentVariable(prefix, setting.key)
+		text, ok := lookupEnv(variable)
+		if !ok {
+			continue
+		}
+		if err := setValue(setting.value, text); err != nil {
+			problems = append(problems, fmt.Sprintf("%v (%v) is invalid: %v", variable, setting.key, err))
+			continue
+		}
+		overridden = append(overridden, setting.key)
+	}
+
+	if len(problems) > 0 {
+		return overridden, fmt.Errorf("%v", strings.Join(problems, "; "))
+	}
+
+	return overridden, nil
+}
+
+func redact(value reflect.Value) interface{} {
+	/* This (unexported) function returns the provided configuration value in a form that can
+	be written as YAML in the order it is declared in, with the values of secret settings
+	(and of secret fields in lists of structs) replaced */
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if value.IsNil() {
+			return nil
+		}
+		return redact(value.Elem())
+	case reflect.Struct:
+		mapped := yaml.MapSlice{}
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+			name := keyName(field)
+			if field.PkgPath != "" || name == "-" {
+				continue
+			}
+			var item interface{} = redact(value.Field(i))
+			if field.Tag.Get("secret") == "true" && !value.Field(i).IsZero() {
+				item = redacted
+			}
+			mapped = append(mapped, yaml.MapItem{Key: name, Value: item})
+		}
+		return mapped
+	case reflect.Slice, reflect.Array:
+		if value.Kind() == reflect.Slice && value.IsNil() {
+			return nil
+		}
+		items := make([]interface{}, value.Len())
+		for i := range items {
+			items[i] = redact(value.Index(i))
+		}
+		return items
+	case reflect.Map:
+		if value.IsNil() {
+			return nil
+		}
+		mapped := map[interface{}]interface{}{}
+		for _, key := range value.MapKeys() {
+			mapped[key.Interface()] = redact(value.MapIndex(key))
+		}
+		return mapped
+	default:
+		return value.Interface()
+	}
+}
@@ -3,27 +3,23 @@ package main
 import (
 	// Native packages
 	"context"
-	"crypto/tls"
-	"crypto/x509"
+	"errors"
 	"fmt"
-	"io/ioutil"
-	"log"
 	"net"
 	"os"
-	"strings"
 	"time"
 
 	// Required packages
 
-	"github.com/go-yaml/yaml"
 	authentication "github.com/nicholasbunn/mastersSandbox/src/authenticationStuff"
+	"github.com/nicholasbunn/mastersSandbox/src/configuration"
 
 	// gRPC packages
 	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
-	grpc_retry "github.com/grpc-ecosystem/go-grpc-middleware/retry"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 
 	// Proto packages
 	authenticationPB "github.com/nicholasbunn/mastersSandbox/src/authenticationService/proto"
@@ -31,182 +27,400 @@ import (
 	estimationPB "github.com/nicholasbunn/mastersSandbox/src/powerEstimationSP/proto"
 
 	// Interceptors
-	"github.com/nicholasbunn/mastersSandbox/src/desktopGateway/interceptors"
+	"github.com/nicholasbunn/mastersSandbox/src/interceptors"
+
+	// Logging
+	"github.com/nicholasbunn/mastersSandbox/src/logging"
 )
 
+// metricsJob is the job that this service's metrics are pushed to the pushgateway under
+const metricsJob = "DesktopGateway"
+
 var (
 	// Addresses
 	addrMyself                string
 	addrEstimationSP          string
 	addrAuthenticationService string
 
-	timeoutDuration     int           // The time, in seconds, that the client should wait when dialing (connecting to) the server before throwing an error
-	callTimeoutDuration time.Duration // The time, in seconds, that the client should wait when making a call to the server before throwing an error
+	// The authentication service, it checks the API keys and sessions of the calls served and exchanges their tokens
+	authenticationService *interceptors.RemoteAuthStruct
+
+	// Long-lived connections to the services called (the authentication service and the health checks), closed once the gateway has drained
+	connections = &interceptors.Connections{}
+
+	// TLS stuff, the gateway verifies its callers (the frontend) and presents its own certificate to the services it calls
+	serverTLS                 authentication.TLSFiles
+	clientTLS                 authentication.TLSFiles
+	certificateReloadInterval time.Duration // The interval at which the certificate files are checked for changes
+	certificateExpiryWarning  time.Duration // How long before a certificate expires to start logging warnings
+	serverCertificates        *authentication.CertificateManager
+	clientCertificates        *authentication.CertificateManager
+
+	timeoutDuration     configuration.Duration // The time that the client should wait when dialing (connecting to) the server before throwing an error
+	callTimeoutDuration configuration.Duration // The time that the client should wait when making a call to the server before throwing an error
 
 	// Input parameters (To be passed through the frontend)
 	INPUTfilename = "TestData/CMU_2019_2020_openWater.xlsx" // MEEP Need to pass a path relative to the execution directory
 	MODELTYPE     = "OPENWATER"
 
 	// JWT stuff, load this in from config
-	secretkey     string
-	tokenduration time.Duration
+	jwtSecret            *authentication.Secret // The JWT signing secret, resolved from the reference in the config (see authentication.Secret)
+	secretReloadInterval time.Duration          // The interval at which a secret held in a file is checked for rotation
+	tokenduration        time.Duration
+	audience             string // The name of this service, as used in exchanged tokens
+
+	audienceEstimationSP string // The name of the aggregator, exchanged tokens for calls to it are restricted to it
+
+	policyFile           string        // The path to the authorisation policy file
+	policyReloadInterval time.Duration // The interval at which the policy file is checked for changes
+
+	// Audit log, shared with the other services
+	auditDirectory string        // The directory (shared by the services) that audit files are written to
+	auditRetention time.Duration // How long audit files are kept for
+	auditLog       *authentication.AuditLog
+
+	sessionCacheDuration time.Duration // How long a session seen to be active is trusted for, before asking the authentication service again
 
-	accessibleRoles map[string][]string // This is a map of service calls with their required permission levels
+	// Metrics, served for Prometheus to scrape and (optionally) pushed to the pushgateway
+	addrMetrics             string
+	addrPushgateway         string
+	metricsPushEnabled      bool
+	metricsPushInterval     time.Duration // The interval at which changed metrics are pushed
+	metricsMaxBackoff       time.Duration // The longest interval between pushes while the pushgateway is unreachable
+	metricExporter          *interceptors.MetricExporter
+	clientMetricInterceptor *interceptors.ClientMetricStruct
+	serverMetricInterceptor *interceptors.ServerMetricStruct
 
-	authMethods map[string]bool // This is a map of which service calls require authentication
+	clientRetryInterceptor *interceptors.ClientRetryStruct // Retries the calls that failed in a way that is safe to retry, see client.retry
 
-	// Logging stuff
-	DebugLogger   *log.Logger
-	InfoLogger    *log.Logger
-	WarningLogger *log.Logger
-	ErrorLogger   *log.Logger
+	tracingConfig interceptors.TracingConfig // Where the gateway's spans are exported to
+
+	loggingConfig logging.Config // How the gateway's log lines are written, the logger is set up with it in main
+
+	// Health checks, the gateway is ready while it can reach the services it calls
+	healthInterval time.Duration // The interval at which the health checks are run
+	healthTimeout  time.Duration // How long a health check may take before it fails
+
+	drainTimeout time.Duration // How long the calls in flight are given to finish when the gateway is stopped
+
+	// Configuration, reloaded while the gateway is running (see applyConfig)
+	configLoader         *configuration.Loader
+	loadedConfig         *Config
+	configReloadInterval time.Duration // The interval at which the configuration file is checked for changes
 )
 
 func init() {
-	/* The init functin is used to load in configuration variables, and set up the logger and metric interceptors whenever the service is started
+	/* The init functin is used to load in configuration variables, and set up the metric interceptors whenever the service is started
 	 */
 
 	// ________CONFIGURATION________
-	// Load YAML configurations into config struct
-	config, _ := DecodeConfig("src/desktopGateway/configuration.yaml")
-
-	// Load port addresses from config
-	addrMyself = os.Getenv("DESKTOPGATEWAYHOST") + ":" + config.Server.Port.Myself
-	addrEstimationSP = os.Getenv("POWERESTIMATIONHOST") + ":" + config.Client.Port.EstimationSP
-	addrAuthenticationService = os.Getenv("AUTHENTICATIONHOST") + ":" + config.Client.Port.AuthenticationService
+	/* Load the configuration (the defaults, then the file, then DESKTOPGATEWAY_* environment
+	variables) into the config struct, refusing to start if anything is missing or insecure */
+	configLoader = configuration.NewLoader("desktop gateway", "src/desktopGateway/configuration.yaml", "DESKTOPGATEWAY")
+	configCheck := authentication.NewConfigCheck("desktop gateway", configLoader.Path)
+	config := &Config{}
+	if err := configLoader.Load(config); err != nil {
+		configCheck.Problem(configLoader.Path, "could not be loaded, services have to be started from the repository root: %v", err)
+		configCheck.Enforce()
+	}
+	if configLoader.PrintRequested {
+		if err := configLoader.Print(os.Stdout, config); err != nil {
+			logging.Logger.Fatalf("Failed to print the configuration: \n%v", err)
+		}
+		os.Exit(0)
+	}
+	validateConfig(config, configCheck)
+	jwtSecret = configCheck.CheckSecretReference("server.authentication.jwt.secretKey", config.Server.Authentication.Jwt.SecretKey)
+	configCheck.Enforce()
+	loadedConfig = config
+	configReloadInterval = time.Duration(config.Server.Configuration.ReloadInterval) * time.Second
+
+	// Load addresses from config
+	addrMyself = config.Server.Host + ":" + config.Server.Port.Myself
+	addrEstimationSP = config.Client.Host.EstimationSP + ":" + config.Client.Port.EstimationSP
+	addrAuthenticationService = config.Client.Host.AuthenticationService + ":" + config.Client.Port.AuthenticationService
+
+	// Load TLS parameters from config
+	serverTLS = config.Server.TLS
+	clientTLS = config.Client.TLS
+	certificateReloadInterval = time.Duration(config.Server.Certificates.ReloadInterval) * time.Second
+	certificateExpiryWarning = time.Duration(config.Server.Certificates.ExpiryWarning) * 24 * time.Hour
 
 	// Load timeouts from config
-	timeoutDuration = config.Client.Timeout.Connection
-	fmt.Println(timeoutDuration)
-	callTimeoutDuration = time.Duration(config.Client.Timeout.Call) * time.Second
-	fmt.Println(callTimeoutDuration)
+	timeoutDuration.Set(time.Duration(config.Client.Timeout.Connection) * time.Second)
+	callTimeoutDuration.Set(time.Duration(config.Client.Timeout.Call) * time.Second)
 
 	// Load JWT parameters from config
-	secretkey = config.Server.Authentication.Jwt.SecretKey
-	fmt.Println(secretkey)
+	secretReloadInterval = time.Duration(config.Server.Authentication.Jwt.ReloadInterval) * time.Second
 	tokenduration = time.Duration(config.Server.Authentication.Jwt.TokenDuration) * (time.Minute)
-	fmt.Println(tokenduration)
+	audience = config.Server.Authentication.Jwt.Audience
+	audienceEstimationSP = config.Client.Audience.EstimationSP
+
+	// Load authorisation policy parameters from config
+	policyFile = config.Server.Authentication.Policy.File
+	policyReloadInterval = time.Duration(config.Server.Authentication.Policy.ReloadInterval) * time.Second
+
+	// Load audit log parameters from config
+	auditDirectory = config.Server.Audit.Directory
+	auditRetention = time.Duration(config.Server.Audit.Retention) * 24 * time.Hour
+
+	// Load session parameters from config
+	sessionCacheDuration = time.Duration(config.Server.Sessions.CacheDuration) * time.Second
+
+	// Load metric parameters from config
+	addrMetrics = config.Server.Host + ":" + config.Server.Metrics.Port
+	addrPushgateway = config.Server.Metrics.Push.Host + ":" + config.Server.Metrics.Push.Port
+	metricsPushEnabled = config.Server.Metrics.Push.Enabled
+	metricsPushInterval = time.Duration(config.Server.Metrics.Push.Interval) * time.Second
+	metricsMaxBackoff = time.Duration(config.Server.Metrics.Push.MaxBackoff) * time.Second
+
+	// Load tracing parameters from config
+	tracingConfig = config.Server.Tracing
+
+	// Load logging parameters from config
+	loggingConfig = config.Server.Logging
+
+	// Load health check parameters from config
+	healthInterval = time.Duration(config.Server.Health.Interval) * time.Second
+	healthTimeout = time.Duration(config.Server.Health.Timeout) * time.Second
+
+	// Load shutdown parameters from config
+	drainTimeout = time.Duration(config.Server.Shutdown.DrainTimeout) * time.Second
+
+	// Metric interceptors, registered on the gateway's own metrics registry
+	metricExporter = interceptors.NewMetricExporter(metricsJob)
+	metricExporter.Registerer.MustRegister(authentication.CertificateMetrics())
+	clientMetricInterceptor = interceptors.NewClientMetrics(metricExporter) // Custom metric (Prometheus) interceptor
+	serverMetricInterceptor = interceptors.NewServerMetrics(metricExporter) // Custom metric (Prometheus) interceptor
+
+	// Retry policies of the methods called, counted on the same registry
+	clientRetryInterceptor = interceptors.NewClientRetries(metricExporter, config.Client.Retry)
+
+	// The authentication service, every request's calls to it share a single connection
+	authenticationService = &interceptors.RemoteAuthStruct{
+		Address:         addrAuthenticationService,
+		Credentials:     loadClientTLSCredentials, // The client certificates are loaded in main, before the first call
+		CallTimeout:     callTimeoutDuration.Get,
+		OutgoingContext: forwardClientAddress, // The authentication service throttles and audits per client, rather than per gateway
+		Interceptors: []grpc.UnaryClientInterceptor{
+			interceptors.ClientRecoveryInterceptor,
+			interceptors.ClientTracingInterceptor,
+			interceptors.ClientRequestIDInterceptor,
+			interceptors.ClientLoggingInterceptor,
+			clientMetricInterceptor.ClientMetricInterceptor,
+			clientRetryInterceptor.ClientRetryInterceptor, // Last, so that the interceptors above see one call however many attempts it takes
+		},
+		Connections: connections,
+	}
+}
 
-	accessibleRoles = map[string][]string{
-		config.Server.Authentication.AccessLevel.Name.PowerEstimationSP: config.Server.Authentication.AccessLevel.Role.PowerEstimationSP,
+func main() {
+	/* The main function sets up a server to listen on the specified port,
+	encrypts the server connection with TLS, and registers the services on
+	offer */
+
+	// Set up the logger first, anything logged before this (while loading the configuration) went to stderr
+	stopLogging, err := logging.Setup(metricsJob, loggingConfig)
+	if err != nil {
+		logging.Logger.Fatalf("Failed to set up logging: \n%v", err)
 	}
-	fmt.Println(accessibleRoles)
+	defer stopLogging()
+	logging.Logger.Infoln("Started gateway")
 
-	authMethods = map[string]bool{
-		config.Client.AuthenticatedMethods.Name.PowerEstimationSP: config.Client.AuthenticatedMethods.RequiresAuthentication.PowerEstimaitonSP,
+	// Reload the configuration on SIGHUP or when its file changes, applying the settings that can be changed while running
+	stopWatchingConfig := configLoader.Watch(loadedConfig, configReloadInterval, checkReloadedConfig, applyConfig)
+	defer stopWatchingConfig()
+
+	// Load in TLS credentials and watch them for changes
+	if serverCertificates, err = loadCertificates("server", serverTLS); err != nil {
+		logging.Logger.Fatalf("Failed to load TLS credentials: \n%v", err)
+	}
+	if clientCertificates, err = loadCertificates("client", clientTLS); err != nil {
+		logging.Logger.Fatalf("Failed to load TLS credentials: \n%v", err)
 	}
-	fmt.Println(authMethods)
+	creds := credentials.NewTLS(serverCertificates.ServerTLSConfig())
+	logging.Logger.Debugln("Succesfully loaded TLS certificates")
 
-	// ________LOGGING________
-	// If the file doesn't exist, create it, otherwise append to the file
-	pathSlice := strings.Split(os.Args[0], "/") // This just extracts the services name (filename)
-	file, err := os.OpenFile("program logs/"+pathSlice[len(pathSlice)-1]+".log", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0666)
+	// Create a listener on the specified tcp port
+	listener, err := net.Listen("tcp", addrMyself)
 	if err != nil {
-		// If opening the log file throws an error, continue to create the loggers but print to terminal instead
-		log.Println("Unable to initialise log file, good luck :)")
-	} else {
-		log.SetOutput(file)
+		logging.Logger.Fatalf("Failed to listen on port %v: \n%v", addrMyself, err)
 	}
+	logging.Logger.Infoln("Listening on port: ", addrMyself)
 
-	DebugLogger = log.New(file, "DEBUG: ", log.Ldate|log.Ltime|log.Lmicroseconds|log.Lshortfile)
-	InfoLogger = log.New(file, "INFO: ", log.Ldate|log.Ltime|log.Lmicroseconds|log.Lshortfile)
-	WarningLogger = log.New(file, "WARNING: ", log.Ldate|log.Ltime|log.Lmicroseconds|log.Lshortfile)
-	ErrorLogger = log.New(file, "ERROR: ", log.Ldate|log.Ltime|log.Lmicroseconds|log.Lshortfile)
-}
+	// Load the authorisation policy and watch it for changes
+	policyManager, err := authentication.NewPolicyManager(policyFile)
+	if err != nil {
+		logging.Logger.Fatalf("Failed to load authorisation policy: \n%v", err)
+	}
+	policyManager.Watch(policyReloadInterval)
+	logging.Logger.Debugln("Succesfully loaded authorisation policy")
 
-func main() {
-	/* The main function sets up a server to listen on the specified port,
-	encrypts the server connection with TLS, and registers the services on
-	offer */
+	// Watch the JWT secret so that a rotated secret is picked up without restarting
+	jwtSecret.Watch(secretReloadInterval)
+	logging.Logger.Infoln("Using JWT secret ", jwtSecret)
 
-	InfoLogger.Println("Started gateway")
+	// Open the audit log, authorisation decisions are recorded in it
+	auditLog, err = authentication.NewAuditLog(auditDirectory, audience, auditRetention)
+	if err != nil {
+		logging.Logger.Fatalf("Failed to open audit log: \n%v", err)
+	}
+	defer auditLog.Close()
+	logging.Logger.Debugln("Succesfully opened audit log")
 
-	// Load in TLS credentials
-	creds, err := loadTLSCredentials()
+	// Serve the metrics for Prometheus to scrape, and push them to the pushgateway in the background if enabled
+	stopServingMetrics, err := metricExporter.Serve(addrMetrics)
 	if err != nil {
-		ErrorLogger.Printf("Error loading TLS credentials")
-	} else {
-		DebugLogger.Println("Succesfully loaded TLS certificates")
+		logging.Logger.Fatalf("Failed to serve metrics on %v: \n%v", addrMetrics, err)
+	}
+	defer stopServingMetrics()
+	if metricsPushEnabled {
+		stopPushingMetrics := metricExporter.StartPushing(addrPushgateway, metricsPushInterval, metricsMaxBackoff)
+		defer stopPushingMetrics()
 	}
 
-	// Create a listener on the specified tcp port
-	listener, err := net.Listen("tcp", addrMyself)
+	// Export the spans of the calls served and made, requests from the frontend start their traces here
+	stopTracing, err := interceptors.StartTracing(metricsJob, tracingConfig)
 	if err != nil {
-		ErrorLogger.Fatalf("Failed to listen on port %v: \n%v", addrMyself, err)
+		logging.Logger.Fatalf("Failed to start tracing: \n%v", err)
 	}
-	InfoLogger.Println("Listening on port: ", addrMyself)
+	defer stopTracing()
 
 	// Create the interceptors required for this connection
-	serverMetricInterceptor := interceptors.NewServerMetrics() // Custom metric (Prometheus) interceptor
-	authInterceptor := interceptors.ServerAuthStruct{          // Custom auth (JWT) interceptor
-		JwtManager:           authentication.NewJWTManager(secretkey, tokenduration),
-		AuthenticatedMethods: accessibleRoles,
+	authInterceptor := interceptors.ServerAuthStruct{ // Custom auth (JWT) interceptor
+		JwtManager: authentication.NewJWTManager(jwtSecret, tokenduration),
+		Policy:     policyManager,
+		APIKeys:    authenticationService,                                                                     // API keys are checked by the authentication service
+		Sessions:   authentication.NewSessionCache(sessionCacheDuration, authenticationService.VerifySession), // And so are the sessions of JWTs
+		Audience:   audience,
+		Audit:      auditLog,
 	}
-	// Create an interceptor chain with the above interceptors
+	// Create interceptor chains (for unary and streaming calls) with the above interceptors
 	interceptorChain := grpc_middleware.ChainUnaryServer(
+		interceptors.ServerRecoveryInterceptor,
+		interceptors.ServerTracingInterceptor,
+		interceptors.ServerRequestIDInterceptor,
+		interceptors.ServerLoggingInterceptor,
 		serverMetricInterceptor.ServerMetricInterceptor,
 		authInterceptor.ServerAuthInterceptor,
 	)
+	streamInterceptorChain := grpc_middleware.ChainStreamServer(
+		interceptors.ServerRecoveryStreamInterceptor,
+		interceptors.ServerTracingStreamInterceptor,
+		interceptors.ServerRequestIDStreamInterceptor,
+		interceptors.ServerLoggingStreamInterceptor,
+		serverMetricInterceptor.ServerMetricStreamInterceptor,
+		authInterceptor.ServerAuthStreamInterceptor,
+	)
 
 	// Create a gRPC server object
 	gatewayServer := grpc.NewServer(
-		grpc.Creds(creds),                       // Add the TLS credentials to this server
-		grpc.UnaryInterceptor(interceptorChain), // Add the interceptor chain to this server
+		grpc.Creds(creds),                              // Add the TLS credentials to this server
+		grpc.UnaryInterceptor(interceptorChain),        // Add the interceptor chain to this server
+		grpc.StreamInterceptor(streamInterceptorChain), // And the stream interceptor chain, so that streaming RPCs are covered too
 	)
 
 	// Attach the Login service offering to the server
 	serverPB.RegisterLoginServiceServer(gatewayServer, &loginServer{})
-	DebugLogger.Println("Succesfully registered Login Service to the server")
+	logging.Logger.Debugln("Succesfully registered Login Service to the server")
 	// Attach the power estimation service package offering to the server
 	serverPB.RegisterPowerEstimationServicesServer(gatewayServer, &estimationServer{})
-	DebugLogger.Println("Succesfully registered Power Estimation Services to the server")
+	logging.Logger.Debugln("Succesfully registered Power Estimation Services to the server")
 
-	// Start the server
-	if err := gatewayServer.Serve(listener); err != nil {
-		ErrorLogger.Fatalf("Failed to expose service: \n%v", err)
+	// Attach the health service, and check the services the gateway calls in the background
+	defer connections.Close()
+	healthChecker, err := newHealthChecker()
+	if err != nil {
+		logging.Logger.Fatalf("Failed to set up health checks: \n%v", err)
 	}
+	healthChecker.Register(gatewayServer)
+	stopHealthChecks := healthChecker.Start()
+	defer stopHealthChecks()
+	logging.Logger.Debugln("Succesfully registered the health service to the server")
+
+	// Start the server, and drain it when the gateway is stopped
+	if err := interceptors.ServeUntilStopped(gatewayServer, listener, healthChecker, drainTimeout); err != nil {
+		logging.Logger.Fatalf("Failed to expose service: \n%v", err)
+	}
+	logging.Logger.Infoln("Stopped gateway")
 }
 
 // ________REQUIRED STRUCTURES_______
 
 type Config struct {
+	/* This struct holds the gateway's configuration. Each field is a key of the configuration
+	file, see the configuration package for the default, validate, secret and reload tags */
 	Server struct {
+		Host string `yaml:"host"`
 		Port struct {
-			Myself string `yaml:"myself"`
+			Myself string `yaml:"myself" validate:"port"`
 		} `yaml:"port"`
+		TLS          authentication.TLSFiles `yaml:"tls"`
+		Certificates struct {
+			ReloadInterval int `yaml:"reloadInterval" default:"60" validate:"positive"`
+			ExpiryWarning  int `yaml:"expiryWarning" default:"30"`
+		} `yaml:"certificates"`
 		Authentication struct {
 			Jwt struct {
-				SecretKey     string `yaml:"secretKey"`
-				TokenDuration int    `yaml:"tokenDuration"`
+				SecretKey      string `yaml:"secretKey" secret:"true"`
+				TokenDuration  int    `yaml:"tokenDuration" default:"15" validate:"positive"`
+				ReloadInterval int    `yaml:"reloadInterval" default:"30" validate:"positive"`
+				Audience       string `yaml:"audience" validate:"required"`
 			} `yaml:"jwt"`
-			AccessLevel struct {
-				Name struct {
-					PowerEstimationSP string `yaml:"powerEstimationSP"`
-				} `yaml:"name"`
-				Role struct {
-					PowerEstimationSP []string `yaml:"powerEstimationSP"`
-				} `yaml:"role"`
-			} `yaml:"accessLevel"`
+			Policy struct {
+				File           string `yaml:"file" default:"authorisation/policy.yaml"`
+				ReloadInterval int    `yaml:"reloadInterval" default:"30" validate:"positive"`
+			} `yaml:"policy"`
 		} `yaml:"authentication"`
+		Audit struct {
+			Directory string `yaml:"directory" default:"audit" validate:"required"`
+			Retention int    `yaml:"retention" default:"90"`
+		} `yaml:"audit"`
+		Sessions struct {
+			CacheDuration int `yaml:"cacheDuration" default:"10" validate:"positive"`
+		} `yaml:"sessions"`
+		Metrics struct {
+			Port string `yaml:"port" validate:"port"`
+			Push struct {
+				Enabled    bool   `yaml:"enabled"`
+				Host       string `yaml:"host" default:"localhost"`
+				Port       string `yaml:"port" default:"9091"`
+				Interval   int    `yaml:"interval" default:"15"`
+				MaxBackoff int    `yaml:"maxBackoff" default:"120"`
+			} `yaml:"push"`
+		} `yaml:"metrics"`
+		Tracing interceptors.TracingConfig `yaml:"tracing"`
+		Logging logging.Config             `yaml:"logging"`
+		Health  struct {
+			Interval int `yaml:"interval" default:"10" validate:"positive"`
+			Timeout  int `yaml:"timeout" default:"3" validate:"positive"`
+		} `yaml:"health"`
+		Shutdown struct {
+			DrainTimeout int `yaml:"drainTimeout" default:"30" validate:"positive"`
+		} `yaml:"shutdown"`
+		Configuration struct {
+			ReloadInterval int `yaml:"reloadInterval" default:"30" validate:"positive"`
+		} `yaml:"configuration"`
 	} `yaml:"server"`
 
 	Client struct {
+		Host struct {
+			EstimationSP          string `yaml:"estimationSP" default:"localhost" validate:"required"`
+			AuthenticationService string `yaml:"authenticationService" default:"localhost" validate:"required"`
+		} `yaml:"host"`
 		Port struct {
-			EstimationSP          string `yaml:"estimationSP"`
-			AuthenticationService string `yaml:"authenticationService"`
+			EstimationSP          string `yaml:"estimationSP" validate:"port"`
+			AuthenticationService string `yaml:"authenticationService" validate:"port"`
 		} `yaml:"port"`
+		TLS      authentication.TLSFiles `yaml:"tls"`
+		Audience struct {
+			EstimationSP string `yaml:"estimationSP" validate:"required"`
+		} `yaml:"audience"`
 		Timeout struct {
-			Connection int `yaml:"connection"`
-			Call       int `yaml:"call"`
-		} `yaml:"timeout"`
-		AuthenticatedMethods struct {
-			Name struct {
-				PowerEstimationSP string `yaml:"powerEstimationSP"`
-			} `yaml:"name"`
-			RequiresAuthentication struct {
-				PowerEstimaitonSP bool `yaml:"powerEstimationSP"`
-			} `yaml:"requiresAuthentication"`
-		} `yaml:"authenticatedMethods"`
+			Connection int `yaml:"connection" default:"5" validate:"positive"`
+			Call       int `yaml:"call" default:"15" validate:"positive"`
+		} `yaml:"timeout" reload:"true"`
+		Retry interceptors.RetryConfig `yaml:"retry" reload:"true"`
 	} `yaml:"client"`
 }
 
@@ -230,61 +444,383 @@ func (s *loginServer) Login(ctx context.Context, request *serverPB.LoginRequest)
 	service to log in the user and provide them with a JWT. It
 	then returns a list of available services to the user/frontend.*/
 
-	InfoLogger.Println("Received Login service call")
-
-	// Create the interceptors required for this connection
-	clientMetricInterceptor := interceptors.NewClientMetrics() // Custom metric (Prometheus) interceptor
-	// Create the retry options to specify how the client should retry connection interrupts
-	retryOptions := []grpc_retry.CallOption{
-		grpc_retry.WithBackoff(grpc_retry.BackoffExponential(100 * time.Millisecond)), // Use exponential backoff to progressively wait longer between retries
-		grpc_retry.WithMax(5), // Set the maximum number of retries
-	}
-
-	interceptorChain := grpc_middleware.ChainUnaryClient(
-		clientMetricInterceptor.ClientMetricInterceptor,
-		grpc_retry.UnaryClientInterceptor(retryOptions...),
-	)
+	logging.FromContext(ctx).Infoln("Received Login service call")
 
-	// Create an insecure connection to the server
-	connAuthenticationService, err := createInsecureServerConnection(
-		addrAuthenticationService, // Set the address of the server
-		timeoutDuration,           // Set the duration the client will wait before timing out
-		interceptorChain,          // Add the interceptor chain to this server
-	)
+	/* Get the client of the connection to the authentication service, which is shared by
+	every request. The login carries the user's password, so the connection is always secured with TLS */
+	clientAuthenticationPB, err := authenticationService.Client()
 	if err != nil {
 		return nil, err
 	}
 
-	/* Create the client and pass the connection made above to it. After the client
-	has been created, we create the gRPC requests */
-	InfoLogger.Println("Creating clients")
-	clientAuthenticationPB := authenticationPB.NewAuthenticationServiceClient(connAuthenticationService)
-	DebugLogger.Println("Succesfully created the client")
-
 	// Create the request message for the authentication service
 	requestMessageAuthenticationService := authenticationPB.LoginAuthRequest{
 		Username: request.Username,
 		Password: request.Password,
 	}
 
-	// Make the service call to the server
-	InfoLogger.Println("Making Login service call")
-	loginContext, _ := context.WithTimeout(context.Background(), callTimeoutDuration)
+	// Make the service call to the server, forwarding the client's address for per-address login throttling
+	logging.FromContext(ctx).Infoln("Making Login service call")
+	loginContext, cancel := authenticationService.CallContext(ctx)
+	defer cancel()
 	// Invoke the login service
 	responseLogin, err := clientAuthenticationPB.LoginAuth(loginContext, &requestMessageAuthenticationService)
-	// Handle errors, if any, otherwise, close the connection to the auth service
+	// Handle errors, if any
 	if err != nil {
-		ErrorLogger.Println("Failed to make the login service call: ", err)
+		logging.FromContext(ctx).Errorln("Failed to make the login service call: ", err)
 		return nil, err
-	} else {
-		DebugLogger.Println("Succesfully made service call to authentication service.")
-		connAuthenticationService.Close()
 	}
+	logging.FromContext(ctx).Debugln("Succesfully made service call to authentication service.")
 
 	// Create and populate the response message for the request being served
 	responseMessage := serverPB.LoginResponse{
-		AccessToken: responseLogin.AccessToken,
-		Permissions: responseLogin.Permissions,
+		AccessToken:  responseLogin.AccessToken,
+		Permissions:  responseLogin.Permissions,
+		Roles:        responseLogin.Roles,
+		Scopes:       responseLogin.Scopes,
+		MfaRequired:  responseLogin.MfaRequired,
+		PartialToken: responseLogin.PartialToken,
+		Enrolment:    gatewayEnrolment(responseLogin.Enrolment),
+	}
+
+	return &responseMessage, nil
+}
+
+func (s *loginServer) VerifyTOTP(ctx context.Context, request *serverPB.VerifyTOTPRequest) (*serverPB.VerifyTOTPResponse, error) {
+	/* This service routes the second step of a login (the partial token returned by Login
+	and the user's TOTP code) to the authentication service, which returns the user's JWT */
+
+	logging.FromContext(ctx).Infoln("Received VerifyTOTP service call")
+
+	clientAuthenticationPB, err := authenticationService.Client()
+	if err != nil {
+		return nil, err
+	}
+
+	// Make the service call to the server, forwarding the client's address for per-address login throttling
+	logging.FromContext(ctx).Infoln("Making VerifyTOTP service call")
+	verifyContext, cancel := authenticationService.ForwardingContext(ctx)
+	defer cancel()
+	responseVerify, err := clientAuthenticationPB.VerifyTOTP(verifyContext, &authenticationPB.VerifyTOTPRequest{
+		PartialToken: request.PartialToken,
+		Code:         request.Code,
+	})
+	if err != nil {
+		logging.FromContext(ctx).Errorln("Failed to make the verify TOTP service call: ", err)
+		return nil, err
+	}
+	logging.FromContext(ctx).Debugln("Succesfully made service call to authentication service.")
+
+	return &serverPB.VerifyTOTPResponse{
+		AccessToken:   responseVerify.AccessToken,
+		Permissions:   responseVerify.Permissions,
+		Roles:         responseVerify.Roles,
+		Scopes:        responseVerify.Scopes,
+		RecoveryCodes: responseVerify.RecoveryCodes,
+	}, nil
+}
+
+func (s *loginServer) EnrolTOTP(ctx context.Context, request *serverPB.EnrolTOTPRequest) (*serverPB.EnrolTOTPResponse, error) {
+	// This service routes a request to enrol an authenticator app to the authentication service, along with the user's JWT
+
+	logging.FromContext(ctx).Infoln("Received EnrolTOTP service call")
+
+	clientAuthenticationPB, err := authenticationService.Client()
+	if err != nil {
+		return nil, err
+	}
+
+	// Make the service call to the server
+	logging.FromContext(ctx).Infoln("Making EnrolTOTP service call")
+	enrolContext, cancel := authenticationService.ForwardingContext(ctx)
+	defer cancel()
+	responseEnrol, err := clientAuthenticationPB.EnrolTOTP(enrolContext, &authenticationPB.EnrolTOTPRequest{})
+	if err != nil {
+		logging.FromContext(ctx).Errorln("Failed to make the enrol TOTP service call: ", err)
+		return nil, err
+	}
+	logging.FromContext(ctx).Debugln("Succesfully made service call to authentication service.")
+
+	return &serverPB.EnrolTOTPResponse{Enrolment: gatewayEnrolment(responseEnrol.Enrolment)}, nil
+}
+
+func (s *loginServer) ConfirmTOTP(ctx context.Context, request *serverPB.ConfirmTOTPRequest) (*serverPB.ConfirmTOTPResponse, error) {
+	// This service routes the code confirming an enrolment to the authentication service, along with the user's JWT
+
+	logging.FromContext(ctx).Infoln("Received ConfirmTOTP service call")
+
+	clientAuthenticationPB, err := authenticationService.Client()
+	if err != nil {
+		return nil, err
+	}
+
+	// Make the service call to the server
+	logging.FromContext(ctx).Infoln("Making ConfirmTOTP service call")
+	confirmContext, cancel := authenticationService.ForwardingContext(ctx)
+	defer cancel()
+	responseConfirm, err := clientAuthenticationPB.ConfirmTOTP(confirmContext, &authenticationPB.ConfirmTOTPRequest{
+		Code: request.Code,
+	})
+	if err != nil {
+		logging.FromContext(ctx).Errorln("Failed to make the confirm TOTP service call: ", err)
+		return nil, err
+	}
+	logging.FromContext(ctx).Debugln("Succesfully made service call to authentication service.")
+
+	return &serverPB.ConfirmTOTPResponse{RecoveryCodes: responseConfirm.RecoveryCodes}, nil
+}
+
+func (s *loginServer) UnlockAccount(ctx context.Context, request *serverPB.UnlockAccountRequest) (*serverPB.UnlockAccountResponse, error) {
+	/* This service routes an account unlock request to the authentication service,
+	along with the administrator's JWT */
+
+	logging.FromContext(ctx).Infoln("Received UnlockAccount service call")
+
+	clientAuthenticationPB, err := authenticationService.Client()
+	if err != nil {
+		return nil, err
+	}
+
+	// Make the service call to the server
+	logging.FromContext(ctx).Infoln("Making UnlockAccount service call")
+	unlockContext, cancel := authenticationService.ForwardingContext(ctx)
+	defer cancel()
+	responseUnlock, err := clientAuthenticationPB.UnlockAccount(unlockContext, &authenticationPB.UnlockAccountRequest{
+		Username: request.Username,
+	})
+	if err != nil {
+		logging.FromContext(ctx).Errorln("Failed to make the unlock account service call: ", err)
+		return nil, err
+	}
+	logging.FromContext(ctx).Debugln("Succesfully made service call to authentication service.")
+
+	return &serverPB.UnlockAccountResponse{Username: responseUnlock.Username}, nil
+}
+
+func (s *loginServer) ResetTOTP(ctx context.Context, request *serverPB.ResetTOTPRequest) (*serverPB.ResetTOTPResponse, error) {
+	/* This service routes a request to remove a user's TOTP enrolment to the authentication
+	service, along with the administrator's JWT */
+
+	logging.FromContext(ctx).Infoln("Received ResetTOTP service call")
+
+	clientAuthenticationPB, err := authenticationService.Client()
+	if err != nil {
+		return nil, err
+	}
+
+	// Make the service call to the server
+	logging.FromContext(ctx).Infoln("Making ResetTOTP service call")
+	resetContext, cancel := authenticationService.ForwardingContext(ctx)
+	defer cancel()
+	responseReset, err := clientAuthenticationPB.ResetTOTP(resetContext, &authenticationPB.ResetTOTPRequest{
+		Username: request.Username,
+	})
+	if err != nil {
+		logging.FromContext(ctx).Errorln("Failed to make the reset TOTP service call: ", err)
+		return nil, err
+	}
+	logging.FromContext(ctx).Debugln("Succesfully made service call to authentication service.")
+
+	return &serverPB.ResetTOTPResponse{Username: responseReset.Username}, nil
+}
+
+func (s *loginServer) CreateAPIKey(ctx context.Context, request *serverPB.CreateAPIKeyRequest) (*serverPB.CreateAPIKeyResponse, error) {
+	// This service routes a request for a new API key to the authentication service, along with the administrator's credentials
+
+	logging.FromContext(ctx).Infoln("Received CreateAPIKey service call")
+
+	clientAuthenticationPB, err := authenticationService.Client()
+	if err != nil {
+		return nil, err
+	}
+
+	// Make the service call to the server
+	logging.FromContext(ctx).Infoln("Making CreateAPIKey service call")
+	createContext, cancel := authenticationService.ForwardingContext(ctx)
+	defer cancel()
+	responseCreate, err := clientAuthenticationPB.CreateAPIKey(createContext, &authenticationPB.CreateAPIKeyRequest{
+		Name:     request.Name,
+		Roles:    request.Roles,
+		Lifetime: request.Lifetime,
+	})
+	if err != nil {
+		logging.FromContext(ctx).Errorln("Failed to make the create API key service call: ", err)
+		return nil, err
+	}
+	logging.FromContext(ctx).Debugln("Succesfully made service call to authentication service.")
+
+	return &serverPB.CreateAPIKeyResponse{Key: gatewayAPIKey(responseCreate.Key), Secret: responseCreate.Secret}, nil
+}
+
+func (s *loginServer) ListAPIKeys(ctx context.Context, request *serverPB.ListAPIKeysRequest) (*serverPB.ListAPIKeysResponse, error) {
+	// This service routes a request to list the API keys to the authentication service, along with the administrator's credentials
+
+	logging.FromContext(ctx).Infoln("Received ListAPIKeys service call")
+
+	clientAuthenticationPB, err := authenticationService.Client()
+	if err != nil {
+		return nil, err
+	}
+
+	// Make the service call to the server
+	logging.FromContext(ctx).Infoln("Making ListAPIKeys service call")
+	listContext, cancel := authenticationService.ForwardingContext(ctx)
+	defer cancel()
+	responseList, err := clientAuthenticationPB.ListAPIKeys(listContext, &authenticationPB.ListAPIKeysRequest{})
+	if err != nil {
+		logging.FromContext(ctx).Errorln("Failed to make the list API keys service call: ", err)
+		return nil, err
+	}
+	logging.FromContext(ctx).Debugln("Succesfully made service call to authentication service.")
+
+	responseMessage := serverPB.ListAPIKeysResponse{}
+	for _, key := range responseList.Keys {
+		responseMessage.Keys = append(responseMessage.Keys, gatewayAPIKey(key))
+	}
+
+	return &responseMessage, nil
+}
+
+func (s *loginServer) RevokeAPIKey(ctx context.Context, request *serverPB.RevokeAPIKeyRequest) (*serverPB.RevokeAPIKeyResponse, error) {
+	// This service routes a request to revoke an API key to the authentication service, along with the administrator's credentials
+
+	logging.FromContext(ctx).Infoln("Received RevokeAPIKey service call")
+
+	clientAuthenticationPB, err := authenticationService.Client()
+	if err != nil {
+		return nil, err
+	}
+
+	// Make the service call to the server
+	logging.FromContext(ctx).Infoln("Making RevokeAPIKey service call")
+	revokeContext, cancel := authenticationService.ForwardingContext(ctx)
+	defer cancel()
+	responseRevoke, err := clientAuthenticationPB.RevokeAPIKey(revokeContext, &authenticationPB.RevokeAPIKeyRequest{
+		Id: request.Id,
+	})
+	if err != nil {
+		logging.FromContext(ctx).Errorln("Failed to make the revoke API key service call: ", err)
+		return nil, err
+	}
+	logging.FromContext(ctx).Debugln("Succesfully made service call to authentication service.")
+
+	return &serverPB.RevokeAPIKeyResponse{Key: gatewayAPIKey(responseRevoke.Key)}, nil
+}
+
+func (s *loginServer) QueryAuditLog(ctx context.Context, request *serverPB.QueryAuditLogRequest) (*serverPB.QueryAuditLogResponse, error) {
+	// This service routes a search of the audit log to the authentication service, along with the administrator's credentials
+
+	logging.FromContext(ctx).Infoln("Received QueryAuditLog service call")
+
+	clientAuthenticationPB, err := authenticationService.Client()
+	if err != nil {
+		return nil, err
+	}
+
+	// Make the service call to the server
+	logging.FromContext(ctx).Infoln("Making QueryAuditLog service call")
+	queryContext, cancel := authenticationService.ForwardingContext(ctx)
+	defer cancel()
+	responseQuery, err := clientAuthenticationPB.QueryAuditLog(queryContext, &authenticationPB.QueryAuditLogRequest{
+		Since:    request.Since,
+		Until:    request.Until,
+		Service:  request.Service,
+		Event:    request.Event,
+		User:     request.User,
+		Method:   request.Method,
+		Decision: request.Decision,
+		Limit:    request.Limit,
+	})
+	if err != nil {
+		logging.FromContext(ctx).Errorln("Failed to make the query audit log service call: ", err)
+		return nil, err
+	}
+	logging.FromContext(ctx).Debugln("Succesfully made service call to authentication service.")
+
+	responseMessage := serverPB.QueryAuditLogResponse{}
+	for _, event := range responseQuery.Events {
+		responseMessage.Events = append(responseMessage.Events, &serverPB.AuditEvent{
+			Time:      event.Time,
+			Service:   event.Service,
+			Event:     event.Event,
+			User:      event.User,
+			Kind:      event.Kind,
+			Roles:     event.Roles,
+			Actor:     event.Actor,
+			Target:    event.Target,
+			Method:    event.Method,
+			Peer:      event.Peer,
+			Decision:  event.Decision,
+			Reason:    event.Reason,
+			RequestId: event.RequestId,
+		})
+	}
+
+	return &responseMessage, nil
+}
+
+func (s *loginServer) ListSessions(ctx context.Context, request *serverPB.ListSessionsRequest) (*serverPB.ListSessionsResponse, error) {
+	/* This service routes a request to list a user's active sessions to the authentication
+	service, along with the caller's credentials. Users can list their own sessions, and
+	administrators anyone's */
+
+	logging.FromContext(ctx).Infoln("Received ListSessions service call")
+
+	clientAuthenticationPB, err := authenticationService.Client()
+	if err != nil {
+		return nil, err
+	}
+
+	// Make the service call to the server
+	logging.FromContext(ctx).Infoln("Making ListSessions service call")
+	listContext, cancel := authenticationService.ForwardingContext(ctx)
+	defer cancel()
+	responseList, err := clientAuthenticationPB.ListSessions(listContext, &authenticationPB.ListSessionsRequest{
+		Username: request.Username,
+	})
+	if err != nil {
+		logging.FromContext(ctx).Errorln("Failed to make the list sessions service call: ", err)
+		return nil, err
+	}
+	logging.FromContext(ctx).Debugln("Succesfully made service call to authentication service.")
+
+	responseMessage := serverPB.ListSessionsResponse{}
+	for _, session := range responseList.Sessions {
+		responseMessage.Sessions = append(responseMessage.Sessions, gatewaySession(session))
+	}
+
+	return &responseMessage, nil
+}
+
+func (s *loginServer) TerminateSession(ctx context.Context, request *serverPB.TerminateSessionRequest) (*serverPB.TerminateSessionResponse, error) {
+	/* This service routes a request to terminate one (or every) session of a user to the
+	authentication service, along with the caller's credentials, for example to log out a
+	shared workstation that was left logged in */
+
+	logging.FromContext(ctx).Infoln("Received TerminateSession service call")
+
+	clientAuthenticationPB, err := authenticationService.Client()
+	if err != nil {
+		return nil, err
+	}
+
+	// Make the service call to the server
+	logging.FromContext(ctx).Infoln("Making TerminateSession service call")
+	terminateContext, cancel := authenticationService.ForwardingContext(ctx)
+	defer cancel()
+	responseTerminate, err := clientAuthenticationPB.TerminateSession(terminateContext, &authenticationPB.TerminateSessionRequest{
+		SessionId: request.SessionId,
+		Username:  request.Username,
+	})
+	if err != nil {
+		logging.FromContext(ctx).Errorln("Failed to make the terminate session service call: ", err)
+		return nil, err
+	}
+	logging.FromContext(ctx).Debugln("Succesfully made service call to authentication service.")
+
+	responseMessage := serverPB.TerminateSessionResponse{}
+	for _, session := range responseTerminate.Sessions {
+		responseMessage.Sessions = append(responseMessage.Sessions, gatewaySession(session))
 	}
 
 	return &responseMessage, nil
@@ -304,56 +840,60 @@ func (s *estimationServer) CostEstimationSP(ctx context.Context, request *server
 func (s *estimationServer) PowerEstimationSP(ctx context.Context, request *serverPB.EstimationRequest) (*serverPB.PowerEstimationResponse, error) {
 	/* This service routes a power estimation request to the power-train estimation aggregator. This request generates an estimation of the power required for a provided route. */
 
-	InfoLogger.Println("Received Power Estimator service call")
+	logging.FromContext(ctx).Infoln("Received Power Estimator service call")
 
 	// Load in credentials for the servers
-	creds, err := loadTLSCredentials()
+	creds := loadClientTLSCredentials()
+
+	/* Exchange the user's credentials for a short-lived token that only the aggregator
+	accepts, rather than passing the user's own token (or API key) on */
+	accessToken, err := authenticationService.ExchangeToken(ctx, audienceEstimationSP)
 	if err != nil {
-		ErrorLogger.Printf("Error loading TLS credentials")
 		return nil, err
-	} else {
-		DebugLogger.Println("Succesfully loaded TLS certificates")
 	}
 
-	// Extract the user's JWT from the incoming request. Can ignore the ok output as ths has already been checked.
-	md, _ := metadata.FromIncomingContext(ctx)
-
 	// Create the interceptors required for this connection
-	clientMetricInterceptor := interceptors.NewClientMetrics() // Custom metric (Prometheus) interceptor
-	authInterceptor := interceptors.ClientAuthStruct{          // Custom auth (JWT) interceptor
-		AccessToken:          md["authorisation"][0], // Pass the user's JWT to the outgoing request
-		AuthenticatedMethods: authMethods,
-	}
-
-	// Create the retry options to specify how the client should retry connection interrupts
-	retryOptions := []grpc_retry.CallOption{
-		grpc_retry.WithBackoff(grpc_retry.BackoffExponential(100 * time.Millisecond)), // Use exponential backoff to progressively wait longer between retries
-		grpc_retry.WithMax(5), // Set the maximum number of retries
+	authInterceptor := interceptors.ClientAuthStruct{ // Custom auth (JWT) interceptor
+		AccessToken: accessToken,
 	}
 
 	// Create an interceptor chain with the above interceptors
 	interceptorChain := grpc_middleware.ChainUnaryClient(
+		interceptors.ClientRecoveryInterceptor,
+		interceptors.ClientTracingInterceptor,
+		interceptors.ClientRequestIDInterceptor,
+		interceptors.ClientLoggingInterceptor,
 		clientMetricInterceptor.ClientMetricInterceptor,
 		authInterceptor.ClientAuthInterceptor,
-		grpc_retry.UnaryClientInterceptor(retryOptions...),
+		clientRetryInterceptor.ClientRetryInterceptor, // Last, so that the interceptors above see one call however many attempts it takes
+	)
+	streamInterceptorChain := grpc_middleware.ChainStreamClient(
+		interceptors.ClientRecoveryStreamInterceptor,
+		interceptors.ClientTracingStreamInterceptor,
+		interceptors.ClientRequestIDStreamInterceptor,
+		interceptors.ClientLoggingStreamInterceptor,
+		clientMetricInterceptor.ClientMetricStreamInterceptor,
+		authInterceptor.ClientAuthStreamInterceptor,
 	)
 
 	// Create an secure connection to the server
 	connEstimationSP, err := createSecureServerConnection(
-		addrEstimationSP, // Set the address of the server
-		creds,            // Add the TLS credentials
-		timeoutDuration,  // Set the duration the client will wait before timing out
-		interceptorChain, // Add the interceptor chain to this server
+		addrEstimationSP,       // Set the address of the server
+		creds,                  // Add the TLS credentials
+		timeoutDuration.Get(),  // Set the duration the client will wait before timing out
+		interceptorChain,       // Add the interceptor chain to this server
+		streamInterceptorChain, // And the stream interceptor chain
 	)
 	if err != nil {
 		return nil, err
 	}
+	defer connEstimationSP.Close()
 
 	/* Create the client and pass the connection made above to it. After the client
 	has been created, we create the gRPC requests */
-	InfoLogger.Println("Creating clients")
+	logging.FromContext(ctx).Infoln("Creating clients")
 	clientEstimationSP := estimationPB.NewPowerEstimationServicePackageClient(connEstimationSP)
-	DebugLogger.Println("Succesfully created the client")
+	logging.FromContext(ctx).Debugln("Succesfully created the client")
 
 	// Create the request message for the power-train estimation aggregator
 	requestMessageEstimationSP := estimationPB.ServicePackageRequestMessage{
@@ -362,18 +902,17 @@ func (s *estimationServer) PowerEstimationSP(ctx context.Context, request *serve
 	}
 
 	// Make the service call to the server
-	InfoLogger.Println("Making PowerEstimationSP service call")
-	estimationContext, _ := context.WithTimeout(context.Background(), callTimeoutDuration)
+	logging.FromContext(ctx).Infoln("Making PowerEstimationSP service call")
+	estimationContext, cancel := context.WithTimeout(interceptors.CarrySpan(ctx, context.Background()), callTimeoutDuration.Get())
+	defer cancel()
 	// Invoke the power estimation service package
 	responseEstimationSP, err := clientEstimationSP.PowerEstimatorService(estimationContext, &requestMessageEstimationSP)
-	// Handle errors, if any, otherwise, close the connection
+	// Handle errors, if any
 	if err != nil {
-		ErrorLogger.Println("Failed to make the power estimation SP service call: ")
+		logging.FromContext(ctx).Errorln("Failed to make the power estimation SP service call: ")
 		return nil, err
-	} else {
-		DebugLogger.Println("Succesfully made service call to estimation SP.")
-		connEstimationSP.Close()
 	}
+	logging.FromContext(ctx).Debugln("Succesfully made service call to estimation SP.")
 
 	// Create and populate the response message for the request being served
 	responseMessage := serverPB.PowerEstimationResponse{
@@ -385,72 +924,188 @@ func (s *estimationServer) PowerEstimationSP(ctx context.Context, request *serve
 
 // ________SUPPORTING FUNCTIONS________
 
-func DecodeConfig(configPath string) (*Config, error) {
-	// Create a new config structure
-	config := &Config{}
+func validateConfig(config *Config, check *authentication.ConfigCheck) {
+	/* This function checks every setting the service needs before it starts, recording the problems found in the provided check.
+	The settings with a validate tag (see the Config struct) are checked by the configuration package */
+	configuration.Validate(config, check)
+	check.CheckServerTLS("server.tls", config.Server.TLS)
+	check.RequireFile("server.authentication.policy.file", config.Server.Authentication.Policy.File, "")
+	if config.Server.Metrics.Push.Enabled {
+		check.RequireValue("server.metrics.push.host", config.Server.Metrics.Push.Host)
+		check.RequirePort("server.metrics.push.port", config.Server.Metrics.Push.Port)
+		check.RequirePositive("server.metrics.push.interval", config.Server.Metrics.Push.Interval)
+		check.RequirePositive("server.metrics.push.maxBackoff", config.Server.Metrics.Push.MaxBackoff)
+	}
+	config.Server.Tracing.Check("server.tracing", check)
+	check.CheckLogging("server.logging", config.Server.Logging)
+	check.CheckClientTLS("client.tls", config.Client.TLS)
+	config.Client.Retry.Check("client.retry", check)
+}
+
+func checkReloadedConfig(loaded interface{}) error {
+	// This (unexported) function checks a reloaded configuration in the same way as the one the gateway started with
+	check := authentication.NewConfigCheck("desktop gateway", configLoader.Path)
+	validateConfig(loaded.(*Config), check)
+	if check.Failed() {
+		return errors.New(check.Report())
+	}
+
+	return nil
+}
+
+func applyConfig(loaded interface{}) {
+	/* This (unexported) function applies the settings of a reloaded configuration that can be
+	changed while the gateway is running (those tagged reload:"true" in the Config struct),
+	the log level, the client timeouts and the retry policies. Calls already made keep their
+	timeouts */
+	config := loaded.(*Config)
+	if err := logging.SetLevel(config.Server.Logging.Level); err != nil {
+		logging.Logger.Warnln("Could not change the log level: ", err)
+	}
+	timeoutDuration.Set(time.Duration(config.Client.Timeout.Connection) * time.Second)
+	callTimeoutDuration.Set(time.Duration(config.Client.Timeout.Call) * time.Second)
+	clientRetryInterceptor.Update(config.Client.Retry)
+}
 
-	// Open the config file
-	file, err := os.Open(configPath)
+func loadCertificates(name string, files authentication.TLSFiles) (*authentication.CertificateManager, error) {
+	/* This (unexported) function loads the certificate, key and CA described by the provided
+	files, and watches them so that rotated certificates are picked up without restarting
+	the gateway. It returns a certificate manager for the files. */
+	manager, err := authentication.NewCertificateManager(name, files)
 	if err != nil {
-		fmt.Println("Could not open config file")
 		return nil, err
 	}
-	defer file.Close()
 
-	// Initialise a new YAML decoder
-	decoder := yaml.NewDecoder(file)
+	manager.ExpiryWarning = certificateExpiryWarning
+	manager.Watch(certificateReloadInterval)
 
-	// Start YAML decoding from file
-	if err := decoder.Decode(&config); err != nil {
-		fmt.Println("Could not decode config file: \n", err)
-		return nil, err
+	return manager, nil
+}
+
+func newHealthChecker() (*interceptors.HealthChecker, error) {
+	/* This (unexported) function creates the gateway's health checker. The gateway is ready
+	while the aggregator and the authentication service are alive, its certificates are valid
+	and its configuration (the policy and JWT secret) can still be loaded. The aggregator's
+	liveness is checked rather than its readiness, so that an outage of one of the Python
+	services doesn't take the gateway (and logging in) out of rotation as well. Each service
+	is checked over a connection of its own, without the interceptors of the calls the gateway
+	makes, so that health checks aren't traced, counted or authenticated as calls */
+	checker := interceptors.NewHealthChecker(healthInterval, healthTimeout)
+
+	dependencies := map[string]string{
+		"powerEstimationSP":     addrEstimationSP,
+		"authenticationService": addrAuthenticationService,
+	}
+	for name, address := range dependencies {
+		conn, err := grpc.Dial(address, grpc.WithTransportCredentials(loadClientTLSCredentials()))
+		if err != nil {
+			return nil, fmt.Errorf("could not create the health check connection to %v: %v", name, err)
+		}
+		connections.Keep(conn)
+		checker.AddCheck(name, interceptors.ServingCheck(conn, interceptors.LivenessService))
 	}
 
-	return config, nil
+	checker.AddCheck("certificates", func(ctx context.Context) error {
+		if err := serverCertificates.Valid(time.Now()); err != nil {
+			return err
+		}
+		return clientCertificates.Valid(time.Now())
+	})
+	checker.AddCheck("configuration", func(ctx context.Context) error {
+		if _, err := authentication.LoadPolicy(policyFile); err != nil {
+			return fmt.Errorf("the authorisation policy can't be loaded, the last valid one is in use: %v", err)
+		}
+		if jwtSecret.Value() == "" {
+			return fmt.Errorf("the JWT secret %v is empty", jwtSecret)
+		}
+		return nil
+	})
+
+	return checker, nil
 }
 
-func loadTLSCredentials() (credentials.TransportCredentials, error) {
-	/* This function loads TLS credentials for both the client and server,
-	enabling mutual TLS authentication between the client and server. It takes no inputs and returns a gRPC TransportCredentials object. */
+func loadClientTLSCredentials() credentials.TransportCredentials {
+	/* This (unexported) function returns the TLS credentials the gateway uses when calling
+	other services. The services' certificates are verified against the CA and the gateway
+	presents its (currently active) client certificate so that they can identify it. It
+	takes no inputs and returns a gRPC TransportCredentials object. */
+	return credentials.NewTLS(clientCertificates.ClientTLSConfig())
+}
 
-	// Load certificate of the CA who signed server's certificate
-	pemServerCA, err := ioutil.ReadFile("certification/ca-cert.pem")
-	if err != nil {
-		return nil, err
+func gatewayAPIKey(key *authenticationPB.APIKey) *serverPB.APIKey {
+	// This function converts an API key returned by the authentication service into the gateway's proto message
+	if key == nil {
+		return nil
 	}
 
-	// Load the server CA's certificates
-	certificatePool := x509.NewCertPool()
-	if !certificatePool.AppendCertsFromPEM(pemServerCA) {
-		return nil, fmt.Errorf("failed to add the server CA's certificate")
+	return &serverPB.APIKey{
+		Id:         key.Id,
+		Name:       key.Name,
+		Roles:      key.Roles,
+		CreatedBy:  key.CreatedBy,
+		CreatedAt:  key.CreatedAt,
+		ExpiresAt:  key.ExpiresAt,
+		RevokedAt:  key.RevokedAt,
+		LastUsed:   key.LastUsed,
+		UsageCount: key.UsageCount,
 	}
+}
 
-	// Load the client's certificate and private key
-	clientCertificate, err := tls.LoadX509KeyPair("certification/client-cert.pem", "certification/client-key.pem")
-	if err != nil {
-		return nil, err
+func gatewaySession(session *authenticationPB.Session) *serverPB.Session {
+	// This function converts a session returned by the authentication service into the gateway's proto message
+	return &serverPB.Session{
+		Id:           session.Id,
+		Username:     session.Username,
+		Client:       session.Client,
+		Via:          session.Via,
+		IssuedAt:     session.IssuedAt,
+		ExpiresAt:    session.ExpiresAt,
+		LastUsed:     session.LastUsed,
+		TerminatedAt: session.TerminatedAt,
+		TerminatedBy: session.TerminatedBy,
+		Current:      session.Current,
+	}
+}
+
+func gatewayEnrolment(enrolment *authenticationPB.TOTPEnrolment) *serverPB.TOTPEnrolment {
+	// This function converts a TOTP enrolment returned by the authentication service into the gateway's proto message
+	if enrolment == nil {
+		return nil
+	}
+
+	return &serverPB.TOTPEnrolment{
+		Secret:          enrolment.Secret,
+		ProvisioningUri: enrolment.ProvisioningUri,
+	}
+}
+
+func forwardClientAddress(incoming context.Context, outgoing context.Context) context.Context {
+	/* This function adds the address of the client that made the incoming request to the
+	outgoing request's metadata, so that the authentication service can throttle logins and
+	audit requests per client rather than per gateway. Any address the client forwarded
+	itself is overwritten. The outgoing request also becomes part of the incoming request's trace */
+	outgoing = interceptors.CarrySpan(incoming, outgoing)
+	p, ok := peer.FromContext(incoming)
+	if !ok {
+		return outgoing
 	}
 
-	// Create and return the credentials object
-	config := &tls.Config{
-		Certificates: []tls.Certificate{clientCertificate},
-		RootCAs:      certificatePool,
+	address := p.Addr.String()
+	if host, _, err := net.SplitHostPort(address); err == nil {
+		address = host
 	}
 
-	return credentials.NewTLS(config), nil
+	return metadata.AppendToOutgoingContext(outgoing, "x-forwarded-for", address)
 }
 
-func createSecureServerConnection(port string, credentials credentials.TransportCredentials, timeout int, interceptor grpc.UnaryClientInterceptor) (*grpc.ClientConn, error) {
+func createSecureServerConnection(port string, credentials credentials.TransportCredentials, timeout time.Duration, interceptor grpc.UnaryClientInterceptor, streamInterceptor grpc.StreamClientInterceptor) (*grpc.ClientConn, error) {
 	/* This (unexported) function takes a port address, gRPC TransportCredentials object, timeout,
-	and UnaryClientInterceptor object as inputs. It creates a connection to the server
-	at the port adress and returns a secure gRPC connection with the specified
-	interceptor */
+	and UnaryClientInterceptor and StreamClientInterceptor objects as inputs. It creates a connection
+	to the server at the port adress and returns a secure gRPC connection with the specified
+	interceptors */
 
 	// Create the context for the request
-	ctx, cancel := context.WithTimeout(
-		context.Background(),
-		(time.Duration(timeoutDuration) * time.Second),
-	)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
 	conn, err := grpc.DialContext(
@@ -459,44 +1114,15 @@ func createSecureServerConnection(port string, credentials credentials.Transport
 		grpc.WithBlock(), // Make the dial a blocking call so that we can ensure the connection is indeed created
 		grpc.WithTransportCredentials(credentials), // Add the TLS credentials
 		grpc.WithUnaryInterceptor(interceptor),     // Add the provided interceptors to the connection
+		grpc.WithStreamInterceptor(streamInterceptor),
 	)
 
 	// Handle errors, if any
 	if err != nil {
-		ErrorLogger.Println("Failed to create connection to the server on port: " + port)
-		return nil, err
-	}
-
-	InfoLogger.Println("Succesfully created connection to the server on port: " + port)
-	return conn, nil
-}
-
-func createInsecureServerConnection(port string, timeout int, interceptor grpc.UnaryClientInterceptor) (*grpc.ClientConn, error) {
-	/* This (unexported) function takes a port address, timeout, and UnaryClientInterceptor
-	object as inputs. It creates a connection to the server	at the port adress
-	and returns an insecure gRPC connection with the specified interceptor */
-
-	// Create the context for the request
-	ctx, cancel := context.WithTimeout(
-		context.Background(),
-		(time.Duration(timeoutDuration) * time.Second),
-	)
-	defer cancel()
-
-	conn, err := grpc.DialContext(
-		ctx,                                    // Add the created context to the connection
-		port,                                   // Add the port that the server is listening on
-		grpc.WithBlock(),                       // Make the dial a blocking call so that we can ensure the connection is indeed created
-		grpc.WithInsecure(),                    // Specify that the connection is insecure (no credentials/authorisation required)
-		grpc.WithUnaryInterceptor(interceptor), // Add the provided interceptors to the connection
-	)
-
-	// Hamndle errors, if any
-	if err != nil {
-		ErrorLogger.Println("Failed to create connection to the server on port: " + port)
+		logging.Logger.Errorln("Failed to create connection to the server on port: " + port)
 		return nil, err
 	}
 
-	InfoLogger.Println("Succesfully created connection to the server on port: " + port)
+	logging.Logger.Infoln("Succesfully created connection to the server on port: " + port)
 	return conn, nil
 }
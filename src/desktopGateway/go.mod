@@ -3,12 +3,23 @@ module github.com/nicholasbunn/mastersSandbox/src/desktopGateway
 go 1.13
 
 require (
-	github.com/go-yaml/yaml v2.1.0+incompatible
 	github.com/golang/protobuf v1.5.2
 	github.com/grpc-ecosystem/go-grpc-middleware v1.3.0
 	github.com/nicholasbunn/mastersSandbox/src/authenticationService v0.0.0-20210609072501-72ca9a7c971b
 	github.com/nicholasbunn/mastersSandbox/src/authenticationStuff v0.0.0-20210609073711-4f41ef16e4d2
+	github.com/nicholasbunn/mastersSandbox/src/configuration v0.0.0-00010101000000-000000000000
+	github.com/nicholasbunn/mastersSandbox/src/interceptors v0.0.0-00010101000000-000000000000
+	github.com/nicholasbunn/mastersSandbox/src/logging v0.0.0-00010101000000-000000000000
 	github.com/nicholasbunn/mastersSandbox/src/powerEstimationSP v0.0.0-20210609073711-4f41ef16e4d2
-	github.com/prometheus/client_golang v1.11.0
-	google.golang.org/grpc v1.38.0
+	google.golang.org/grpc v1.46.0
 )
+
+replace github.com/nicholasbunn/mastersSandbox/src/authenticationStuff => ../authenticationStuff
+
+replace github.com/nicholasbunn/mastersSandbox/src/authenticationService => ../authenticationService
+
+replace github.com/nicholasbunn/mastersSandbox/src/interceptors => ../interceptors
+
+replace github.com/nicholasbunn/mastersSandbox/src/logging => ../logging
+
+replace github.com/nicholasbunn/mastersSandbox/src/configuration => ../configuration
@@ -187,11 +187,16 @@ func (m *LoginRequest) GetPassword() string {
 }
 
 type LoginResponse struct {
-	Permissions          string   `protobuf:"bytes,1,opt,name=permissions,proto3" json:"permissions,omitempty"`
-	AccessToken          string   `protobuf:"bytes,2,opt,name=access_token,json=accessToken,proto3" json:"access_token,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	Permissions          string         `protobuf:"bytes,1,opt,name=permissions,proto3" json:"permissions,omitempty"`
+	AccessToken          string         `protobuf:"bytes,2,opt,name=access_token,json=accessToken,proto3" json:"access_token,omitempty"`
+	Roles                []string       `protobuf:"bytes,3,rep,name=roles,proto3" json:"roles,omitempty"`
+	Scopes               []string       `protobuf:"bytes,4,rep,name=scopes,proto3" json:"scopes,omitempty"`
+	MfaRequired          bool           `protobuf:"varint,5,opt,name=mfa_required,json=mfaRequired,proto3" json:"mfa_required,omitempty"`
+	PartialToken         string         `protobuf:"bytes,6,opt,name=partial_token,json=partialToken,proto3" json:"partial_token,omitempty"`
+	Enrolment            *TOTPEnrolment `protobuf:"bytes,7,opt,name=enrolment,proto3" json:"enrolment,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
 }
 
 func (m *LoginResponse) Reset()         { *m = LoginResponse{} }
@@ -233,12 +238,1441 @@ func (m *LoginResponse) GetAccessToken() string {
 	return ""
 }
 
+func (m *LoginResponse) GetRoles() []string {
+	if m != nil {
+		return m.Roles
+	}
+	return nil
+}
+
+func (m *LoginResponse) GetScopes() []string {
+	if m != nil {
+		return m.Scopes
+	}
+	return nil
+}
+
+func (m *LoginResponse) GetMfaRequired() bool {
+	if m != nil {
+		return m.MfaRequired
+	}
+	return false
+}
+
+func (m *LoginResponse) GetPartialToken() string {
+	if m != nil {
+		return m.PartialToken
+	}
+	return ""
+}
+
+func (m *LoginResponse) GetEnrolment() *TOTPEnrolment {
+	if m != nil {
+		return m.Enrolment
+	}
+	return nil
+}
+
+// Messages for the second factor (TOTP) of a login
+type TOTPEnrolment struct {
+	Secret               string   `protobuf:"bytes,1,opt,name=secret,proto3" json:"secret,omitempty"`
+	ProvisioningUri      string   `protobuf:"bytes,2,opt,name=provisioning_uri,json=provisioningUri,proto3" json:"provisioning_uri,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TOTPEnrolment) Reset()         { *m = TOTPEnrolment{} }
+func (m *TOTPEnrolment) String() string { return proto.CompactTextString(m) }
+func (*TOTPEnrolment) ProtoMessage()    {}
+func (*TOTPEnrolment) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4293fa92ac258706, []int{5}
+}
+
+func (m *TOTPEnrolment) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TOTPEnrolment.Unmarshal(m, b)
+}
+func (m *TOTPEnrolment) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TOTPEnrolment.Marshal(b, m, deterministic)
+}
+func (m *TOTPEnrolment) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TOTPEnrolment.Merge(m, src)
+}
+func (m *TOTPEnrolment) XXX_Size() int {
+	return xxx_messageInfo_TOTPEnrolment.Size(m)
+}
+func (m *TOTPEnrolment) XXX_DiscardUnknown() {
+	xxx_messageInfo_TOTPEnrolment.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TOTPEnrolment proto.InternalMessageInfo
+
+func (m *TOTPEnrolment) GetSecret() string {
+	if m != nil {
+		return m.Secret
+	}
+	return ""
+}
+
+func (m *TOTPEnrolment) GetProvisioningUri() string {
+	if m != nil {
+		return m.ProvisioningUri
+	}
+	return ""
+}
+
+type VerifyTOTPRequest struct {
+	PartialToken         string   `protobuf:"bytes,1,opt,name=partial_token,json=partialToken,proto3" json:"partial_token,omitempty"`
+	Code                 string   `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *VerifyTOTPRequest) Reset()         { *m = VerifyTOTPRequest{} }
+func (m *VerifyTOTPRequest) String() string { return proto.CompactTextString(m) }
+func (*VerifyTOTPRequest) ProtoMessage()    {}
+func (*VerifyTOTPRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4293fa92ac258706, []int{6}
+}
+
+func (m *VerifyTOTPRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_VerifyTOTPRequest.Unmarshal(m, b)
+}
+func (m *VerifyTOTPRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_VerifyTOTPRequest.Marshal(b, m, deterministic)
+}
+func (m *VerifyTOTPRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_VerifyTOTPRequest.Merge(m, src)
+}
+func (m *VerifyTOTPRequest) XXX_Size() int {
+	return xxx_messageInfo_VerifyTOTPRequest.Size(m)
+}
+func (m *VerifyTOTPRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_VerifyTOTPRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_VerifyTOTPRequest proto.InternalMessageInfo
+
+func (m *VerifyTOTPRequest) GetPartialToken() string {
+	if m != nil {
+		return m.PartialToken
+	}
+	return ""
+}
+
+func (m *VerifyTOTPRequest) GetCode() string {
+	if m != nil {
+		return m.Code
+	}
+	return ""
+}
+
+type VerifyTOTPResponse struct {
+	Permissions          string   `protobuf:"bytes,1,opt,name=permissions,proto3" json:"permissions,omitempty"`
+	AccessToken          string   `protobuf:"bytes,2,opt,name=access_token,json=accessToken,proto3" json:"access_token,omitempty"`
+	Roles                []string `protobuf:"bytes,3,rep,name=roles,proto3" json:"roles,omitempty"`
+	Scopes               []string `protobuf:"bytes,4,rep,name=scopes,proto3" json:"scopes,omitempty"`
+	RecoveryCodes        []string `protobuf:"bytes,5,rep,name=recovery_codes,json=recoveryCodes,proto3" json:"recovery_codes,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *VerifyTOTPResponse) Reset()         { *m = VerifyTOTPResponse{} }
+func (m *VerifyTOTPResponse) String() string { return proto.CompactTextString(m) }
+func (*VerifyTOTPResponse) ProtoMessage()    {}
+func (*VerifyTOTPResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4293fa92ac258706, []int{7}
+}
+
+func (m *VerifyTOTPResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_VerifyTOTPResponse.Unmarshal(m, b)
+}
+func (m *VerifyTOTPResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_VerifyTOTPResponse.Marshal(b, m, deterministic)
+}
+func (m *VerifyTOTPResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_VerifyTOTPResponse.Merge(m, src)
+}
+func (m *VerifyTOTPResponse) XXX_Size() int {
+	return xxx_messageInfo_VerifyTOTPResponse.Size(m)
+}
+func (m *VerifyTOTPResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_VerifyTOTPResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_VerifyTOTPResponse proto.InternalMessageInfo
+
+func (m *VerifyTOTPResponse) GetPermissions() string {
+	if m != nil {
+		return m.Permissions
+	}
+	return ""
+}
+
+func (m *VerifyTOTPResponse) GetAccessToken() string {
+	if m != nil {
+		return m.AccessToken
+	}
+	return ""
+}
+
+func (m *VerifyTOTPResponse) GetRoles() []string {
+	if m != nil {
+		return m.Roles
+	}
+	return nil
+}
+
+func (m *VerifyTOTPResponse) GetScopes() []string {
+	if m != nil {
+		return m.Scopes
+	}
+	return nil
+}
+
+func (m *VerifyTOTPResponse) GetRecoveryCodes() []string {
+	if m != nil {
+		return m.RecoveryCodes
+	}
+	return nil
+}
+
+type EnrolTOTPRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *EnrolTOTPRequest) Reset()         { *m = EnrolTOTPRequest{} }
+func (m *EnrolTOTPRequest) String() string { return proto.CompactTextString(m) }
+func (*EnrolTOTPRequest) ProtoMessage()    {}
+func (*EnrolTOTPRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4293fa92ac258706, []int{8}
+}
+
+func (m *EnrolTOTPRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EnrolTOTPRequest.Unmarshal(m, b)
+}
+func (m *EnrolTOTPRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EnrolTOTPRequest.Marshal(b, m, deterministic)
+}
+func (m *EnrolTOTPRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EnrolTOTPRequest.Merge(m, src)
+}
+func (m *EnrolTOTPRequest) XXX_Size() int {
+	return xxx_messageInfo_EnrolTOTPRequest.Size(m)
+}
+func (m *EnrolTOTPRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_EnrolTOTPRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EnrolTOTPRequest proto.InternalMessageInfo
+
+type EnrolTOTPResponse struct {
+	Enrolment            *TOTPEnrolment `protobuf:"bytes,1,opt,name=enrolment,proto3" json:"enrolment,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *EnrolTOTPResponse) Reset()         { *m = EnrolTOTPResponse{} }
+func (m *EnrolTOTPResponse) String() string { return proto.CompactTextString(m) }
+func (*EnrolTOTPResponse) ProtoMessage()    {}
+func (*EnrolTOTPResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4293fa92ac258706, []int{9}
+}
+
+func (m *EnrolTOTPResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EnrolTOTPResponse.Unmarshal(m, b)
+}
+func (m *EnrolTOTPResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EnrolTOTPResponse.Marshal(b, m, deterministic)
+}
+func (m *EnrolTOTPResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EnrolTOTPResponse.Merge(m, src)
+}
+func (m *EnrolTOTPResponse) XXX_Size() int {
+	return xxx_messageInfo_EnrolTOTPResponse.Size(m)
+}
+func (m *EnrolTOTPResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_EnrolTOTPResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EnrolTOTPResponse proto.InternalMessageInfo
+
+func (m *EnrolTOTPResponse) GetEnrolment() *TOTPEnrolment {
+	if m != nil {
+		return m.Enrolment
+	}
+	return nil
+}
+
+type ConfirmTOTPRequest struct {
+	Code                 string   `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ConfirmTOTPRequest) Reset()         { *m = ConfirmTOTPRequest{} }
+func (m *ConfirmTOTPRequest) String() string { return proto.CompactTextString(m) }
+func (*ConfirmTOTPRequest) ProtoMessage()    {}
+func (*ConfirmTOTPRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4293fa92ac258706, []int{10}
+}
+
+func (m *ConfirmTOTPRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ConfirmTOTPRequest.Unmarshal(m, b)
+}
+func (m *ConfirmTOTPRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ConfirmTOTPRequest.Marshal(b, m, deterministic)
+}
+func (m *ConfirmTOTPRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ConfirmTOTPRequest.Merge(m, src)
+}
+func (m *ConfirmTOTPRequest) XXX_Size() int {
+	return xxx_messageInfo_ConfirmTOTPRequest.Size(m)
+}
+func (m *ConfirmTOTPRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ConfirmTOTPRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ConfirmTOTPRequest proto.InternalMessageInfo
+
+func (m *ConfirmTOTPRequest) GetCode() string {
+	if m != nil {
+		return m.Code
+	}
+	return ""
+}
+
+type ConfirmTOTPResponse struct {
+	RecoveryCodes        []string `protobuf:"bytes,1,rep,name=recovery_codes,json=recoveryCodes,proto3" json:"recovery_codes,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ConfirmTOTPResponse) Reset()         { *m = ConfirmTOTPResponse{} }
+func (m *ConfirmTOTPResponse) String() string { return proto.CompactTextString(m) }
+func (*ConfirmTOTPResponse) ProtoMessage()    {}
+func (*ConfirmTOTPResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4293fa92ac258706, []int{11}
+}
+
+func (m *ConfirmTOTPResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ConfirmTOTPResponse.Unmarshal(m, b)
+}
+func (m *ConfirmTOTPResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ConfirmTOTPResponse.Marshal(b, m, deterministic)
+}
+func (m *ConfirmTOTPResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ConfirmTOTPResponse.Merge(m, src)
+}
+func (m *ConfirmTOTPResponse) XXX_Size() int {
+	return xxx_messageInfo_ConfirmTOTPResponse.Size(m)
+}
+func (m *ConfirmTOTPResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ConfirmTOTPResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ConfirmTOTPResponse proto.InternalMessageInfo
+
+func (m *ConfirmTOTPResponse) GetRecoveryCodes() []string {
+	if m != nil {
+		return m.RecoveryCodes
+	}
+	return nil
+}
+
+type ResetTOTPRequest struct {
+	Username             string   `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ResetTOTPRequest) Reset()         { *m = ResetTOTPRequest{} }
+func (m *ResetTOTPRequest) String() string { return proto.CompactTextString(m) }
+func (*ResetTOTPRequest) ProtoMessage()    {}
+func (*ResetTOTPRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4293fa92ac258706, []int{12}
+}
+
+func (m *ResetTOTPRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ResetTOTPRequest.Unmarshal(m, b)
+}
+func (m *ResetTOTPRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ResetTOTPRequest.Marshal(b, m, deterministic)
+}
+func (m *ResetTOTPRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ResetTOTPRequest.Merge(m, src)
+}
+func (m *ResetTOTPRequest) XXX_Size() int {
+	return xxx_messageInfo_ResetTOTPRequest.Size(m)
+}
+func (m *ResetTOTPRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ResetTOTPRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ResetTOTPRequest proto.InternalMessageInfo
+
+func (m *ResetTOTPRequest) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
+}
+
+type ResetTOTPResponse struct {
+	Username             string   `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ResetTOTPResponse) Reset()         { *m = ResetTOTPResponse{} }
+func (m *ResetTOTPResponse) String() string { return proto.CompactTextString(m) }
+func (*ResetTOTPResponse) ProtoMessage()    {}
+func (*ResetTOTPResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4293fa92ac258706, []int{13}
+}
+
+func (m *ResetTOTPResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ResetTOTPResponse.Unmarshal(m, b)
+}
+func (m *ResetTOTPResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ResetTOTPResponse.Marshal(b, m, deterministic)
+}
+func (m *ResetTOTPResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ResetTOTPResponse.Merge(m, src)
+}
+func (m *ResetTOTPResponse) XXX_Size() int {
+	return xxx_messageInfo_ResetTOTPResponse.Size(m)
+}
+func (m *ResetTOTPResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ResetTOTPResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ResetTOTPResponse proto.InternalMessageInfo
+
+func (m *ResetTOTPResponse) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
+}
+
+type UnlockAccountRequest struct {
+	Username             string   `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *UnlockAccountRequest) Reset()         { *m = UnlockAccountRequest{} }
+func (m *UnlockAccountRequest) String() string { return proto.CompactTextString(m) }
+func (*UnlockAccountRequest) ProtoMessage()    {}
+func (*UnlockAccountRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4293fa92ac258706, []int{14}
+}
+
+func (m *UnlockAccountRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UnlockAccountRequest.Unmarshal(m, b)
+}
+func (m *UnlockAccountRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UnlockAccountRequest.Marshal(b, m, deterministic)
+}
+func (m *UnlockAccountRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UnlockAccountRequest.Merge(m, src)
+}
+func (m *UnlockAccountRequest) XXX_Size() int {
+	return xxx_messageInfo_UnlockAccountRequest.Size(m)
+}
+func (m *UnlockAccountRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_UnlockAccountRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UnlockAccountRequest proto.InternalMessageInfo
+
+func (m *UnlockAccountRequest) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
+}
+
+type UnlockAccountResponse struct {
+	Username             string   `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *UnlockAccountResponse) Reset()         { *m = UnlockAccountResponse{} }
+func (m *UnlockAccountResponse) String() string { return proto.CompactTextString(m) }
+func (*UnlockAccountResponse) ProtoMessage()    {}
+func (*UnlockAccountResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4293fa92ac258706, []int{15}
+}
+
+func (m *UnlockAccountResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UnlockAccountResponse.Unmarshal(m, b)
+}
+func (m *UnlockAccountResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UnlockAccountResponse.Marshal(b, m, deterministic)
+}
+func (m *UnlockAccountResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UnlockAccountResponse.Merge(m, src)
+}
+func (m *UnlockAccountResponse) XXX_Size() int {
+	return xxx_messageInfo_UnlockAccountResponse.Size(m)
+}
+func (m *UnlockAccountResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_UnlockAccountResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UnlockAccountResponse proto.InternalMessageInfo
+
+func (m *UnlockAccountResponse) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
+}
+
+// Messages for API key management. Times are Unix timestamps (in seconds), zero if unset
+type APIKey struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name                 string   `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Roles                []string `protobuf:"bytes,3,rep,name=roles,proto3" json:"roles,omitempty"`
+	CreatedBy            string   `protobuf:"bytes,4,opt,name=created_by,json=createdBy,proto3" json:"created_by,omitempty"`
+	CreatedAt            int64    `protobuf:"varint,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	ExpiresAt            int64    `protobuf:"varint,6,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	RevokedAt            int64    `protobuf:"varint,7,opt,name=revoked_at,json=revokedAt,proto3" json:"revoked_at,omitempty"`
+	LastUsed             int64    `protobuf:"varint,8,opt,name=last_used,json=lastUsed,proto3" json:"last_used,omitempty"`
+	UsageCount           int64    `protobuf:"varint,9,opt,name=usage_count,json=usageCount,proto3" json:"usage_count,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *APIKey) Reset()         { *m = APIKey{} }
+func (m *APIKey) String() string { return proto.CompactTextString(m) }
+func (*APIKey) ProtoMessage()    {}
+func (*APIKey) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4293fa92ac258706, []int{16}
+}
+
+func (m *APIKey) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_APIKey.Unmarshal(m, b)
+}
+func (m *APIKey) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_APIKey.Marshal(b, m, deterministic)
+}
+func (m *APIKey) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_APIKey.Merge(m, src)
+}
+func (m *APIKey) XXX_Size() int {
+	return xxx_messageInfo_APIKey.Size(m)
+}
+func (m *APIKey) XXX_DiscardUnknown() {
+	xxx_messageInfo_APIKey.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_APIKey proto.InternalMessageInfo
+
+func (m *APIKey) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *APIKey) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *APIKey) GetRoles() []string {
+	if m != nil {
+		return m.Roles
+	}
+	return nil
+}
+
+func (m *APIKey) GetCreatedBy() string {
+	if m != nil {
+		return m.CreatedBy
+	}
+	return ""
+}
+
+func (m *APIKey) GetCreatedAt() int64 {
+	if m != nil {
+		return m.CreatedAt
+	}
+	return 0
+}
+
+func (m *APIKey) GetExpiresAt() int64 {
+	if m != nil {
+		return m.ExpiresAt
+	}
+	return 0
+}
+
+func (m *APIKey) GetRevokedAt() int64 {
+	if m != nil {
+		return m.RevokedAt
+	}
+	return 0
+}
+
+func (m *APIKey) GetLastUsed() int64 {
+	if m != nil {
+		return m.LastUsed
+	}
+	return 0
+}
+
+func (m *APIKey) GetUsageCount() int64 {
+	if m != nil {
+		return m.UsageCount
+	}
+	return 0
+}
+
+type CreateAPIKeyRequest struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Roles                []string `protobuf:"bytes,2,rep,name=roles,proto3" json:"roles,omitempty"`
+	Lifetime             int64    `protobuf:"varint,3,opt,name=lifetime,proto3" json:"lifetime,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CreateAPIKeyRequest) Reset()         { *m = CreateAPIKeyRequest{} }
+func (m *CreateAPIKeyRequest) String() string { return proto.CompactTextString(m) }
+func (*CreateAPIKeyRequest) ProtoMessage()    {}
+func (*CreateAPIKeyRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4293fa92ac258706, []int{17}
+}
+
+func (m *CreateAPIKeyRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CreateAPIKeyRequest.Unmarshal(m, b)
+}
+func (m *CreateAPIKeyRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CreateAPIKeyRequest.Marshal(b, m, deterministic)
+}
+func (m *CreateAPIKeyRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CreateAPIKeyRequest.Merge(m, src)
+}
+func (m *CreateAPIKeyRequest) XXX_Size() int {
+	return xxx_messageInfo_CreateAPIKeyRequest.Size(m)
+}
+func (m *CreateAPIKeyRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CreateAPIKeyRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CreateAPIKeyRequest proto.InternalMessageInfo
+
+func (m *CreateAPIKeyRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *CreateAPIKeyRequest) GetRoles() []string {
+	if m != nil {
+		return m.Roles
+	}
+	return nil
+}
+
+func (m *CreateAPIKeyRequest) GetLifetime() int64 {
+	if m != nil {
+		return m.Lifetime
+	}
+	return 0
+}
+
+type CreateAPIKeyResponse struct {
+	Key                  *APIKey  `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Secret               string   `protobuf:"bytes,2,opt,name=secret,proto3" json:"secret,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CreateAPIKeyResponse) Reset()         { *m = CreateAPIKeyResponse{} }
+func (m *CreateAPIKeyResponse) String() string { return proto.CompactTextString(m) }
+func (*CreateAPIKeyResponse) ProtoMessage()    {}
+func (*CreateAPIKeyResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4293fa92ac258706, []int{18}
+}
+
+func (m *CreateAPIKeyResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CreateAPIKeyResponse.Unmarshal(m, b)
+}
+func (m *CreateAPIKeyResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CreateAPIKeyResponse.Marshal(b, m, deterministic)
+}
+func (m *CreateAPIKeyResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CreateAPIKeyResponse.Merge(m, src)
+}
+func (m *CreateAPIKeyResponse) XXX_Size() int {
+	return xxx_messageInfo_CreateAPIKeyResponse.Size(m)
+}
+func (m *CreateAPIKeyResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_CreateAPIKeyResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CreateAPIKeyResponse proto.InternalMessageInfo
+
+func (m *CreateAPIKeyResponse) GetKey() *APIKey {
+	if m != nil {
+		return m.Key
+	}
+	return nil
+}
+
+func (m *CreateAPIKeyResponse) GetSecret() string {
+	if m != nil {
+		return m.Secret
+	}
+	return ""
+}
+
+type ListAPIKeysRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListAPIKeysRequest) Reset()         { *m = ListAPIKeysRequest{} }
+func (m *ListAPIKeysRequest) String() string { return proto.CompactTextString(m) }
+func (*ListAPIKeysRequest) ProtoMessage()    {}
+func (*ListAPIKeysRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4293fa92ac258706, []int{19}
+}
+
+func (m *ListAPIKeysRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListAPIKeysRequest.Unmarshal(m, b)
+}
+func (m *ListAPIKeysRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListAPIKeysRequest.Marshal(b, m, deterministic)
+}
+func (m *ListAPIKeysRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListAPIKeysRequest.Merge(m, src)
+}
+func (m *ListAPIKeysRequest) XXX_Size() int {
+	return xxx_messageInfo_ListAPIKeysRequest.Size(m)
+}
+func (m *ListAPIKeysRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListAPIKeysRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListAPIKeysRequest proto.InternalMessageInfo
+
+type ListAPIKeysResponse struct {
+	Keys                 []*APIKey `protobuf:"bytes,1,rep,name=keys,proto3" json:"keys,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *ListAPIKeysResponse) Reset()         { *m = ListAPIKeysResponse{} }
+func (m *ListAPIKeysResponse) String() string { return proto.CompactTextString(m) }
+func (*ListAPIKeysResponse) ProtoMessage()    {}
+func (*ListAPIKeysResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4293fa92ac258706, []int{20}
+}
+
+func (m *ListAPIKeysResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListAPIKeysResponse.Unmarshal(m, b)
+}
+func (m *ListAPIKeysResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListAPIKeysResponse.Marshal(b, m, deterministic)
+}
+func (m *ListAPIKeysResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListAPIKeysResponse.Merge(m, src)
+}
+func (m *ListAPIKeysResponse) XXX_Size() int {
+	return xxx_messageInfo_ListAPIKeysResponse.Size(m)
+}
+func (m *ListAPIKeysResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListAPIKeysResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListAPIKeysResponse proto.InternalMessageInfo
+
+func (m *ListAPIKeysResponse) GetKeys() []*APIKey {
+	if m != nil {
+		return m.Keys
+	}
+	return nil
+}
+
+type RevokeAPIKeyRequest struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RevokeAPIKeyRequest) Reset()         { *m = RevokeAPIKeyRequest{} }
+func (m *RevokeAPIKeyRequest) String() string { return proto.CompactTextString(m) }
+func (*RevokeAPIKeyRequest) ProtoMessage()    {}
+func (*RevokeAPIKeyRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4293fa92ac258706, []int{21}
+}
+
+func (m *RevokeAPIKeyRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RevokeAPIKeyRequest.Unmarshal(m, b)
+}
+func (m *RevokeAPIKeyRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RevokeAPIKeyRequest.Marshal(b, m, deterministic)
+}
+func (m *RevokeAPIKeyRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RevokeAPIKeyRequest.Merge(m, src)
+}
+func (m *RevokeAPIKeyRequest) XXX_Size() int {
+	return xxx_messageInfo_RevokeAPIKeyRequest.Size(m)
+}
+func (m *RevokeAPIKeyRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RevokeAPIKeyRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RevokeAPIKeyRequest proto.InternalMessageInfo
+
+func (m *RevokeAPIKeyRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+type RevokeAPIKeyResponse struct {
+	Key                  *APIKey  `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RevokeAPIKeyResponse) Reset()         { *m = RevokeAPIKeyResponse{} }
+func (m *RevokeAPIKeyResponse) String() string { return proto.CompactTextString(m) }
+func (*RevokeAPIKeyResponse) ProtoMessage()    {}
+func (*RevokeAPIKeyResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4293fa92ac258706, []int{22}
+}
+
+func (m *RevokeAPIKeyResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RevokeAPIKeyResponse.Unmarshal(m, b)
+}
+func (m *RevokeAPIKeyResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RevokeAPIKeyResponse.Marshal(b, m, deterministic)
+}
+func (m *RevokeAPIKeyResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RevokeAPIKeyResponse.Merge(m, src)
+}
+func (m *RevokeAPIKeyResponse) XXX_Size() int {
+	return xxx_messageInfo_RevokeAPIKeyResponse.Size(m)
+}
+func (m *RevokeAPIKeyResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_RevokeAPIKeyResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RevokeAPIKeyResponse proto.InternalMessageInfo
+
+func (m *RevokeAPIKeyResponse) GetKey() *APIKey {
+	if m != nil {
+		return m.Key
+	}
+	return nil
+}
+
+type AuditEvent struct {
+	Time                 int64    `protobuf:"varint,1,opt,name=time,proto3" json:"time,omitempty"`
+	Service              string   `protobuf:"bytes,2,opt,name=service,proto3" json:"service,omitempty"`
+	Event                string   `protobuf:"bytes,3,opt,name=event,proto3" json:"event,omitempty"`
+	User                 string   `protobuf:"bytes,4,opt,name=user,proto3" json:"user,omitempty"`
+	Kind                 string   `protobuf:"bytes,5,opt,name=kind,proto3" json:"kind,omitempty"`
+	Roles                []string `protobuf:"bytes,6,rep,name=roles,proto3" json:"roles,omitempty"`
+	Actor                string   `protobuf:"bytes,7,opt,name=actor,proto3" json:"actor,omitempty"`
+	Method               string   `protobuf:"bytes,8,opt,name=method,proto3" json:"method,omitempty"`
+	Peer                 string   `protobuf:"bytes,9,opt,name=peer,proto3" json:"peer,omitempty"`
+	Decision             string   `protobuf:"bytes,10,opt,name=decision,proto3" json:"decision,omitempty"`
+	Reason               string   `protobuf:"bytes,11,opt,name=reason,proto3" json:"reason,omitempty"`
+	RequestId            string   `protobuf:"bytes,12,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	Target               string   `protobuf:"bytes,13,opt,name=target,proto3" json:"target,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AuditEvent) Reset()         { *m = AuditEvent{} }
+func (m *AuditEvent) String() string { return proto.CompactTextString(m) }
+func (*AuditEvent) ProtoMessage()    {}
+func (*AuditEvent) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4293fa92ac258706, []int{23}
+}
+
+func (m *AuditEvent) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AuditEvent.Unmarshal(m, b)
+}
+func (m *AuditEvent) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AuditEvent.Marshal(b, m, deterministic)
+}
+func (m *AuditEvent) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AuditEvent.Merge(m, src)
+}
+func (m *AuditEvent) XXX_Size() int {
+	return xxx_messageInfo_AuditEvent.Size(m)
+}
+func (m *AuditEvent) XXX_DiscardUnknown() {
+	xxx_messageInfo_AuditEvent.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AuditEvent proto.InternalMessageInfo
+
+func (m *AuditEvent) GetTime() int64 {
+	if m != nil {
+		return m.Time
+	}
+	return 0
+}
+
+func (m *AuditEvent) GetService() string {
+	if m != nil {
+		return m.Service
+	}
+	return ""
+}
+
+func (m *AuditEvent) GetEvent() string {
+	if m != nil {
+		return m.Event
+	}
+	return ""
+}
+
+func (m *AuditEvent) GetUser() string {
+	if m != nil {
+		return m.User
+	}
+	return ""
+}
+
+func (m *AuditEvent) GetKind() string {
+	if m != nil {
+		return m.Kind
+	}
+	return ""
+}
+
+func (m *AuditEvent) GetRoles() []string {
+	if m != nil {
+		return m.Roles
+	}
+	return nil
+}
+
+func (m *AuditEvent) GetActor() string {
+	if m != nil {
+		return m.Actor
+	}
+	return ""
+}
+
+func (m *AuditEvent) GetMethod() string {
+	if m != nil {
+		return m.Method
+	}
+	return ""
+}
+
+func (m *AuditEvent) GetPeer() string {
+	if m != nil {
+		return m.Peer
+	}
+	return ""
+}
+
+func (m *AuditEvent) GetDecision() string {
+	if m != nil {
+		return m.Decision
+	}
+	return ""
+}
+
+func (m *AuditEvent) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+func (m *AuditEvent) GetRequestId() string {
+	if m != nil {
+		return m.RequestId
+	}
+	return ""
+}
+
+func (m *AuditEvent) GetTarget() string {
+	if m != nil {
+		return m.Target
+	}
+	return ""
+}
+
+type QueryAuditLogRequest struct {
+	Since                int64    `protobuf:"varint,1,opt,name=since,proto3" json:"since,omitempty"`
+	Until                int64    `protobuf:"varint,2,opt,name=until,proto3" json:"until,omitempty"`
+	Service              string   `protobuf:"bytes,3,opt,name=service,proto3" json:"service,omitempty"`
+	Event                string   `protobuf:"bytes,4,opt,name=event,proto3" json:"event,omitempty"`
+	User                 string   `protobuf:"bytes,5,opt,name=user,proto3" json:"user,omitempty"`
+	Method               string   `protobuf:"bytes,6,opt,name=method,proto3" json:"method,omitempty"`
+	Decision             string   `protobuf:"bytes,7,opt,name=decision,proto3" json:"decision,omitempty"`
+	Limit                int32    `protobuf:"varint,8,opt,name=limit,proto3" json:"limit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *QueryAuditLogRequest) Reset()         { *m = QueryAuditLogRequest{} }
+func (m *QueryAuditLogRequest) String() string { return proto.CompactTextString(m) }
+func (*QueryAuditLogRequest) ProtoMessage()    {}
+func (*QueryAuditLogRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4293fa92ac258706, []int{24}
+}
+
+func (m *QueryAuditLogRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_QueryAuditLogRequest.Unmarshal(m, b)
+}
+func (m *QueryAuditLogRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_QueryAuditLogRequest.Marshal(b, m, deterministic)
+}
+func (m *QueryAuditLogRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_QueryAuditLogRequest.Merge(m, src)
+}
+func (m *QueryAuditLogRequest) XXX_Size() int {
+	return xxx_messageInfo_QueryAuditLogRequest.Size(m)
+}
+func (m *QueryAuditLogRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_QueryAuditLogRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_QueryAuditLogRequest proto.InternalMessageInfo
+
+func (m *QueryAuditLogRequest) GetSince() int64 {
+	if m != nil {
+		return m.Since
+	}
+	return 0
+}
+
+func (m *QueryAuditLogRequest) GetUntil() int64 {
+	if m != nil {
+		return m.Until
+	}
+	return 0
+}
+
+func (m *QueryAuditLogRequest) GetService() string {
+	if m != nil {
+		return m.Service
+	}
+	return ""
+}
+
+func (m *QueryAuditLogRequest) GetEvent() string {
+	if m != nil {
+		return m.Event
+	}
+	return ""
+}
+
+func (m *QueryAuditLogRequest) GetUser() string {
+	if m != nil {
+		return m.User
+	}
+	return ""
+}
+
+func (m *QueryAuditLogRequest) GetMethod() string {
+	if m != nil {
+		return m.Method
+	}
+	return ""
+}
+
+func (m *QueryAuditLogRequest) GetDecision() string {
+	if m != nil {
+		return m.Decision
+	}
+	return ""
+}
+
+func (m *QueryAuditLogRequest) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+type QueryAuditLogResponse struct {
+	Events               []*AuditEvent `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *QueryAuditLogResponse) Reset()         { *m = QueryAuditLogResponse{} }
+func (m *QueryAuditLogResponse) String() string { return proto.CompactTextString(m) }
+func (*QueryAuditLogResponse) ProtoMessage()    {}
+func (*QueryAuditLogResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4293fa92ac258706, []int{25}
+}
+
+func (m *QueryAuditLogResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_QueryAuditLogResponse.Unmarshal(m, b)
+}
+func (m *QueryAuditLogResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_QueryAuditLogResponse.Marshal(b, m, deterministic)
+}
+func (m *QueryAuditLogResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_QueryAuditLogResponse.Merge(m, src)
+}
+func (m *QueryAuditLogResponse) XXX_Size() int {
+	return xxx_messageInfo_QueryAuditLogResponse.Size(m)
+}
+func (m *QueryAuditLogResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_QueryAuditLogResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_QueryAuditLogResponse proto.InternalMessageInfo
+
+func (m *QueryAuditLogResponse) GetEvents() []*AuditEvent {
+	if m != nil {
+		return m.Events
+	}
+	return nil
+}
+
+type Session struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Username             string   `protobuf:"bytes,2,opt,name=username,proto3" json:"username,omitempty"`
+	Client               string   `protobuf:"bytes,3,opt,name=client,proto3" json:"client,omitempty"`
+	Via                  string   `protobuf:"bytes,4,opt,name=via,proto3" json:"via,omitempty"`
+	IssuedAt             int64    `protobuf:"varint,5,opt,name=issued_at,json=issuedAt,proto3" json:"issued_at,omitempty"`
+	ExpiresAt            int64    `protobuf:"varint,6,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	LastUsed             int64    `protobuf:"varint,7,opt,name=last_used,json=lastUsed,proto3" json:"last_used,omitempty"`
+	TerminatedAt         int64    `protobuf:"varint,8,opt,name=terminated_at,json=terminatedAt,proto3" json:"terminated_at,omitempty"`
+	TerminatedBy         string   `protobuf:"bytes,9,opt,name=terminated_by,json=terminatedBy,proto3" json:"terminated_by,omitempty"`
+	Current              bool     `protobuf:"varint,10,opt,name=current,proto3" json:"current,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Session) Reset()         { *m = Session{} }
+func (m *Session) String() string { return proto.CompactTextString(m) }
+func (*Session) ProtoMessage()    {}
+func (*Session) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4293fa92ac258706, []int{26}
+}
+
+func (m *Session) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Session.Unmarshal(m, b)
+}
+func (m *Session) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Session.Marshal(b, m, deterministic)
+}
+func (m *Session) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Session.Merge(m, src)
+}
+func (m *Session) XXX_Size() int {
+	return xxx_messageInfo_Session.Size(m)
+}
+func (m *Session) XXX_DiscardUnknown() {
+	xxx_messageInfo_Session.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Session proto.InternalMessageInfo
+
+func (m *Session) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *Session) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
+}
+
+func (m *Session) GetClient() string {
+	if m != nil {
+		return m.Client
+	}
+	return ""
+}
+
+func (m *Session) GetVia() string {
+	if m != nil {
+		return m.Via
+	}
+	return ""
+}
+
+func (m *Session) GetIssuedAt() int64 {
+	if m != nil {
+		return m.IssuedAt
+	}
+	return 0
+}
+
+func (m *Session) GetExpiresAt() int64 {
+	if m != nil {
+		return m.ExpiresAt
+	}
+	return 0
+}
+
+func (m *Session) GetLastUsed() int64 {
+	if m != nil {
+		return m.LastUsed
+	}
+	return 0
+}
+
+func (m *Session) GetTerminatedAt() int64 {
+	if m != nil {
+		return m.TerminatedAt
+	}
+	return 0
+}
+
+func (m *Session) GetTerminatedBy() string {
+	if m != nil {
+		return m.TerminatedBy
+	}
+	return ""
+}
+
+func (m *Session) GetCurrent() bool {
+	if m != nil {
+		return m.Current
+	}
+	return false
+}
+
+type ListSessionsRequest struct {
+	Username             string   `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListSessionsRequest) Reset()         { *m = ListSessionsRequest{} }
+func (m *ListSessionsRequest) String() string { return proto.CompactTextString(m) }
+func (*ListSessionsRequest) ProtoMessage()    {}
+func (*ListSessionsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4293fa92ac258706, []int{27}
+}
+
+func (m *ListSessionsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListSessionsRequest.Unmarshal(m, b)
+}
+func (m *ListSessionsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListSessionsRequest.Marshal(b, m, deterministic)
+}
+func (m *ListSessionsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListSessionsRequest.Merge(m, src)
+}
+func (m *ListSessionsRequest) XXX_Size() int {
+	return xxx_messageInfo_ListSessionsRequest.Size(m)
+}
+func (m *ListSessionsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListSessionsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListSessionsRequest proto.InternalMessageInfo
+
+func (m *ListSessionsRequest) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
+}
+
+type ListSessionsResponse struct {
+	Sessions             []*Session `protobuf:"bytes,1,rep,name=sessions,proto3" json:"sessions,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
+func (m *ListSessionsResponse) Reset()         { *m = ListSessionsResponse{} }
+func (m *ListSessionsResponse) String() string { return proto.CompactTextString(m) }
+func (*ListSessionsResponse) ProtoMessage()    {}
+func (*ListSessionsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4293fa92ac258706, []int{28}
+}
+
+func (m *ListSessionsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListSessionsResponse.Unmarshal(m, b)
+}
+func (m *ListSessionsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListSessionsResponse.Marshal(b, m, deterministic)
+}
+func (m *ListSessionsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListSessionsResponse.Merge(m, src)
+}
+func (m *ListSessionsResponse) XXX_Size() int {
+	return xxx_messageInfo_ListSessionsResponse.Size(m)
+}
+func (m *ListSessionsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListSessionsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListSessionsResponse proto.InternalMessageInfo
+
+func (m *ListSessionsResponse) GetSessions() []*Session {
+	if m != nil {
+		return m.Sessions
+	}
+	return nil
+}
+
+type TerminateSessionRequest struct {
+	SessionId            string   `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Username             string   `protobuf:"bytes,2,opt,name=username,proto3" json:"username,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TerminateSessionRequest) Reset()         { *m = TerminateSessionRequest{} }
+func (m *TerminateSessionRequest) String() string { return proto.CompactTextString(m) }
+func (*TerminateSessionRequest) ProtoMessage()    {}
+func (*TerminateSessionRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4293fa92ac258706, []int{29}
+}
+
+func (m *TerminateSessionRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TerminateSessionRequest.Unmarshal(m, b)
+}
+func (m *TerminateSessionRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TerminateSessionRequest.Marshal(b, m, deterministic)
+}
+func (m *TerminateSessionRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TerminateSessionRequest.Merge(m, src)
+}
+func (m *TerminateSessionRequest) XXX_Size() int {
+	return xxx_messageInfo_TerminateSessionRequest.Size(m)
+}
+func (m *TerminateSessionRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_TerminateSessionRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TerminateSessionRequest proto.InternalMessageInfo
+
+func (m *TerminateSessionRequest) GetSessionId() string {
+	if m != nil {
+		return m.SessionId
+	}
+	return ""
+}
+
+func (m *TerminateSessionRequest) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
+}
+
+type TerminateSessionResponse struct {
+	Sessions             []*Session `protobuf:"bytes,1,rep,name=sessions,proto3" json:"sessions,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
+func (m *TerminateSessionResponse) Reset()         { *m = TerminateSessionResponse{} }
+func (m *TerminateSessionResponse) String() string { return proto.CompactTextString(m) }
+func (*TerminateSessionResponse) ProtoMessage()    {}
+func (*TerminateSessionResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4293fa92ac258706, []int{30}
+}
+
+func (m *TerminateSessionResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TerminateSessionResponse.Unmarshal(m, b)
+}
+func (m *TerminateSessionResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TerminateSessionResponse.Marshal(b, m, deterministic)
+}
+func (m *TerminateSessionResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TerminateSessionResponse.Merge(m, src)
+}
+func (m *TerminateSessionResponse) XXX_Size() int {
+	return xxx_messageInfo_TerminateSessionResponse.Size(m)
+}
+func (m *TerminateSessionResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_TerminateSessionResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TerminateSessionResponse proto.InternalMessageInfo
+
+func (m *TerminateSessionResponse) GetSessions() []*Session {
+	if m != nil {
+		return m.Sessions
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*EstimationRequest)(nil), "EstimationRequest")
 	proto.RegisterType((*CostEstimationRespose)(nil), "CostEstimationRespose")
 	proto.RegisterType((*PowerEstimationResponse)(nil), "PowerEstimationResponse")
 	proto.RegisterType((*LoginRequest)(nil), "LoginRequest")
 	proto.RegisterType((*LoginResponse)(nil), "LoginResponse")
+	proto.RegisterType((*TOTPEnrolment)(nil), "TOTPEnrolment")
+	proto.RegisterType((*VerifyTOTPRequest)(nil), "VerifyTOTPRequest")
+	proto.RegisterType((*VerifyTOTPResponse)(nil), "VerifyTOTPResponse")
+	proto.RegisterType((*EnrolTOTPRequest)(nil), "EnrolTOTPRequest")
+	proto.RegisterType((*EnrolTOTPResponse)(nil), "EnrolTOTPResponse")
+	proto.RegisterType((*ConfirmTOTPRequest)(nil), "ConfirmTOTPRequest")
+	proto.RegisterType((*ConfirmTOTPResponse)(nil), "ConfirmTOTPResponse")
+	proto.RegisterType((*ResetTOTPRequest)(nil), "ResetTOTPRequest")
+	proto.RegisterType((*ResetTOTPResponse)(nil), "ResetTOTPResponse")
+	proto.RegisterType((*UnlockAccountRequest)(nil), "UnlockAccountRequest")
+	proto.RegisterType((*UnlockAccountResponse)(nil), "UnlockAccountResponse")
+	proto.RegisterType((*APIKey)(nil), "APIKey")
+	proto.RegisterType((*CreateAPIKeyRequest)(nil), "CreateAPIKeyRequest")
+	proto.RegisterType((*CreateAPIKeyResponse)(nil), "CreateAPIKeyResponse")
+	proto.RegisterType((*ListAPIKeysRequest)(nil), "ListAPIKeysRequest")
+	proto.RegisterType((*ListAPIKeysResponse)(nil), "ListAPIKeysResponse")
+	proto.RegisterType((*RevokeAPIKeyRequest)(nil), "RevokeAPIKeyRequest")
+	proto.RegisterType((*RevokeAPIKeyResponse)(nil), "RevokeAPIKeyResponse")
+	proto.RegisterType((*AuditEvent)(nil), "AuditEvent")
+	proto.RegisterType((*QueryAuditLogRequest)(nil), "QueryAuditLogRequest")
+	proto.RegisterType((*QueryAuditLogResponse)(nil), "QueryAuditLogResponse")
+	proto.RegisterType((*Session)(nil), "Session")
+	proto.RegisterType((*ListSessionsRequest)(nil), "ListSessionsRequest")
+	proto.RegisterType((*ListSessionsResponse)(nil), "ListSessionsResponse")
+	proto.RegisterType((*TerminateSessionRequest)(nil), "TerminateSessionRequest")
+	proto.RegisterType((*TerminateSessionResponse)(nil), "TerminateSessionResponse")
 }
 
 func init() {
@@ -246,25 +1680,91 @@ func init() {
 }
 
 var fileDescriptor_4293fa92ac258706 = []byte{
-	// 312 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x92, 0xc1, 0x4b, 0x02, 0x41,
-	0x14, 0xc6, 0x59, 0x25, 0xa9, 0xa7, 0x86, 0x0e, 0x64, 0x8b, 0x27, 0xdb, 0x32, 0x3c, 0xc4, 0x08,
-	0x06, 0x5d, 0x82, 0xc2, 0xa2, 0x22, 0xe8, 0x20, 0xea, 0xa9, 0x4b, 0x8c, 0xfa, 0x88, 0x41, 0xdd,
-	0x99, 0xe6, 0x8d, 0x2d, 0xfd, 0x2f, 0xfd, 0xb1, 0xb1, 0xeb, 0x64, 0xbb, 0xba, 0xdd, 0xe6, 0xfb,
-	0xbd, 0x37, 0xdf, 0x7b, 0xf3, 0x31, 0x70, 0x31, 0x43, 0x9a, 0x5b, 0xa5, 0x9f, 0x84, 0xc5, 0x48,
-	0x7c, 0x75, 0xb5, 0x51, 0x56, 0x75, 0xb3, 0xb0, 0x3f, 0x78, 0xe6, 0x09, 0x0f, 0xda, 0x50, 0x7f,
-	0x20, 0x2b, 0x97, 0xc2, 0x4a, 0x15, 0x0e, 0xf1, 0x63, 0x85, 0x64, 0x59, 0x0d, 0x8a, 0x93, 0x85,
-	0xf0, 0xbd, 0x96, 0xd7, 0x39, 0x18, 0xc6, 0xc7, 0xa0, 0x0b, 0x47, 0xf7, 0x8a, 0x6c, 0xba, 0x95,
-	0xb4, 0x22, 0x64, 0x0d, 0x28, 0x4d, 0x16, 0xe2, 0xaf, 0xdb, 0xa9, 0xe0, 0x16, 0x8e, 0x07, 0x2a,
-	0x42, 0xb3, 0x75, 0x23, 0x24, 0x64, 0x67, 0x50, 0xd5, 0xa9, 0x12, 0xfa, 0x5e, 0xab, 0xd8, 0x29,
-	0x0c, 0xb3, 0x30, 0x78, 0x84, 0xca, 0x8b, 0x7a, 0x97, 0x9b, 0x9d, 0x9a, 0xb0, 0xbf, 0x22, 0x34,
-	0xa1, 0x58, 0xa2, 0x1b, 0xb5, 0xd1, 0x71, 0x4d, 0x0b, 0xa2, 0x48, 0x99, 0x99, 0x5f, 0x58, 0xd7,
-	0x7e, 0x75, 0x30, 0x86, 0xaa, 0xf3, 0x71, 0xe3, 0x5b, 0x50, 0xd6, 0x68, 0x96, 0x92, 0x48, 0xaa,
-	0x90, 0x9c, 0x57, 0x1a, 0xb1, 0x13, 0xa8, 0x88, 0xe9, 0x14, 0x89, 0xde, 0xac, 0x9a, 0x63, 0xe8,
-	0x2c, 0xcb, 0x6b, 0x36, 0x8e, 0x51, 0xef, 0xdb, 0xdb, 0x79, 0xdf, 0x08, 0xcd, 0xa7, 0x9c, 0x22,
-	0xb1, 0x1b, 0xa8, 0x65, 0xb3, 0x1a, 0x0d, 0x18, 0xe3, 0x3b, 0x29, 0x37, 0x1b, 0x3c, 0x3f, 0xd2,
-	0x3e, 0xd4, 0xb7, 0xad, 0xf3, 0x0d, 0x7c, 0xfe, 0x4f, 0xc4, 0xbd, 0x2b, 0x17, 0x9e, 0xdb, 0x89,
-	0x9d, 0xc3, 0x5e, 0xa2, 0x59, 0x95, 0xa7, 0x43, 0x6d, 0x1e, 0xf2, 0x4c, 0x36, 0x77, 0xed, 0xd7,
-	0xd3, 0xbc, 0xdf, 0x73, 0x9d, 0x85, 0x93, 0x52, 0x42, 0x2f, 0x7f, 0x02, 0x00, 0x00, 0xff, 0xff,
-	0xca, 0xcb, 0xbf, 0x90, 0x6b, 0x02, 0x00, 0x00,
+	// 1368 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xc4, 0x57, 0xdf, 0x6e, 0xdb, 0xb6,
+	0x17, 0x86, 0xec, 0xd8, 0xb1, 0x8e, 0xed, 0xfc, 0x62, 0x5a, 0x4e, 0x55, 0x15, 0xc5, 0x2f, 0x53,
+	0x96, 0x21, 0x03, 0x0a, 0x06, 0x75, 0x31, 0xa0, 0x40, 0x8a, 0x6e, 0x6e, 0xd0, 0x15, 0xc1, 0x02,
+	0x2c, 0x53, 0xd3, 0x5d, 0x6c, 0x17, 0x86, 0x22, 0x33, 0x19, 0x61, 0x5b, 0x72, 0x49, 0x3a, 0x9d,
+	0xdf, 0xa5, 0xcf, 0xb0, 0x17, 0xd8, 0x13, 0xec, 0x6e, 0x8f, 0xb3, 0x8b, 0x5d, 0x0c, 0xfc, 0x23,
+	0x9b, 0xb2, 0x95, 0xb6, 0xbb, 0xda, 0x9d, 0xce, 0x77, 0xc8, 0x43, 0xf2, 0x3b, 0x87, 0x87, 0x9f,
+	0xe0, 0xd1, 0x88, 0xf0, 0xb1, 0xc8, 0x66, 0xaf, 0x62, 0x41, 0xde, 0xc5, 0x8b, 0xe3, 0x19, 0xcb,
+	0x44, 0x76, 0x5c, 0x04, 0x07, 0x17, 0x67, 0x58, 0xe1, 0xe1, 0x21, 0x74, 0x5e, 0x72, 0x41, 0xa7,
+	0xb1, 0xa0, 0x59, 0x1a, 0x91, 0xb7, 0x73, 0xc2, 0x05, 0xda, 0x85, 0xea, 0xd5, 0x24, 0xf6, 0x9d,
+	0x7d, 0xe7, 0xc8, 0x8d, 0xe4, 0x67, 0x78, 0x0c, 0xbd, 0xd3, 0x8c, 0x0b, 0x7b, 0x28, 0x9f, 0x65,
+	0x9c, 0xa0, 0x3d, 0xa8, 0x5f, 0x4d, 0xe2, 0xd5, 0x68, 0x63, 0x85, 0x5f, 0xc3, 0xbd, 0x8b, 0xec,
+	0x1d, 0x61, 0x6b, 0x33, 0x52, 0x4e, 0xd0, 0xe7, 0xd0, 0x9e, 0x59, 0x2e, 0xe2, 0x3b, 0xfb, 0xd5,
+	0xa3, 0x4a, 0x54, 0x04, 0xc3, 0x6f, 0xa1, 0x75, 0x9e, 0xdd, 0xd0, 0xe5, 0x9e, 0x02, 0x68, 0xcc,
+	0x39, 0x61, 0x69, 0x3c, 0x25, 0x66, 0xa9, 0xa5, 0x2d, 0x7d, 0xb3, 0x98, 0xf3, 0x77, 0x19, 0x1b,
+	0xf9, 0x15, 0xed, 0xcb, 0xed, 0xf0, 0x2f, 0x07, 0xda, 0x26, 0x90, 0x59, 0x7f, 0x1f, 0x9a, 0x33,
+	0xc2, 0xa6, 0x94, 0x73, 0x9a, 0xa5, 0xdc, 0x04, 0xb3, 0x21, 0xf4, 0x19, 0xb4, 0xe2, 0x24, 0x21,
+	0x9c, 0x0f, 0x45, 0x36, 0x26, 0xa9, 0x89, 0xd9, 0xd4, 0xd8, 0xa5, 0x84, 0x90, 0x07, 0x35, 0x96,
+	0x4d, 0x08, 0xf7, 0xab, 0xfb, 0xd5, 0x23, 0x37, 0xd2, 0x86, 0x64, 0x83, 0x27, 0xd9, 0x8c, 0x70,
+	0x7f, 0x4b, 0xc1, 0xc6, 0x92, 0x01, 0xa7, 0xd7, 0xf1, 0x90, 0x91, 0xb7, 0x73, 0xca, 0xc8, 0xc8,
+	0xaf, 0xed, 0x3b, 0x47, 0x8d, 0xa8, 0x39, 0xbd, 0x8e, 0x23, 0x03, 0xa1, 0x03, 0x68, 0xcf, 0x62,
+	0x26, 0x68, 0x3c, 0x31, 0x8b, 0xd6, 0xd5, 0xa2, 0x2d, 0x03, 0xea, 0x55, 0x1f, 0x81, 0x4b, 0x52,
+	0x96, 0x4d, 0xa6, 0x24, 0x15, 0xfe, 0xf6, 0xbe, 0x73, 0xd4, 0xec, 0xef, 0xe0, 0xcb, 0xef, 0x2f,
+	0x2f, 0x5e, 0xe6, 0x68, 0xb4, 0x1a, 0x10, 0x46, 0xd0, 0x2e, 0xf8, 0xd4, 0xf6, 0x48, 0xc2, 0x88,
+	0xc8, 0x93, 0xa5, 0x2d, 0xf4, 0x25, 0xec, 0xce, 0x58, 0x76, 0x4b, 0xe5, 0xe9, 0x69, 0x7a, 0x33,
+	0x9c, 0x33, 0x6a, 0xce, 0xfc, 0x3f, 0x1b, 0x7f, 0xc3, 0x68, 0x78, 0x0e, 0x9d, 0x1f, 0x09, 0xa3,
+	0xd7, 0x0b, 0x19, 0x39, 0xcf, 0xcd, 0xc6, 0xde, 0x9d, 0x92, 0xbd, 0x23, 0xd8, 0x4a, 0xb2, 0x11,
+	0x31, 0x81, 0xd5, 0x77, 0xf8, 0x9b, 0x03, 0xc8, 0x0e, 0xf7, 0xdf, 0x65, 0xe8, 0x10, 0x76, 0x18,
+	0x49, 0xb2, 0x5b, 0xc2, 0x16, 0x43, 0xb9, 0x35, 0xee, 0xd7, 0x94, 0xbf, 0x9d, 0xa3, 0xa7, 0x12,
+	0x0c, 0x11, 0xec, 0x2a, 0x3a, 0xad, 0xd3, 0x87, 0x03, 0xe8, 0x58, 0x98, 0x39, 0x42, 0x21, 0x53,
+	0xce, 0xc7, 0x32, 0x75, 0x04, 0xe8, 0x34, 0x4b, 0xaf, 0x29, 0x9b, 0xda, 0xb4, 0xe6, 0x8c, 0x39,
+	0x16, 0x63, 0xcf, 0xa0, 0x5b, 0x18, 0x69, 0x96, 0xdb, 0xdc, 0xbe, 0x53, 0xb6, 0x7d, 0x0c, 0xbb,
+	0x11, 0xe1, 0x44, 0xd8, 0xab, 0x7c, 0xe0, 0x62, 0x85, 0xc7, 0xd0, 0xb1, 0xc6, 0x9b, 0xb5, 0x3e,
+	0x34, 0xa1, 0x0f, 0xde, 0x9b, 0x74, 0x92, 0x25, 0xe3, 0x41, 0x92, 0x64, 0xf3, 0x54, 0x7c, 0xca,
+	0x22, 0x4f, 0xa0, 0xb7, 0x36, 0xe7, 0x13, 0x16, 0xfa, 0xdb, 0x81, 0xfa, 0xe0, 0xe2, 0xec, 0x3b,
+	0xb2, 0x40, 0x3b, 0x50, 0xa1, 0x23, 0x33, 0xa0, 0x42, 0x47, 0x92, 0x36, 0x35, 0xc5, 0x14, 0x9a,
+	0xfc, 0xbe, 0xa3, 0x18, 0x1e, 0x02, 0x24, 0x8c, 0xc4, 0x82, 0x8c, 0x86, 0x57, 0x0b, 0x7f, 0x4b,
+	0x8d, 0x77, 0x0d, 0xf2, 0x62, 0x61, 0xbb, 0x63, 0xa1, 0xee, 0x6c, 0x75, 0xe9, 0x1e, 0x08, 0xe9,
+	0x26, 0xbf, 0xce, 0x28, 0x23, 0x5c, 0xba, 0xeb, 0xda, 0x6d, 0x10, 0xed, 0x66, 0xe4, 0x36, 0x1b,
+	0xeb, 0xd9, 0xdb, 0xda, 0x6d, 0x90, 0x81, 0x40, 0x0f, 0xc0, 0x9d, 0xc4, 0x5c, 0x0c, 0xe7, 0x9c,
+	0x8c, 0xfc, 0x86, 0xf2, 0x36, 0x24, 0xf0, 0x86, 0x93, 0x11, 0xfa, 0x3f, 0x34, 0xe7, 0x3c, 0xbe,
+	0x21, 0x43, 0x45, 0x88, 0xef, 0x2a, 0x37, 0x28, 0xe8, 0x54, 0x22, 0xe1, 0xcf, 0xd0, 0x3d, 0x55,
+	0x1b, 0xd1, 0x1c, 0x58, 0x15, 0x63, 0xb1, 0xb5, 0x76, 0xf4, 0x8a, 0x7d, 0xf4, 0x00, 0x1a, 0x13,
+	0x7a, 0x4d, 0x04, 0x9d, 0x12, 0xbf, 0x6a, 0x56, 0x37, 0x76, 0x78, 0x06, 0x5e, 0x31, 0xb8, 0xc9,
+	0xc7, 0x7d, 0xa8, 0x8e, 0xc9, 0xc2, 0x54, 0xf3, 0x36, 0x36, 0x5e, 0x89, 0x59, 0x9d, 0xa5, 0x62,
+	0x77, 0x96, 0xd0, 0x03, 0x74, 0x4e, 0xb9, 0xd0, 0x43, 0x79, 0x7e, 0x63, 0xfa, 0xd0, 0x2d, 0xa0,
+	0x26, 0xfe, 0x03, 0xd8, 0x1a, 0x93, 0x85, 0x2e, 0x5d, 0x6b, 0x01, 0x05, 0x86, 0x87, 0xd0, 0x8d,
+	0x14, 0x79, 0xc5, 0x13, 0xaf, 0x25, 0x3f, 0x7c, 0x0c, 0x5e, 0x71, 0xd8, 0x47, 0xf7, 0x1e, 0xfe,
+	0x5e, 0x01, 0x18, 0xcc, 0x47, 0x54, 0xbc, 0xbc, 0x95, 0x4d, 0x12, 0xc1, 0x96, 0x62, 0xc5, 0x51,
+	0xac, 0xa8, 0x6f, 0xe4, 0xc3, 0x36, 0x27, 0xec, 0x96, 0x26, 0x79, 0x55, 0xe5, 0xa6, 0x64, 0x97,
+	0xc8, 0x69, 0x8a, 0x44, 0x37, 0xaa, 0x91, 0x3c, 0x86, 0xac, 0x54, 0x53, 0x52, 0xea, 0x5b, 0x62,
+	0x63, 0x9a, 0xea, 0xde, 0xef, 0x46, 0xea, 0x7b, 0x95, 0x9b, 0xba, 0x9d, 0x1b, 0x0f, 0x6a, 0x71,
+	0x22, 0x32, 0xa6, 0x8a, 0xc6, 0x8d, 0xb4, 0x21, 0x29, 0x9e, 0x12, 0xf1, 0x4b, 0xa6, 0xab, 0xc5,
+	0x8d, 0x8c, 0x25, 0xe3, 0xce, 0x08, 0x61, 0xaa, 0x48, 0xdc, 0x48, 0x7d, 0xcb, 0xec, 0x8e, 0x48,
+	0xa2, 0xfa, 0xb6, 0x0f, 0xfa, 0xe6, 0xe4, 0xb6, 0x8c, 0xc3, 0x48, 0xcc, 0xb3, 0xd4, 0x6f, 0xea,
+	0x38, 0xda, 0xd2, 0xf5, 0xaa, 0x48, 0x1d, 0xd2, 0x91, 0xdf, 0xd2, 0x97, 0xc1, 0x20, 0x67, 0x23,
+	0x39, 0x4d, 0xc4, 0xec, 0x86, 0x08, 0xbf, 0xad, 0xa7, 0x69, 0x2b, 0xfc, 0xd3, 0x01, 0xef, 0x87,
+	0x39, 0x61, 0x0b, 0x45, 0xe1, 0x79, 0x76, 0x93, 0x67, 0xc6, 0x83, 0x1a, 0xa7, 0x69, 0x92, 0x13,
+	0xa9, 0x0d, 0x89, 0xce, 0x53, 0x41, 0x27, 0x8a, 0xc7, 0x6a, 0xa4, 0x0d, 0x9b, 0xdf, 0xea, 0x1d,
+	0xfc, 0x6e, 0x95, 0xf1, 0x5b, 0xb3, 0xf8, 0x5d, 0xf1, 0x53, 0x2f, 0xf0, 0x63, 0x73, 0xb1, 0xbd,
+	0xc6, 0x85, 0x07, 0xb5, 0x09, 0x9d, 0x52, 0xa1, 0x28, 0xad, 0x45, 0xda, 0x08, 0x9f, 0x41, 0x6f,
+	0xed, 0x44, 0xa6, 0x88, 0x0e, 0xa0, 0xae, 0xd6, 0xcf, 0x4b, 0xb4, 0x89, 0x57, 0x75, 0x13, 0x19,
+	0x57, 0xf8, 0xbe, 0x02, 0xdb, 0xaf, 0x89, 0x7a, 0xa7, 0x36, 0x5a, 0x93, 0xdd, 0xd1, 0x2a, 0x6b,
+	0x22, 0x66, 0x0f, 0xea, 0xc9, 0x84, 0xae, 0x4a, 0xc9, 0x58, 0x52, 0x8c, 0xdd, 0xd2, 0xd8, 0x9c,
+	0x5f, 0x7e, 0xca, 0xd6, 0x41, 0x39, 0x9f, 0xdb, 0x6d, 0xa9, 0xa1, 0x81, 0x8f, 0x77, 0xa5, 0x42,
+	0xdb, 0xd9, 0x5e, 0x6b, 0x3b, 0x07, 0xd0, 0x16, 0xf2, 0x91, 0x4d, 0xf3, 0x9e, 0xa7, 0xfb, 0x52,
+	0x6b, 0x05, 0x0e, 0xc4, 0xda, 0xa0, 0xab, 0x85, 0x29, 0x3c, 0x6b, 0xd0, 0x8b, 0x85, 0x4c, 0x68,
+	0x32, 0x67, 0x4c, 0x9e, 0x06, 0x94, 0xd6, 0xc9, 0xcd, 0xf0, 0xb1, 0xbe, 0xfb, 0x86, 0x21, 0xfe,
+	0x29, 0x0f, 0xc4, 0x33, 0xf0, 0x8a, 0x53, 0x96, 0x42, 0xb2, 0xc1, 0xc9, 0x52, 0x23, 0xc8, 0x84,
+	0x34, 0xb0, 0x19, 0x14, 0x2d, 0x3d, 0xe1, 0x25, 0xdc, 0xbb, 0xcc, 0xb7, 0x96, 0x7b, 0xcd, 0xa2,
+	0x0f, 0x01, 0xcc, 0xb0, 0xe1, 0x32, 0x4d, 0xae, 0x41, 0xce, 0x3e, 0x98, 0xad, 0xf0, 0x1b, 0xf0,
+	0x37, 0xa3, 0xfe, 0x9b, 0x7d, 0xf5, 0xdf, 0x3b, 0x1b, 0x12, 0xf9, 0xb5, 0x2e, 0x7a, 0x8e, 0x9e,
+	0xc3, 0x6e, 0x51, 0x6e, 0xbf, 0xbe, 0x40, 0x08, 0x6f, 0x08, 0xf5, 0x60, 0x0f, 0x97, 0xab, 0xf2,
+	0x01, 0x74, 0xd6, 0x43, 0x97, 0x07, 0xf0, 0xf1, 0x1d, 0x2a, 0xbd, 0xff, 0x47, 0xcd, 0x08, 0x70,
+	0xb3, 0x29, 0xf4, 0x05, 0xd4, 0x94, 0x8d, 0xda, 0xd8, 0x16, 0xe6, 0xc1, 0x0e, 0x2e, 0xca, 0xeb,
+	0xaf, 0x00, 0x56, 0x92, 0x0e, 0x21, 0xbc, 0x21, 0x17, 0x83, 0x2e, 0x2e, 0xd1, 0x7c, 0x7d, 0x70,
+	0x97, 0x2a, 0x0a, 0x75, 0xf0, 0xba, 0xca, 0x0a, 0x10, 0xde, 0x14, 0x59, 0x4f, 0xa1, 0x69, 0x89,
+	0x21, 0xd4, 0xc5, 0x9b, 0x22, 0x2a, 0xf0, 0x70, 0x99, 0x5e, 0x7a, 0x0e, 0xed, 0x82, 0xe6, 0x40,
+	0x3d, 0x5c, 0xa6, 0x5b, 0x82, 0x3d, 0x5c, 0x2e, 0x4d, 0xfa, 0xe0, 0x2e, 0x85, 0x11, 0xea, 0xe0,
+	0x75, 0x51, 0x15, 0x20, 0xbc, 0xa9, 0x9b, 0x4e, 0xa0, 0x65, 0x3f, 0xab, 0xc8, 0xc3, 0x25, 0x4f,
+	0x78, 0xd0, 0xc3, 0xa5, 0x6f, 0xef, 0x53, 0x68, 0x5a, 0x4f, 0x26, 0xea, 0xe2, 0xcd, 0x67, 0x35,
+	0xf0, 0x70, 0xd9, 0xab, 0x7a, 0x02, 0x2d, 0xfb, 0x45, 0x44, 0x1e, 0x2e, 0x79, 0x47, 0x83, 0x1e,
+	0x2e, 0x7d, 0x36, 0x9f, 0x43, 0xbb, 0xd0, 0x0a, 0x51, 0x0f, 0x97, 0x35, 0xfb, 0x60, 0x0f, 0x97,
+	0x77, 0xcc, 0x13, 0x68, 0xd9, 0x57, 0x17, 0x79, 0xd8, 0x36, 0x57, 0x8b, 0x97, 0xde, 0xef, 0x57,
+	0xb0, 0xbb, 0x7e, 0xc7, 0x90, 0x8f, 0xef, 0xb8, 0xcc, 0xc1, 0x7d, 0x7c, 0xd7, 0x85, 0x7c, 0x71,
+	0xf8, 0xd3, 0x41, 0xd9, 0x4f, 0xf1, 0x49, 0x11, 0xbc, 0xaa, 0x2b, 0xf4, 0xc9, 0x3f, 0x03, 0x00,
+	0x73, 0xbf, 0x0a, 0x6b, 0x42, 0x0f, 0x00, 0x00,
 }
@@ -9,6 +9,8 @@ import proto.estimateAPI_pb2 as power_estimation_pb2
 import proto.estimateAPI_pb2_grpc as power_estimation_pb2_grpc
 import interceptors.metricInterceptor as metricInterceptor
 import interceptors.authenticationInterceptor as authenticationInterceptor
+import interceptors.tracingInterceptor as tracingInterceptor
+import interceptors.requestIDInterceptor as requestIDInterceptor
 import pandas as pd
 from keras import models
 
@@ -77,7 +79,8 @@ class EstimatePowerServicer(power_estimation_pb2_grpc.EstimatePowerServicer):
 		myResponseMessage = power_estimation_pb2.EstimateResponseMessage()
 
 		# ________LOADING A PRE-TRAINED MODEL_______
-		activeModel = loadModel(request.model_type)
+		with tracingInterceptor.startSpan("Load model"):
+			activeModel = loadModel(request.model_type)
 		logger.debug("Successfully loaded model")
 
 		# ________RUN THE LOADED MODEL_______
@@ -89,12 +92,14 @@ class EstimatePowerServicer(power_estimation_pb2_grpc.EstimatePowerServicer):
 						'Wave length': request.wave_length}
 		
 		# Run the model
-		estimatedPower = runModel(activeModel, pd.DataFrame(processedData))
+		with tracingInterceptor.startSpan("Run model"):
+			estimatedPower = runModel(activeModel, pd.DataFrame(processedData))
 		logger.debug("Succesfully ran the model")
 
 		# ________EVALUATE THE LOADED MODEL_______
 		rawData = {'PortPropMotorPower': request.motor_power_port, 'StbdPropMotorPower': request.motor_power_stbd}
-		actualPower = evaluateModel(activeModel, pd.DataFrame(processedData), pd.DataFrame(rawData))
+		with tracingInterceptor.startSpan("Evaluate model"):
+			actualPower = evaluateModel(activeModel, pd.DataFrame(processedData), pd.DataFrame(rawData))
 		logger.debug("Successfully evaluated model")
 
 		seriesAttempt = pd.Series(estimatedPower[:,0])
@@ -111,8 +116,8 @@ def loadTLSCredentials():
 	# a server credentials object with the key and certificate, and  returns 
 	# that object for use in the server connection
 	
-	serverKeyFile = "certification/server-key.pem"
-	serverCertFile = "certification/server-cert.pem"
+	serverKeyFile = "certification/estimateservice/server-key.pem"
+	serverCertFile = "certification/estimateservice/server-cert.pem"
 	caCertFile = "certification/ca-cert.pem"
 
 	# Load the server's certificate and private key
@@ -133,7 +138,10 @@ def loadTLSCredentials():
 def serve():
 	# This function creates a server with specified interceptors, registers the service calls offered by that server, and exposes the server over a specified port. The connection to this port is secured with server-side TLS encryption.
 
-	activeInterceptors = [metricInterceptor.MetricInterceptor(), authenticationInterceptor.AuthenticationInterceptor("secret", 15, {"/estimate.EstimatePower/EstimatePowerService": ["admin"]})] # List containing the interceptors to be chained
+	# Export the spans of the calls served, as part of the traces started by the Go services
+	stopTracing = tracingInterceptor.startTracing("EstimateService", config["tracing"])
+
+	activeInterceptors = [tracingInterceptor.TracingInterceptor(), requestIDInterceptor.RequestIDInterceptor(), metricInterceptor.MetricInterceptor(), authenticationInterceptor.AuthenticationInterceptor(config["authentication"]["jwt"]["secretKey"], config["authentication"]["jwt"]["tokenDuration"], config["authentication"]["policy"]["file"], config["authentication"]["jwt"]["audience"])] # List containing the interceptors to be chained
 
 	# Create a server to serve calls
 	server = grpc.server(
@@ -156,6 +164,7 @@ def serve():
 		# Defer termination for a 'persistent' service
 
 	server.wait_for_termination()
+	stopTracing()
 
 if __name__ == '__main__':
 	# ________LOAD CONFIG FILE________
@@ -167,10 +176,11 @@ if __name__ == '__main__':
 	logger.setLevel(logging.DEBUG)
 
 	# Set the fields to be included in the logs
-	formatter = logging.Formatter('%(asctime)s:%(name)s:%(levelname)s:%(module)s:%(funcName)s:%(message)s')
+	formatter = logging.Formatter('%(asctime)s:%(name)s:%(levelname)s:%(module)s:%(funcName)s:%(requestID)s:%(message)s')
 
 	fileHandler = logging.FileHandler("program logs/" + serviceName + ".log")
 	fileHandler.setFormatter(formatter)
+	fileHandler.addFilter(requestIDInterceptor.RequestIDFilter()) # Adds the ID of the request being served to each record
 
 	logger.addHandler(fileHandler)
 
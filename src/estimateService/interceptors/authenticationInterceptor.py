@@ -1,10 +1,20 @@
 import os
+import re
 import time
 import logging
+import threading
+import yaml
 import grpc # Change to grpcio???
 from grpc_interceptor import ServerInterceptor
+from grpc_status import rpc_status
+from google.protobuf import any_pb2
+from google.rpc import code_pb2, error_details_pb2, status_pb2
 import jwt
 
+# Error model shared with the Go services: missing or invalid credentials are UNAUTHENTICATED,
+# insufficient roles are PERMISSION_DENIED, and each error carries an ErrorInfo detail
+ERROR_DOMAIN = "mastersSandbox.authentication"
+
 # Logger setup
 try:
 	logger = logging.getLogger(__file__.rsplit("/")[-3].rsplit(".")[0])
@@ -12,19 +22,155 @@ try:
 except:
     print("Unable to initialise log file, good luck :)")
 
+class Secret:
+	# This class holds the JWT signing secret, resolved from a reference in the configuration file in the same way
+	# as the Go services: "${env:NAME}", "file:/path", "secret:name" (a Docker secret) or the secret itself.
+	# Secrets held in files are re-read when the file changes, the secret they replaced is still accepted for
+	# a grace period so that tokens signed just before a rotation remain valid
+
+	def __init__(self, reference, grace = 15 * 60):
+		self.reference = reference
+		self.grace = grace # Seconds for which the previous secret is still accepted
+		self.path = None
+		self.value = None
+		self.previous = None
+		self.rotatedAt = 0
+		self.modTime = None
+
+		if reference.startswith("${env:") and reference.endswith("}"):
+			name = reference[len("${env:"):-1]
+			self.value = os.getenv(name)
+			if not self.value:
+				raise ValueError(f"JWT secret refers to the environment variable {name}, which is not set")
+		elif reference.startswith("file:"):
+			self.path = reference[len("file:"):]
+		elif reference.startswith("secret:"):
+			self.path = os.path.join(os.getenv("MASTERS_SECRETS_DIRECTORY", default = "/run/secrets"), reference[len("secret:"):])
+		else:
+			self.value = reference
+
+		self.reload()
+
+	def reload(self):
+		# This function re-reads a secret held in a file if the file has changed, keeping the current secret if the file is empty
+		if self.path == None:
+			return
+
+		modTime = os.stat(self.path).st_mtime
+		if modTime == self.modTime:
+			return
+		self.modTime = modTime
+
+		with open(self.path, "r") as f:
+			value = f.read().strip()
+		if not value:
+			raise ValueError(f"JWT secret file {self.path} is empty")
+
+		if self.value and value != self.value:
+			self.previous = self.value
+			self.rotatedAt = time.time()
+			logger.info(f"Reloaded JWT secret {self.reference}")
+		self.value = value
+
+	def values(self):
+		# This function returns the current secret, followed by the one it replaced during the grace period
+		try:
+			self.reload()
+		except Exception as e:
+			logger.warning(f"Could not reload JWT secret {self.reference}, keeping the current secret: {e}")
+
+		if self.previous and time.time() - self.rotatedAt < self.grace:
+			return [self.value, self.previous]
+		return [self.value]
+
+	def __repr__(self):
+		# Printing the secret describes its reference, never its value
+		return "Secret([redacted])" if self.path == None and not self.reference.startswith("${env:") else f"Secret({self.reference})"
+
+class Policy:
+	# This class holds the authorisation policy shared with the Go services (authorisation/policy.yaml). Rules are evaluated
+	# in order and the first rule with a matching method pattern applies, a rule is satisfied by any of its roles (held
+	# directly or through inheritance) together with all of its scopes. The policy is re-read when the file changes, a bad
+	# edit is reported and the current policy is kept
+
+	def __init__(self, path):
+		self.path = path
+		self.modTime = None
+		self.lock = threading.Lock()
+		self.active = None # The compiled policy (see compilePolicy), replaced as a whole when the file is reloaded
+
+		self.reload()
+
+	def reload(self):
+		# This function re-reads the policy file if it has changed, the policy is only replaced if the new file is valid
+		with self.lock:
+			modTime = os.stat(self.path).st_mtime
+			if modTime == self.modTime:
+				return
+			self.modTime = modTime # Only attempt each edit once, a bad file is reported a single time
+
+			with open(self.path, "r") as f:
+				policy = compilePolicy(yaml.safe_load(f) or {})
+			if self.active:
+				logger.info(f"Reloaded policy file {self.path}")
+			self.active = policy
+
+	def current(self):
+		# This function returns the active policy, reloading it first if the file has changed
+		try:
+			self.reload()
+		except Exception as e:
+			logger.warning(f"Could not reload policy file {self.path}, keeping the current policy: {e}")
+
+		return self.active
+
+	def requiresAuthentication(self, method):
+		# This function reports whether a caller needs to present a token for the provided method
+		policy = self.current()
+		rule = matchRule(policy["rules"], method)
+		if rule == None:
+			return policy["defaultDeny"]
+
+		return not rule.get("public", False)
+
+	def authorise(self, method, roles, scopes):
+		# This function reports whether a caller holding the provided roles and scopes may invoke the provided method
+		policy = self.current()
+		rule = matchRule(policy["rules"], method)
+		if rule == None:
+			return not policy["defaultDeny"]
+		if rule.get("public", False):
+			return True
+
+		for scope in rule.get("scopes") or []:
+			if scope not in scopes:
+				return False
+
+		if not rule.get("roles"):
+			# A rule that only lists identities is reserved for workloads, not for users or API keys
+			return bool(rule.get("scopes")) or not rule.get("identities")
+		for role in roles:
+			for effective in policy["effectiveRoles"].get(role, []):
+				if effective in rule["roles"]:
+					return True
+
+		return False
+
 class AuthenticationInterceptor(ServerInterceptor):
 	
-	def __init__(self, secretKey, tokenDuration, authenticatedMethods):
-		self.secretKey = secretKey
+	def __init__(self, secretKey, tokenDuration, policyFile, audience):
+		if not audience:
+			raise ValueError("the service's audience (authentication.jwt.audience) is not set, every exchanged token would be refused")
+		self.secretKey = Secret(secretKey, grace = tokenDuration * 60) # A reference to the secret (see Secret)
 		self.tokenDuration = tokenDuration
-		self.authenticatedMethods = authenticatedMethods
+		self.policy = Policy(policyFile) # The authorisation policy shared with the Go services (see Policy)
+		self.audience = audience # The name of this service (as the aggregator knows it), only tokens exchanged for it are accepted
 	
 	def authorise(self, methodName, context):
     	# This function goes through a series of checks to verify that the user making a request is properly authenticated for that request
 
 		# Check if the method requires authentication
-		accessibleRoles = self.authenticatedMethods.get(methodName)
-		if accessibleRoles == None:
+		if not self.policy.requiresAuthentication(methodName):
 			logger.info(f"Authentication is not required for {methodName}")
 			return
 			
@@ -33,14 +179,14 @@ class AuthenticationInterceptor(ServerInterceptor):
 			metadata = dict(context.invocation_metadata())
 		except:
 			logger.debug("Failed to authenticate: metadata is not provided")
-			return grpc.StatusCode.PERMISSION_DENIED
+			return authError(code_pb2.UNAUTHENTICATED, "METADATA_MISSING", "login", "metadata is not provided")
 
 		# Check if a JWT has been included in the metadata
 		try:
 			encodedToken = metadata["authorisation"]
 		except:
 			logger.debug("Failed to authenticate: JWT has not been provided")
-			return grpc.StatusCode.PERMISSION_DENIED
+			return authError(code_pb2.UNAUTHENTICATED, "TOKEN_MISSING", "login", "authentication token has not been provided")
 
 		# Check that the provided JWT is valid
 		claims, err = self.verifyJWT(encodedToken)
@@ -48,32 +194,129 @@ class AuthenticationInterceptor(ServerInterceptor):
 			logger.debug("Failed to authenticate: Provided JWT is invalid")
 			return err
 
-			#RESUME: HANDLE ERRORS BETTER (return claims, err) AND IMPLEMENT ROLE CHECK
-
-		# Check that the role of the user making the service call authenticates them for the service being called
-		for role in accessibleRoles:
-			if (role == claims["role"]):
-				logger.debug(f"Successfully authenticated request for {methodName}")
-				return
+		# Check that the roles (and scopes) of the user making the service call authorise them for the service being called
+		if self.policy.authorise(methodName, claims.get("roles") or [], claims.get("scopes") or []):
+			logger.debug(f"Successfully authenticated request for {methodName}")
+			return
 
-		logger.debug("Failed to authenticate: the user does not have permission to access the requested service")
-		return grpc.StatusCode.PERMISSION_DENIED
+		logger.debug("Failed to authorise: the user does not have permission to access the requested service")
+		return authError(code_pb2.PERMISSION_DENIED, "PERMISSION_DENIED", "none", "user does not have permission to access this RPC", {"method": methodName})
 
 	def verifyJWT(self, accessToken):
 		try:
-			token = jwt.decode(accessToken, self.secretKey, algorithms=["HS256"])
+			# The audience is checked below
+			token = self.decode(accessToken)
+		except jwt.ExpiredSignatureError:
+			logger.debug("Invalid token: token has expired")
+			return None, authError(code_pb2.UNAUTHENTICATED, "TOKEN_EXPIRED", "login", "access token has expired")
 		except Exception as e:
 			logger.debug(f"Invalid token: {e}")
-			return None, grpc.StatusCode.PERMISSION_DENIED
+			return None, authError(code_pb2.UNAUTHENTICATED, "TOKEN_INVALID", "login", "access token is invalid")
+
+		# Only tokens exchanged for this service are accepted. This service can't check whether a user's session is still
+		# active, so a user's own token (which has no audience) would keep working after they log out, until it expires.
+		# Exchanged tokens are short-lived, and are only issued while the user's session is active
+		if not token.get("aud"):
+			logger.debug("Invalid token: token was not exchanged for this service")
+			return None, authError(code_pb2.UNAUTHENTICATED, "TOKEN_INVALID", "login", "access token was not exchanged for this service")
+		if token.get("aud") != self.audience:
+			logger.debug(f"Invalid token: token was issued for {token.get('aud')}")
+			return None, authError(code_pb2.UNAUTHENTICATED, "TOKEN_INVALID", "login", "access token was issued for another service")
 		
 		return token, None
 
+	def decode(self, accessToken):
+		# This function decodes the provided token with the current secret, or with the previous one if the secret was just rotated
+		secrets = self.secretKey.values()
+		for secret in secrets:
+			try:
+				return jwt.decode(accessToken, secret, algorithms=["HS256"], options={"verify_aud": False})
+			except jwt.InvalidSignatureError:
+				if secret == secrets[-1]:
+					raise
+
 	def intercept(self, method, request, context, methodName):
 		logger.info("Starting server-side authentication interceptor")
 
-		# This isn't working but the error isn't being handled by the actual service
+		# Abort the call with the (rich) status returned by the authorisation checks, if any
 		err = self.authorise(methodName, context)
 		if err:
-			return err	
+			context.abort_with_status(rpc_status.to_status(err))
 		
-		return method(request, context)    
\ No newline at end of file
+		return method(request, context)
+
+def authError(code, reason, action, message, metadata = None):
+	# This function creates a status with the provided code and message, and attaches an ErrorInfo
+	# detail describing the reason and the action the caller should take
+	errorInfo = error_details_pb2.ErrorInfo(reason = reason, domain = ERROR_DOMAIN, metadata = {"action": action, **(metadata or {})})
+
+	detail = any_pb2.Any()
+	detail.Pack(errorInfo)
+
+	return status_pb2.Status(code = code, message = message, details = [detail])
+
+def compilePolicy(policy):
+	# This function validates a policy, as loaded from the policy file, and resolves the inheritance of its roles. It returns
+	# the policy's rules, whether unmatched methods are denied and each role mapped to itself and every role it inherits
+	roles = policy.get("roles") or {}
+	effectiveRoles = {role: resolveRole(roles, role, set()) for role in roles}
+
+	rules = policy.get("rules") or []
+	for index, rule in enumerate(rules):
+		if not rule.get("methods"):
+			raise ValueError(f"policy rule {index} does not list any methods")
+		for role in rule.get("roles") or []:
+			if role not in roles:
+				raise ValueError(f"policy rule {index} references unknown role {role!r}")
+		if rule.get("public", False) and (rule.get("roles") or rule.get("scopes") or rule.get("identities")):
+			raise ValueError(f"policy rule {index} is public but also lists roles, scopes or identities")
+
+	return {"defaultDeny": policy.get("defaultDeny", False), "rules": rules, "effectiveRoles": effectiveRoles}
+
+def resolveRole(roles, role, visiting):
+	# This function walks the inheritance tree of a role, returning every role it grants
+	if role not in roles:
+		raise ValueError(f"policy references unknown role {role!r}")
+	if role in visiting:
+		raise ValueError(f"policy role {role!r} inherits from itself")
+
+	resolved = {role}
+	for parent in (roles[role] or {}).get("inherits") or []:
+		resolved |= resolveRole(roles, parent, visiting | {role})
+
+	return resolved
+
+def matchRule(rules, method):
+	# This function returns the first rule with a method pattern matching the provided method, or None
+	for rule in rules:
+		for pattern in rule["methods"]:
+			if matchPattern(pattern, method):
+				return rule
+
+	return None
+
+def matchPattern(pattern, name):
+	# This function matches a name against a pattern in the same way as the Go services (path.Match): "*" and "?" match
+	# any characters but "/", and "[...]" matches a character class
+	expression = ""
+	index = 0
+	while index < len(pattern):
+		character = pattern[index]
+		if character == "*":
+			expression += "[^/]*"
+		elif character == "?":
+			expression += "[^/]"
+		elif character == "[":
+			end = pattern.find("]", index + 1)
+			if end == -1:
+				return False # Malformed patterns never match
+			expression += pattern[index:end + 1]
+			index = end
+		elif character == "\\" and index + 1 < len(pattern):
+			index += 1
+			expression += re.escape(pattern[index])
+		else:
+			expression += re.escape(character)
+		index += 1
+
+	return re.fullmatch(expression, name) != None
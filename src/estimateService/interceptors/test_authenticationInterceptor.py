@@ -0,0 +1,114 @@
+# Run from the service's directory with: python -m unittest interceptors.test_authenticationInterceptor
+import os
+import tempfile
+import time
+import unittest
+from google.rpc import code_pb2
+import jwt
+import interceptors.authenticationInterceptor as authenticationInterceptor
+
+SECRET = "test-secret"
+METHOD = "/estimate.EstimatePower/EstimatePowerService"
+AUDIENCE = "estimateservice"
+
+# The roles of the shared policy, the service's method is open to analysts (and therefore administrators)
+POLICY = f"""
+defaultDeny: true
+roles:
+  guest:
+    scopes: ["estimation:read"]
+  analyst:
+    inherits: ["guest"]
+  admin:
+    inherits: ["analyst"]
+rules:
+  - methods: ["/grpc.health.v1.Health/*"]
+    public: true
+  - methods: ["{METHOD}"]
+    roles: ["analyst"]
+"""
+
+class TestContext:
+	# This class stands in for the context of a call, carrying the provided metadata
+	def __init__(self, metadata):
+		self.metadata = metadata
+
+	def invocation_metadata(self):
+		return self.metadata
+
+def signToken(**claims):
+	# This function signs a token for the user "admin" with the provided claims added
+	return jwt.encode({"username": "admin", "roles": ["admin"], "exp": int(time.time()) + 60, **claims}, SECRET, algorithm="HS256")
+
+class TestAuthenticationInterceptor(unittest.TestCase):
+
+	def setUp(self):
+		directory = tempfile.TemporaryDirectory()
+		self.addCleanup(directory.cleanup)
+		self.policyPath = os.path.join(directory.name, "policy.yaml")
+		self.edits = 0
+		self.writePolicy(POLICY)
+
+		self.interceptor = authenticationInterceptor.AuthenticationInterceptor(SECRET, 15, self.policyPath, AUDIENCE)
+
+	def writePolicy(self, contents):
+		# This function writes the policy file, moving its modification time on so that the change is noticed
+		with open(self.policyPath, "w") as f:
+			f.write(contents)
+		self.edits += 1
+		modTime = time.time() + self.edits
+		os.utime(self.policyPath, (modTime, modTime))
+
+	def authorise(self, token):
+		return self.interceptor.authorise(METHOD, TestContext([("authorisation", token)]))
+
+	def test_exchanged_tokens_are_accepted(self):
+		self.assertIsNone(self.authorise(signToken(aud=AUDIENCE, sid="session-1")))
+
+	def test_logged_out_users_are_refused(self):
+		# A user's own token outlives their session, so it must not be accepted once they have logged out
+		err = self.authorise(signToken(sid="session-1"))
+		self.assertIsNotNone(err)
+		self.assertEqual(err.code, code_pb2.UNAUTHENTICATED)
+		self.assertEqual(err.message, "access token was not exchanged for this service")
+
+	def test_tokens_for_other_services_are_refused(self):
+		err = self.authorise(signToken(aud="otherservice"))
+		self.assertIsNotNone(err)
+		self.assertEqual(err.code, code_pb2.UNAUTHENTICATED)
+
+	def test_missing_tokens_are_refused(self):
+		err = self.interceptor.authorise(METHOD, TestContext([]))
+		self.assertIsNotNone(err)
+		self.assertEqual(err.code, code_pb2.UNAUTHENTICATED)
+
+	def test_inherited_roles_are_authorised(self):
+		self.assertIsNone(self.authorise(signToken(aud=AUDIENCE, roles=["admin"])))
+
+		err = self.authorise(signToken(aud=AUDIENCE, roles=["guest"]))
+		self.assertIsNotNone(err)
+		self.assertEqual(err.code, code_pb2.PERMISSION_DENIED)
+
+	def test_public_and_unknown_methods(self):
+		self.assertIsNone(self.interceptor.authorise("/grpc.health.v1.Health/Check", TestContext([])))
+
+		err = self.interceptor.authorise(METHOD.rsplit("/", 1)[0] + "/Unknown", TestContext([("authorisation", signToken(aud=AUDIENCE))]))
+		self.assertIsNotNone(err)
+		self.assertEqual(err.code, code_pb2.PERMISSION_DENIED)
+
+	def test_policy_changes_are_reloaded(self):
+		self.writePolicy(POLICY.replace('roles: ["analyst"]', 'roles: ["admin"]'))
+		err = self.authorise(signToken(aud=AUDIENCE, roles=["analyst"]))
+		self.assertIsNotNone(err)
+		self.assertEqual(err.code, code_pb2.PERMISSION_DENIED)
+
+		# A bad edit keeps the current policy
+		self.writePolicy("rules: [{methods: []}]")
+		self.assertIsNone(self.authorise(signToken(aud=AUDIENCE, roles=["admin"])))
+
+	def test_the_audience_is_required(self):
+		with self.assertRaises(ValueError):
+			authenticationInterceptor.AuthenticationInterceptor(SECRET, 15, self.policyPath, "")
+
+if __name__ == "__main__":
+	unittest.main()
@@ -9,6 +9,8 @@ import proto.fetchDataAPI_pb2 as fetch_data_api_pb2
 import proto.fetchDataAPI_pb2_grpc as fetch_data_api_pb2_grpc
 import interceptors.metricInterceptor as metricInterceptor
 import interceptors.authenticationInterceptor as authenticationInterceptor
+import interceptors.tracingInterceptor as tracingInterceptor
+import interceptors.requestIDInterceptor as requestIDInterceptor
 import pandas as pd
 
 # ToDo: Look at how to get/distribute TLS certs to containers, maybe have a certification service in its own container?
@@ -39,7 +41,8 @@ class FetchDataServicer(fetch_data_api_pb2_grpc.FetchDataServicer):
 		thisResponse = fetch_data_api_pb2.FetchDataResponseMessage()
 
 		# Import raw data
-		rawDataSet = importData(request.input_file) # NOTE: This is quite a slow function, it could be sped up if csv files were read instead of Excel files
+		with tracingInterceptor.startSpan("Import data"):
+			rawDataSet = importData(request.input_file) # NOTE: This is quite a slow function, it could be sped up if csv files were read instead of Excel files
 		logger.debug("Succesfully imported data")
 
 		# Populate the response message fields
@@ -92,8 +95,8 @@ def loadTLSCredentials():
 	# a server credentials object with the key and certificate, and  returns 
 	# that object for use in the server connection
 	
-	serverKeyFile = "certification/server-key.pem"
-	serverCertFile = "certification/server-cert.pem"
+	serverKeyFile = "certification/fetchdataservice/server-key.pem"
+	serverCertFile = "certification/fetchdataservice/server-cert.pem"
 	caCertFile = "certification/ca-cert.pem"
 
 	# Load the server's certificate and private key
@@ -116,7 +119,10 @@ def serve():
 	# This function creates a server with specified interceptors, registers the service calls offered by that server, and exposes
 	# the server over a specified port. The connection to this port is secured with server-side TLS encryption.
 
-	activeInterceptors = [metricInterceptor.MetricInterceptor(), authenticationInterceptor.AuthenticationInterceptor("secret", 15, {"/fetchData.FetchData/FetchDataService": ["admin"]})] # List containing the interceptors to be chained
+	# Export the spans of the calls served, as part of the traces started by the Go services
+	stopTracing = tracingInterceptor.startTracing("FetchDataService", config["tracing"])
+
+	activeInterceptors = [tracingInterceptor.TracingInterceptor(), requestIDInterceptor.RequestIDInterceptor(), metricInterceptor.MetricInterceptor(), authenticationInterceptor.AuthenticationInterceptor(config["authentication"]["jwt"]["secretKey"], config["authentication"]["jwt"]["tokenDuration"], config["authentication"]["policy"]["file"], config["authentication"]["jwt"]["audience"])] # List containing the interceptors to be chained
 
 	# Create a server to serve calls in its own thread
 	server = grpc.server(
@@ -138,6 +144,7 @@ def serve():
 	
 	# Defer termination for a 'persistent' service
 	server.wait_for_termination()
+	stopTracing()
 
 if __name__ == '__main__':
 	# ________LOAD CONFIG FILE________
@@ -149,13 +156,14 @@ if __name__ == '__main__':
 	logger.setLevel(logging.DEBUG)
 
 	# Set the fields to be included in the logs
-	formatter = logging.Formatter('%(asctime)s:%(name)s:%(levelname)s:%(module)s:%(funcName)s:%(message)s')
+	formatter = logging.Formatter('%(asctime)s:%(name)s:%(levelname)s:%(module)s:%(funcName)s:%(requestID)s:%(message)s')
 
 	# Create/set the file in which the log will be stored
 	fileHandler = logging.FileHandler("program logs/" + serviceName + ".log")
 	fileHandler.setFormatter(formatter)
+	fileHandler.addFilter(requestIDInterceptor.RequestIDFilter()) # Adds the ID of the request being served to each record
 
 	logger.addHandler(fileHandler)
 
 	# ________SERVE REQUEST________
-	serve() # Finish initialisation by serving the request
\ No newline at end of file
+	serve() # Finish initialisation by serving the request
\ No newline at end of file
@@ -7,21 +7,26 @@ import (
 	"fmt"
 	"io/ioutil"
 	"log"
+	"os"
 	"time"
 
 	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
-	grpc_retry "github.com/grpc-ecosystem/go-grpc-middleware/retry"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 
+	authentication "github.com/nicholasbunn/mastersSandbox/src/authenticationStuff"
 	desktopPB "github.com/nicholasbunn/mastersSandbox/src/desktopGateway/proto"
-	"github.com/nicholasbunn/mastersSandbox/src/frontend/interceptors"
+	"github.com/nicholasbunn/mastersSandbox/src/interceptors"
+	"github.com/nicholasbunn/mastersSandbox/src/logging"
 )
 
 const (
 	addrMyself         = "localhost:50301"
 	addrDesktopGateway = "localhost:50201"
 
+	// The job that the frontend's metrics are pushed to the pushgateway under
+	metricsJob = "Frontend"
+
 	// Timeouts (to be passed in a config file)
 	timeoutDuration     = 5 // The time, in seconds, that the client should wait when dialing (connecting to) the server before throwing an error
 	callTimeoutDuration = 15 * time.Second
@@ -31,10 +36,47 @@ const (
 	MODELTYPE     = "OPENWATER"
 )
 
+// The frontend's calls start the traces that the services add their spans to, the spans are sent to the local collector
+var tracingConfig = interceptors.TracingConfig{
+	Exporter:    interceptors.TraceExporterOTLP,
+	Port:        "4317",
+	Insecure:    true,
+	SampleRatio: 1,
+}
+
+// The frontend only retries calls the gateway couldn't be reached for, a throttled login (RESOURCE_EXHAUSTED) is never retried
+var retryConfig = interceptors.RetryConfig{
+	Default: interceptors.RetryPolicy{
+		Codes:          []string{"UNAVAILABLE"},
+		MaxAttempts:    3,
+		InitialBackoff: 100,
+		MaxBackoff:     1000,
+		Multiplier:     2,
+		Jitter:         0.2,
+	},
+	Budget: interceptors.RetryBudget{MaxTokens: 10, TokenRatio: 0.1},
+}
+
+// The frontend's log lines are written to a file, so that they don't get in the way of what it prints for the user
+var loggingConfig = logging.Config{
+	Level:      "info",
+	Format:     logging.FormatLogfmt,
+	Output:     logging.OutputFile,
+	File:       "program logs/frontend.log",
+	MaxSize:    10,
+	MaxBackups: 3,
+}
+
 func main() {
 
 	fmt.Println("Started frontend")
 
+	stopLogging, err := logging.Setup(metricsJob, loggingConfig)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer stopLogging()
+
 	// Load in TLS credentials
 	creds, err := loadTLSCredentials()
 	if err != nil {
@@ -43,19 +85,37 @@ func main() {
 		fmt.Println("Succesfully loaded TLS certificates")
 	}
 
-	metricInterceptor := interceptors.NewClientMetrics()
-	authInterceptor := interceptors.ClientAuthStruct{}
-	retryOptions := []grpc_retry.CallOption{
-		grpc_retry.WithBackoff(grpc_retry.BackoffExponential(100 * time.Millisecond)),
-		grpc_retry.WithMax(5),
+	// The frontend doesn't run long enough to be scraped, so its metrics are only pushed (and pushed once more when it exits)
+	metricExporter := interceptors.NewMetricExporter(metricsJob)
+	stopPushingMetrics := metricExporter.StartPushing(os.Getenv("PUSHGATEWAYHOST")+":9091", 15*time.Second, 2*time.Minute)
+	defer stopPushingMetrics()
+
+	stopTracing, err := interceptors.StartTracing(metricsJob, tracingConfig)
+	if err != nil {
+		log.Fatal(err)
 	}
+	defer stopTracing()
+
+	metricInterceptor := interceptors.NewClientMetrics(metricExporter)
+	authInterceptor := interceptors.ClientAuthStruct{}
+	retryInterceptor := interceptors.NewClientRetries(metricExporter, retryConfig)
 	interceptorChain := grpc_middleware.ChainUnaryClient(
+		interceptors.ClientRecoveryInterceptor,
+		interceptors.ClientTracingInterceptor,
+		interceptors.ClientLoggingInterceptor,
 		metricInterceptor.ClientMetricInterceptor,
 		authInterceptor.ClientAuthInterceptor,
-		grpc_retry.UnaryClientInterceptor(retryOptions...),
+		retryInterceptor.ClientRetryInterceptor,
+	)
+	streamInterceptorChain := grpc_middleware.ChainStreamClient(
+		interceptors.ClientRecoveryStreamInterceptor,
+		interceptors.ClientTracingStreamInterceptor,
+		interceptors.ClientLoggingStreamInterceptor,
+		metricInterceptor.ClientMetricStreamInterceptor,
+		authInterceptor.ClientAuthStreamInterceptor,
 	)
 
-	connDesktopGateway, err := createSecureServerConnection(addrDesktopGateway, creds, timeoutDuration, interceptorChain)
+	connDesktopGateway, err := createSecureServerConnection(addrDesktopGateway, creds, timeoutDuration, interceptorChain, streamInterceptorChain)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -64,20 +124,30 @@ func main() {
 
 	desktopContext, _ := context.WithTimeout(context.Background(), callTimeoutDuration)
 
-	loginRequest := desktopPB.LoginRequest{
-		Username: "admin",
-		Password: "myPassword",
-	}
-
-	newResponse, newErr := clientLoginDesktopGateway.Login(desktopContext, &loginRequest)
-	if newErr != nil {
-		fmt.Println("Login failed")
+	// Scripts and scheduled jobs authenticate with an API key instead of logging in
+	if apiKey := os.Getenv("MASTERS_API_KEY"); apiKey != "" {
+		fmt.Println("Using the API key provided in MASTERS_API_KEY")
+		authInterceptor.APIKey = apiKey
 	} else {
-		fmt.Println(newResponse)
+		loginRequest := desktopPB.LoginRequest{
+			Username: "admin",
+			Password: "myPassword",
+		}
+
+		newResponse, newErr := clientLoginDesktopGateway.Login(desktopContext, &loginRequest)
+		if newErr != nil {
+			handleServiceError(newErr)
+			log.Fatal("Login failed")
+		} else {
+			fmt.Println(newResponse)
+		}
+
+		authInterceptor.AccessToken = newResponse.AccessToken
+		if newResponse.MfaRequired {
+			authInterceptor.AccessToken = verifySecondFactor(clientLoginDesktopGateway, newResponse)
+		}
 	}
 
-	authInterceptor.AccessToken = newResponse.AccessToken
-
 	requestMessage := desktopPB.EstimationRequest{
 		Bla: "blank",
 	}
@@ -86,12 +156,69 @@ func main() {
 
 	response, err := clientDesktopGateway.PowerEstimationSP(desktopContext, &requestMessage)
 	if err != nil {
-		fmt.Println(err)
+		handleServiceError(err)
 	} else {
 		fmt.Println(response.PowerEstimate[1])
 	}
 }
 
+func verifySecondFactor(client desktopPB.LoginServiceClient, login *desktopPB.LoginResponse) string {
+	/* This function completes a login that requires a second factor, enrolling the user's
+	authenticator app first if they haven't yet. The code is read from MASTERS_TOTP_CODE if
+	it is set, and asked for otherwise. It returns the user's JWT */
+	if login.Enrolment != nil {
+		fmt.Println("Your account requires a second factor. Add this account to your authenticator app, using the QR code for the URI below or the secret:")
+		fmt.Println(login.Enrolment.ProvisioningUri)
+		fmt.Println("Secret: ", login.Enrolment.Secret)
+	}
+
+	code := os.Getenv("MASTERS_TOTP_CODE")
+	if code == "" {
+		fmt.Print("Enter the code from your authenticator app (or a recovery code): ")
+		fmt.Scanln(&code)
+	}
+
+	verifyContext, cancel := context.WithTimeout(context.Background(), callTimeoutDuration)
+	defer cancel()
+	response, err := client.VerifyTOTP(verifyContext, &desktopPB.VerifyTOTPRequest{
+		PartialToken: login.PartialToken,
+		Code:         code,
+	})
+	if err != nil {
+		handleServiceError(err)
+		log.Fatal("Login failed")
+	}
+
+	if len(response.RecoveryCodes) > 0 {
+		fmt.Println("Store these recovery codes somewhere safe, each can be used once in place of a code if you lose your authenticator:")
+		for _, recoveryCode := range response.RecoveryCodes {
+			fmt.Println("  ", recoveryCode)
+		}
+	}
+
+	return response.AccessToken
+}
+
+func handleServiceError(err error) {
+	/* This function reports a failed service call to the user, using the ErrorInfo detail
+	attached to authentication errors to decide what the user should do next */
+	fmt.Println(err)
+
+	info := authentication.ErrorInfoFromError(err)
+	if info == nil {
+		return
+	}
+
+	switch info.Metadata["action"] {
+	case authentication.ActionLogin:
+		fmt.Println("Your session is no longer valid, please log in again")
+	case authentication.ActionRetry:
+		fmt.Println("Please check your details and try again")
+	case authentication.ActionNone:
+		fmt.Println("You do not have permission to do this, contact an administrator if you need access")
+	}
+}
+
 func loadTLSCredentials() (credentials.TransportCredentials, error) {
 	/* This function loads TLS credentials for both the client and server,
 	enabling mutual TLS authentication between the client and server. It takes no inputs and returns a gRPC TransportCredentials object. */
@@ -109,7 +236,7 @@ func loadTLSCredentials() (credentials.TransportCredentials, error) {
 	}
 
 	// Load the client's certificate and private key
-	clientCertificate, err := tls.LoadX509KeyPair("certification/client-cert.pem", "certification/client-key.pem")
+	clientCertificate, err := tls.LoadX509KeyPair("certification/frontend/client-cert.pem", "certification/frontend/client-key.pem")
 	if err != nil {
 		return nil, err
 	}
@@ -147,11 +274,11 @@ func createInsecureServerConnection(port string, timeout int, interceptor grpc.U
 	}
 }
 
-func createSecureServerConnection(port string, credentials credentials.TransportCredentials, timeout int, interceptor grpc.UnaryClientInterceptor) (*grpc.ClientConn, error) {
+func createSecureServerConnection(port string, credentials credentials.TransportCredentials, timeout int, interceptor grpc.UnaryClientInterceptor, streamInterceptor grpc.StreamClientInterceptor) (*grpc.ClientConn, error) {
 	/* This (unexported) function takes a port address, gRPC TransportCredentials object, timeout,
-	and UnaryClientInterceptor object as inputs. It creates a connection to the server
-	at the port adress and returns a secure gRPC connection with the specified
-	interceptor */
+	and UnaryClientInterceptor and StreamClientInterceptor objects as inputs. It creates a connection
+	to the server at the port adress and returns a secure gRPC connection with the specified
+	interceptors */
 
 	// Create the context for the request
 	ctx, cancel := context.WithTimeout(
@@ -166,6 +293,7 @@ func createSecureServerConnection(port string, credentials credentials.Transport
 		grpc.WithBlock(), // Make the dial a blocking call so that we can ensure the connection is indeed created
 		grpc.WithTransportCredentials(credentials), // Add the TLS credentials
 		grpc.WithUnaryInterceptor(interceptor),     // Add the provided interceptors to the connection
+		grpc.WithStreamInterceptor(streamInterceptor),
 	)
 
 	// Handle errors, if any
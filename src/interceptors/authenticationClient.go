@@ -0,0 +1,208 @@
+package interceptors
+
+import (
+	// Native packages
+	"context"
+	"sync"
+	"time"
+
+	// gRPC packages
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	// Personal packages
+	authenticationPB "github.com/nicholasbunn/mastersSandbox/src/authenticationService/proto"
+	authentication "github.com/nicholasbunn/mastersSandbox/src/authenticationStuff"
+	"github.com/nicholasbunn/mastersSandbox/src/logging"
+)
+
+/* The services that accept calls on a user's behalf (the gateway and the aggregator) leave API
+keys, sessions and token exchanges to the authentication service. They call it over a single
+long-lived connection, identifying themselves with their client certificate, and pass its
+rejections straight back to their callers. Any other failure is reported as UNAVAILABLE, so
+that the caller can try again rather than being told that its credentials are wrong */
+
+type RemoteAuthStruct struct {
+	/* This struct checks credentials presented to a service with the authentication service.
+	It is an authentication.APIKeyVerifier, and its VerifySession can be given to an
+	authentication.SessionCache */
+	Address         string                                                                   // The address of the authentication service
+	Credentials     func() credentials.TransportCredentials                                  // Returns the TLS credentials the service calls with, called when the connection is made
+	CallTimeout     func() time.Duration                                                     // Returns the time a call to the authentication service may take
+	OutgoingContext func(incoming context.Context, outgoing context.Context) context.Context // Prepares the context of a call from the request it serves, CarrySpan if nil
+	Interceptors    []grpc.UnaryClientInterceptor                                            // The interceptors every call goes through, the tracing and request ID interceptors if nil
+	Connections     *Connections                                                             // The connection is kept here (if set), so that it is closed once the service has drained
+
+	connOnce sync.Once
+	conn     *grpc.ClientConn
+	connErr  error
+}
+
+type Connections struct {
+	// This struct holds a service's long-lived connections to the services it calls, so that they are closed once it has drained
+	mutex       sync.Mutex
+	connections []*grpc.ClientConn
+}
+
+func (remote *RemoteAuthStruct) VerifyAPIKey(ctx context.Context, key string, method string) (*authentication.Caller, error) {
+	/* This function checks an API key presented to the service with the authentication
+	service, which also records its use */
+	client, err := remote.Client()
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "could not verify api key")
+	}
+
+	verifyContext, cancel := remote.CallContext(ctx)
+	defer cancel()
+	response, err := client.VerifyAPIKey(verifyContext, &authenticationPB.VerifyAPIKeyRequest{
+		ApiKey: key,
+		Method: method,
+	})
+	if status.Code(err) == codes.Unauthenticated {
+		return nil, err // The key was rejected, pass the reason on to the caller
+	} else if err != nil {
+		logging.FromContext(ctx).Errorln("Failed to make the verify API key service call: ", err)
+		return nil, status.Errorf(codes.Unavailable, "could not verify api key")
+	}
+
+	return &authentication.Caller{
+		Kind:   authentication.CallerAPIKey,
+		ID:     response.Id,
+		Roles:  response.Roles,
+		Scopes: response.Scopes,
+	}, nil
+}
+
+func (remote *RemoteAuthStruct) VerifySession(ctx context.Context, sessionID string) error {
+	/* This function checks with the authentication service that the session of a JWT
+	presented to the service hasn't been terminated. Answers are cached (see
+	authentication.SessionCache), so it is only called once in a while for each session */
+	client, err := remote.Client()
+	if err != nil {
+		return status.Errorf(codes.Unavailable, "could not verify session")
+	}
+
+	verifyContext, cancel := remote.CallContext(ctx)
+	defer cancel()
+	_, err = client.VerifySession(verifyContext, &authenticationPB.VerifySessionRequest{SessionId: sessionID})
+	if status.Code(err) == codes.Unauthenticated {
+		return err // The session has ended, pass the reason on to the caller
+	} else if err != nil {
+		logging.FromContext(ctx).Errorln("Failed to make the verify session service call: ", err)
+		return status.Errorf(codes.Unavailable, "could not verify session")
+	}
+
+	return nil
+}
+
+func (remote *RemoteAuthStruct) ExchangeToken(ctx context.Context, audience string) (string, error) {
+	/* This function exchanges the credentials (JWT or API key) presented on an incoming
+	request for a short-lived token that lets the service call the provided audience on the
+	caller's behalf. Requests without credentials are refused rather than passed on */
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	var subjectToken string
+	if values := md["authorisation"]; len(values) > 0 {
+		subjectToken = values[0]
+	} else if values := md["x-api-key"]; len(values) > 0 {
+		subjectToken = values[0]
+	} else {
+		logging.FromContext(ctx).Warnln("No credentials to exchange for a call to ", audience)
+		return "", authentication.UnauthenticatedError(authentication.ReasonTokenMissing, "authentication token has not been provided")
+	}
+
+	client, err := remote.Client()
+	if err != nil {
+		return "", status.Errorf(codes.Unavailable, "could not exchange access token")
+	}
+
+	exchangeContext, cancel := remote.CallContext(ctx)
+	defer cancel()
+	response, err := client.ExchangeToken(exchangeContext, &authenticationPB.ExchangeTokenRequest{
+		SubjectToken: subjectToken,
+		Audience:     audience,
+	})
+	if status.Code(err) == codes.Unauthenticated {
+		return "", err // The credentials were rejected, pass the reason on to the caller
+	} else if err != nil {
+		logging.FromContext(ctx).Errorln("Failed to make the exchange token service call: ", err)
+		return "", status.Errorf(codes.Unavailable, "could not exchange access token")
+	}
+
+	return response.AccessToken, nil
+}
+
+func (remote *RemoteAuthStruct) Client() (authenticationPB.AuthenticationServiceClient, error) {
+	/* This function returns a client for the connection to the authentication service that
+	is shared by every check, token exchange and call the service makes to it. The connection
+	is created on first use */
+	remote.connOnce.Do(func() {
+		interceptors := remote.Interceptors
+		if interceptors == nil {
+			interceptors = []grpc.UnaryClientInterceptor{ClientTracingInterceptor, ClientRequestIDInterceptor} // Calls to the authentication service are part of the trace (and carry the ID) of the request that needed them
+		}
+		remote.conn, remote.connErr = grpc.Dial(
+			remote.Address,
+			grpc.WithTransportCredentials(remote.Credentials()),
+			grpc.WithChainUnaryInterceptor(interceptors...),
+		)
+		if remote.connErr == nil && remote.Connections != nil {
+			remote.Connections.Keep(remote.conn)
+		}
+	})
+	if remote.connErr != nil {
+		logging.Logger.Errorln("Failed to create connection to the authentication service: ", remote.connErr)
+		return nil, remote.connErr
+	}
+
+	return authenticationPB.NewAuthenticationServiceClient(remote.conn), nil
+}
+
+func (remote *RemoteAuthStruct) CallContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	/* This function returns the context of a call made to the authentication service while
+	serving the provided request. It doesn't derive from the request's context, so that the
+	request's metadata (and credentials) and deadline aren't passed on */
+	outgoingContext := remote.OutgoingContext
+	if outgoingContext == nil {
+		outgoingContext = CarrySpan
+	}
+
+	return context.WithTimeout(outgoingContext(ctx, context.Background()), remote.CallTimeout())
+}
+
+func (remote *RemoteAuthStruct) ForwardingContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	/* This function returns the context of a call made to the authentication service on
+	behalf of the caller of the provided request, carrying the credentials (JWT or API key)
+	that the caller presented, so that the authentication service authorises the caller */
+	forwarded := ClientAuthStruct{}
+	forwarded.ForwardCredentials(ctx)
+
+	callContext, cancel := remote.CallContext(ctx)
+	return forwarded.attachToken(callContext), cancel
+}
+
+func (connections *Connections) Keep(conn *grpc.ClientConn) {
+	// This function keeps a long-lived connection, so that it is closed by Close
+	connections.mutex.Lock()
+	defer connections.mutex.Unlock()
+
+	connections.connections = append(connections.connections, conn)
+}
+
+func (connections *Connections) Close() {
+	/* This function closes the long-lived connections to the services called, it is deferred
+	in main so that it runs once the calls in flight (which may still be using them) have finished */
+	connections.mutex.Lock()
+	defer connections.mutex.Unlock()
+
+	for _, conn := range connections.connections {
+		if err := conn.Close(); err != nil {
+			logging.Logger.Warnf("Could not close the connection to %v: %v", conn.Target(), err)
+		}
+	}
+	connections.connections = nil
+	logging.Logger.Debugln("Closed the connections to the services called")
+}
@@ -0,0 +1,179 @@
+package interceptors
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	authenticationPB "github.com/nicholasbunn/mastersSandbox/src/authenticationService/proto"
+	authentication "github.com/nicholasbunn/mastersSandbox/src/authenticationStuff"
+)
+
+type testAuthenticationServer struct {
+	// This struct is an authentication service that accepts the key "valid-key", the session "active" and the token "user-token"
+	authenticationPB.UnimplementedAuthenticationServiceServer
+	unavailable bool // Fail every call as if the service was struggling
+}
+
+func (server *testAuthenticationServer) VerifyAPIKey(ctx context.Context, request *authenticationPB.VerifyAPIKeyRequest) (*authenticationPB.VerifyAPIKeyResponse, error) {
+	if server.unavailable {
+		return nil, status.Error(codes.Internal, "database is down")
+	}
+	if request.ApiKey != "valid-key" {
+		return nil, status.Error(codes.Unauthenticated, "api key is invalid")
+	}
+	return &authenticationPB.VerifyAPIKeyResponse{Id: "key-1", Roles: []string{"admin"}, Scopes: []string{"estimate"}}, nil
+}
+
+func (server *testAuthenticationServer) VerifySession(ctx context.Context, request *authenticationPB.VerifySessionRequest) (*authenticationPB.VerifySessionResponse, error) {
+	if request.SessionId != "active" {
+		return nil, status.Error(codes.Unauthenticated, "session has ended")
+	}
+	return &authenticationPB.VerifySessionResponse{Username: "admin"}, nil
+}
+
+func (server *testAuthenticationServer) ExchangeToken(ctx context.Context, request *authenticationPB.ExchangeTokenRequest) (*authenticationPB.ExchangeTokenResponse, error) {
+	if request.SubjectToken != "user-token" {
+		return nil, status.Error(codes.Unauthenticated, "token is invalid")
+	}
+	return &authenticationPB.ExchangeTokenResponse{AccessToken: "token-for-" + request.Audience}, nil
+}
+
+func serveAuthentication(t *testing.T, server *testAuthenticationServer) *RemoteAuthStruct {
+	// This function serves the provided authentication service on a local port, and returns a client for it
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	grpcServer := grpc.NewServer()
+	authenticationPB.RegisterAuthenticationServiceServer(grpcServer, server)
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	connections := &Connections{}
+	t.Cleanup(connections.Close)
+
+	return &RemoteAuthStruct{
+		Address:     listener.Addr().String(),
+		Credentials: func() credentials.TransportCredentials { return insecure.NewCredentials() },
+		CallTimeout: func() time.Duration { return 5 * time.Second },
+		Connections: connections,
+	}
+}
+
+func TestRemoteAPIKeys(t *testing.T) {
+	server := &testAuthenticationServer{}
+	remote := serveAuthentication(t, server)
+
+	caller, err := remote.VerifyAPIKey(context.Background(), "valid-key", "/Package/Method")
+	if err != nil || caller.Kind != authentication.CallerAPIKey || caller.ID != "key-1" || len(caller.Roles) != 1 {
+		t.Fatalf("Expected the key's caller, received %+v (%v)", caller, err)
+	}
+
+	if _, err := remote.VerifyAPIKey(context.Background(), "revoked-key", "/Package/Method"); status.Code(err) != codes.Unauthenticated {
+		t.Error("Expected a rejected key to be passed on as Unauthenticated, received ", err)
+	}
+
+	server.unavailable = true
+	if _, err := remote.VerifyAPIKey(context.Background(), "valid-key", "/Package/Method"); status.Code(err) != codes.Unavailable {
+		t.Error("Expected other failures to be reported as Unavailable, received ", err)
+	}
+}
+
+func TestRemoteSessions(t *testing.T) {
+	remote := serveAuthentication(t, &testAuthenticationServer{})
+
+	if err := remote.VerifySession(context.Background(), "active"); err != nil {
+		t.Error("Expected an active session to be accepted, received ", err)
+	}
+	if err := remote.VerifySession(context.Background(), "terminated"); status.Code(err) != codes.Unauthenticated {
+		t.Error("Expected a terminated session to be refused, received ", err)
+	}
+}
+
+func TestRemoteTokenExchange(t *testing.T) {
+	remote := serveAuthentication(t, &testAuthenticationServer{})
+	incoming := func(key string, value string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs(key, value))
+	}
+
+	var Tests = []struct {
+		name          string
+		ctx           context.Context
+		expectedToken string
+		expectedCode  codes.Code
+	}{
+		{"Tokens are exchanged", incoming("authorisation", "user-token"), "token-for-estimateservice", codes.OK},
+		{"Rejected tokens are passed on", incoming("authorisation", "forged-token"), "", codes.Unauthenticated},
+		{"Requests without credentials are refused", context.Background(), "", codes.Unauthenticated},
+	}
+
+	for _, test := range Tests {
+		t.Run(test.name, func(t *testing.T) {
+			token, err := remote.ExchangeToken(test.ctx, "estimateservice")
+			if token != test.expectedToken || status.Code(err) != test.expectedCode {
+				t.Errorf("Expected %q (%v), received %q (%v)", test.expectedToken, test.expectedCode, token, err)
+			}
+		})
+	}
+}
+
+func TestRemoteCallContexts(t *testing.T) {
+	remote := &RemoteAuthStruct{CallTimeout: func() time.Duration { return 5 * time.Second }}
+	incoming := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorisation", "user-token", "x-api-key", "user-key"))
+
+	callContext, cancel := remote.CallContext(incoming)
+	defer cancel()
+	if md, _ := metadata.FromOutgoingContext(callContext); len(md["authorisation"]) != 0 || len(md["x-api-key"]) != 0 {
+		t.Error("Expected the caller's credentials not to be passed on, received ", md)
+	}
+
+	forwardingContext, cancel := remote.ForwardingContext(incoming)
+	defer cancel()
+	md, _ := metadata.FromOutgoingContext(forwardingContext)
+	if len(md["authorisation"]) != 1 || md["authorisation"][0] != "user-token" || len(md["x-api-key"]) != 1 || md["x-api-key"][0] != "user-key" {
+		t.Error("Expected the caller's credentials to be forwarded, received ", md)
+	}
+	if _, ok := forwardingContext.Deadline(); !ok {
+		t.Error("Expected forwarded calls to have a deadline")
+	}
+}
+
+func TestRemoteInterceptors(t *testing.T) {
+	remote := serveAuthentication(t, &testAuthenticationServer{})
+	var methods []string
+	remote.Interceptors = []grpc.UnaryClientInterceptor{
+		func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			methods = append(methods, method)
+			return invoker(ctx, method, req, reply, cc, opts...)
+		},
+	}
+
+	if err := remote.VerifySession(context.Background(), "active"); err != nil {
+		t.Fatal(err)
+	}
+	if len(methods) != 1 || methods[0] != "/authentication.AuthenticationService/VerifySession" {
+		t.Error("Expected the call to go through the provided interceptors, received ", methods)
+	}
+}
+
+func TestConnectionsClose(t *testing.T) {
+	remote := serveAuthentication(t, &testAuthenticationServer{})
+	if err := remote.VerifySession(context.Background(), "active"); err != nil {
+		t.Fatal(err)
+	}
+
+	remote.Connections.Close()
+	if state := remote.conn.GetState(); state != connectivity.Shutdown {
+		t.Error("Expected the kept connection to be closed, received ", state)
+	}
+}
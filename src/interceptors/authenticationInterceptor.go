@@ -0,0 +1,188 @@
+package interceptors
+
+import (
+	// Native packages
+	"context"
+
+	// gRPC packages
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	// Personal packages
+	authentication "github.com/nicholasbunn/mastersSandbox/src/authenticationStuff"
+	"github.com/nicholasbunn/mastersSandbox/src/logging"
+)
+
+type ClientAuthStruct struct {
+	AccessToken string
+	APIKey      string // Attached instead of (or as well as) the access token, if set
+}
+
+type ServerAuthStruct struct {
+	JwtManager *authentication.JWTManager
+	Policy     *authentication.PolicyManager
+	APIKeys    authentication.APIKeyVerifier  // Checks API keys presented instead of a JWT, API keys are refused if nil
+	Sessions   authentication.SessionVerifier // Checks that the session a JWT belongs to is still active, sessions aren't checked if nil
+	Audience   string                         // The name of this service, exchanged tokens issued for other services are refused
+	Audit      *authentication.AuditLog       // Records every authorisation decision, decisions aren't audited if nil
+}
+
+func (interceptor *ClientAuthStruct) ClientAuthInterceptor(ctx context.Context, method string, req interface{}, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	logging.FromContext(ctx).Debugln("Starting client-side authentication interceptor")
+
+	// Always inject JWT, even if the requested service is publically available. This removes the need for the frontend to know of what calls are on offer
+	logging.FromContext(ctx).Debugln("Injecting JWT into metadata")
+	return invoker(interceptor.attachToken(ctx), method, req, reply, cc, opts...)
+}
+
+func (interceptor *ClientAuthStruct) ClientAuthStreamInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	// This function attaches the credentials to a stream when it is opened, in the same way as for unary calls
+	logging.FromContext(ctx).Debugln("Starting client-side authentication stream interceptor")
+
+	return streamer(interceptor.attachToken(ctx), desc, cc, method, opts...)
+}
+
+func (interceptor *ServerAuthStruct) ServerAuthInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	logging.FromContext(ctx).Debugln("Starting server-side authentication interceptor")
+
+	ctx, err := interceptor.authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+func (interceptor *ServerAuthStruct) ServerAuthStreamInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	/* This function authorises a stream once, when it is opened. The handler receives the
+	stream with the caller attached to its context, as unary handlers do */
+	logging.FromContext(stream.Context()).Debugln("Starting server-side authentication stream interceptor")
+
+	ctx, err := interceptor.authenticate(stream.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+
+	wrappedStream := grpc_middleware.WrapServerStream(stream)
+	wrappedStream.WrappedContext = ctx
+	return handler(srv, wrappedStream)
+}
+
+func (interceptor *ClientAuthStruct) ForwardCredentials(ctx context.Context) {
+	/* This function copies the credentials (JWT or API key) that the caller presented on
+	an incoming request, so that they are passed on to the services called while serving it */
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	if values := md["authorisation"]; len(values) > 0 {
+		interceptor.AccessToken = values[0]
+	}
+	if values := md["x-api-key"]; len(values) > 0 {
+		interceptor.APIKey = values[0]
+	}
+}
+
+func (interceptor *ClientAuthStruct) attachToken(ctx context.Context) context.Context {
+	if interceptor.AccessToken != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "authorisation", interceptor.AccessToken)
+	}
+	if interceptor.APIKey != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "x-api-key", interceptor.APIKey)
+	}
+
+	return ctx
+}
+
+func (interceptor *ServerAuthStruct) authenticate(ctx context.Context, method string) (context.Context, error) {
+	/* This (unexported) function authorises a request (or a stream) for the provided method
+	and records the decision in the audit log. It returns the context to serve the request
+	with, which carries the caller if there is one. The caller is added to the request's log fields as well */
+	caller, reason, err := interceptor.authorise(ctx, method)
+	if caller != nil {
+		ctx = authentication.ContextWithCaller(ctx, caller)
+		logging.AddField(ctx, logging.FieldUser, caller.ID)
+	}
+
+	// Record the decision, along with whoever the caller turned out to be
+	auditEvent := authentication.AuditEventFromContext(ctx, authentication.AuditAuthorisation, method)
+	auditEvent.Decision, auditEvent.Reason = authentication.AuditAllow, reason
+	if err != nil {
+		auditEvent.Decision = authentication.AuditDeny
+		if errorInfo := authentication.ErrorInfoFromError(err); errorInfo != nil {
+			auditEvent.Reason = errorInfo.Reason
+		}
+	}
+	interceptor.Audit.Record(auditEvent)
+
+	return ctx, err
+}
+
+func (interceptor *ServerAuthStruct) authorise(ctx context.Context, method string) (*authentication.Caller, string, error) {
+	/* This (unexported) function goes through a series of checks to verify that the user making a request is properly
+	authenticated for that request. It returns the authorised caller (nil for public methods) and why they were allowed.
+	The caller is also returned when a valid caller is refused, so that the refusal can be audited */
+
+	// Check if the method requires authentication
+	if !interceptor.Policy.RequiresAuthentication(method) {
+		// If the policy marks the method as public, no credentials are required
+		logging.FromContext(ctx).Infoln("Authentication is not required for ", method)
+		return nil, "public", nil
+	}
+
+	// Check if the caller is a workload (identified by its verified client certificate) that may call the method on its own behalf
+	if identity, ok := authentication.PeerIdentityFromContext(ctx); ok && interceptor.Policy.AuthoriseIdentity(method, identity.Names()) {
+		logging.FromContext(ctx).Debugln("Succesfully authorised workload ", identity.CommonName, " for ", method)
+		return &authentication.Caller{Kind: authentication.CallerWorkload, ID: identity.CommonName}, "identity", nil
+	}
+
+	// Check if the request has metadata attached to it
+	md, ok := metadata.FromIncomingContext(ctx)
+
+	if !ok {
+		logging.FromContext(ctx).Debugln("Failed to authenticate: metadata is not provided")
+		return nil, "", authentication.UnauthenticatedError(authentication.ReasonMetadataMissing, "metadata is not provided")
+	}
+
+	// Check that a valid JWT or API key has been included in the metadata
+	var caller *authentication.Caller
+	if values := md["authorisation"]; len(values) > 0 {
+		claims, err := interceptor.JwtManager.VerifyJWT(values[0])
+		if err != nil {
+			logging.FromContext(ctx).Debugln("Failed to authenticate: Provided JWT is invalid: ", err)
+			return nil, "", authentication.TokenError(err)
+		}
+		if !claims.AcceptedBy(interceptor.Audience) {
+			logging.FromContext(ctx).Debugln("Failed to authenticate: Provided JWT was issued for ", claims.Audience)
+			return nil, "", authentication.UnauthenticatedError(authentication.ReasonTokenInvalid, "access token was issued for another service")
+		}
+		if claims.SessionID != "" && interceptor.Sessions != nil {
+			if err := interceptor.Sessions.VerifySession(ctx, claims.SessionID); err != nil {
+				logging.FromContext(ctx).Debugln("Failed to authenticate: Provided JWT belongs to a session that has ended: ", err)
+				return nil, "", err
+			}
+		}
+		caller = &authentication.Caller{Kind: authentication.CallerUser, ID: claims.Username, Roles: claims.Roles, Scopes: claims.Scopes, Session: claims.SessionID}
+		if actors := claims.ActorChain(); len(actors) > 0 {
+			caller.Actor = actors[0]
+		}
+	} else if values := md["x-api-key"]; len(values) > 0 && interceptor.APIKeys != nil {
+		var err error
+		caller, err = interceptor.APIKeys.VerifyAPIKey(ctx, values[0], method)
+		if err != nil {
+			logging.FromContext(ctx).Debugln("Failed to authenticate: Provided API key is invalid: ", err)
+			return nil, "", err
+		}
+	} else {
+		logging.FromContext(ctx).Debugln("Failed to authenticate: JWT has not been provided")
+		return nil, "", authentication.UnauthenticatedError(authentication.ReasonTokenMissing, "authentication token has not been provided")
+	}
+
+	// Check that the roles and scopes of the caller authorise them for the service being called
+	if interceptor.Policy.Authorise(method, caller.Roles, caller.Scopes) {
+		logging.FromContext(ctx).Debugln("Succesfully authenticated request for ", method)
+		return caller, "policy", nil
+	}
+
+	logging.FromContext(ctx).Debugln("Failed to authorise: the caller does not have permission to access the requested service")
+	return caller, "", authentication.PermissionDeniedError(method)
+}
@@ -0,0 +1,31 @@
+module github.com/nicholasbunn/mastersSandbox/src/interceptors
+
+go 1.13
+
+require (
+	github.com/grpc-ecosystem/go-grpc-middleware v1.3.0
+	github.com/nicholasbunn/mastersSandbox/src/authenticationService v0.0.0-00010101000000-000000000000
+	github.com/nicholasbunn/mastersSandbox/src/authenticationStuff v0.0.0-20210609072109-f7b080e72cb4
+	github.com/nicholasbunn/mastersSandbox/src/logging v0.0.0-00010101000000-000000000000
+	github.com/prometheus/client_golang v1.11.0
+	github.com/prometheus/client_model v0.2.0
+	github.com/sirupsen/logrus v1.8.1
+	go.opentelemetry.io/otel v1.7.0
+	go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc v1.7.0
+	go.opentelemetry.io/otel/exporters/stdout/stdouttrace v1.7.0
+	go.opentelemetry.io/otel/sdk v1.7.0
+	go.opentelemetry.io/otel/trace v1.7.0
+	google.golang.org/grpc v1.46.0
+	google.golang.org/protobuf v1.28.0
+)
+
+replace github.com/nicholasbunn/mastersSandbox/src/authenticationStuff => ../authenticationStuff
+
+replace github.com/nicholasbunn/mastersSandbox/src/logging => ../logging
+
+replace github.com/nicholasbunn/mastersSandbox/src/authenticationService => ../authenticationService
+
+replace github.com/nicholasbunn/mastersSandbox/src/configuration => ../configuration
+
+// The authentication service's client (see authenticationClient.go) brings in its module, which requires this one in turn
+replace github.com/nicholasbunn/mastersSandbox/src/interceptors => ./
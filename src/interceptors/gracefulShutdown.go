@@ -0,0 +1,76 @@
+package interceptors
+
+import (
+	// Native packages
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	// gRPC packages
+	"google.golang.org/grpc"
+
+	// Personal packages
+	"github.com/nicholasbunn/mastersSandbox/src/logging"
+)
+
+/* Services are stopped with SIGTERM (by Docker, when a container is stopped or restarted) or
+SIGINT (Ctrl+C, when run with make). Rather than exiting straight away, which cuts off the calls
+in flight (estimations take several seconds), a service is drained first: its health is
+reported as NOT_SERVING so that probes take it out of rotation, it stops accepting new calls,
+and the calls in flight are given up to the drain timeout to finish. Calls still running after
+the timeout are cut off. Once the server has stopped, the service's deferred clean up (closing
+its connections, and flushing its metrics, spans and logs) runs as main returns */
+
+func ServeUntilStopped(server *grpc.Server, listener net.Listener, checker *HealthChecker, drainTimeout time.Duration) error {
+	/* This function serves calls on the provided listener until the service is asked to stop,
+	and then drains the server. It returns once the server has stopped, with an error if
+	serving failed. A second signal while draining cuts off the calls in flight straight away */
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT)
+	defer signal.Stop(signals)
+
+	return serveUntil(server, listener, checker, drainTimeout, signals)
+}
+
+// ________SUPPORTING FUNCTIONS________
+
+func serveUntil(server *grpc.Server, listener net.Listener, checker *HealthChecker, drainTimeout time.Duration, signals <-chan os.Signal) error {
+	// This (unexported) function serves calls until a signal is received on the provided channel, and then drains the server
+	served := make(chan error, 1)
+	go func() {
+		served <- server.Serve(listener)
+	}()
+
+	select {
+	case err := <-served:
+		return err
+	case received := <-signals:
+		logging.Logger.Infof("Received %v, draining the calls in flight for up to %v", received, drainTimeout)
+	}
+
+	// Take the service out of rotation, and stop accepting new calls while the calls in flight finish
+	checker.Shutdown()
+	drained := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(drained)
+	}()
+
+	timeout := time.NewTimer(drainTimeout)
+	defer timeout.Stop()
+	select {
+	case <-drained:
+		logging.Logger.Infoln("Drained the calls in flight")
+	case <-timeout.C:
+		logging.Logger.Warnf("Calls were still in flight after %v, cutting them off", drainTimeout)
+		server.Stop()
+	case received := <-signals:
+		logging.Logger.Warnf("Received %v while draining, cutting off the calls in flight", received)
+		server.Stop()
+	}
+	<-drained
+
+	return <-served
+}
@@ -0,0 +1,101 @@
+package interceptors
+
+import (
+	"context"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func TestServeUntilStopped(t *testing.T) {
+	var Tests = []struct {
+		name         string
+		callDuration time.Duration // How long the call in flight takes
+		drainTimeout time.Duration
+		expectedCode codes.Code // The code the call in flight finishes with
+	}{
+		{"Calls in flight are drained", 200 * time.Millisecond, 5 * time.Second, codes.OK},
+		{"Calls still running after the drain timeout are cut off", 5 * time.Second, 200 * time.Millisecond, codes.Unavailable},
+	}
+
+	for _, test := range Tests {
+		t.Run(test.name, func(t *testing.T) {
+			started := make(chan struct{})
+			listener := bufconn.Listen(1 << 16)
+			server := grpc.NewServer(grpc.UnknownServiceHandler(func(srv interface{}, stream grpc.ServerStream) error {
+				// Every call is a slow one
+				if err := stream.RecvMsg(&emptypb.Empty{}); err != nil {
+					return err
+				}
+				close(started)
+				select {
+				case <-time.After(test.callDuration):
+				case <-stream.Context().Done():
+					return stream.Context().Err()
+				}
+				return stream.SendMsg(&emptypb.Empty{})
+			}))
+			checker := NewHealthChecker(time.Hour, time.Second)
+			checker.Register(server)
+
+			signals := make(chan os.Signal, 1)
+			stopped := make(chan error, 1)
+			go func() { stopped <- serveUntil(server, listener, checker, test.drainTimeout, signals) }()
+
+			conn, err := grpc.Dial("bufnet", grpc.WithInsecure(), grpc.WithContextDialer(func(ctx context.Context, address string) (net.Conn, error) {
+				return listener.DialContext(ctx)
+			}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			watch, err := healthpb.NewHealthClient(conn).Watch(ctx, &healthpb.HealthCheckRequest{Service: LivenessService})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if response, err := watch.Recv(); err != nil || response.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+				t.Fatal("Expected the service to be alive, received ", response, err)
+			}
+
+			inFlight := make(chan error, 1)
+			go func() { inFlight <- conn.Invoke(ctx, "/Test/Slow", &emptypb.Empty{}, &emptypb.Empty{}) }()
+			<-started
+			signals <- syscall.SIGTERM
+
+			// Watchers are told the service is going away, and their streams end so that they don't hold up the drain
+			if response, err := watch.Recv(); err != nil || response.GetStatus() != healthpb.HealthCheckResponse_NOT_SERVING {
+				t.Error("Expected the watch stream to report NOT_SERVING, received ", response, err)
+			}
+			if _, err := watch.Recv(); err == nil {
+				t.Error("Expected the watch stream to end once the service shut down")
+			}
+
+			if err := <-inFlight; status.Code(err) != test.expectedCode {
+				t.Errorf("Expected the call in flight to finish with %v, received %v", test.expectedCode, err)
+			}
+			select {
+			case err := <-stopped:
+				if err != nil {
+					t.Error("Expected the server to stop without an error, received ", err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("Expected the server to have stopped")
+			}
+			if err := conn.Invoke(ctx, "/Test/Slow", &emptypb.Empty{}, &emptypb.Empty{}, grpc.WaitForReady(false)); err == nil {
+				t.Error("Expected new calls to be refused once the server has stopped")
+			}
+		})
+	}
+}
@@ -0,0 +1,393 @@
+package interceptors
+
+import (
+	// Native packages
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	// gRPC packages
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	// Personal packages
+	"github.com/nicholasbunn/mastersSandbox/src/logging"
+)
+
+/* The health checker serves the standard gRPC health service (grpc.health.v1.Health) on a
+service's server, so that Docker and Envoy (or grpc_health_probe) can tell whether the service
+is alive and whether it is ready to serve. The service reports on:
+	- "liveness", which is SERVING for as long as the process serves calls at all, and only
+	changes once the service starts shutting down
+	- "" (the server as a whole, which probes ask for by default), the readiness of the
+	service, which is SERVING only while every check added to the checker passes
+	- the name of each check (a downstream connection, the certificates), so that the check
+	that is failing can be told apart
+Checks are run in the background every interval, rather than on every probe, so that probes
+are cheap and a slow dependency can't hold them up. Every change of status is logged, and sent
+to the callers watching the service (Watch streams). Once the service shuts down, watchers are
+sent NOT_SERVING and their streams are ended, so that they don't hold up draining the server */
+
+const (
+	// LivenessService is the health service name that reports whether the process is alive
+	LivenessService = "liveness"
+
+	// ReadinessService is the health service name that reports whether the service is ready to serve
+	ReadinessService = ""
+
+	// healthMethodPrefix is the prefix of the health service's methods, which probes call every few seconds
+	healthMethodPrefix = "/grpc.health.v1.Health/"
+)
+
+// errNotChecked is the error a check is reported with until it has run for the first time
+var errNotChecked = errors.New("not checked yet")
+
+// HealthCheck reports whether something the service depends on is healthy, it returns nil if it is
+type HealthCheck func(ctx context.Context) error
+
+type HealthChecker struct {
+	/* This struct runs a service's health checks and serves their results over the gRPC
+	health service */
+	server   *healthServer
+	interval time.Duration
+	timeout  time.Duration
+
+	mutex    sync.Mutex
+	names    []string               // The names of the checks, in the order they were added
+	checks   map[string]HealthCheck // The checks, by name
+	failures map[string]error       // The error of each check's last run, nil if it passed
+	ready    bool
+	shutdown bool
+}
+
+func NewHealthChecker(interval time.Duration, timeout time.Duration) *HealthChecker {
+	/* This function creates a health checker that runs its checks every interval, giving each
+	check up to timeout to finish. The service is alive from the start, and isn't ready until
+	its checks have run and passed */
+	server := newHealthServer()
+	server.SetServingStatus(LivenessService, healthpb.HealthCheckResponse_SERVING)
+	server.SetServingStatus(ReadinessService, healthpb.HealthCheckResponse_NOT_SERVING)
+
+	return &HealthChecker{
+		server:   server,
+		interval: interval,
+		timeout:  timeout,
+		checks:   map[string]HealthCheck{},
+		failures: map[string]error{},
+	}
+}
+
+func (checker *HealthChecker) AddCheck(name string, check HealthCheck) {
+	// This function adds a check, which the service's readiness depends on, under the provided name
+	checker.mutex.Lock()
+	defer checker.mutex.Unlock()
+
+	if _, exists := checker.checks[name]; !exists {
+		checker.names = append(checker.names, name)
+	}
+	checker.checks[name] = check
+	checker.failures[name] = errNotChecked
+	checker.server.SetServingStatus(name, healthpb.HealthCheckResponse_NOT_SERVING)
+}
+
+func (checker *HealthChecker) Register(server *grpc.Server) {
+	// This function registers the health service on the provided server
+	healthpb.RegisterHealthServer(server, checker.server)
+}
+
+func (checker *HealthChecker) Start() (stop func()) {
+	/* This function runs the checks straight away, and then every interval in the background.
+	It returns a function that stops the checks */
+	done := make(chan struct{})
+	ticker := time.NewTicker(checker.interval)
+
+	go func() {
+		defer ticker.Stop()
+		checker.runChecks()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				checker.runChecks()
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
+
+func (checker *HealthChecker) Shutdown() {
+	/* This function reports every service (liveness included) as NOT_SERVING, so that probes
+	stop sending calls to the service while it shuts down, and ends the watch streams once
+	they have been sent the change. The statuses no longer change after this, whatever the
+	checks report */
+	checker.mutex.Lock()
+	defer checker.mutex.Unlock()
+
+	checker.shutdown = true
+	checker.ready = false
+	checker.server.Shutdown()
+	logging.Logger.Infoln("Reporting the service as not serving, it is shutting down")
+}
+
+func ConnectionCheck(conn *grpc.ClientConn) HealthCheck {
+	/* This function returns a check that passes once the provided connection is ready (the
+	server at the other end has completed a TLS handshake with the service), asking the
+	connection to connect if it is idle. It is used for dependencies that don't serve the
+	health service themselves (the Python services) */
+	return func(ctx context.Context) error {
+		for {
+			state := conn.GetState()
+			switch state {
+			case connectivity.Ready:
+				return nil
+			case connectivity.Idle:
+				conn.Connect()
+			}
+			if !conn.WaitForStateChange(ctx, state) {
+				return fmt.Errorf("the connection to %v is %v", conn.Target(), strings.ToLower(state.String()))
+			}
+		}
+	}
+}
+
+func ServingCheck(conn *grpc.ClientConn, service string) HealthCheck {
+	/* This function returns a check that passes while the server at the other end of the
+	provided connection reports the provided health service as SERVING. It is used for the
+	Go services, which serve the health service. Services check the liveness of the services
+	they call rather than their readiness, so that a failing dependency further down only
+	takes the service next to it out of rotation, rather than every service above it */
+	client := healthpb.NewHealthClient(conn)
+
+	return func(ctx context.Context) error {
+		response, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+		if err != nil {
+			return fmt.Errorf("could not check the health of %v: %v", conn.Target(), status.Convert(err).Message())
+		}
+		if response.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+			return fmt.Errorf("%v reports %v", conn.Target(), response.GetStatus())
+		}
+
+		return nil
+	}
+}
+
+// ________SUPPORTING FUNCTIONS________
+
+func (checker *HealthChecker) runChecks() {
+	/* This (unexported) function runs every check at the same time, and updates the status of
+	each check and the service's readiness with the results. Changes of status are logged */
+	checker.mutex.Lock()
+	checks := make(map[string]HealthCheck, len(checker.checks))
+	for name, check := range checker.checks {
+		checks[name] = check
+	}
+	checker.mutex.Unlock()
+
+	results := make(map[string]error, len(checks))
+	var resultsMutex sync.Mutex
+	var wait sync.WaitGroup
+	for name, check := range checks {
+		wait.Add(1)
+		go func(name string, check HealthCheck) {
+			defer wait.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), checker.timeout)
+			defer cancel()
+
+			err := check(ctx)
+			resultsMutex.Lock()
+			results[name] = err
+			resultsMutex.Unlock()
+		}(name, check)
+	}
+	wait.Wait()
+
+	checker.mutex.Lock()
+	defer checker.mutex.Unlock()
+	if checker.shutdown {
+		return
+	}
+
+	ready := true
+	for _, name := range checker.names {
+		err, checked := results[name]
+		if !checked {
+			ready = false // Added while the checks were running, it is checked on the next run
+			continue
+		}
+		checker.updateCheck(name, err)
+		if err != nil {
+			ready = false
+		}
+	}
+
+	if ready != checker.ready {
+		if ready {
+			logging.Logger.Infoln("The service is ready to serve")
+			checker.server.SetServingStatus(ReadinessService, healthpb.HealthCheckResponse_SERVING)
+		} else {
+			logging.Logger.Warnln("The service is not ready to serve, see the failing health checks")
+			checker.server.SetServingStatus(ReadinessService, healthpb.HealthCheckResponse_NOT_SERVING)
+		}
+		checker.ready = ready
+	}
+}
+
+func (checker *HealthChecker) updateCheck(name string, err error) {
+	/* This (unexported) function records the result of a check, logging it if the check
+	started or stopped failing. The checker's mutex must be held */
+	previous := checker.failures[name]
+	checker.failures[name] = err
+
+	switch {
+	case err != nil && (previous == nil || previous == errNotChecked):
+		logging.Logger.Warnf("Health check %v is failing: %v", name, err)
+		checker.server.SetServingStatus(name, healthpb.HealthCheckResponse_NOT_SERVING)
+	case err == nil && previous != nil:
+		if previous != errNotChecked {
+			logging.Logger.Infof("Health check %v is passing again", name)
+		}
+		checker.server.SetServingStatus(name, healthpb.HealthCheckResponse_SERVING)
+	}
+}
+
+func isHealthMethod(fullMethod string) bool {
+	// This (unexported) function reports whether the provided method is one of the health service's
+	return strings.HasPrefix(fullMethod, healthMethodPrefix)
+}
+
+type healthServer struct {
+	/* This (unexported) struct serves the gRPC health service from the statuses set on it.
+	It does what grpc's own health server does, except that watch streams are ended once
+	the server shuts down, rather than staying open until their callers cancel them */
+	healthpb.UnimplementedHealthServer
+
+	mutex    sync.Mutex
+	statuses map[string]healthpb.HealthCheckResponse_ServingStatus
+	watchers map[string]map[chan healthpb.HealthCheckResponse_ServingStatus]bool // The channels of each service's watch streams
+	shutdown bool
+	done     chan struct{} // Closed when the server shuts down
+}
+
+func newHealthServer() *healthServer {
+	// This (unexported) function creates a health server that serves no services yet
+	return &healthServer{
+		statuses: map[string]healthpb.HealthCheckResponse_ServingStatus{},
+		watchers: map[string]map[chan healthpb.HealthCheckResponse_ServingStatus]bool{},
+		done:     make(chan struct{}),
+	}
+}
+
+func (server *healthServer) Check(ctx context.Context, request *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	// This function returns the status of the requested service
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	servingStatus, known := server.statuses[request.GetService()]
+	if !known {
+		return nil, status.Errorf(codes.NotFound, "unknown service %q", request.GetService())
+	}
+
+	return &healthpb.HealthCheckResponse{Status: servingStatus}, nil
+}
+
+func (server *healthServer) Watch(request *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	/* This function sends the status of the requested service, and then every change of it,
+	until the caller cancels the stream or the server shuts down. Services that aren't known
+	are reported as SERVICE_UNKNOWN, until they are set */
+	service := request.GetService()
+	updates := make(chan healthpb.HealthCheckResponse_ServingStatus, 1)
+
+	server.mutex.Lock()
+	current, known := server.statuses[service]
+	if !known {
+		current = healthpb.HealthCheckResponse_SERVICE_UNKNOWN
+	}
+	updates <- current
+	if server.watchers[service] == nil {
+		server.watchers[service] = map[chan healthpb.HealthCheckResponse_ServingStatus]bool{}
+	}
+	server.watchers[service][updates] = true
+	server.mutex.Unlock()
+
+	defer func() {
+		server.mutex.Lock()
+		delete(server.watchers[service], updates)
+		server.mutex.Unlock()
+	}()
+
+	lastSent := healthpb.HealthCheckResponse_ServingStatus(-1)
+	send := func(servingStatus healthpb.HealthCheckResponse_ServingStatus) error {
+		if servingStatus == lastSent {
+			return nil
+		}
+		lastSent = servingStatus
+		return stream.Send(&healthpb.HealthCheckResponse{Status: servingStatus})
+	}
+
+	for {
+		select {
+		case servingStatus := <-updates:
+			if err := send(servingStatus); err != nil {
+				return err
+			}
+		case <-server.done:
+			// The last status was queued before the server shut down, send it before ending the stream
+			select {
+			case servingStatus := <-updates:
+				return send(servingStatus)
+			default:
+				return nil
+			}
+		case <-stream.Context().Done():
+			return status.Error(codes.Canceled, "the watch stream has ended")
+		}
+	}
+}
+
+func (server *healthServer) SetServingStatus(service string, servingStatus healthpb.HealthCheckResponse_ServingStatus) {
+	// This function sets the status of a service, and sends it to the service's watchers. It does nothing once the server has shut down
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	if server.shutdown {
+		return
+	}
+	server.setServingStatus(service, servingStatus)
+}
+
+func (server *healthServer) Shutdown() {
+	/* This function sets every service to NOT_SERVING, sends the change to their watchers,
+	and then ends the watch streams */
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	if server.shutdown {
+		return
+	}
+	server.shutdown = true
+	for service := range server.statuses {
+		server.setServingStatus(service, healthpb.HealthCheckResponse_NOT_SERVING)
+	}
+	close(server.done)
+}
+
+func (server *healthServer) setServingStatus(service string, servingStatus healthpb.HealthCheckResponse_ServingStatus) {
+	/* This (unexported) function sets the status of a service, replacing any update its
+	watchers haven't picked up yet with the new status. The server's mutex must be held */
+	server.statuses[service] = servingStatus
+	for updates := range server.watchers[service] {
+		select {
+		case <-updates:
+		default:
+		}
+		updates <- servingStatus
+	}
+}
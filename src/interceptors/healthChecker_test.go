@@ -0,0 +1,131 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func serveHealth(t *testing.T, checker *HealthChecker) *grpc.ClientConn {
+	// This function serves the checker's health service in memory, and returns a connection to it
+	listener := bufconn.Listen(1 << 16)
+	server := grpc.NewServer()
+	checker.Register(server)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("bufnet", grpc.WithInsecure(), grpc.WithContextDialer(func(ctx context.Context, address string) (net.Conn, error) {
+		return listener.DialContext(ctx)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return conn
+}
+
+func TestHealthChecker(t *testing.T) {
+	checker := NewHealthChecker(time.Hour, time.Second)
+	var dependencyErr error = errors.New("connection refused")
+	checker.AddCheck("dependency", func(ctx context.Context) error { return dependencyErr })
+	checker.AddCheck("certificates", func(ctx context.Context) error { return nil })
+
+	conn := serveHealth(t, checker)
+	client := healthpb.NewHealthClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	statusOf := func(service string) healthpb.HealthCheckResponse_ServingStatus {
+		response, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+		if err != nil {
+			t.Fatal("Failed to check ", service, ": ", err)
+		}
+		return response.GetStatus()
+	}
+
+	watch, err := client.Watch(ctx, &healthpb.HealthCheckRequest{Service: ReadinessService})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectTransition := func(expected healthpb.HealthCheckResponse_ServingStatus) {
+		response, err := watch.Recv()
+		if err != nil || response.GetStatus() != expected {
+			t.Fatalf("Expected the watch stream to report %v, received %v (%v)", expected, response.GetStatus(), err)
+		}
+	}
+
+	t.Run("The service is alive but not ready until its checks pass", func(t *testing.T) {
+		expectTransition(healthpb.HealthCheckResponse_NOT_SERVING)
+		if statusOf(LivenessService) != healthpb.HealthCheckResponse_SERVING || statusOf(ReadinessService) != healthpb.HealthCheckResponse_NOT_SERVING {
+			t.Error("Expected the service to be alive and not ready")
+		}
+	})
+
+	t.Run("Failing checks are reported on by name", func(t *testing.T) {
+		checker.runChecks()
+		if statusOf("dependency") != healthpb.HealthCheckResponse_NOT_SERVING || statusOf("certificates") != healthpb.HealthCheckResponse_SERVING {
+			t.Error("Expected only the dependency to be failing")
+		}
+		if statusOf(ReadinessService) != healthpb.HealthCheckResponse_NOT_SERVING {
+			t.Error("Expected the service not to be ready while a check fails")
+		}
+	})
+
+	t.Run("Readiness follows the checks, and watchers are sent the transitions", func(t *testing.T) {
+		dependencyErr = nil
+		checker.runChecks()
+		expectTransition(healthpb.HealthCheckResponse_SERVING)
+
+		dependencyErr = errors.New("connection reset")
+		checker.runChecks()
+		expectTransition(healthpb.HealthCheckResponse_NOT_SERVING)
+
+		dependencyErr = nil
+		checker.runChecks()
+		expectTransition(healthpb.HealthCheckResponse_SERVING)
+	})
+
+	t.Run("Nothing is serving once the service shuts down", func(t *testing.T) {
+		checker.Shutdown()
+		expectTransition(healthpb.HealthCheckResponse_NOT_SERVING)
+
+		checker.runChecks()
+		if statusOf(LivenessService) != healthpb.HealthCheckResponse_NOT_SERVING || statusOf(ReadinessService) != healthpb.HealthCheckResponse_NOT_SERVING {
+			t.Error("Expected the service to stay not serving after shutting down")
+		}
+	})
+}
+
+func TestDependencyChecks(t *testing.T) {
+	conn := serveHealth(t, NewHealthChecker(time.Hour, time.Second))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := ConnectionCheck(conn)(ctx); err != nil {
+		t.Error("Expected the connection to become ready, received ", err)
+	}
+	if err := ServingCheck(conn, LivenessService)(ctx); err != nil {
+		t.Error("Expected the dependency to be alive, received ", err)
+	}
+	if err := ServingCheck(conn, ReadinessService)(ctx); err == nil {
+		t.Error("Expected the dependency not to be ready before its checks have run")
+	}
+
+	unreachable, err := grpc.Dial("127.0.0.1:1", grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unreachable.Close()
+	shortCtx, shortCancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer shortCancel()
+	if err := ConnectionCheck(unreachable)(shortCtx); err == nil {
+		t.Error("Expected the check of an unreachable dependency to fail")
+	}
+}
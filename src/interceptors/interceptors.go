@@ -0,0 +1,52 @@
+package interceptors
+
+import (
+	// Native packages
+	"strings"
+
+	// gRPC packages
+	"google.golang.org/grpc"
+)
+
+/* This package holds the gRPC interceptors shared by every Go service: authentication, request IDs,
+metrics, tracing, logging and recovery, each with unary and stream variants for both the client
+and the server side. Services configure the interceptors with their own identity (the audience
+their tokens are issued for and the job their metrics are pushed and spans are exported under),
+and chain them with grpc_middleware so that streaming RPCs are covered in the same way as unary
+ones. The package also holds what the services share to export their metrics and spans, to
+report their health and to drain their servers when they are stopped */
+
+// ________SUPPORTING FUNCTIONS________
+
+func splitMethod(fullMethod string) (string, string) {
+	/* This (unexported) function splits a full gRPC method name ("/package.Service/Method")
+	into its service and method names */
+	requesterInfo := strings.Split(fullMethod, "/")
+	if len(requesterInfo) < 3 {
+		return "unknown", fullMethod
+	}
+
+	return requesterInfo[1], requesterInfo[2]
+}
+
+func streamType(clientStreams bool, serverStreams bool) string {
+	// This (unexported) function returns the "grpc_type" label of a streaming RPC
+	switch {
+	case clientStreams && serverStreams:
+		return "bidi_stream"
+	case clientStreams:
+		return "client_stream"
+	default:
+		return "server_stream"
+	}
+}
+
+func clientStreamType(desc *grpc.StreamDesc) string {
+	// This (unexported) function returns the "grpc_type" label of a streaming RPC made by a client
+	return streamType(desc.ClientStreams, desc.ServerStreams)
+}
+
+func serverStreamType(info *grpc.StreamServerInfo) string {
+	// This (unexported) function returns the "grpc_type" label of a streaming RPC being served
+	return streamType(info.IsClientStream, info.IsServerStream)
+}
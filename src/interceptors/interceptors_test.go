@@ -0,0 +1,160 @@
+package interceptors
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	authentication "github.com/nicholasbunn/mastersSandbox/src/authenticationStuff"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const testPolicy = `
+defaultDeny: true
+roles:
+  guest:
+    scopes: ["estimation:read"]
+  analyst:
+    inherits: ["guest"]
+rules:
+  - methods: ["/Package/Public"]
+    public: true
+  - methods: ["/Package/*"]
+    roles: ["analyst"]
+`
+
+type testServerStream struct {
+	// This struct is a server stream that only carries a context, enough to run the stream interceptors
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (stream *testServerStream) Context() context.Context {
+	return stream.ctx
+}
+
+func TestServerAuthStreamInterceptor(t *testing.T) {
+	policyPath := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := ioutil.WriteFile(policyPath, []byte(testPolicy), 0644); err != nil {
+		t.Fatal(err)
+	}
+	policyManager, err := authentication.NewPolicyManager(policyPath)
+	if err != nil {
+		t.Fatal("Failed to load test policy: ", err)
+	}
+	jwtManager := authentication.NewJWTManager(authentication.StaticSecret("testSecret"), time.Minute)
+	interceptor := ServerAuthStruct{JwtManager: jwtManager, Policy: policyManager, Audience: "testservice"}
+
+	analystToken, _ := jwtManager.GenerateManager(&authentication.User{Username: "analyst", Roles: []string{"analyst"}}, nil, "")
+	guestToken, _ := jwtManager.GenerateManager(&authentication.User{Username: "guest", Roles: []string{"guest"}}, nil, "")
+
+	var Tests = []struct {
+		name           string
+		method         string
+		token          string
+		expectedCode   codes.Code
+		expectedCaller string
+	}{
+		{"Public streams don't need a token", "/Package/Public", "", codes.OK, ""},
+		{"Streams without a token are refused", "/Package/Stream", "", codes.Unauthenticated, ""},
+		{"Streams with an invalid token are refused", "/Package/Stream", "notAToken", codes.Unauthenticated, ""},
+		{"Callers without the required role are refused", "/Package/Stream", guestToken, codes.PermissionDenied, ""},
+		{"Authorised callers are passed on to the handler", "/Package/Stream", analystToken, codes.OK, "analyst"},
+	}
+
+	for _, test := range Tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.MD{})
+			if test.token != "" {
+				ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorisation", test.token))
+			}
+
+			handlerCalled := false
+			err := interceptor.ServerAuthStreamInterceptor(nil, &testServerStream{ctx: ctx}, &grpc.StreamServerInfo{FullMethod: test.method, IsServerStream: true}, func(srv interface{}, stream grpc.ServerStream) error {
+				handlerCalled = true
+				caller, ok := authentication.CallerFromContext(stream.Context())
+				if test.expectedCaller != "" && (!ok || caller.ID != test.expectedCaller) {
+					t.Error("Expected the handler's stream to carry caller ", test.expectedCaller, ", received ", caller)
+				}
+				return nil
+			})
+
+			if status.Code(err) != test.expectedCode {
+				t.Error("Expected ", test.expectedCode, ", received ", err)
+			}
+			if handlerCalled != (test.expectedCode == codes.OK) {
+				t.Error("Expected the handler to be called only for authorised streams")
+			}
+		})
+	}
+}
+
+func TestClientAuthStreamInterceptor(t *testing.T) {
+	interceptor := ClientAuthStruct{AccessToken: "aToken", APIKey: "aKey"}
+
+	_, err := interceptor.ClientAuthStreamInterceptor(context.Background(), &grpc.StreamDesc{ServerStreams: true}, nil, "/Package/Stream", func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		if len(md["authorisation"]) != 1 || md["authorisation"][0] != "aToken" || len(md["x-api-key"]) != 1 || md["x-api-key"][0] != "aKey" {
+			t.Error("Expected the credentials to be attached to the stream, received ", md)
+		}
+		return nil, nil
+	})
+	if err != nil {
+		t.Error("Expected no error, received ", err)
+	}
+}
+
+func TestRecoveryInterceptors(t *testing.T) {
+	t.Run("Panics while serving a call become Internal errors", func(t *testing.T) {
+		_, err := ServerRecoveryInterceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/Package/Unary"}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			panic("something went wrong")
+		})
+		if status.Code(err) != codes.Internal {
+			t.Error("Expected Internal, received ", err)
+		}
+	})
+
+	t.Run("Panics while serving a stream become Internal errors", func(t *testing.T) {
+		err := ServerRecoveryStreamInterceptor(nil, &testServerStream{ctx: context.Background()}, &grpc.StreamServerInfo{FullMethod: "/Package/Stream"}, func(srv interface{}, stream grpc.ServerStream) error {
+			panic("something went wrong")
+		})
+		if status.Code(err) != codes.Internal {
+			t.Error("Expected Internal, received ", err)
+		}
+	})
+
+	t.Run("Errors are passed on untouched", func(t *testing.T) {
+		err := ServerRecoveryStreamInterceptor(nil, &testServerStream{ctx: context.Background()}, &grpc.StreamServerInfo{FullMethod: "/Package/Stream"}, func(srv interface{}, stream grpc.ServerStream) error {
+			return status.Error(codes.NotFound, "not found")
+		})
+		if status.Code(err) != codes.NotFound {
+			t.Error("Expected NotFound, received ", err)
+		}
+	})
+}
+
+func TestStreamType(t *testing.T) {
+	var Tests = []struct {
+		name           string
+		clientStreams  bool
+		serverStreams  bool
+		expectedOutput string
+	}{
+		{"Server streams", false, true, "server_stream"},
+		{"Client streams", true, false, "client_stream"},
+		{"Bidirectional streams", true, true, "bidi_stream"},
+	}
+
+	for _, test := range Tests {
+		t.Run(test.name, func(t *testing.T) {
+			if output := streamType(test.clientStreams, test.serverStreams); output != test.expectedOutput {
+				t.Errorf("Expected %q, received %q", test.expectedOutput, output)
+			}
+		})
+	}
+}
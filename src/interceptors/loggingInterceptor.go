@@ -0,0 +1,111 @@
+package interceptors
+
+import (
+	// Native packages
+	"context"
+	"time"
+
+	// Required packages
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+
+	// gRPC packages
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	// Personal packages
+	"github.com/nicholasbunn/mastersSandbox/src/logging"
+)
+
+/* The logging interceptors log every call made and served, along with how long it took and
+the status it ended with. Calls that fail are logged as warnings, so that the log shows which
+calls failed without having to match them up with the service's own messages. The server
+interceptors start the request context of every call served (see logging.NewRequestContext),
+so that every line logged while serving it carries its request ID, method and trace ID. They
+have to come after the tracing and request ID interceptors in a chain for the trace ID and
+request ID to be known */
+
+func ClientLoggingInterceptor(ctx context.Context, method string, req interface{}, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	// Client side interceptor, to be attached to all client connections
+	start := time.Now()
+
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	logCall(ctx, "Called", method, cc.Target(), start, err)
+
+	return err
+}
+
+func ClientLoggingStreamInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	// Client side stream interceptor, to be attached to all client connections. Only the opening of the stream is logged
+	start := time.Now()
+
+	stream, err := streamer(ctx, desc, cc, method, opts...)
+	logCall(ctx, "Opened stream", method, cc.Target(), start, err)
+
+	return stream, err
+}
+
+func ServerLoggingInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	// Server-side interceptor, to be attached to all server connections
+	start := time.Now()
+	ctx = newRequestContext(ctx, info.FullMethod)
+
+	h, err := handler(ctx, req)
+	logCall(ctx, "Served", info.FullMethod, "", start, err)
+
+	return h, err
+}
+
+func ServerLoggingStreamInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	// Server-side stream interceptor, to be attached to all server connections. The stream is logged once it has been served
+	start := time.Now()
+	wrappedStream := grpc_middleware.WrapServerStream(stream)
+	wrappedStream.WrappedContext = newRequestContext(stream.Context(), info.FullMethod)
+
+	err := handler(srv, wrappedStream)
+	logCall(wrappedStream.WrappedContext, "Served stream", info.FullMethod, "", start, err)
+
+	return err
+}
+
+// ________SUPPORTING FUNCTIONS________
+
+func newRequestContext(ctx context.Context, method string) context.Context {
+	/* This (unexported) function starts the request context of a call being served, with the
+	call's request ID (see the request ID interceptors), or a new one if it has none */
+	requestID := RequestIDFromContext(ctx)
+	if requestID == "" {
+		requestID = logging.NewRequestID()
+	}
+	fields := logrus.Fields{
+		logging.FieldRequestID: requestID,
+		logging.FieldMethod:    method,
+	}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		fields[logging.FieldTraceID] = spanContext.TraceID().String()
+	}
+
+	return logging.NewRequestContext(ctx, fields)
+}
+
+func logCall(ctx context.Context, action string, method string, target string, start time.Time, err error) {
+	/* This (unexported) function logs a finished call, as a warning if it failed. Health checks
+	are made every few seconds by probes, so they are only logged at debug level unless they fail */
+	probe := isHealthMethod(method)
+	if target != "" {
+		method = method + " on " + target
+	}
+	code, duration := status.Code(err), time.Since(start)
+	logger := logging.FromContext(ctx).WithFields(logrus.Fields{"grpc_code": code.String(), "duration": duration.String()})
+
+	if err != nil {
+		logger.Warnf("%s %s in %v: %s (%v)", action, method, duration, code, status.Convert(err).Message())
+		return
+	}
+	if probe {
+		logger.Debugf("%s %s in %v: %s", action, method, duration, code)
+		return
+	}
+	logger.Infof("%s %s in %v: %s", action, method, duration, code)
+}
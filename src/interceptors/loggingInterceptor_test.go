@@ -0,0 +1,75 @@
+package interceptors
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	authentication "github.com/nicholasbunn/mastersSandbox/src/authenticationStuff"
+	"github.com/nicholasbunn/mastersSandbox/src/logging"
+)
+
+func TestLoggingRequestFields(t *testing.T) {
+	recordSpans(t)
+	buffer := &bytes.Buffer{}
+	previousOutput, previousFormatter := logging.Logger.Out, logging.Logger.Formatter
+	logging.Logger.SetOutput(buffer)
+	logging.Logger.SetFormatter(&logrus.JSONFormatter{})
+	t.Cleanup(func() {
+		logging.Logger.SetOutput(previousOutput)
+		logging.Logger.SetFormatter(previousFormatter)
+	})
+
+	policyPath := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := ioutil.WriteFile(policyPath, []byte(testPolicy), 0644); err != nil {
+		t.Fatal(err)
+	}
+	policyManager, err := authentication.NewPolicyManager(policyPath)
+	if err != nil {
+		t.Fatal("Failed to load test policy: ", err)
+	}
+	jwtManager := authentication.NewJWTManager(authentication.StaticSecret("testSecret"), time.Minute)
+	authInterceptor := ServerAuthStruct{JwtManager: jwtManager, Policy: policyManager, Audience: "testservice"}
+	token, _ := jwtManager.GenerateManager(&authentication.User{Username: "analyst", Roles: []string{"analyst"}}, nil, "")
+
+	// The chain is the one the services use: tracing, then logging, then authentication
+	chain := grpc_middleware.ChainUnaryServer(ServerTracingInterceptor, ServerLoggingInterceptor, authInterceptor.ServerAuthInterceptor)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorisation", token))
+	_, err = chain(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/Package/Unary"}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		logging.FromContext(ctx).Infoln("Handling the call")
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatal("Expected the call to be served, received ", err)
+	}
+
+	var requestID string
+	for _, message := range []string{"Handling the call", "Served /Package/Unary"} {
+		var line map[string]interface{}
+		for _, text := range strings.Split(strings.TrimSpace(buffer.String()), "\n") {
+			if strings.Contains(text, message) {
+				json.Unmarshal([]byte(text), &line)
+			}
+		}
+		if line == nil {
+			t.Fatalf("Expected a line for %q, received:\n%s", message, buffer)
+		}
+		if line[logging.FieldUser] != "analyst" || line[logging.FieldMethod] != "/Package/Unary" || line[logging.FieldTraceID] == nil || line[logging.FieldRequestID] == nil {
+			t.Errorf("Expected %q to carry the call's fields, received %v", message, line)
+		}
+		if requestID != "" && line[logging.FieldRequestID] != requestID {
+			t.Error("Expected the lines of a call to share its request ID")
+		}
+		requestID, _ = line[logging.FieldRequestID].(string)
+	}
+}
@@ -0,0 +1,168 @@
+package interceptors
+
+import (
+	// Native packages
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	// Required packages
+	prometheus "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/push"
+
+	// Personal packages
+	"github.com/nicholasbunn/mastersSandbox/src/logging"
+)
+
+/* A service's metrics are kept on a registry of their own, which Prometheus scrapes from the
+service's /metrics endpoint. Pushing to the pushgateway is optional and happens in the
+background: the interceptors only mark the metrics as changed, and changed metrics are pushed
+together at most once per push interval. While the pushgateway is unreachable the interval
+backs off, so exporting metrics never slows down (or fails) the calls being measured. Every
+metric carries a service label, and the instance label is added by Prometheus when it scrapes
+the service (or by the pushgateway, from the grouping key the metrics were pushed under), so
+that the process the metrics came from can be told apart from the other replicas */
+
+// pushTimeout is how long a push may take, so that an unresponsive pushgateway can't hold up the push loop
+const pushTimeout = 10 * time.Second
+
+type MetricExporter struct {
+	/* This struct holds the metrics registry of a service and exports it, over HTTP and (if
+	started) by pushing it to the pushgateway under the service's job */
+	Job        string                // The job the metrics are pushed under, normally the service's name
+	Instance   string                // The process the metrics come from, normally the host name
+	Registry   *prometheus.Registry  // The registry the service's metrics are gathered from
+	Registerer prometheus.Registerer // Registers metrics on the registry, adding the service label
+
+	changed int32 // Set (atomically) when the metrics have changed since they were last pushed
+}
+
+func NewMetricExporter(job string) *MetricExporter {
+	/* This function creates an exporter with a new registry for the provided job, identifying
+	the process by its host name. The registry starts out with the Go runtime and process
+	metrics of the service */
+	instance, err := os.Hostname()
+	if err != nil || instance == "" {
+		logging.Logger.Warnln("Could not determine the host name, the metrics' instance will be unknown: ", err)
+		instance = "unknown"
+	}
+
+	registry := prometheus.NewRegistry()
+	registerer := prometheus.WrapRegistererWith(prometheus.Labels{"service": job}, registry)
+	registerer.MustRegister(
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+	)
+
+	return &MetricExporter{Job: job, Instance: instance, Registry: registry, Registerer: registerer}
+}
+
+func (exporter *MetricExporter) Changed() {
+	// This function marks the metrics as changed, so that they are included in the next push
+	if exporter != nil {
+		atomic.StoreInt32(&exporter.changed, 1)
+	}
+}
+
+func (exporter *MetricExporter) Handler() http.Handler {
+	// This function returns a handler that serves the registry's metrics in the Prometheus exposition format, or OpenMetrics (with exemplars) if the scraper asks for it
+	return promhttp.HandlerFor(exporter.Registry, promhttp.HandlerOpts{ErrorLog: logging.Logger, EnableOpenMetrics: true})
+}
+
+func (exporter *MetricExporter) Serve(address string) (stop func(), err error) {
+	/* This function serves the metrics at /metrics on the provided address, in the
+	background, along with the log level at /loglevel (see logging.LevelHandler). It returns a function that stops the server, or an error if the address
+	can't be listened on */
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", exporter.Handler())
+	mux.Handle("/loglevel", logging.LevelHandler())
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			logging.Logger.Errorln("Metrics endpoint stopped: \n", err)
+		}
+	}()
+	logging.Logger.Infoln("Serving metrics on ", listener.Addr().String(), "/metrics")
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(ctx)
+	}, nil
+}
+
+func (exporter *MetricExporter) StartPushing(address string, interval time.Duration, maxBackoff time.Duration) (stop func()) {
+	/* This function pushes the metrics to the pushgateway at the provided address in the
+	background. The metrics are pushed once when the loop starts and then every interval,
+	if they have changed. Failed pushes are retried with an interval that doubles up to
+	maxBackoff. The returned function makes a last push of any changes and stops the loop */
+	atomic.StoreInt32(&exporter.changed, 1)
+	stopping := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		wait := interval
+		for {
+			if atomic.SwapInt32(&exporter.changed, 0) == 1 {
+				if err := exporter.push(address); err != nil {
+					// Keep the changes for the next attempt, and back off while the pushgateway is unreachable
+					atomic.StoreInt32(&exporter.changed, 1)
+					wait *= 2
+					if wait > maxBackoff {
+						wait = maxBackoff
+					}
+					logging.Logger.Warnf("Could not push metrics to %v, retrying in %v: %v", address, wait, err)
+				} else {
+					wait = interval
+				}
+			}
+
+			timer := time.NewTimer(wait)
+			select {
+			case <-timer.C:
+			case <-stopping:
+				timer.Stop()
+				if atomic.SwapInt32(&exporter.changed, 0) == 1 {
+					if err := exporter.push(address); err != nil {
+						logging.Logger.Warnln("Could not push metrics before stopping: ", err)
+					}
+				}
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(stopping)
+			<-done
+		})
+	}
+}
+
+func (exporter *MetricExporter) push(address string) error {
+	/* This (unexported) function pushes every metric on the registry to the pushgateway,
+	replacing the metrics this instance of the job pushed before */
+	err := push.New(address, exporter.Job).
+		Grouping("instance", exporter.Instance).
+		Client(&http.Client{Timeout: pushTimeout}).
+		Gatherer(exporter.Registry).
+		Push()
+	if err == nil {
+		logging.Logger.Debugln("Succesfully pushed metrics to ", address)
+	}
+
+	return err
+}
@@ -0,0 +1,106 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+func TestMetricExporterHandler(t *testing.T) {
+	exporter := NewMetricExporter("TestService")
+	metrics := NewServerMetrics(exporter)
+
+	_, err := metrics.ServerMetricInterceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/Package/Unary"}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatal("Expected no error, received ", err)
+	}
+
+	recorder := httptest.NewRecorder()
+	exporter.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := recorder.Body.String()
+
+	for _, expected := range []string{
+		`server_request_counter{grpc_method="Unary",grpc_service="Package",grpc_type="unary",service="TestService"} 1`,
+		`server_response_counter{grpc_code="OK",grpc_method="Unary",grpc_service="Package",grpc_type="unary",service="TestService"} 1`,
+		`server_in_flight{grpc_method="Unary",grpc_service="Package",grpc_type="unary",service="TestService"} 0`,
+		`go_goroutines{service="TestService"}`,
+	} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("Expected the metrics to contain %q, received:\n%v", expected, body)
+		}
+	}
+}
+
+func TestMetricExporterPush(t *testing.T) {
+	t.Run("Changes are pushed together, and pushed once more when stopping", func(t *testing.T) {
+		exporter := NewMetricExporter("TestService")
+		var pushes int32
+		pushgateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.Contains(r.URL.Path, "/job/TestService/instance/"+exporter.Instance) {
+				atomic.AddInt32(&pushes, 1)
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer pushgateway.Close()
+
+		stop := exporter.StartPushing(pushgateway.URL, time.Hour, time.Hour)
+		for deadline := time.Now().Add(2 * time.Second); atomic.LoadInt32(&pushes) == 0; time.Sleep(time.Millisecond) {
+			if time.Now().After(deadline) {
+				t.Fatal("The metrics were not pushed when the loop started")
+			}
+		}
+		for i := 0; i < 10; i++ {
+			exporter.Changed()
+		}
+		stop()
+
+		if received := atomic.LoadInt32(&pushes); received != 2 {
+			t.Error("Expected the initial push and a final push, received ", received, " pushes")
+		}
+	})
+
+	t.Run("Pushes back off while the pushgateway fails", func(t *testing.T) {
+		var attempts int32
+		pushgateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&attempts, 1)
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer pushgateway.Close()
+
+		exporter := NewMetricExporter("TestService")
+		stop := exporter.StartPushing(pushgateway.URL, 10*time.Millisecond, 80*time.Millisecond)
+		time.Sleep(300 * time.Millisecond)
+		stop()
+
+		// Without backing off there would be about 30 attempts, with it there are about 6 (and one more when stopping)
+		if received := atomic.LoadInt32(&attempts); received < 2 || received > 12 {
+			t.Error("Expected the attempts to back off, received ", received, " attempts")
+		}
+	})
+
+	t.Run("Calls are unaffected while the pushgateway is unreachable", func(t *testing.T) {
+		exporter := NewMetricExporter("TestService")
+		metrics := NewServerMetrics(exporter)
+		stop := exporter.StartPushing("127.0.0.1:1", 10*time.Millisecond, 10*time.Millisecond)
+		defer stop()
+
+		handlerErr := errors.New("handler failed")
+		for _, expected := range []error{nil, handlerErr} {
+			_, err := metrics.ServerMetricInterceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/Package/Unary"}, func(ctx context.Context, req interface{}) (interface{}, error) {
+				return nil, expected
+			})
+			if err != expected {
+				t.Error("Expected the handler's result ", expected, ", received ", err)
+			}
+		}
+	})
+}
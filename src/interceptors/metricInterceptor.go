@@ -0,0 +1,473 @@
+package interceptors
+
+import (
+	// Native packages
+	"context"
+	"io"
+	"time"
+
+	// Required packages
+	prometheus "github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/proto"
+
+	// gRPC packages
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	// Personal packages
+	"github.com/nicholasbunn/mastersSandbox/src/logging"
+)
+
+/* Every call is counted when it starts and again when it finishes, by the status code it
+finished with, so failed calls show up as responses with a code other than OK (and in the
+error counter). Calls that have started but not finished are tracked by the in-flight gauges.
+A stream counts as a single call that finishes when it is closed, while the size of every
+message sent and received on it is recorded. Latencies carry the request ID and trace ID of the
+call as an exemplar, so that a slow call seen in the metrics can be looked up in the logs and
+traces (exemplars are only served in the OpenMetrics format) */
+
+var (
+	// latencyBuckets (in seconds) cover quick lookups as well as long-running estimations
+	latencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120}
+	// sizeBuckets (in bytes) range from a message with a few fields to a 16 MB batch of data
+	sizeBuckets = prometheus.ExponentialBuckets(64, 4, 10)
+)
+
+type ClientMetricStruct struct {
+	/* This struct represents a collection of client-side metrics to be registered on a
+	Prometheus metrics registry. The metrics are exported by the service's exporter */
+	exporter                  *MetricExporter
+	clientRequestCounter      *prometheus.CounterVec   // Counts the number of call made by the client
+	clientResponseCounter     *prometheus.CounterVec   // Counts the number of calls that have finished, by status code
+	clientErrorCounter        *prometheus.CounterVec   // Counts the number of calls that failed, by status code
+	clientInFlight            *prometheus.GaugeVec     // Records the number of calls that have started but not finished
+	clientRequestLatency      *prometheus.HistogramVec // Records the amount of time the calls took, as seen by the client
+	clientRequestMessageSize  *prometheus.HistogramVec // Records the size of the request message sent out
+	clientResponseMessageSize *prometheus.HistogramVec // Records the size of the response message received
+}
+
+type ServerMetricStruct struct {
+	/* This struct represents a collection of server-side metrics to be reqistered on a
+	Prometheus metrics registry. The metrics are exported by the service's exporter */
+	exporter                  *MetricExporter
+	serverRequestCounter      *prometheus.CounterVec   // Counts the number of requests received by the server
+	serverResponseCounter     *prometheus.CounterVec   // Counts the number of responses sent by the server, by status code
+	serverErrorCounter        *prometheus.CounterVec   // Counts the number of requests that failed, by status code
+	serverInFlight            *prometheus.GaugeVec     // Records the number of requests being served
+	serverLastCallTime        *prometheus.GaugeVec     // Records the lat time a call was made to the server
+	serverRequestLatency      *prometheus.HistogramVec // Records the amount of time the server took to serve the call
+	serverRequestMessageSize  *prometheus.HistogramVec // Records the size of the request message received
+	serverResponseMessageSize *prometheus.HistogramVec // Records the size of the response message sent
+}
+
+type LoginMetricStruct struct {
+	/* This struct represents a collection of login metrics to be registered on a
+	Prometheus metrics registry. The metrics are exported by the service's exporter */
+	exporter            *MetricExporter
+	loginFailureCounter *prometheus.CounterVec // Counts the number of rejected logins, by reason
+	lockoutCounter      *prometheus.CounterVec // Counts the number of lockouts triggered, by what was locked out
+}
+
+func NewClientMetrics(exporter *MetricExporter) *ClientMetricStruct {
+	// This function creates the client-side metrics of a service and registers them on the provided exporter's registry
+	metrics := &ClientMetricStruct{
+		exporter: exporter,
+		clientRequestCounter: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "client_request_counter",
+				Help: "The number of requests made by the client",
+			}, []string{"grpc_type", "grpc_service", "grpc_method"}),
+		clientResponseCounter: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "client_response_counter",
+				Help: "The number of calls made by the client that have finished, by status code",
+			}, []string{"grpc_type", "grpc_service", "grpc_method", "grpc_code"}),
+		clientErrorCounter: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "client_error_counter",
+				Help: "The number of calls made by the client that failed, by status code",
+			}, []string{"grpc_type", "grpc_service", "grpc_method", "grpc_code"}),
+		clientInFlight: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "client_in_flight",
+				Help: "The number of calls made by the client that haven't finished yet",
+			}, []string{"grpc_type", "grpc_service", "grpc_method"}),
+		clientRequestLatency: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "client_request_latency",
+				Help:    "The time (in seconds) it took for a call to finish, as seen by the client",
+				Buckets: latencyBuckets,
+			}, []string{"grpc_type", "grpc_service", "grpc_method"}),
+		clientRequestMessageSize: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "client_request_size",
+				Help:    "The size (in bytes) of the request sent by the client",
+				Buckets: sizeBuckets,
+			}, []string{"grpc_type", "grpc_service", "grpc_method"}),
+		clientResponseMessageSize: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "client_response_size",
+				Help:    "The size (in bytes) of the response received by the client",
+				Buckets: sizeBuckets,
+			}, []string{"grpc_type", "grpc_service", "grpc_method"}),
+	}
+	exporter.Registerer.MustRegister(
+		metrics.clientRequestCounter,
+		metrics.clientResponseCounter,
+		metrics.clientErrorCounter,
+		metrics.clientInFlight,
+		metrics.clientRequestLatency,
+		metrics.clientRequestMessageSize,
+		metrics.clientResponseMessageSize,
+	)
+
+	return metrics
+}
+
+func NewServerMetrics(exporter *MetricExporter) *ServerMetricStruct {
+	// This function creates the server-side metrics of a service and registers them on the provided exporter's registry
+	metrics := &ServerMetricStruct{
+		exporter: exporter,
+		serverRequestCounter: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "server_request_counter",
+				Help: "The number of requests made to the server",
+			}, []string{"grpc_type", "grpc_service", "grpc_method"}),
+		serverResponseCounter: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "server_response_counter",
+				Help: "The number of response sent by the server, by status code",
+			}, []string{"grpc_type", "grpc_service", "grpc_method", "grpc_code"}),
+		serverErrorCounter: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "server_error_counter",
+				Help: "The number of requests to the server that failed, by status code",
+			}, []string{"grpc_type", "grpc_service", "grpc_method", "grpc_code"}),
+		serverInFlight: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "server_in_flight",
+				Help: "The number of requests being served",
+			}, []string{"grpc_type", "grpc_service", "grpc_method"}),
+		serverLastCallTime: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "server_last_call_time",
+				Help: "The last time a call was made to the server",
+			}, []string{"grpc_type", "grpc_service", "grpc_method"}),
+		serverRequestLatency: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "server_request_latency",
+				Help:    "The time (in seconds) it took for the server to serve the request",
+				Buckets: latencyBuckets,
+			}, []string{"grpc_type", "grpc_service", "grpc_method"}),
+		serverRequestMessageSize: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "server_request_size",
+				Help:    "The size (in bytes) of the request received by the server",
+				Buckets: sizeBuckets,
+			}, []string{"grpc_type", "grpc_service", "grpc_method"}),
+		serverResponseMessageSize: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "server_response_size",
+				Help:    "The size (in bytes) of the response sent by the server",
+				Buckets: sizeBuckets,
+			}, []string{"grpc_type", "grpc_service", "grpc_method"}),
+	}
+	exporter.Registerer.MustRegister(
+		metrics.serverRequestCounter,
+		metrics.serverResponseCounter,
+		metrics.serverErrorCounter,
+		metrics.serverInFlight,
+		metrics.serverLastCallTime,
+		metrics.serverRequestLatency,
+		metrics.serverRequestMessageSize,
+		metrics.serverResponseMessageSize,
+	)
+
+	return metrics
+}
+
+func NewLoginMetrics(exporter *MetricExporter) *LoginMetricStruct {
+	// This function creates the login metrics of a service and registers them on the provided exporter's registry
+	metrics := &LoginMetricStruct{
+		exporter: exporter,
+		loginFailureCounter: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "login_failure_counter",
+				Help: "The number of rejected login attempts",
+			}, []string{"reason"}),
+		lockoutCounter: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "login_lockout_counter",
+				Help: "The number of lockouts triggered by repeated login failures",
+			}, []string{"target"}),
+	}
+	exporter.Registerer.MustRegister(metrics.loginFailureCounter, metrics.lockoutCounter)
+
+	return metrics
+}
+
+func (metr *ClientMetricStruct) ClientMetricInterceptor(ctx context.Context, method string, req interface{}, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	// Client side interceptor, to be attached to all client connections
+
+	logging.FromContext(ctx).Debugln("Starting client interceptor method")
+
+	// Start the call and record the request size
+	labels := callLabels("unary", method)
+	start := metr.started(labels)
+	metr.clientRequestMessageSize.With(labels).Observe(float64(messageSize(req)))
+
+	// Run gRPC call here
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	if err != nil {
+		logging.FromContext(ctx).Errorln("Failed to make service call from client-side metric interceptor: \n", err)
+	} else {
+		metr.clientResponseMessageSize.With(labels).Observe(float64(messageSize(reply)))
+	}
+
+	// Finish the call, the metrics are pushed in the background
+	metr.finished(ctx, labels, start, err)
+
+	return err
+}
+
+func (metr *ClientMetricStruct) ClientMetricStreamInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	/* Client side stream interceptor, to be attached to all client connections. The call
+	finishes once a message can no longer be received on the stream, with io.EOF counting as
+	OK, so streams that aren't read to the end stay in flight */
+
+	logging.FromContext(ctx).Debugln("Starting client stream interceptor method")
+
+	// Start the call
+	labels := callLabels(clientStreamType(desc), method)
+	start := metr.started(labels)
+
+	// Open the stream here
+	stream, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		logging.FromContext(ctx).Errorln("Failed to open stream from client-side metric interceptor: \n", err)
+		metr.finished(ctx, labels, start, err)
+		return nil, err
+	}
+
+	return &monitoredClientStream{ClientStream: stream, ctx: ctx, metrics: metr, labels: labels, start: start}, nil
+}
+
+func (metr *ServerMetricStruct) ServerMetricInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	// Server-side interceptor, to be attached to all server connections
+
+	logging.FromContext(ctx).Debugln("Starting server interceptor method")
+
+	// Start the call and record the request size
+	labels := callLabels("unary", info.FullMethod)
+	start := metr.started(labels)
+	metr.serverRequestMessageSize.With(labels).Observe(float64(messageSize(req)))
+
+	// Run gRPC call here
+	h, err := handler(ctx, req)
+	if err != nil {
+		logging.FromContext(ctx).Errorln("Failed to make service call from server-side metric interceptor: \n", err)
+	} else {
+		metr.serverResponseMessageSize.With(labels).Observe(float64(messageSize(h)))
+	}
+
+	// Finish the call, the metrics are pushed in the background
+	metr.finished(ctx, labels, start, err)
+
+	return h, err
+}
+
+func (metr *ServerMetricStruct) ServerMetricStreamInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	/* Server-side stream interceptor, to be attached to all server connections. The latency
+	is the time the stream was open for */
+
+	logging.FromContext(stream.Context()).Debugln("Starting server stream interceptor method")
+
+	// Start the call
+	labels := callLabels(serverStreamType(info), info.FullMethod)
+	start := metr.started(labels)
+
+	// Serve the stream here, recording the size of the messages sent and received on it
+	err := handler(srv, &monitoredServerStream{ServerStream: stream, metrics: metr, labels: labels})
+	if err != nil {
+		logging.FromContext(stream.Context()).Errorln("Failed to serve stream from server-side metric interceptor: \n", err)
+	}
+
+	// Finish the call, the metrics are pushed in the background
+	metr.finished(stream.Context(), labels, start, err)
+
+	return err
+}
+
+func (metr *LoginMetricStruct) RecordLoginFailure(reason string) {
+	// This function counts a rejected login ("invalid_credentials" or "throttled")
+	metr.loginFailureCounter.With(prometheus.Labels{"reason": reason}).Inc()
+	metr.exporter.Changed()
+}
+
+func (metr *LoginMetricStruct) RecordLockout(target string) {
+	// This function counts a lockout of a "username" or an "address"
+	metr.lockoutCounter.With(prometheus.Labels{"target": target}).Inc()
+	metr.exporter.Changed()
+}
+
+func (metr *ClientMetricStruct) started(labels prometheus.Labels) time.Time {
+	// This (unexported) function counts a call made by the client and returns the time it started
+	metr.clientRequestCounter.With(labels).Inc()
+	metr.clientInFlight.With(labels).Inc()
+
+	return time.Now()
+}
+
+func (metr *ClientMetricStruct) finished(ctx context.Context, labels prometheus.Labels, start time.Time, err error) {
+	// This (unexported) function counts a call made by the client as finished, with the status code of the provided error
+	metr.clientInFlight.With(labels).Dec()
+	observeLatency(ctx, metr.clientRequestLatency.With(labels), start)
+
+	codeLabels := withCode(labels, err)
+	metr.clientResponseCounter.With(codeLabels).Inc()
+	if err != nil {
+		metr.clientErrorCounter.With(codeLabels).Inc()
+	}
+
+	metr.exporter.Changed()
+}
+
+func (metr *ServerMetricStruct) started(labels prometheus.Labels) time.Time {
+	// This (unexported) function counts a request to the server and returns the time it started
+	metr.serverRequestCounter.With(labels).Inc()
+	metr.serverInFlight.With(labels).Inc()
+	metr.serverLastCallTime.With(labels).SetToCurrentTime()
+
+	return time.Now()
+}
+
+func (metr *ServerMetricStruct) finished(ctx context.Context, labels prometheus.Labels, start time.Time, err error) {
+	// This (unexported) function counts a request to the server as served, with the status code of the provided error
+	metr.serverInFlight.With(labels).Dec()
+	observeLatency(ctx, metr.serverRequestLatency.With(labels), start)
+
+	codeLabels := withCode(labels, err)
+	metr.serverResponseCounter.With(codeLabels).Inc()
+	if err != nil {
+		metr.serverErrorCounter.With(codeLabels).Inc()
+	}
+
+	metr.exporter.Changed()
+}
+
+type monitoredClientStream struct {
+	/* This (unexported) struct wraps a client stream to measure the messages sent and
+	received on it, and to finish the call once the stream has ended */
+	grpc.ClientStream
+	ctx     context.Context
+	metrics *ClientMetricStruct
+	labels  prometheus.Labels
+	start   time.Time
+	ended   bool
+}
+
+func (stream *monitoredClientStream) SendMsg(m interface{}) error {
+	// This function records the size of every message sent on the stream
+	err := stream.ClientStream.SendMsg(m)
+	if err == nil {
+		stream.metrics.clientRequestMessageSize.With(stream.labels).Observe(float64(messageSize(m)))
+	}
+
+	return err
+}
+
+func (stream *monitoredClientStream) RecvMsg(m interface{}) error {
+	/* This function records the size of every message received on the stream, and finishes
+	the call once the stream has ended (io.EOF or an error) */
+	err := stream.ClientStream.RecvMsg(m)
+	if err == nil {
+		stream.metrics.clientResponseMessageSize.With(stream.labels).Observe(float64(messageSize(m)))
+		return nil
+	}
+
+	if !stream.ended {
+		stream.ended = true
+		if err == io.EOF {
+			stream.metrics.finished(stream.ctx, stream.labels, stream.start, nil)
+		} else {
+			stream.metrics.finished(stream.ctx, stream.labels, stream.start, err)
+		}
+	}
+
+	return err
+}
+
+type monitoredServerStream struct {
+	// This (unexported) struct wraps a server stream to measure the messages sent and received on it
+	grpc.ServerStream
+	metrics *ServerMetricStruct
+	labels  prometheus.Labels
+}
+
+func (stream *monitoredServerStream) SendMsg(m interface{}) error {
+	// This function records the size of every message sent on the stream
+	err := stream.ServerStream.SendMsg(m)
+	if err == nil {
+		stream.metrics.serverResponseMessageSize.With(stream.labels).Observe(float64(messageSize(m)))
+	}
+
+	return err
+}
+
+func (stream *monitoredServerStream) RecvMsg(m interface{}) error {
+	// This function records the size of every message received on the stream
+	err := stream.ServerStream.RecvMsg(m)
+	if err == nil {
+		stream.metrics.serverRequestMessageSize.With(stream.labels).Observe(float64(messageSize(m)))
+	}
+
+	return err
+}
+
+// ________SUPPORTING FUNCTIONS________
+
+func callLabels(grpcType string, fullMethod string) prometheus.Labels {
+	// This (unexported) function returns the labels identifying a call of the provided type to the provided method
+	serviceName, serviceMethod := splitMethod(fullMethod)
+
+	return prometheus.Labels{"grpc_type": grpcType, "grpc_service": serviceName, "grpc_method": serviceMethod}
+}
+
+func observeLatency(ctx context.Context, observer prometheus.Observer, start time.Time) {
+	// This (unexported) function records the latency of a call, with the call's request ID and trace ID as an exemplar
+	exemplar := prometheus.Labels{}
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		exemplar["request_id"] = requestID
+	}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		exemplar["trace_id"] = spanContext.TraceID().String()
+	}
+
+	exemplarObserver, ok := observer.(prometheus.ExemplarObserver)
+	if !ok || len(exemplar) == 0 {
+		observer.Observe(time.Since(start).Seconds())
+		return
+	}
+	exemplarObserver.ObserveWithExemplar(time.Since(start).Seconds(), exemplar)
+}
+
+func withCode(labels prometheus.Labels, err error) prometheus.Labels {
+	// This (unexported) function returns a copy of the provided labels with the status code of the provided error added
+	codeLabels := prometheus.Labels{"grpc_code": status.Code(err).String()}
+	for name, value := range labels {
+		codeLabels[name] = value
+	}
+
+	return codeLabels
+}
+
+func messageSize(message interface{}) int {
+	/* This function returns the size (in bytes) of a gRPC message as it is sent on the wire.
+	Anything that isn't a protobuf message is counted as empty */
+	if protoMessage, ok := message.(proto.Message); ok {
+		return proto.Size(protoMessage)
+	}
+
+	return 0
+}
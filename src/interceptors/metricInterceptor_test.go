@@ -0,0 +1,143 @@
+package interceptors
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	prometheus "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type testClientStream struct {
+	// This struct is a client stream that ends with the provided error after sending a single message
+	grpc.ClientStream
+	sent bool
+	end  error
+}
+
+func (stream *testClientStream) RecvMsg(m interface{}) error {
+	if stream.sent {
+		return stream.end
+	}
+	stream.sent = true
+	return nil
+}
+
+func TestServerMetricInterceptor(t *testing.T) {
+	var Tests = []struct {
+		name         string
+		handlerErr   error
+		expectedCode string
+	}{
+		{"Successful calls are counted as OK", nil, "OK"},
+		{"Failed calls are counted with their status code", status.Error(codes.NotFound, "not found"), "NotFound"},
+		{"Errors without a status are counted as Unknown", io.ErrUnexpectedEOF, "Unknown"},
+	}
+
+	for _, test := range Tests {
+		t.Run(test.name, func(t *testing.T) {
+			metrics := NewServerMetrics(NewMetricExporter("TestService"))
+			labels := prometheus.Labels{"grpc_type": "unary", "grpc_service": "Package", "grpc_method": "Unary"}
+			codeLabels := withCode(labels, test.handlerErr)
+
+			_, err := metrics.ServerMetricInterceptor(context.Background(), wrapperspb.String("hello"), &grpc.UnaryServerInfo{FullMethod: "/Package/Unary"}, func(ctx context.Context, req interface{}) (interface{}, error) {
+				if inFlight := testutil.ToFloat64(metrics.serverInFlight.With(labels)); inFlight != 1 {
+					t.Error("Expected the call to be in flight while it is served, received ", inFlight)
+				}
+				return wrapperspb.String("hello, world"), test.handlerErr
+			})
+			if err != test.handlerErr {
+				t.Error("Expected the handler's error ", test.handlerErr, ", received ", err)
+			}
+
+			if code := codeLabels["grpc_code"]; code != test.expectedCode {
+				t.Errorf("Expected the code %q, received %q", test.expectedCode, code)
+			}
+			if responses := testutil.ToFloat64(metrics.serverResponseCounter.With(codeLabels)); responses != 1 {
+				t.Error("Expected one response, received ", responses)
+			}
+			expectedErrors := 1.0
+			if test.handlerErr == nil {
+				expectedErrors = 0
+			}
+			if errors := testutil.CollectAndCount(metrics.serverErrorCounter); float64(errors) != expectedErrors {
+				t.Error("Expected ", expectedErrors, " error series, received ", errors)
+			}
+			if inFlight := testutil.ToFloat64(metrics.serverInFlight.With(labels)); inFlight != 0 {
+				t.Error("Expected no calls in flight after the call, received ", inFlight)
+			}
+			if latencies := testutil.CollectAndCount(metrics.serverRequestLatency); latencies != 1 {
+				t.Error("Expected the latency to be recorded, received ", latencies, " series")
+			}
+		})
+	}
+}
+
+func TestClientMetricStreamInterceptor(t *testing.T) {
+	var Tests = []struct {
+		name         string
+		end          error
+		expectedCode string
+	}{
+		{"Streams that end with io.EOF are counted as OK", io.EOF, "OK"},
+		{"Streams that end with an error are counted with its status code", status.Error(codes.Unavailable, "unavailable"), "Unavailable"},
+	}
+
+	for _, test := range Tests {
+		t.Run(test.name, func(t *testing.T) {
+			metrics := NewClientMetrics(NewMetricExporter("TestService"))
+			labels := prometheus.Labels{"grpc_type": "server_stream", "grpc_service": "Package", "grpc_method": "Stream"}
+
+			stream, err := metrics.ClientMetricStreamInterceptor(context.Background(), &grpc.StreamDesc{ServerStreams: true}, nil, "/Package/Stream", func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+				return &testClientStream{end: test.end}, nil
+			})
+			if err != nil {
+				t.Fatal("Expected no error, received ", err)
+			}
+
+			for stream.RecvMsg(wrapperspb.String("")) == nil {
+				if inFlight := testutil.ToFloat64(metrics.clientInFlight.With(labels)); inFlight != 1 {
+					t.Error("Expected the stream to be in flight until it ends, received ", inFlight)
+				}
+			}
+			stream.RecvMsg(wrapperspb.String(""))
+
+			codeLabels := prometheus.Labels{"grpc_code": test.expectedCode}
+			for name, value := range labels {
+				codeLabels[name] = value
+			}
+			if responses := testutil.ToFloat64(metrics.clientResponseCounter.With(codeLabels)); responses != 1 {
+				t.Error("Expected the stream to finish once, received ", responses, " responses")
+			}
+			if inFlight := testutil.ToFloat64(metrics.clientInFlight.With(labels)); inFlight != 0 {
+				t.Error("Expected no streams in flight after the stream ended, received ", inFlight)
+			}
+		})
+	}
+}
+
+func TestMessageSize(t *testing.T) {
+	var Tests = []struct {
+		name           string
+		message        interface{}
+		expectedOutput int
+	}{
+		{"Protobuf messages are measured as they are sent", wrapperspb.String("hello"), 7},
+		{"Empty protobuf messages are empty", &wrapperspb.StringValue{}, 0},
+		{"Anything else is counted as empty", "hello", 0},
+		{"Nil messages are counted as empty", nil, 0},
+	}
+
+	for _, test := range Tests {
+		t.Run(test.name, func(t *testing.T) {
+			if output := messageSize(test.message); output != test.expectedOutput {
+				t.Error("Expected ", test.expectedOutput, ", received ", output)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package interceptors
+
+import (
+	// Native packages
+	"context"
+	"runtime/debug"
+
+	// gRPC packages
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	// Personal packages
+	"github.com/nicholasbunn/mastersSandbox/src/logging"
+)
+
+/* The recovery interceptors stop a panic while making or serving a call from taking the whole
+service down. The panic is logged with its stack trace and the call fails with an Internal
+error instead, without revealing what went wrong to the caller. They should be the outermost
+interceptors of a chain, so that panics in the other interceptors are recovered as well */
+
+func ClientRecoveryInterceptor(ctx context.Context, method string, req interface{}, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) (err error) {
+	// Client side interceptor, to be attached to all client connections
+	defer recoverCall(method, &err)
+
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+func ClientRecoveryStreamInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (stream grpc.ClientStream, err error) {
+	// Client side stream interceptor, to be attached to all client connections
+	defer recoverCall(method, &err)
+
+	return streamer(ctx, desc, cc, method, opts...)
+}
+
+func ServerRecoveryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (h interface{}, err error) {
+	// Server-side interceptor, to be attached to all server connections
+	defer recoverCall(info.FullMethod, &err)
+
+	return handler(ctx, req)
+}
+
+func ServerRecoveryStreamInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	// Server-side stream interceptor, to be attached to all server connections
+	defer recoverCall(info.FullMethod, &err)
+
+	return handler(srv, stream)
+}
+
+// ________SUPPORTING FUNCTIONS________
+
+func recoverCall(method string, err *error) {
+	/* This (unexported) function recovers from a panic in the call to the provided method
+	(it must be deferred) and replaces the call's error with an Internal error */
+	if r := recover(); r != nil {
+		logging.Logger.Errorf("Recovered from a panic in %s: %v\n%s", method, r, debug.Stack())
+		*err = status.Error(codes.Internal, "internal error")
+	}
+}
@@ -0,0 +1,162 @@
+package interceptors
+
+import (
+	// Native packages
+	"context"
+	"fmt"
+	"strings"
+
+	// gRPC packages
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	// Personal packages
+	"github.com/nicholasbunn/mastersSandbox/src/logging"
+)
+
+/* The request ID interceptors give every request a single ID that it keeps as it passes through
+the services, so that a request a user reports (by the ID in the error they received) can be
+followed from the gateway through the aggregator to the Python services. The ID is carried in the
+"x-request-id" metadata key, alongside "authorisation". A server accepts the ID its caller sent,
+and assigns a new one to calls that arrive without one (calls from the frontend). The ID is
+returned to the caller in the call's trailer, added to the message of any error returned, and
+attached to the log lines (see the logging interceptors) and latency exemplars (see the metric
+interceptors) of the call. The server interceptors should come right after the tracing
+interceptors in a chain, so that the interceptors after them know the ID */
+
+const (
+	// RequestIDKey is the metadata key (request and trailer) that carries a call's request ID
+	RequestIDKey = "x-request-id"
+
+	// maxRequestIDLength is the longest request ID accepted from a caller, longer IDs are replaced
+	maxRequestIDLength = 64
+)
+
+// requestIDKey is the (unexported) key the request ID is stored under in a call's context
+type requestIDKey struct{}
+
+func ClientRequestIDInterceptor(ctx context.Context, method string, req interface{}, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	// Client side interceptor, to be attached to all client connections
+	return invoker(attachRequestID(ctx), method, req, reply, cc, opts...)
+}
+
+func ClientRequestIDStreamInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	// Client side stream interceptor, to be attached to all client connections
+	return streamer(attachRequestID(ctx), desc, cc, method, opts...)
+}
+
+func ServerRequestIDInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	// Server-side interceptor, to be attached to all server connections
+	ctx, requestID := acceptRequestID(ctx)
+	grpc.SetTrailer(ctx, metadata.Pairs(RequestIDKey, requestID))
+
+	h, err := handler(ctx, req)
+
+	return h, errorWithRequestID(err, requestID)
+}
+
+func ServerRequestIDStreamInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	// Server-side stream interceptor, to be attached to all server connections
+	ctx, requestID := acceptRequestID(stream.Context())
+	stream.SetTrailer(metadata.Pairs(RequestIDKey, requestID))
+
+	wrappedStream := grpc_middleware.WrapServerStream(stream)
+	wrappedStream.WrappedContext = ctx
+	err := handler(srv, wrappedStream)
+
+	return errorWithRequestID(err, requestID)
+}
+
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	// This function returns the provided context with the provided request ID, which is sent with the calls made with it
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+func RequestIDFromContext(ctx context.Context) string {
+	// This function returns the request ID of the call being served with the provided context, or "" if it has none
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+func RequestIDFromTrailer(trailer metadata.MD) string {
+	// This function returns the request ID a server returned in a call's trailer, or "" if it returned none
+	if values := trailer.Get(RequestIDKey); len(values) > 0 {
+		return values[0]
+	}
+
+	return ""
+}
+
+// ________SUPPORTING FUNCTIONS________
+
+func acceptRequestID(ctx context.Context) (context.Context, string) {
+	/* This (unexported) function returns the request ID the caller sent with the call being
+	served, or a new one if the caller didn't send a (valid) one, along with the context to
+	serve the call with. A new ID replaces the caller's in the incoming metadata as well, so
+	that the ID recorded from the metadata (in the audit log) is the one the call is served with */
+	md, ok := metadata.FromIncomingContext(ctx)
+	if values := md.Get(RequestIDKey); len(values) > 0 && validRequestID(values[0]) {
+		return ContextWithRequestID(ctx, values[0]), values[0]
+	}
+
+	requestID := logging.NewRequestID()
+	if ok {
+		md = md.Copy()
+	} else {
+		md = metadata.MD{}
+	}
+	md.Set(RequestIDKey, requestID)
+
+	return ContextWithRequestID(metadata.NewIncomingContext(ctx, md), requestID), requestID
+}
+
+func validRequestID(requestID string) bool {
+	/* This (unexported) function reports whether a request ID sent by a caller can be used.
+	IDs are written into log lines and error messages, so only short IDs made of letters,
+	digits, '-', '_' and '.' are accepted */
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for _, character := range requestID {
+		switch {
+		case character >= 'a' && character <= 'z', character >= 'A' && character <= 'Z', character >= '0' && character <= '9':
+		case character == '-', character == '_', character == '.':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+func attachRequestID(ctx context.Context) context.Context {
+	// This (unexported) function adds the request ID of the provided context (if any) to its outgoing metadata
+	requestID := RequestIDFromContext(ctx)
+	if requestID == "" {
+		return ctx
+	}
+	if md, ok := metadata.FromOutgoingContext(ctx); ok && len(md.Get(RequestIDKey)) > 0 {
+		return ctx
+	}
+
+	return metadata.AppendToOutgoingContext(ctx, RequestIDKey, requestID)
+}
+
+func errorWithRequestID(err error, requestID string) error {
+	/* This (unexported) function adds the request ID to the message of the provided error,
+	keeping its code and details. Errors passed on from the services called while serving the
+	request already carry the ID, and are returned as they are */
+	if err == nil {
+		return nil
+	}
+	st := status.Convert(err)
+	if strings.Contains(st.Message(), requestID) {
+		return err
+	}
+
+	withID := st.Proto()
+	withID.Message = fmt.Sprintf("%s (request ID %s)", withID.Message, requestID)
+	return status.ErrorProto(withID)
+}
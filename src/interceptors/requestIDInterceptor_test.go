@@ -0,0 +1,95 @@
+package interceptors
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestServerRequestIDInterceptor(t *testing.T) {
+	var Tests = []struct {
+		name       string
+		incoming   metadata.MD
+		expectedID string // The ID the call should be served with, "" for a new one
+	}{
+		{"Calls without an ID are assigned one", metadata.MD{}, ""},
+		{"The caller's ID is kept", metadata.Pairs(RequestIDKey, "ticket-1234"), "ticket-1234"},
+		{"IDs that can't be logged safely are replaced", metadata.Pairs(RequestIDKey, "bad id\nlevel=error"), ""},
+		{"Overly long IDs are replaced", metadata.Pairs(RequestIDKey, strings.Repeat("a", maxRequestIDLength+1)), ""},
+	}
+
+	for _, test := range Tests {
+		t.Run(test.name, func(t *testing.T) {
+			var servedID string
+			_, err := ServerRequestIDInterceptor(metadata.NewIncomingContext(context.Background(), test.incoming), nil, &grpc.UnaryServerInfo{FullMethod: "/Package/Unary"}, func(ctx context.Context, req interface{}) (interface{}, error) {
+				servedID = RequestIDFromContext(ctx)
+				if md, _ := metadata.FromIncomingContext(ctx); len(md.Get(RequestIDKey)) != 1 || md.Get(RequestIDKey)[0] != servedID {
+					t.Error("Expected the incoming metadata to carry the ID the call is served with, received ", md)
+				}
+				return nil, status.Error(codes.NotFound, "not found")
+			})
+
+			if test.expectedID != "" && servedID != test.expectedID {
+				t.Errorf("Expected the call to be served with %q, received %q", test.expectedID, servedID)
+			}
+			if test.expectedID == "" && (!validRequestID(servedID) || len(servedID) != 32) {
+				t.Errorf("Expected a new ID, received %q", servedID)
+			}
+			if status.Code(err) != codes.NotFound || status.Convert(err).Message() != "not found (request ID "+servedID+")" {
+				t.Error("Expected the error to keep its code and carry the ID, received ", err)
+			}
+		})
+	}
+}
+
+func TestRequestIDPropagation(t *testing.T) {
+	incoming := ContextWithRequestID(context.Background(), "ticket-1234")
+
+	// The ID is sent with the calls made while serving the request, even with a context that doesn't derive from the request's
+	err := ClientRequestIDInterceptor(CarrySpan(incoming, context.Background()), "/Package/Unary", nil, nil, nil, func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		if values := md.Get(RequestIDKey); len(values) != 1 || values[0] != "ticket-1234" {
+			t.Error("Expected the call to carry the request ID, received ", md)
+		}
+		return status.Error(codes.Unavailable, "unavailable (request ID ticket-1234)")
+	})
+
+	// Errors passed on from the services called already carry the ID
+	if message := status.Convert(errorWithRequestID(err, "ticket-1234")).Message(); strings.Count(message, "ticket-1234") != 1 {
+		t.Error("Expected the ID to be added to the message once, received ", message)
+	}
+	if errorWithRequestID(nil, "ticket-1234") != nil {
+		t.Error("Expected no error for a successful call")
+	}
+}
+
+func TestLatencyExemplars(t *testing.T) {
+	recordSpans(t)
+	metrics := NewServerMetrics(NewMetricExporter("TestService"))
+	ctx, span := StartSpan(ContextWithRequestID(context.Background(), "ticket-1234"), "Request")
+	defer span.End()
+
+	metrics.ServerMetricInterceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/Package/Unary"}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	})
+
+	var metric dto.Metric
+	if err := metrics.serverRequestLatency.With(callLabels("unary", "/Package/Unary")).(interface{ Write(*dto.Metric) error }).Write(&metric); err != nil {
+		t.Fatal(err)
+	}
+	exemplarLabels := map[string]string{}
+	for _, bucket := range metric.GetHistogram().GetBucket() {
+		for _, label := range bucket.GetExemplar().GetLabel() {
+			exemplarLabels[label.GetName()] = label.GetValue()
+		}
+	}
+	if exemplarLabels["request_id"] != "ticket-1234" || exemplarLabels["trace_id"] != span.SpanContext().TraceID().String() {
+		t.Error("Expected the latency's exemplar to carry the request and trace IDs, received ", exemplarLabels)
+	}
+}
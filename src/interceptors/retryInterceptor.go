@@ -0,0 +1,292 @@
+package interceptors
+
+import (
+	// Native packages
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	// Required packages
+	prometheus "github.com/prometheus/client_golang/prometheus"
+
+	// gRPC packages
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	// Personal packages
+	authentication "github.com/nicholasbunn/mastersSandbox/src/authenticationStuff"
+	"github.com/nicholasbunn/mastersSandbox/src/logging"
+)
+
+/* The retry interceptor retries unary calls that fail in a way that is safe to retry, as declared
+for each method called in the service's configuration. A call is only retried if it failed with
+one of its policy's codes, it has attempts left and there is time left before its deadline. The
+attempts are spaced out with exponential backoff, with jitter so that clients that failed
+together don't retry together, and each attempt can be given a timeout of its own.
+
+Retries are limited by a budget for every service called, so that a struggling service (such
+as the estimate service) isn't buried under retries. Every attempt that fails with a retryable
+code spends a token, every call that succeeds earns back a fraction of one, and retries stop
+while less than half of the tokens are left. The interceptor has to be the last in a chain, so
+that the logging, metric and tracing interceptors see a single call. Streams aren't retried */
+
+type RetryPolicy struct {
+	/* This struct describes how calls to a method are retried, as read from a service's
+	configuration file. The defaults only fill in the default policy, the policies of methods
+	are written in full */
+	Codes          []string `yaml:"codes" default:"[UNAVAILABLE]"` // The status codes ("UNAVAILABLE") that are retried, nothing is retried if empty
+	MaxAttempts    int      `yaml:"maxAttempts" default:"3"`       // The most attempts made, the first one included
+	InitialBackoff int      `yaml:"initialBackoff" default:"100"`  // Time (in milliseconds) waited before the first retry
+	MaxBackoff     int      `yaml:"maxBackoff" default:"1000"`     // Longest time (in milliseconds) waited between attempts
+	Multiplier     float64  `yaml:"multiplier" default:"2"`        // Factor the backoff grows by with every retry
+	Jitter         float64  `yaml:"jitter" default:"0.2"`          // Fraction of the backoff it is randomly changed by, up or down
+	AttemptTimeout int      `yaml:"attemptTimeout" default:"0"`    // Time (in milliseconds) each attempt may take, zero leaves only the call's deadline
+}
+
+type RetryBudget struct {
+	// This struct limits the retries made to each service called, as read from a service's configuration file
+	MaxTokens  float64 `yaml:"maxTokens" default:"10"`   // Tokens each service starts with, retries stop below half of them
+	TokenRatio float64 `yaml:"tokenRatio" default:"0.1"` // Fraction of a token earned back by every call that succeeds
+}
+
+type RetryConfig struct {
+	// This struct holds the retry policies of the methods a service calls, as read from its configuration file
+	Default RetryPolicy            `yaml:"default"` // The policy of the methods that aren't listed
+	Methods map[string]RetryPolicy `yaml:"methods"` // Policies by full method ("/package.Service/Method") or service ("/package.Service/")
+	Budget  RetryBudget            `yaml:"budget"`
+}
+
+type ClientRetryStruct struct {
+	/* This struct retries the calls made by a client as configured, keeping a retry budget
+	for every service called. The retries are counted on the service's metrics registry */
+	exporter          *MetricExporter
+	mutex             sync.RWMutex
+	config            RetryConfig
+	budgets           map[string]*retryBudget
+	retryCounter      *prometheus.CounterVec // Counts the retries made, by the status code of the attempt that failed
+	retryLimitCounter *prometheus.CounterVec // Counts the calls that weren't retried because the budget was spent
+	retryBudgetTokens *prometheus.GaugeVec   // Records the tokens left in the budget of each service called
+	randomFloat       func() float64         // Returns a number in [0, 1), used for the jitter
+}
+
+type retryBudget struct {
+	// This (unexported) struct holds the tokens left for retrying the calls to a service
+	mutex  sync.Mutex
+	tokens float64
+}
+
+func (config RetryConfig) Check(setting string, check *authentication.ConfigCheck) {
+	// This function records the problems with the provided retry settings (found at setting) in the provided check
+	config.Default.Check(setting+".default", check)
+	for method, policy := range config.Methods {
+		if !strings.HasPrefix(method, "/") || strings.Count(method, "/") != 2 {
+			check.Problem(fmt.Sprintf("%v.methods[%v]", setting, method), "is not a method (\"/package.Service/Method\") or service (\"/package.Service/\")")
+		}
+		policy.Check(fmt.Sprintf("%v.methods[%v]", setting, method), check)
+	}
+
+	if config.Budget.MaxTokens <= 0 {
+		check.Problem(setting+".budget.maxTokens", "has to be greater than zero, found %v", config.Budget.MaxTokens)
+	}
+	if config.Budget.TokenRatio <= 0 || config.Budget.TokenRatio > 1 {
+		check.Problem(setting+".budget.tokenRatio", "has to be greater than zero and at most one, found %v", config.Budget.TokenRatio)
+	}
+}
+
+func (policy RetryPolicy) Check(setting string, check *authentication.ConfigCheck) {
+	// This function records the problems with the provided retry policy (found at setting) in the provided check
+	check.RequirePositive(setting+".maxAttempts", policy.MaxAttempts)
+	for _, name := range policy.Codes {
+		if _, err := parseCode(name); err != nil {
+			check.Problem(setting+".codes", "%v", err)
+		}
+	}
+	if policy.MaxAttempts <= 1 || len(policy.Codes) == 0 {
+		return // Calls aren't retried, so the backoff isn't used
+	}
+
+	check.RequirePositive(setting+".initialBackoff", policy.InitialBackoff)
+	if policy.MaxBackoff < policy.InitialBackoff {
+		check.Problem(setting+".maxBackoff", "has to be at least the initial backoff (%v), found %v", policy.InitialBackoff, policy.MaxBackoff)
+	}
+	if policy.Multiplier < 1 {
+		check.Problem(setting+".multiplier", "has to be at least one, found %v", policy.Multiplier)
+	}
+	if policy.Jitter < 0 || policy.Jitter > 1 {
+		check.Problem(setting+".jitter", "has to be between zero and one, found %v", policy.Jitter)
+	}
+	if policy.AttemptTimeout < 0 {
+		check.Problem(setting+".attemptTimeout", "can't be negative, found %v", policy.AttemptTimeout)
+	}
+}
+
+func NewClientRetries(exporter *MetricExporter, config RetryConfig) *ClientRetryStruct {
+	// This function creates a client's retry interceptor with the provided (checked) settings, and registers its metrics on the provided exporter's registry
+	retries := &ClientRetryStruct{
+		exporter: exporter,
+		config:   config,
+		budgets:  map[string]*retryBudget{},
+		retryCounter: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "client_retry_counter",
+				Help: "The number of calls made by the client that were retried, by the status code of the attempt that failed",
+			}, []string{"grpc_service", "grpc_method", "grpc_code"}),
+		retryLimitCounter: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "client_retry_budget_exhausted_counter",
+				Help: "The number of calls made by the client that weren't retried because the retry budget of the service was spent",
+			}, []string{"grpc_service", "grpc_method"}),
+		retryBudgetTokens: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "client_retry_budget_tokens",
+				Help: "The tokens left in the retry budget of each service called, retries stop below half of the budget",
+			}, []string{"grpc_service"}),
+		randomFloat: rand.Float64,
+	}
+	exporter.Registerer.MustRegister(retries.retryCounter, retries.retryLimitCounter, retries.retryBudgetTokens)
+
+	return retries
+}
+
+func (retries *ClientRetryStruct) Update(config RetryConfig) {
+	/* This function replaces the retry settings, while the client is running. The budgets
+	keep the tokens they have, up to the new limit */
+	retries.mutex.Lock()
+	defer retries.mutex.Unlock()
+
+	retries.config = config
+	for _, budget := range retries.budgets {
+		budget.mutex.Lock()
+		budget.tokens = math.Min(budget.tokens, config.Budget.MaxTokens)
+		budget.mutex.Unlock()
+	}
+}
+
+func (retries *ClientRetryStruct) ClientRetryInterceptor(ctx context.Context, method string, req interface{}, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	// Client side interceptor, to be attached (last) to the client connections whose calls are retried
+	policy, budgetConfig, budget := retries.policy(method)
+	serviceName, methodName := splitMethod(method)
+
+	for attempt := 1; ; attempt++ {
+		err := invokeAttempt(ctx, time.Duration(policy.AttemptTimeout)*time.Millisecond, method, req, reply, cc, invoker, opts...)
+		code := status.Code(err)
+		if err == nil {
+			retries.spend(serviceName, budget, -budgetConfig.TokenRatio, budgetConfig.MaxTokens)
+			return nil
+		}
+		if !policy.retries(code) {
+			return err
+		}
+		tokens := retries.spend(serviceName, budget, 1, budgetConfig.MaxTokens)
+
+		// Only retry while the call is still wanted, has attempts left and the service has budget left
+		if ctx.Err() != nil || attempt >= policy.MaxAttempts {
+			return err
+		}
+		if tokens <= budgetConfig.MaxTokens/2 {
+			retries.retryLimitCounter.With(prometheus.Labels{"grpc_service": serviceName, "grpc_method": methodName}).Inc()
+			retries.exporter.Changed()
+			logging.FromContext(ctx).Warnf("Not retrying %v after %v, the retry budget of %v is spent", method, code, serviceName)
+			return err
+		}
+		backoff := retries.backoff(policy, attempt)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < backoff {
+			return err
+		}
+
+		retries.retryCounter.With(prometheus.Labels{"grpc_service": serviceName, "grpc_method": methodName, "grpc_code": code.String()}).Inc()
+		retries.exporter.Changed()
+		logging.FromContext(ctx).Warnf("Retrying %v in %v (attempt %d of %d), the last attempt failed with %v", method, backoff.Round(time.Millisecond), attempt+1, policy.MaxAttempts, code)
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// ________SUPPORTING FUNCTIONS________
+
+func (retries *ClientRetryStruct) policy(method string) (RetryPolicy, RetryBudget, *retryBudget) {
+	/* This (unexported) function returns the retry policy of the provided method (its own, its
+	service's or the default one), the budget settings and the budget of the method's service */
+	serviceName, _ := splitMethod(method)
+	retries.mutex.Lock()
+	defer retries.mutex.Unlock()
+
+	policy, ok := retries.config.Methods[method]
+	if !ok {
+		policy, ok = retries.config.Methods[method[:strings.LastIndex(method, "/")+1]]
+	}
+	if !ok {
+		policy = retries.config.Default
+	}
+
+	budget, ok := retries.budgets[serviceName]
+	if !ok {
+		budget = &retryBudget{tokens: retries.config.Budget.MaxTokens}
+		retries.budgets[serviceName] = budget
+	}
+
+	return policy, retries.config.Budget, budget
+}
+
+func (retries *ClientRetryStruct) spend(serviceName string, budget *retryBudget, cost float64, maxTokens float64) float64 {
+	// This (unexported) function takes the provided cost (a negative cost earns tokens back) from a budget, and returns the tokens left
+	budget.mutex.Lock()
+	budget.tokens = math.Max(0, math.Min(maxTokens, budget.tokens-cost))
+	tokens := budget.tokens
+	budget.mutex.Unlock()
+
+	retries.retryBudgetTokens.With(prometheus.Labels{"grpc_service": serviceName}).Set(tokens)
+	return tokens
+}
+
+func (retries *ClientRetryStruct) backoff(policy RetryPolicy, attempt int) time.Duration {
+	// This (unexported) function returns the time to wait after the provided (failed) attempt, with jitter
+	backoff := float64(policy.InitialBackoff) * math.Pow(policy.Multiplier, float64(attempt-1))
+	backoff = math.Min(backoff, float64(policy.MaxBackoff))
+	backoff *= 1 + policy.Jitter*(2*retries.randomFloat()-1)
+
+	return time.Duration(backoff * float64(time.Millisecond))
+}
+
+func (policy RetryPolicy) retries(code codes.Code) bool {
+	// This (unexported) function reports whether calls that failed with the provided code are retried
+	for _, name := range policy.Codes {
+		if parsed, err := parseCode(name); err == nil && parsed == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+func invokeAttempt(ctx context.Context, timeout time.Duration, method string, req interface{}, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	// This (unexported) function makes a single attempt of a call, within the provided timeout (if any)
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+func parseCode(name string) (codes.Code, error) {
+	// This (unexported) function returns the status code with the provided name ("UNAVAILABLE")
+	var code codes.Code
+	if err := code.UnmarshalJSON([]byte(`"` + strings.ToUpper(name) + `"`)); err != nil {
+		return code, fmt.Errorf("%q is not a status code, use names such as \"UNAVAILABLE\"", name)
+	}
+
+	return code, nil
+}
@@ -0,0 +1,237 @@
+package interceptors
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	prometheus "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	authentication "github.com/nicholasbunn/mastersSandbox/src/authenticationStuff"
+)
+
+const retriedMethod = "/estimate.EstimatePower/EstimatePowerService"
+
+func testRetryConfig() RetryConfig {
+	// This function returns retry settings that retry UNAVAILABLE calls quickly, three times at most
+	return RetryConfig{
+		Default: RetryPolicy{Codes: []string{"UNAVAILABLE"}, MaxAttempts: 3, InitialBackoff: 1, MaxBackoff: 5, Multiplier: 2, Jitter: 0.2},
+		Budget:  RetryBudget{MaxTokens: 10, TokenRatio: 0.1},
+	}
+}
+
+func failingInvoker(errs ...error) (grpc.UnaryInvoker, *int) {
+	// This function returns an invoker that fails with the provided errors in turn (and then succeeds), and the number of attempts made
+	attempts := 0
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		attempts++
+		if attempts <= len(errs) {
+			return errs[attempts-1]
+		}
+		return nil
+	}, &attempts
+}
+
+func TestClientRetryInterceptor(t *testing.T) {
+	unavailable := status.Error(codes.Unavailable, "unavailable")
+	exhausted := status.Error(codes.ResourceExhausted, "throttled")
+
+	var Tests = []struct {
+		name             string
+		errs             []error
+		expectedAttempts int
+		expectedCode     codes.Code
+	}{
+		{"Successful calls are made once", nil, 1, codes.OK},
+		{"Retryable failures are retried", []error{unavailable, unavailable}, 3, codes.OK},
+		{"Other failures aren't retried", []error{exhausted}, 1, codes.ResourceExhausted},
+		{"Calls stop at the most attempts", []error{unavailable, unavailable, unavailable, unavailable}, 3, codes.Unavailable},
+	}
+
+	for _, test := range Tests {
+		t.Run(test.name, func(t *testing.T) {
+			retries := NewClientRetries(NewMetricExporter("TestService"), testRetryConfig())
+			invoker, attempts := failingInvoker(test.errs...)
+
+			err := retries.ClientRetryInterceptor(context.Background(), retriedMethod, nil, nil, nil, invoker)
+			if status.Code(err) != test.expectedCode || *attempts != test.expectedAttempts {
+				t.Errorf("Expected %v after %d attempts, received %v after %d", test.expectedCode, test.expectedAttempts, err, *attempts)
+			}
+			labels := prometheus.Labels{"grpc_service": "estimate.EstimatePower", "grpc_method": "EstimatePowerService", "grpc_code": "Unavailable"}
+			if retried := testutil.ToFloat64(retries.retryCounter.With(labels)); int(retried) != test.expectedAttempts-1 {
+				t.Errorf("Expected %d retries to be counted, received %v", test.expectedAttempts-1, retried)
+			}
+		})
+	}
+}
+
+func TestRetryPolicies(t *testing.T) {
+	config := testRetryConfig()
+	config.Methods = map[string]RetryPolicy{
+		"/estimate.EstimatePower/":                    {Codes: []string{"UNAVAILABLE", "DEADLINE_EXCEEDED"}, MaxAttempts: 2, InitialBackoff: 1, MaxBackoff: 1, Multiplier: 1},
+		"/fetchData.FetchData/FetchDataService":       {Codes: []string{"UNAVAILABLE"}, MaxAttempts: 4, InitialBackoff: 1, MaxBackoff: 1, Multiplier: 1},
+		"/authentication.AuthenticationService/Login": {MaxAttempts: 1},
+	}
+	retries := NewClientRetries(NewMetricExporter("TestService"), config)
+
+	var Tests = []struct {
+		method           string
+		err              error
+		expectedAttempts int
+	}{
+		{retriedMethod, status.Error(codes.DeadlineExceeded, "slow"), 2},
+		{"/fetchData.FetchData/FetchDataService", status.Error(codes.Unavailable, "unavailable"), 4},
+		{"/authentication.AuthenticationService/Login", status.Error(codes.Unavailable, "unavailable"), 1},
+		{"/prepareData.PrepareData/PrepareEstimateDataService", status.Error(codes.Unavailable, "unavailable"), 3},
+	}
+
+	for _, test := range Tests {
+		invoker, attempts := failingInvoker(test.err, test.err, test.err, test.err)
+		retries.ClientRetryInterceptor(context.Background(), test.method, nil, nil, nil, invoker)
+		if *attempts != test.expectedAttempts {
+			t.Errorf("Expected %v to be attempted %d times, received %d", test.method, test.expectedAttempts, *attempts)
+		}
+	}
+}
+
+func TestRetryAttemptTimeout(t *testing.T) {
+	config := testRetryConfig()
+	config.Default.Codes = []string{"DEADLINE_EXCEEDED"}
+	config.Default.AttemptTimeout = 20
+	retries := NewClientRetries(NewMetricExporter("TestService"), config)
+
+	attempts := 0
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		attempts++
+		if attempts == 1 {
+			<-ctx.Done()
+			return status.FromContextError(ctx.Err()).Err()
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := retries.ClientRetryInterceptor(ctx, retriedMethod, nil, nil, nil, invoker); err != nil || attempts != 2 {
+		t.Errorf("Expected the attempt that timed out to be retried, received %v after %d attempts", err, attempts)
+	}
+}
+
+func TestRetryDeadline(t *testing.T) {
+	config := testRetryConfig()
+	config.Default.InitialBackoff, config.Default.MaxBackoff = 1000, 1000
+	retries := NewClientRetries(NewMetricExporter("TestService"), config)
+	invoker, attempts := failingInvoker(status.Error(codes.Unavailable, "unavailable"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	retries.ClientRetryInterceptor(ctx, retriedMethod, nil, nil, nil, invoker)
+	if *attempts != 1 || time.Since(start) > 50*time.Millisecond {
+		t.Errorf("Expected no retry to outlive the call's deadline, received %d attempts in %v", *attempts, time.Since(start))
+	}
+}
+
+func TestRetryBudget(t *testing.T) {
+	config := testRetryConfig()
+	config.Default.MaxAttempts = 2
+	config.Budget = RetryBudget{MaxTokens: 4, TokenRatio: 0.5}
+	retries := NewClientRetries(NewMetricExporter("TestService"), config)
+	unavailable := status.Error(codes.Unavailable, "unavailable")
+
+	// Each call that fails twice spends two tokens, so only the first call is retried
+	for call, expectedAttempts := range []int{2, 1, 1} {
+		invoker, attempts := failingInvoker(unavailable, unavailable)
+		retries.ClientRetryInterceptor(context.Background(), retriedMethod, nil, nil, nil, invoker)
+		if *attempts != expectedAttempts {
+			t.Errorf("Expected call %d to be attempted %d times, received %d", call+1, expectedAttempts, *attempts)
+		}
+	}
+	labels := prometheus.Labels{"grpc_service": "estimate.EstimatePower", "grpc_method": "EstimatePowerService"}
+	if exhausted := testutil.ToFloat64(retries.retryLimitCounter.With(labels)); exhausted != 2 {
+		t.Error("Expected two calls to be counted as not retried, received ", exhausted)
+	}
+
+	// Other services have budgets of their own
+	invoker, attempts := failingInvoker(unavailable)
+	retries.ClientRetryInterceptor(context.Background(), "/fetchData.FetchData/FetchDataService", nil, nil, nil, invoker)
+	if *attempts != 2 {
+		t.Error("Expected a call to another service to be retried, received attempts: ", *attempts)
+	}
+
+	// Successful calls earn the budget back
+	for i := 0; i < 8; i++ {
+		invoker, _ := failingInvoker()
+		retries.ClientRetryInterceptor(context.Background(), retriedMethod, nil, nil, nil, invoker)
+	}
+	invoker, attempts = failingInvoker(unavailable)
+	retries.ClientRetryInterceptor(context.Background(), retriedMethod, nil, nil, nil, invoker)
+	if *attempts != 2 {
+		t.Error("Expected calls to be retried again once the budget was earned back, received attempts: ", *attempts)
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	retries := NewClientRetries(NewMetricExporter("TestService"), testRetryConfig())
+	policy := RetryPolicy{InitialBackoff: 100, MaxBackoff: 300, Multiplier: 2, Jitter: 0.5}
+
+	for _, random := range []float64{0, 0.5, 0.999} {
+		retries.randomFloat = func() float64 { return random }
+		for attempt, expected := range []float64{100, 200, 300, 300} {
+			backoff := retries.backoff(policy, attempt+1)
+			low, high := time.Duration(expected*0.5)*time.Millisecond, time.Duration(expected*1.5)*time.Millisecond
+			if backoff < low || backoff > high {
+				t.Errorf("Expected the backoff after attempt %d to be between %v and %v, received %v", attempt+1, low, high, backoff)
+			}
+		}
+	}
+	retries.randomFloat = func() float64 { return 0.5 }
+	if backoff := retries.backoff(policy, 2); backoff != 200*time.Millisecond {
+		t.Error("Expected no jitter in the middle of the range, received ", backoff)
+	}
+}
+
+func TestRetryConfigCheck(t *testing.T) {
+	var Tests = []struct {
+		name     string
+		modify   func(config *RetryConfig)
+		problems []string
+	}{
+		{"Complete settings pass", func(config *RetryConfig) {}, nil},
+		{"Unknown codes are refused", func(config *RetryConfig) { config.Default.Codes = []string{"UNAVAILIBLE"} }, []string{"retry.default.codes"}},
+		{"Policies that don't retry need no backoff", func(config *RetryConfig) {
+			config.Methods = map[string]RetryPolicy{"/authentication.AuthenticationService/LoginAuth": {MaxAttempts: 1}}
+		}, nil},
+		{"Policies that retry need a backoff", func(config *RetryConfig) {
+			config.Methods = map[string]RetryPolicy{"/fetchData.FetchData/": {Codes: []string{"UNAVAILABLE"}, MaxAttempts: 2, Jitter: 2}}
+		}, []string{"retry.methods[/fetchData.FetchData/].initialBackoff", "retry.methods[/fetchData.FetchData/].multiplier", "retry.methods[/fetchData.FetchData/].jitter"}},
+		{"Methods are named in full", func(config *RetryConfig) {
+			config.Methods = map[string]RetryPolicy{"FetchDataService": {MaxAttempts: 1}}
+		}, []string{"retry.methods[FetchDataService]"}},
+		{"The budget has to be positive", func(config *RetryConfig) { config.Budget = RetryBudget{} }, []string{"retry.budget.maxTokens", "retry.budget.tokenRatio"}},
+	}
+
+	for _, test := range Tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := testRetryConfig()
+			test.modify(&config)
+			check := authentication.NewConfigCheck("TestService", "configuration.yaml")
+			config.Check("retry", check)
+
+			report := check.Report()
+			if check.Failed() != (len(test.problems) > 0) {
+				t.Fatal("Expected problems with ", test.problems, ", received:\n", report)
+			}
+			for _, problem := range test.problems {
+				if !strings.Contains(report, problem) {
+					t.Errorf("Expected a problem with %v, received:\n%v", problem, report)
+				}
+			}
+		})
+	}
+}
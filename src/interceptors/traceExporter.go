@@ -0,0 +1,133 @@
+package interceptors
+
+import (
+	// Native packages
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	// Required packages
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+
+	// Personal packages
+	authentication "github.com/nicholasbunn/mastersSandbox/src/authenticationStuff"
+	"github.com/nicholasbunn/mastersSandbox/src/logging"
+)
+
+/* A service's spans are exported in batches in the background, either over OTLP to a
+collector (on shore, or a collector running next to the services) or to a file (on the ship,
+where there is no collector to send them to). Span files hold one JSON object per line and are
+only ever appended to, so that they can be collected and loaded into a tracing backend later.
+The W3C trace context propagator is set up however the spans are exported, so that a service
+that doesn't export its spans still passes the trace on */
+
+const (
+	// The exporters a service can send its spans to
+	TraceExporterOTLP = "otlp" // Over gRPC to an OpenTelemetry collector
+	TraceExporterFile = "file" // Appended to a file, one span per line
+	TraceExporterNone = "none" // Not exported (or recorded) at all
+
+	// traceShutdownTimeout is how long the spans still waiting to be exported may take to be sent when the service stops
+	traceShutdownTimeout = 5 * time.Second
+)
+
+type TracingConfig struct {
+	// This struct holds the tracing settings of a service, as read from its configuration file
+	Exporter    string  `yaml:"exporter" default:"none"`  // Where the spans are exported to: "otlp", "file" or "none"
+	Host        string  `yaml:"host" default:"localhost"` // Host of the OTLP collector
+	Port        string  `yaml:"port" default:"4317"`      // Port of the OTLP collector
+	Insecure    bool    `yaml:"insecure"`                 // Send the spans to the collector without TLS, for a collector on the services' own network
+	File        string  `yaml:"file"`                     // Path (relative to the execution directory) of the file the spans are appended to
+	SampleRatio float64 `yaml:"sampleRatio" default:"1"`  // Fraction of the traces started by the service that are recorded, traces started elsewhere follow their caller
+}
+
+func (config TracingConfig) Check(setting string, check *authentication.ConfigCheck) {
+	// This function records the problems with the provided tracing settings (found at setting) in the provided check
+	switch config.Exporter {
+	case TraceExporterNone:
+		return
+	case TraceExporterOTLP:
+		check.RequireValue(setting+".host", config.Host)
+		check.RequirePort(setting+".port", config.Port)
+	case TraceExporterFile:
+		check.RequireValue(setting+".file", config.File)
+	case "":
+		check.Problem(setting+".exporter", "is not set, use %q, %q or %q", TraceExporterOTLP, TraceExporterFile, TraceExporterNone)
+	default:
+		check.Problem(setting+".exporter", "%q is not an exporter, use %q, %q or %q", config.Exporter, TraceExporterOTLP, TraceExporterFile, TraceExporterNone)
+	}
+
+	if config.SampleRatio <= 0 || config.SampleRatio > 1 {
+		check.Problem(setting+".sampleRatio", "has to be greater than zero and at most one, found %v", config.SampleRatio)
+	}
+}
+
+func StartTracing(service string, config TracingConfig) (stop func(), err error) {
+	/* This function sets up the tracer that the provided service's spans are recorded with,
+	and exports them as configured. It returns a function that exports the spans that are
+	still waiting and stops the tracer, or an error if the exporter can't be created */
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	if config.Exporter == TraceExporterNone {
+		logging.Logger.Infoln("Tracing is disabled, trace context is still passed on")
+		return func() {}, nil
+	}
+
+	var exporter sdktrace.SpanExporter
+	var file *os.File
+	switch config.Exporter {
+	case TraceExporterOTLP:
+		address := config.Host + ":" + config.Port
+		options := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(address)}
+		if config.Insecure {
+			options = append(options, otlptracegrpc.WithInsecure())
+		}
+		// The exporter connects in the background, spans are kept (up to the batch limit) while the collector is unreachable
+		exporter, err = otlptracegrpc.New(context.Background(), options...)
+		if err != nil {
+			return nil, err
+		}
+		logging.Logger.Infoln("Exporting spans to the collector at ", address)
+	case TraceExporterFile:
+		if err = os.MkdirAll(filepath.Dir(config.File), 0755); err != nil {
+			return nil, err
+		}
+		file, err = os.OpenFile(config.File, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return nil, err
+		}
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(file))
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		logging.Logger.Infoln("Exporting spans to ", config.File)
+	default:
+		return nil, fmt.Errorf("%q is not a trace exporter", config.Exporter)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceNameKey.String(service))),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), traceShutdownTimeout)
+		defer cancel()
+		if err := provider.Shutdown(ctx); err != nil {
+			logging.Logger.Warnln("Could not export the remaining spans before stopping: ", err)
+		}
+		if file != nil {
+			file.Close()
+		}
+	}, nil
+}
@@ -0,0 +1,89 @@
+package interceptors
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+
+	authentication "github.com/nicholasbunn/mastersSandbox/src/authenticationStuff"
+)
+
+func TestTracingConfigCheck(t *testing.T) {
+	var Tests = []struct {
+		name     string
+		config   TracingConfig
+		problems []string
+	}{
+		{"Disabled tracing needs no other settings", TracingConfig{Exporter: "none"}, nil},
+		{"Exporting to a collector needs its host and port", TracingConfig{Exporter: "otlp", SampleRatio: 1}, []string{"tracing.host", "tracing.port"}},
+		{"Exporting to a file needs the file", TracingConfig{Exporter: "file", SampleRatio: 0.5}, []string{"tracing.file"}},
+		{"The exporter has to be set", TracingConfig{SampleRatio: 1}, []string{"tracing.exporter"}},
+		{"Unknown exporters are refused", TracingConfig{Exporter: "jaeger", Port: "4317", SampleRatio: 1}, []string{"tracing.exporter"}},
+		{"The sample ratio is a fraction", TracingConfig{Exporter: "otlp", Host: "otelcollector", Port: "4317", SampleRatio: 2}, []string{"tracing.sampleRatio"}},
+		{"Complete settings pass", TracingConfig{Exporter: "otlp", Host: "otelcollector", Port: "4317", SampleRatio: 0.1}, nil},
+	}
+
+	for _, test := range Tests {
+		t.Run(test.name, func(t *testing.T) {
+			check := authentication.NewConfigCheck("TestService", "configuration.yaml")
+			test.config.Check("tracing", check)
+
+			report := check.Report()
+			if check.Failed() != (len(test.problems) > 0) {
+				t.Fatal("Expected problems with ", test.problems, ", received:\n", report)
+			}
+			for _, problem := range test.problems {
+				if !strings.Contains(report, problem) {
+					t.Errorf("Expected a problem with %v, received:\n%v", problem, report)
+				}
+			}
+		})
+	}
+}
+
+func TestStartTracingToFile(t *testing.T) {
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	defer func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	}()
+
+	path := filepath.Join(t.TempDir(), "traces", "spans.json")
+	stop, err := StartTracing("TestService", TracingConfig{Exporter: TraceExporterFile, File: path, SampleRatio: 1})
+	if err != nil {
+		t.Fatal("Expected tracing to start, received ", err)
+	}
+
+	for _, name := range []string{"First", "Second"} {
+		_, span := StartSpan(context.Background(), name)
+		EndSpan(span, nil)
+	}
+	stop()
+
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(contents)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected one line per span, received:\n%s", contents)
+	}
+	var span struct {
+		Name     string
+		Resource []struct {
+			Key   string
+			Value struct{ Value interface{} }
+		}
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &span); err != nil || span.Name != "First" {
+		t.Fatalf("Expected the first span as JSON, received %v (%v)", lines[0], err)
+	}
+	if !strings.Contains(lines[0], `"TestService"`) {
+		t.Error("Expected the span to name the service, received ", lines[0])
+	}
+}
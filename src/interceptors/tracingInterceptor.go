@@ -0,0 +1,210 @@
+package interceptors
+
+import (
+	// Native packages
+	"context"
+	"io"
+	"strings"
+
+	// Required packages
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"go.opentelemetry.io/otel/trace"
+
+	// gRPC packages
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	// Personal packages
+	"github.com/nicholasbunn/mastersSandbox/src/logging"
+)
+
+/* The tracing interceptors start a span for every call made and served, so that a request can
+be followed from the frontend through the gateway and the aggregator to the Python services.
+The W3C trace context ("traceparent" and "tracestate") of the call is carried in its metadata,
+where the Python services pick it up as well. The spans are exported by the tracer that
+StartTracing sets up. Until it has been set up the spans aren't recorded, and a service that
+doesn't record spans still passes the trace context of the requests it serves on to the
+services it calls. Services add spans of their own (for the stages of a pipeline) with
+StartSpan */
+
+// tracerName identifies the spans started by this package
+const tracerName = "github.com/nicholasbunn/mastersSandbox/src/interceptors"
+
+func ClientTracingInterceptor(ctx context.Context, method string, req interface{}, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	// Client side interceptor, to be attached to all client connections
+	ctx, span := startCallSpan(ctx, method, trace.SpanKindClient, semconv.NetPeerNameKey.String(cc.Target()))
+
+	err := invoker(injectTraceContext(ctx), method, req, reply, cc, opts...)
+	endCallSpan(span, err)
+
+	return err
+}
+
+func ClientTracingStreamInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	/* Client side stream interceptor, to be attached to all client connections. The span ends
+	once a message can no longer be received on the stream, in the same way as the call is
+	finished by the metric interceptor */
+	ctx, span := startCallSpan(ctx, method, trace.SpanKindClient, semconv.NetPeerNameKey.String(cc.Target()))
+
+	stream, err := streamer(injectTraceContext(ctx), desc, cc, method, opts...)
+	if err != nil {
+		endCallSpan(span, err)
+		return nil, err
+	}
+
+	return &tracedClientStream{ClientStream: stream, span: span}, nil
+}
+
+func ServerTracingInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	// Server-side interceptor, to be attached to all server connections
+	ctx, span := startCallSpan(extractTraceContext(ctx), info.FullMethod, trace.SpanKindServer)
+
+	h, err := handler(ctx, req)
+	endCallSpan(span, err)
+
+	return h, err
+}
+
+func ServerTracingStreamInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	// Server-side stream interceptor, to be attached to all server connections. The span lasts for as long as the stream is served
+	ctx, span := startCallSpan(extractTraceContext(stream.Context()), info.FullMethod, trace.SpanKindServer)
+
+	err := handler(srv, &tracedServerStream{ServerStream: stream, ctx: ctx})
+	endCallSpan(span, err)
+
+	return err
+}
+
+func StartSpan(ctx context.Context, name string) (context.Context, trace.Span) {
+	/* This function starts a span for a part of the work done while serving a call (a stage
+	of a pipeline, for example), as a child of the span in the provided context. The span has
+	to be ended with EndSpan */
+	return otel.Tracer(tracerName).Start(ctx, name)
+}
+
+func EndSpan(span trace.Span, err error) {
+	// This function ends a span started with StartSpan, recording the provided error (if any) on it
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, status.Convert(err).Message())
+	}
+	span.End()
+}
+
+func CarrySpan(incoming context.Context, outgoing context.Context) context.Context {
+	/* This function adds the span of the incoming request to a context made for an outgoing
+	request, so that calls made with a context that doesn't derive from the request's own (to
+	keep its metadata and deadline from being passed on) are still part of the request's trace.
+	The request's ID and log fields are carried along with the span */
+	outgoing = trace.ContextWithSpan(outgoing, trace.SpanFromContext(incoming))
+	if requestID := RequestIDFromContext(incoming); requestID != "" {
+		outgoing = ContextWithRequestID(outgoing, requestID)
+	}
+
+	return logging.CarryFields(incoming, outgoing)
+}
+
+type tracedClientStream struct {
+	// This (unexported) struct wraps a client stream to end its span once the stream has ended
+	grpc.ClientStream
+	span  trace.Span
+	ended bool
+}
+
+func (stream *tracedClientStream) RecvMsg(m interface{}) error {
+	// This function ends the stream's span once the stream has ended (io.EOF or an error)
+	err := stream.ClientStream.RecvMsg(m)
+	if err != nil && !stream.ended {
+		stream.ended = true
+		if err == io.EOF {
+			endCallSpan(stream.span, nil)
+		} else {
+			endCallSpan(stream.span, err)
+		}
+	}
+
+	return err
+}
+
+type tracedServerStream struct {
+	// This (unexported) struct wraps a server stream to hand the stream's span on to the handler
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (stream *tracedServerStream) Context() context.Context {
+	return stream.ctx
+}
+
+// metadataCarrier lets the propagators read the trace context from, and write it to, a call's metadata
+type metadataCarrier metadata.MD
+
+func (carrier metadataCarrier) Get(key string) string {
+	values := metadata.MD(carrier).Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
+
+func (carrier metadataCarrier) Set(key string, value string) {
+	metadata.MD(carrier).Set(key, value)
+}
+
+func (carrier metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(carrier))
+	for key := range carrier {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+// ________SUPPORTING FUNCTIONS________
+
+func startCallSpan(ctx context.Context, fullMethod string, kind trace.SpanKind, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	/* This (unexported) function starts the span of a call to the provided method, named
+	after the method in the same way the Python services name theirs ("package.Service/Method") */
+	serviceName, serviceMethod := splitMethod(fullMethod)
+	attributes = append(attributes,
+		semconv.RPCSystemGRPC,
+		semconv.RPCServiceKey.String(serviceName),
+		semconv.RPCMethodKey.String(serviceMethod),
+	)
+
+	return otel.Tracer(tracerName).Start(ctx, strings.TrimPrefix(fullMethod, "/"), trace.WithSpanKind(kind), trace.WithAttributes(attributes...))
+}
+
+func endCallSpan(span trace.Span, err error) {
+	// This (unexported) function ends the span of a call, with the status code of the provided error
+	span.SetAttributes(semconv.RPCGRPCStatusCodeKey.Int(int(status.Code(err))))
+	EndSpan(span, err)
+}
+
+func injectTraceContext(ctx context.Context) context.Context {
+	// This (unexported) function adds the trace context of the span in the provided context to its outgoing metadata
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if ok {
+		md = md.Copy()
+	} else {
+		md = metadata.MD{}
+	}
+	otel.GetTextMapPropagator().Inject(ctx, metadataCarrier(md))
+
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+func extractTraceContext(ctx context.Context) context.Context {
+	// This (unexported) function returns the provided context with the trace context of the incoming call's metadata added to it
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx
+	}
+
+	return otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+}
@@ -0,0 +1,161 @@
+package interceptors
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/nicholasbunn/mastersSandbox/src/logging"
+)
+
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	// This function records every span started during the test, restoring the global tracer afterwards
+	recorder := tracetest.NewSpanRecorder()
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	return recorder
+}
+
+func TestTracingPropagation(t *testing.T) {
+	recorder := recordSpans(t)
+	conn, err := grpc.Dial("localhost:0", grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// The trace context the client sends is handed to the server as the incoming metadata
+	var serverSpan trace.SpanContext
+	err = ClientTracingInterceptor(context.Background(), "/Package/Unary", nil, nil, conn, func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		if len(md.Get("traceparent")) != 1 {
+			t.Fatal("Expected the call to carry a traceparent, received ", md)
+		}
+
+		_, err := ServerTracingInterceptor(metadata.NewIncomingContext(context.Background(), md), nil, &grpc.UnaryServerInfo{FullMethod: "/Package/Unary"}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			_, stage := StartSpan(ctx, "Stage")
+			EndSpan(stage, nil)
+			serverSpan = trace.SpanContextFromContext(ctx)
+			return nil, status.Error(codes.NotFound, "not found")
+		})
+		return err
+	})
+	if status.Code(err) != codes.NotFound {
+		t.Fatal("Expected the server's error, received ", err)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatal("Expected a stage, server and client span, received ", len(spans))
+	}
+	stage, server, client := spans[0], spans[1], spans[2]
+
+	if !server.SpanContext().Equal(serverSpan) {
+		t.Error("Expected the handler to run in the server's span")
+	}
+	if server.Parent().SpanID() != client.SpanContext().SpanID() || server.SpanContext().TraceID() != client.SpanContext().TraceID() {
+		t.Error("Expected the server span to be a child of the client span")
+	}
+	if stage.Parent().SpanID() != server.SpanContext().SpanID() {
+		t.Error("Expected the stage span to be a child of the server span")
+	}
+
+	for _, span := range []sdktrace.ReadOnlySpan{server, client} {
+		if span.Name() != "Package/Unary" {
+			t.Errorf("Expected the span to be named after the method, received %q", span.Name())
+		}
+		if span.Status().Code != otelcodes.Error {
+			t.Error("Expected the failed call's span to have an error status, received ", span.Status())
+		}
+		found := false
+		for _, attribute := range span.Attributes() {
+			if attribute == semconv.RPCGRPCStatusCodeKey.Int(int(codes.NotFound)) {
+				found = true
+			}
+		}
+		if !found {
+			t.Error("Expected the span to record the NotFound status code, received ", span.Attributes())
+		}
+	}
+	if server.SpanKind() != trace.SpanKindServer || client.SpanKind() != trace.SpanKindClient {
+		t.Error("Expected server and client spans, received ", server.SpanKind(), " and ", client.SpanKind())
+	}
+}
+
+func TestTracingStreamInterceptors(t *testing.T) {
+	recorder := recordSpans(t)
+	conn, err := grpc.Dial("localhost:0", grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	stream, err := ClientTracingStreamInterceptor(context.Background(), &grpc.StreamDesc{ServerStreams: true}, conn, "/Package/Stream", func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return &testClientStream{end: io.EOF}, nil
+	})
+	if err != nil {
+		t.Fatal("Expected the stream to open, received ", err)
+	}
+	if len(recorder.Ended()) != 0 {
+		t.Fatal("Expected the span to last while the stream is open")
+	}
+	stream.RecvMsg(nil)
+	stream.RecvMsg(nil)
+	stream.RecvMsg(nil)
+	if spans := recorder.Ended(); len(spans) != 1 || spans[0].Status().Code == otelcodes.Error {
+		t.Fatal("Expected the stream's span to end once, without an error, at io.EOF")
+	}
+
+	err = ServerTracingStreamInterceptor(nil, &testServerStream{ctx: context.Background()}, &grpc.StreamServerInfo{FullMethod: "/Package/Stream", IsServerStream: true}, func(srv interface{}, stream grpc.ServerStream) error {
+		if !trace.SpanContextFromContext(stream.Context()).IsValid() {
+			t.Error("Expected the handler's stream to carry the span")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal("Expected no error, received ", err)
+	}
+	if spans := recorder.Ended(); len(spans) != 2 || spans[1].SpanKind() != trace.SpanKindServer {
+		t.Fatal("Expected the served stream's span to have ended")
+	}
+}
+
+func TestCarrySpan(t *testing.T) {
+	recorder := recordSpans(t)
+
+	incoming, span := StartSpan(logging.NewRequestContext(context.Background(), logrus.Fields{logging.FieldRequestID: "1234"}), "Request")
+	outgoing := CarrySpan(incoming, metadata.AppendToOutgoingContext(context.Background(), "key", "value"))
+	_, child := StartSpan(outgoing, "Call")
+	EndSpan(child, nil)
+	EndSpan(span, nil)
+
+	spans := recorder.Ended()
+	if spans[0].Parent().SpanID() != spans[1].SpanContext().SpanID() {
+		t.Error("Expected the outgoing call's span to be a child of the request's span")
+	}
+	if md, _ := metadata.FromOutgoingContext(outgoing); len(md.Get("key")) != 1 {
+		t.Error("Expected the outgoing context to keep its metadata")
+	}
+	if logging.FromContext(outgoing).Data[logging.FieldRequestID] != "1234" {
+		t.Error("Expected the outgoing context to carry the request's log fields")
+	}
+}
@@ -0,0 +1,10 @@
+module github.com/nicholasbunn/mastersSandbox/src/logging
+
+go 1.13
+
+require (
+	github.com/BurntSushi/toml v1.6.0 // indirect
+	github.com/sirupsen/logrus v1.8.1
+	gopkg.in/natefinch/lumberjack.v2 v2.0.0
+	gopkg.in/yaml.v2 v2.4.0 // indirect
+)
@@ -0,0 +1,169 @@
+package logging
+
+import (
+	// Native packages
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	// Required packages
+	"github.com/sirupsen/logrus"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+/* This package holds the logger shared by every Go service. Log lines are structured (JSON, or
+logfmt to be read by people) and carry the service that wrote them, and the lines written while
+serving a call carry the call's request ID, user, method and trace ID as well (see
+NewRequestContext and FromContext). The logger is set up by each service's main function with
+the logging settings from its configuration file. Until then it writes logfmt to stderr, so
+nothing logged while a service starts up is lost. The level can be changed while the service is
+running, through the handler served next to the service's metrics */
+
+const (
+	// The formats log lines can be written in
+	FormatJSON   = "json"   // One JSON object per line, for log collectors
+	FormatLogfmt = "logfmt" // key=value pairs, for people reading the logs
+
+	// The outputs log lines can be written to
+	OutputStdout = "stdout" // The service's standard output, collected by Docker
+	OutputFile   = "file"   // A file, started afresh (keeping the old ones) once it grows too large
+)
+
+// Logger is the logger every package of a service writes to. Use FromContext while serving a call
+var Logger = logrus.New()
+
+type Config struct {
+	// This struct holds the logging settings of a service, as read from its configuration file
+	Level      string `yaml:"level" default:"info" reload:"true"` // The least severe level that is logged: "debug", "info", "warning" or "error"
+	Format     string `yaml:"format" default:"json"`              // "json" or "logfmt"
+	Output     string `yaml:"output" default:"stdout"`            // "stdout" or "file"
+	File       string `yaml:"file"`                               // Path (relative to the execution directory) of the log file
+	MaxSize    int    `yaml:"maxSize" default:"10"`               // Size (in MB) a log file may grow to before a new one is started
+	MaxBackups int    `yaml:"maxBackups" default:"5"`             // Number of old log files that are kept, zero keeps all of them
+	MaxAge     int    `yaml:"maxAge" default:"30"`                // Number of days old log files are kept for, zero keeps them regardless of age
+}
+
+func Setup(service string, config Config) (stop func(), err error) {
+	/* This function sets the shared logger up as configured, naming the provided service in
+	every line it writes. It returns a function that closes the log file (if any), or an error
+	if the settings are invalid or the log file can't be created */
+	level, err := logrus.ParseLevel(config.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	var formatter logrus.Formatter
+	switch config.Format {
+	case FormatJSON:
+		formatter = &logrus.JSONFormatter{TimestampFormat: timestampFormat}
+	case FormatLogfmt:
+		formatter = &logrus.TextFormatter{DisableColors: true, FullTimestamp: true, TimestampFormat: timestampFormat}
+	default:
+		return nil, fmt.Errorf("%q is not a log format", config.Format)
+	}
+
+	var output io.Writer
+	stop = func() {}
+	switch config.Output {
+	case OutputStdout:
+		output = os.Stdout
+	case OutputFile:
+		// The directory is created up front, so that a missing directory is found now rather than with the first line
+		if err = os.MkdirAll(filepath.Dir(config.File), 0755); err != nil {
+			return nil, err
+		}
+		file := &lumberjack.Logger{
+			Filename:   config.File,
+			MaxSize:    config.MaxSize,
+			MaxBackups: config.MaxBackups,
+			MaxAge:     config.MaxAge,
+		}
+		output = file
+		stop = func() { file.Close() }
+	default:
+		return nil, fmt.Errorf("%q is not a log output", config.Output)
+	}
+
+	Logger.SetFormatter(formatter)
+	Logger.SetOutput(output)
+	Logger.SetLevel(level)
+	Logger.ReplaceHooks(logrus.LevelHooks{})
+	Logger.AddHook(serviceHook(service))
+
+	return stop, nil
+}
+
+func SetLevel(level string) error {
+	// This function changes the least severe level that is logged, while the service is running
+	parsed, err := logrus.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	Logger.SetLevel(parsed)
+	Logger.Infoln("Logging at level ", parsed)
+
+	return nil
+}
+
+func LevelHandler() http.Handler {
+	/* This function returns an HTTP handler that reports the current level on GET, and changes
+	it to the level in the body of a PUT. Changing the level is only allowed from the service's
+	own host, since the handler is served on the same port as the metrics */
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			fmt.Fprintln(w, Logger.GetLevel())
+		case http.MethodPut:
+			if !fromLoopback(r.RemoteAddr) {
+				http.Error(w, "the level can only be changed from the service's host", http.StatusForbidden)
+				return
+			}
+			body, err := ioutil.ReadAll(io.LimitReader(r.Body, 32))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if err := SetLevel(strings.TrimSpace(string(body))); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			fmt.Fprintln(w, Logger.GetLevel())
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			http.Error(w, "use GET or PUT", http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+// ________SUPPORTING FUNCTIONS________
+
+// timestampFormat is RFC 3339 with microseconds, so that the lines of calls served together can be put in order
+const timestampFormat = "2006-01-02T15:04:05.000000Z07:00"
+
+// serviceHook adds the name of the service to every line the logger writes
+type serviceHook string
+
+func (hook serviceHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (hook serviceHook) Fire(entry *logrus.Entry) error {
+	entry.Data[FieldService] = string(hook)
+	return nil
+}
+
+func fromLoopback(remoteAddr string) bool {
+	// This (unexported) function reports whether a request was made from the service's own host
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,146 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func captureLogs(t *testing.T, config Config) *bytes.Buffer {
+	// This function sets the logger up as configured, writing to the returned buffer instead of the configured output
+	stop, err := Setup("TestService", config)
+	if err != nil {
+		t.Fatal("Expected the logger to be set up, received ", err)
+	}
+	buffer := &bytes.Buffer{}
+	Logger.SetOutput(buffer)
+	t.Cleanup(func() {
+		stop()
+		Logger.SetLevel(logrus.InfoLevel)
+	})
+
+	return buffer
+}
+
+func TestSetupRefusesInvalidSettings(t *testing.T) {
+	var Tests = []struct {
+		name   string
+		config Config
+	}{
+		{"Unknown levels are refused", Config{Level: "verbose", Format: FormatJSON, Output: OutputStdout}},
+		{"Unknown formats are refused", Config{Level: "info", Format: "xml", Output: OutputStdout}},
+		{"Unknown outputs are refused", Config{Level: "info", Format: FormatJSON, Output: "syslog"}},
+	}
+
+	for _, test := range Tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := Setup("TestService", test.config); err == nil {
+				t.Error("Expected an error, received none")
+			}
+		})
+	}
+}
+
+func TestRequestFields(t *testing.T) {
+	buffer := captureLogs(t, Config{Level: "debug", Format: FormatJSON, Output: OutputStdout})
+
+	ctx := NewRequestContext(context.Background(), logrus.Fields{FieldRequestID: "1234", FieldMethod: "/Package/Method"})
+	AddField(ctx, FieldUser, "alice")
+	FromContext(ctx).Infoln("Serving the call")
+	FromContext(context.Background()).Debugln("Outside of a call")
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected two lines, received:\n%s", buffer)
+	}
+	var line map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &line); err != nil {
+		t.Fatal("Expected a JSON line, received ", lines[0])
+	}
+	for key, value := range map[string]string{FieldService: "TestService", FieldRequestID: "1234", FieldUser: "alice", FieldMethod: "/Package/Method", "level": "info", "msg": "Serving the call"} {
+		if line[key] != value {
+			t.Errorf("Expected %v to be %q, received %v", key, value, line[key])
+		}
+	}
+	if strings.Contains(lines[1], FieldRequestID) || !strings.Contains(lines[1], "TestService") {
+		t.Error("Expected a line without the call's fields, received ", lines[1])
+	}
+}
+
+func TestLevels(t *testing.T) {
+	buffer := captureLogs(t, Config{Level: "warning", Format: FormatLogfmt, Output: OutputStdout})
+
+	Logger.Infoln("Hidden")
+	Logger.Warnln("Shown")
+	if strings.Contains(buffer.String(), "Hidden") || !strings.Contains(buffer.String(), `level=warning msg=Shown service=TestService`) {
+		t.Fatalf("Expected only the warning in logfmt, received:\n%s", buffer)
+	}
+
+	if err := SetLevel("loud"); err == nil {
+		t.Error("Expected an unknown level to be refused")
+	}
+	if err := SetLevel("debug"); err != nil || Logger.GetLevel() != logrus.DebugLevel {
+		t.Error("Expected the level to change to debug, received ", Logger.GetLevel(), err)
+	}
+}
+
+func TestLevelHandler(t *testing.T) {
+	captureLogs(t, Config{Level: "info", Format: FormatJSON, Output: OutputStdout})
+
+	var Tests = []struct {
+		name       string
+		method     string
+		remoteAddr string
+		body       string
+		status     int
+		level      logrus.Level
+	}{
+		{"The level is reported", http.MethodGet, "10.0.0.2:4000", "", http.StatusOK, logrus.InfoLevel},
+		{"Other hosts can't change the level", http.MethodPut, "10.0.0.2:4000", "debug", http.StatusForbidden, logrus.InfoLevel},
+		{"Unknown levels are refused", http.MethodPut, "127.0.0.1:4000", "loud", http.StatusBadRequest, logrus.InfoLevel},
+		{"The service's host can change the level", http.MethodPut, "127.0.0.1:4000", "debug\n", http.StatusOK, logrus.DebugLevel},
+		{"Other methods are refused", http.MethodPost, "127.0.0.1:4000", "error", http.StatusMethodNotAllowed, logrus.DebugLevel},
+	}
+
+	for _, test := range Tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := httptest.NewRequest(test.method, "/loglevel", strings.NewReader(test.body))
+			request.RemoteAddr = test.remoteAddr
+			recorder := httptest.NewRecorder()
+			LevelHandler().ServeHTTP(recorder, request)
+
+			if recorder.Code != test.status {
+				t.Errorf("Expected status %v, received %v (%s)", test.status, recorder.Code, recorder.Body)
+			}
+			if Logger.GetLevel() != test.level {
+				t.Errorf("Expected level %v, received %v", test.level, Logger.GetLevel())
+			}
+		})
+	}
+}
+
+func TestSetupToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "program logs", "TestService.log")
+	stop, err := Setup("TestService", Config{Level: "info", Format: FormatJSON, Output: OutputFile, File: path, MaxSize: 1})
+	if err != nil {
+		t.Fatal("Expected the logger to be set up, received ", err)
+	}
+	Logger.Infoln("Written to the file")
+	stop()
+
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(contents), `"msg":"Written to the file"`) {
+		t.Errorf("Expected the line in the file, received:\n%s", contents)
+	}
+}
@@ -0,0 +1,89 @@
+package logging
+
+import (
+	// Native packages
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+
+	// Required packages
+	"github.com/sirupsen/logrus"
+)
+
+/* The fields of a call are kept in its context, so that everything logged while serving the
+call can be matched up with it. The server logging interceptors start a request context with
+the call's request ID, method and trace ID, and the authentication interceptors add the user
+once the caller is known. Fields added further down a call's chain are seen by every logger
+made from the call's context, including the interceptors' own once the call has been served */
+
+// The fields that identify the service and the call a line was logged for
+const (
+	FieldService   = "service"
+	FieldRequestID = "request_id"
+	FieldUser      = "user"
+	FieldMethod    = "method"
+	FieldTraceID   = "trace_id"
+)
+
+// requestKey is the (unexported) key the request's fields are stored under in its context
+type requestKey struct{}
+
+type requestFields struct {
+	// This (unexported) struct holds the fields of a call, which can be added to while the call is served
+	mutex  sync.RWMutex
+	fields logrus.Fields
+}
+
+func NewRequestContext(ctx context.Context, fields logrus.Fields) context.Context {
+	// This function returns the provided context with the provided fields of the call it is for
+	copied := make(logrus.Fields, len(fields))
+	for key, value := range fields {
+		copied[key] = value
+	}
+
+	return context.WithValue(ctx, requestKey{}, &requestFields{fields: copied})
+}
+
+func AddField(ctx context.Context, key string, value interface{}) {
+	// This function adds a field to the call of the provided context, it does nothing if the context isn't a request context
+	request, ok := ctx.Value(requestKey{}).(*requestFields)
+	if !ok {
+		return
+	}
+	request.mutex.Lock()
+	request.fields[key] = value
+	request.mutex.Unlock()
+}
+
+func FromContext(ctx context.Context) *logrus.Entry {
+	// This function returns a logger that adds the fields of the call of the provided context to the lines it writes
+	request, ok := ctx.Value(requestKey{}).(*requestFields)
+	if !ok {
+		return logrus.NewEntry(Logger)
+	}
+	request.mutex.RLock()
+	defer request.mutex.RUnlock()
+
+	return Logger.WithFields(request.fields)
+}
+
+func CarryFields(incoming context.Context, outgoing context.Context) context.Context {
+	// This function adds the fields of the incoming call to a context made for the calls made while serving it
+	request, ok := incoming.Value(requestKey{}).(*requestFields)
+	if !ok {
+		return outgoing
+	}
+
+	return context.WithValue(outgoing, requestKey{}, request)
+}
+
+func NewRequestID() string {
+	// This function returns a random ID for a call, 16 bytes written as hex (in the same way trace IDs are)
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		Logger.Warnln("Could not generate a random request ID: ", err)
+	}
+
+	return hex.EncodeToString(id)
+}
@@ -3,23 +3,20 @@ package main
 import (
 	// Native packages
 	"context"
-	"crypto/tls"
-	"crypto/x509"
+	"errors"
 	"fmt"
-	"io/ioutil"
-	"log"
 	"net"
 	"os"
-	"strings"
 	"time"
 
 	// gRPC packages
-	"github.com/go-yaml/yaml"
 	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
-	grpc_retry "github.com/grpc-ecosystem/go-grpc-middleware/retry"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
-	"google.golang.org/grpc/metadata"
+
+	// Personal packages
+	authentication "github.com/nicholasbunn/mastersSandbox/src/authenticationStuff"
+	"github.com/nicholasbunn/mastersSandbox/src/configuration"
 
 	// Proto packages
 	estimateServicePB "github.com/nicholasbunn/mastersSandbox/src/estimateService/proto"
@@ -28,9 +25,15 @@ import (
 	prepareDataServicePB "github.com/nicholasbunn/mastersSandbox/src/prepareDataService/proto"
 
 	// Interceptors
-	"github.com/nicholasbunn/mastersSandbox/src/powerEstimationSP/interceptors"
+	"github.com/nicholasbunn/mastersSandbox/src/interceptors"
+
+	// Logging
+	"github.com/nicholasbunn/mastersSandbox/src/logging"
 )
 
+// metricsJob is the job that this service's metrics are pushed to the pushgateway under
+const metricsJob = "PowerEstimationSP"
+
 var (
 	// Addresses (To be passed in a config file)
 	addrMyself string
@@ -38,175 +41,387 @@ var (
 	addrPS     string
 	addrES     string
 
-	timeoutDuration     int           // The time, in seconds, that the client should wait when dialing (connecting to) the server before throwing an error
-	callTimeoutDuration time.Duration // The time, in seconds, that the client should wait when making a call to the server before throwing an error
+	addrAuthenticationService string
+
+	// The authentication service, it checks the API keys and sessions of the calls served and exchanges their tokens
+	authenticationService *interceptors.RemoteAuthStruct
+
+	// Long-lived connections to the services called (the authentication service and the health checks), closed once the aggregator has drained
+	connections = &interceptors.Connections{}
+
+	// TLS stuff, the aggregator verifies its callers (the desktop gateway) and presents its own certificate to the services it calls
+	serverTLS                 authentication.TLSFiles
+	clientTLS                 authentication.TLSFiles
+	certificateReloadInterval time.Duration // The interval at which the certificate files are checked for changes
+	certificateExpiryWarning  time.Duration // How long before a certificate expires to start logging warnings
+	serverCertificates        *authentication.CertificateManager
+	clientCertificates        *authentication.CertificateManager
+
+	timeoutDuration     configuration.Duration // The time that the client should wait when dialing (connecting to) the server before throwing an error
+	callTimeoutDuration configuration.Duration // The time that the client should wait when making a call to the server before throwing an error
 
 	// Input parameters (To be passed through the frontend)
 	INPUTfilename = "TestData/CMU_2019_2020_openWater.xlsx" // MEEP Need to pass a path relative to the execution directory
 	MODELTYPE     = "OPENWATER"
 
 	// JWT stuff, load this in from config
-	secretkey     string
-	tokenduration time.Duration
+	jwtSecret            *authentication.Secret // The JWT signing secret, resolved from the reference in the config (see authentication.Secret)
+	secretReloadInterval time.Duration          // The interval at which a secret held in a file is checked for rotation
+	tokenduration        time.Duration
+	audience             string // The name of this service, as used in exchanged tokens
+
+	// The names of the services called, exchanged tokens for calls to them are restricted to them
+	audienceFS string
+	audiencePS string
+	audienceES string
+
+	policyFile           string        // The path to the authorisation policy file
+	policyReloadInterval time.Duration // The interval at which the policy file is checked for changes
+
+	// Audit log, shared with the other services
+	auditDirectory string        // The directory (shared by the services) that audit files are written to
+	auditRetention time.Duration // How long audit files are kept for
+	auditLog       *authentication.AuditLog
+
+	sessionCacheDuration time.Duration // How long a session seen to be active is trusted for, before asking the authentication service again
+
+	// Metrics, served for Prometheus to scrape and (optionally) pushed to the pushgateway
+	addrMetrics             string
+	addrPushgateway         string
+	metricsPushEnabled      bool
+	metricsPushInterval     time.Duration // The interval at which changed metrics are pushed
+	metricsMaxBackoff       time.Duration // The longest interval between pushes while the pushgateway is unreachable
+	metricExporter          *interceptors.MetricExporter
+	clientMetricInterceptor *interceptors.ClientMetricStruct
+	serverMetricInterceptor *interceptors.ServerMetricStruct
 
-	accessibleRoles map[string][]string // This is a map of service calls with their required permission levels
+	clientRetryInterceptor *interceptors.ClientRetryStruct // Retries the calls that failed in a way that is safe to retry, see client.retry
 
-	authMethods map[string]bool // This is a map of which service calls require authentication
+	tracingConfig interceptors.TracingConfig // Where the aggregator's spans are exported to
 
-	// Logging stuff
-	DebugLogger   *log.Logger
-	InfoLogger    *log.Logger
-	WarningLogger *log.Logger
-	ErrorLogger   *log.Logger
+	loggingConfig logging.Config // How the aggregator's log lines are written, the logger is set up with it in main
 
-	// Metric interceptors
-	clientMetricInterceptor *interceptors.ClientMetricStruct
-	serverMetricInterceptor *interceptors.ServerMetricStruct
+	// Health checks, the aggregator is ready while it can reach the services it calls
+	healthInterval time.Duration // The interval at which the health checks are run
+	healthTimeout  time.Duration // How long a health check may take before it fails
+
+	drainTimeout time.Duration // How long the calls in flight are given to finish when the aggregator is stopped
+
+	// Configuration, reloaded while the aggregator is running (see applyConfig)
+	configLoader         *configuration.Loader
+	loadedConfig         *Config
+	configReloadInterval time.Duration // The interval at which the configuration file is checked for changes
 )
 
 func init() {
-	/* The init function is used to load in configuration variables, and set up the logger and metric interceptors whenever the service is started
+	/* The init function is used to load in configuration variables, and set up the metric interceptors whenever the service is started
 	 */
 
 	// ________CONFIGURATION________
-	// Load YAML configurations into config struct
-	config, _ := DecodeConfig("src/powerEstimationSP/configuration.yaml")
+	/* Load the configuration (the defaults, then the file, then POWERESTIMATIONSP_* environment
+	variables) into the config struct, refusing to start if anything is missing or insecure */
+	configLoader = configuration.NewLoader("power estimation aggregator", "src/powerEstimationSP/configuration.yaml", "POWERESTIMATIONSP")
+	configCheck := authentication.NewConfigCheck("power estimation aggregator", configLoader.Path)
+	config := &Config{}
+	if err := configLoader.Load(config); err != nil {
+		configCheck.Problem(configLoader.Path, "could not be loaded, services have to be started from the repository root: %v", err)
+		configCheck.Enforce()
+	}
+	if configLoader.PrintRequested {
+		if err := configLoader.Print(os.Stdout, config); err != nil {
+			logging.Logger.Fatalf("Failed to print the configuration: \n%v", err)
+		}
+		os.Exit(0)
+	}
+	validateConfig(config, configCheck)
+	jwtSecret = configCheck.CheckSecretReference("server.authentication.jwt.secretKey", config.Server.Authentication.Jwt.SecretKey)
+	configCheck.Enforce()
+	loadedConfig = config
+	configReloadInterval = time.Duration(config.Server.Configuration.ReloadInterval) * time.Second
+
+	addrMyself = config.Server.Host + ":" + config.Server.Port.Myself
+	addrFS = config.Client.Host.FetchService + ":" + config.Client.Port.FetchService
+	addrPS = config.Client.Host.PrepareService + ":" + config.Client.Port.PrepareService
+	addrES = config.Client.Host.EstimationService + ":" + config.Client.Port.EstimationService
+	addrAuthenticationService = config.Client.Host.AuthenticationService + ":" + config.Client.Port.AuthenticationService
+	authenticationService = &interceptors.RemoteAuthStruct{
+		Address:     addrAuthenticationService,
+		Credentials: loadClientTLSCredentials, // The client certificates are loaded in main, before the first call
+		CallTimeout: callTimeoutDuration.Get,
+		Connections: connections,
+	}
 
-	addrMyself = os.Getenv("POWERESTIMATIONHOST") + ":" + config.Server.Port.Myself
-	addrFS = os.Getenv("FETCHHOST") + ":" + config.Client.Port.FetchService
-	addrPS = os.Getenv("PREPAREHOST") + ":" + config.Client.Port.PrepareService
-	addrES = os.Getenv("ESTIMATEHOST") + ":" + config.Client.Port.EstimationService
+	// Load TLS parameters from config
+	serverTLS = config.Server.TLS
+	clientTLS = config.Client.TLS
+	certificateReloadInterval = time.Duration(config.Server.Certificates.ReloadInterval) * time.Second
+	certificateExpiryWarning = time.Duration(config.Server.Certificates.ExpiryWarning) * 24 * time.Hour
 
 	// Load timeouts from config
-	timeoutDuration = config.Client.Timeout.Connection
-	fmt.Println(timeoutDuration)
-	callTimeoutDuration = time.Duration(config.Client.Timeout.Call) * time.Second
-	fmt.Println(callTimeoutDuration)
+	timeoutDuration.Set(time.Duration(config.Client.Timeout.Connection) * time.Second)
+	callTimeoutDuration.Set(time.Duration(config.Client.Timeout.Call) * time.Second)
 
 	// Load JWT parameters from config
-	secretkey = config.Server.Authentication.Jwt.SecretKey
-	fmt.Println(secretkey)
+	secretReloadInterval = time.Duration(config.Server.Authentication.Jwt.ReloadInterval) * time.Second
 	tokenduration = time.Duration(config.Server.Authentication.Jwt.TokenDuration) * (time.Minute)
-	fmt.Println(tokenduration)
+	audience = config.Server.Authentication.Jwt.Audience
+	audienceFS = config.Client.Audience.FetchService
+	audiencePS = config.Client.Audience.PrepareService
+	audienceES = config.Client.Audience.EstimationService
+
+	// Load authorisation policy parameters from config
+	policyFile = config.Server.Authentication.Policy.File
+	policyReloadInterval = time.Duration(config.Server.Authentication.Policy.ReloadInterval) * time.Second
+
+	// Load audit log parameters from config
+	auditDirectory = config.Server.Audit.Directory
+	auditRetention = time.Duration(config.Server.Audit.Retention) * 24 * time.Hour
+
+	// Load session parameters from config
+	sessionCacheDuration = time.Duration(config.Server.Sessions.CacheDuration) * time.Second
+
+	// Load metric parameters from config
+	addrMetrics = config.Server.Host + ":" + config.Server.Metrics.Port
+	addrPushgateway = config.Server.Metrics.Push.Host + ":" + config.Server.Metrics.Push.Port
+	metricsPushEnabled = config.Server.Metrics.Push.Enabled
+	metricsPushInterval = time.Duration(config.Server.Metrics.Push.Interval) * time.Second
+	metricsMaxBackoff = time.Duration(config.Server.Metrics.Push.MaxBackoff) * time.Second
+
+	// Load tracing parameters from config
+	tracingConfig = config.Server.Tracing
+
+	// Load logging parameters from config
+	loggingConfig = config.Server.Logging
+
+	// Load health check parameters from config
+	healthInterval = time.Duration(config.Server.Health.Interval) * time.Second
+	healthTimeout = time.Duration(config.Server.Health.Timeout) * time.Second
+
+	// Load shutdown parameters from config
+	drainTimeout = time.Duration(config.Server.Shutdown.DrainTimeout) * time.Second
+
+	// Metric interceptors, registered on the aggregator's own metrics registry
+	metricExporter = interceptors.NewMetricExporter(metricsJob)
+	metricExporter.Registerer.MustRegister(authentication.CertificateMetrics())
+	clientMetricInterceptor = interceptors.NewClientMetrics(metricExporter) // Custom metric (Prometheus) interceptor
+	serverMetricInterceptor = interceptors.NewServerMetrics(metricExporter) // Custom metric (Prometheus) interceptor
+
+	// Retry policies of the methods called, counted on the same registry
+	clientRetryInterceptor = interceptors.NewClientRetries(metricExporter, config.Client.Retry)
+}
 
-	accessibleRoles = map[string][]string{
-		config.Server.Authentication.AccessLevel.Name.PowerEstimate:  config.Server.Authentication.AccessLevel.Role.PowerEstimate,
-		config.Server.Authentication.AccessLevel.Name.PowerEvaluator: config.Server.Authentication.AccessLevel.Role.PowerEvaluator,
+func main() {
+	/* The main function sets up a server to listen on the specified port,
+	encrypts the server connection with TLS, and registers the services on
+	offer */
+
+	// Set up the logger first, anything logged before this (while loading the configuration) went to stderr
+	stopLogging, err := logging.Setup(metricsJob, loggingConfig)
+	if err != nil {
+		logging.Logger.Fatalf("Failed to set up logging: \n%v", err)
 	}
-	fmt.Println(accessibleRoles)
+	defer stopLogging()
+	logging.Logger.Infoln("Started aggregator")
 
-	authMethods = map[string]bool{
-		config.Client.AuthenticatedMethods.Name.FetchDataService:   config.Client.AuthenticatedMethods.RequiresAuthentication.FetchDataService,
-		config.Client.AuthenticatedMethods.Name.PrepareDataService: config.Client.AuthenticatedMethods.RequiresAuthentication.PrepareDataService,
-		config.Client.AuthenticatedMethods.Name.EstimateService:    config.Client.AuthenticatedMethods.RequiresAuthentication.EstimateService,
+	// Reload the configuration on SIGHUP or when its file changes, applying the settings that can be changed while running
+	stopWatchingConfig := configLoader.Watch(loadedConfig, configReloadInterval, checkReloadedConfig, applyConfig)
+	defer stopWatchingConfig()
+
+	// Load in TLS credentials and watch them for changes
+	if serverCertificates, err = loadCertificates("server", serverTLS); err != nil {
+		logging.Logger.Fatalf("Failed to load TLS credentials: \n%v", err)
+	}
+	if clientCertificates, err = loadCertificates("client", clientTLS); err != nil {
+		logging.Logger.Fatalf("Failed to load TLS credentials: \n%v", err)
 	}
-	fmt.Println(authMethods)
+	creds := credentials.NewTLS(serverCertificates.ServerTLSConfig())
+	logging.Logger.Debugln("Succesfully loaded TLS certificates")
 
-	// If the file doesn't exist, create it, otherwise append to the file
-	pathSlice := strings.Split(os.Args[0], "/") // This just extracts the services name (filename)
-	file, err := os.OpenFile("program logs/"+pathSlice[len(pathSlice)-1]+".log", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0666)
+	// Create a listener on the specified tcp port
+	listener, err := net.Listen("tcp", addrMyself)
 	if err != nil {
-		// If opening the log file throws an error, continue to create the loggers but print to terminal instead
-		log.Println("Unable to initialise log file, good luck :)")
-	} else {
-		log.SetOutput(file)
+		logging.Logger.Fatalf("Failed to listen on port %v: \n%v", addrMyself, err)
 	}
+	logging.Logger.Infoln("Listening on port: ", addrMyself)
 
-	DebugLogger = log.New(file, "DEBUG: ", log.Ldate|log.Ltime|log.Lmicroseconds|log.Lshortfile)
-	InfoLogger = log.New(file, "INFO: ", log.Ldate|log.Ltime|log.Lmicroseconds|log.Lshortfile)
-	WarningLogger = log.New(file, "WARNING: ", log.Ldate|log.Ltime|log.Lmicroseconds|log.Lshortfile)
-	ErrorLogger = log.New(file, "ERROR: ", log.Ldate|log.Ltime|log.Lmicroseconds|log.Lshortfile)
-
-	// Metric interceptor
-	clientMetricInterceptor = interceptors.NewClientMetrics() // Custom metric (Prometheus) interceptor
-	serverMetricInterceptor = interceptors.NewServerMetrics() // Custom metric (Prometheus) interceptor
-}
+	// Load the authorisation policy and watch it for changes
+	policyManager, err := authentication.NewPolicyManager(policyFile)
+	if err != nil {
+		logging.Logger.Fatalf("Failed to load authorisation policy: \n%v", err)
+	}
+	policyManager.Watch(policyReloadInterval)
+	logging.Logger.Debugln("Succesfully loaded authorisation policy")
 
-func main() {
-	/* The main function sets up a server to listen on the specified port,
-	encrypts the server connection with TLS, and registers the services on
-	offer */
+	// Watch the JWT secret so that a rotated secret is picked up without restarting
+	jwtSecret.Watch(secretReloadInterval)
+	logging.Logger.Infoln("Using JWT secret ", jwtSecret)
 
-	InfoLogger.Println("Started aggregator")
+	// Open the audit log, authorisation decisions are recorded in it
+	auditLog, err = authentication.NewAuditLog(auditDirectory, audience, auditRetention)
+	if err != nil {
+		logging.Logger.Fatalf("Failed to open audit log: \n%v", err)
+	}
+	defer auditLog.Close()
+	logging.Logger.Debugln("Succesfully opened audit log")
 
-	// Load in TLS credentials
-	creds, err := loadTLSCredentials()
+	// Serve the metrics for Prometheus to scrape, and push them to the pushgateway in the background if enabled
+	stopServingMetrics, err := metricExporter.Serve(addrMetrics)
 	if err != nil {
-		ErrorLogger.Fatalf("Failed to load TLS credentials")
-	} else {
-		DebugLogger.Println("Succesfully loaded TLS certificates")
+		logging.Logger.Fatalf("Failed to serve metrics on %v: \n%v", addrMetrics, err)
+	}
+	defer stopServingMetrics()
+	if metricsPushEnabled {
+		stopPushingMetrics := metricExporter.StartPushing(addrPushgateway, metricsPushInterval, metricsMaxBackoff)
+		defer stopPushingMetrics()
 	}
 
-	// Create a listener on the specified tcp port
-	listener, err := net.Listen("tcp", addrMyself)
+	// Export the spans of the calls served and made, and of the stages of the estimation pipeline
+	stopTracing, err := interceptors.StartTracing(metricsJob, tracingConfig)
 	if err != nil {
-		ErrorLogger.Fatalf("Failed to listen on port %v: \n%v", addrMyself, err)
+		logging.Logger.Fatalf("Failed to start tracing: \n%v", err)
+	}
+	defer stopTracing()
+
+	// Create the interceptors required for this connection
+	authInterceptor := interceptors.ServerAuthStruct{ // Custom auth (JWT) interceptor
+		JwtManager: authentication.NewJWTManager(jwtSecret, tokenduration),
+		Policy:     policyManager,
+		APIKeys:    authenticationService,                                                                     // API keys are checked by the authentication service
+		Sessions:   authentication.NewSessionCache(sessionCacheDuration, authenticationService.VerifySession), // And so are the sessions of JWTs
+		Audience:   audience,
+		Audit:      auditLog,
 	}
-	InfoLogger.Println("Listening on port: ", addrMyself)
+	// Create interceptor chains (for unary and streaming calls) with the above interceptors
+	interceptorChain := grpc_middleware.ChainUnaryServer(
+		interceptors.ServerRecoveryInterceptor,
+		interceptors.ServerTracingInterceptor,
+		interceptors.ServerRequestIDInterceptor,
+		interceptors.ServerLoggingInterceptor,
+		serverMetricInterceptor.ServerMetricInterceptor,
+		authInterceptor.ServerAuthInterceptor,
+	)
+	streamInterceptorChain := grpc_middleware.ChainStreamServer(
+		interceptors.ServerRecoveryStreamInterceptor,
+		interceptors.ServerTracingStreamInterceptor,
+		interceptors.ServerRequestIDStreamInterceptor,
+		interceptors.ServerLoggingStreamInterceptor,
+		serverMetricInterceptor.ServerMetricStreamInterceptor,
+		authInterceptor.ServerAuthStreamInterceptor,
+	)
 
 	// Create a gRPC server object
 	estimationServer := grpc.NewServer(
 		grpc.Creds(creds),
-		grpc.UnaryInterceptor(serverMetricInterceptor.ServerMetricInterceptor), // Add the interceptor to this server
+		grpc.UnaryInterceptor(interceptorChain),        // Add the interceptor chain to this server
+		grpc.StreamInterceptor(streamInterceptorChain), // And the stream interceptor chain, so that streaming RPCs are covered too
 	)
 
 	// Attach the power-train estimation service offering to the server
 	serverPB.RegisterPowerEstimationServicePackageServer(estimationServer, &server{})
-	DebugLogger.Println("Succesfully registered Power Estimation Service Package to the server")
+	logging.Logger.Debugln("Succesfully registered Power Estimation Service Package to the server")
 
-	// Start the server
-	if err := estimationServer.Serve(listener); err != nil {
-		ErrorLogger.Fatalf("Failed to expose service: \n%v", err)
+	// Attach the health service, and check the services the aggregator calls in the background
+	defer connections.Close()
+	healthChecker, err := newHealthChecker()
+	if err != nil {
+		logging.Logger.Fatalf("Failed to set up health checks: \n%v", err)
+	}
+	healthChecker.Register(estimationServer)
+	stopHealthChecks := healthChecker.Start()
+	defer stopHealthChecks()
+	logging.Logger.Debugln("Succesfully registered the health service to the server")
+
+	// Start the server, and drain it when the aggregator is stopped
+	if err := interceptors.ServeUntilStopped(estimationServer, listener, healthChecker, drainTimeout); err != nil {
+		logging.Logger.Fatalf("Failed to expose service: \n%v", err)
 	}
+	logging.Logger.Infoln("Stopped aggregator")
 }
 
 // ________REQUIRED STRUCTS________
 
 type Config struct {
+	/* This struct holds the aggregator's configuration. Each field is a key of the configuration
+	file, see the configuration package for the default, validate, secret and reload tags */
 	Server struct {
+		Host string `yaml:"host"`
 		Port struct {
-			Myself string `yaml:"myself"`
+			Myself string `yaml:"myself" validate:"port"`
 		} `yaml:"port"`
+		TLS          authentication.TLSFiles `yaml:"tls"`
+		Certificates struct {
+			ReloadInterval int `yaml:"reloadInterval" default:"60" validate:"positive"`
+			ExpiryWarning  int `yaml:"expiryWarning" default:"30"`
+		} `yaml:"certificates"`
 		Authentication struct {
 			Jwt struct {
-				SecretKey     string `yaml:"secretKey"`
-				TokenDuration int    `yaml:"tokenDuration"`
+				SecretKey      string `yaml:"secretKey" secret:"true"`
+				TokenDuration  int    `yaml:"tokenDuration" default:"15" validate:"positive"`
+				ReloadInterval int    `yaml:"reloadInterval" default:"30" validate:"positive"`
+				Audience       string `yaml:"audience" validate:"required"`
 			} `yaml:"jwt"`
-			AccessLevel struct {
-				Name struct {
-					PowerEstimate  string `yaml:"powerEstimate"`
-					PowerEvaluator string `yaml:"powerEvaluator"`
-				} `yaml:"name"`
-				Role struct {
-					PowerEstimate  []string `yaml:"powerEstimate"`
-					PowerEvaluator []string `yaml:"powerEvaluator"`
-				} `yaml:"role"`
-			} `yaml:"accessLevel"`
+			Policy struct {
+				File           string `yaml:"file" default:"authorisation/policy.yaml"`
+				ReloadInterval int    `yaml:"reloadInterval" default:"30" validate:"positive"`
+			} `yaml:"policy"`
 		} `yaml:"authentication"`
+		Audit struct {
+			Directory string `yaml:"directory" default:"audit" validate:"required"`
+			Retention int    `yaml:"retention" default:"90"`
+		} `yaml:"audit"`
+		Sessions struct {
+			CacheDuration int `yaml:"cacheDuration" default:"10" validate:"positive"`
+		} `yaml:"sessions"`
+		Metrics struct {
+			Port string `yaml:"port" validate:"port"`
+			Push struct {
+				Enabled    bool   `yaml:"enabled"`
+				Host       string `yaml:"host" default:"localhost"`
+				Port       string `yaml:"port" default:"9091"`
+				Interval   int    `yaml:"interval" default:"15"`
+				MaxBackoff int    `yaml:"maxBackoff" default:"120"`
+			} `yaml:"push"`
+		} `yaml:"metrics"`
+		Tracing interceptors.TracingConfig `yaml:"tracing"`
+		Logging logging.Config             `yaml:"logging"`
+		Health  struct {
+			Interval int `yaml:"interval" default:"10" validate:"positive"`
+			Timeout  int `yaml:"timeout" default:"3" validate:"positive"`
+		} `yaml:"health"`
+		Shutdown struct {
+			DrainTimeout int `yaml:"drainTimeout" default:"30" validate:"positive"`
+		} `yaml:"shutdown"`
+		Configuration struct {
+			ReloadInterval int `yaml:"reloadInterval" default:"30" validate:"positive"`
+		} `yaml:"configuration"`
 	} `yaml:"server"`
 
 	Client struct {
+		Host struct {
+			FetchService      string `yaml:"fetch" default:"localhost" validate:"required"`
+			PrepareService    string `yaml:"prepare" default:"localhost" validate:"required"`
+			EstimationService string `yaml:"estimation" default:"localhost" validate:"required"`
+
+			AuthenticationService string `yaml:"authenticationService" default:"localhost" validate:"required"`
+		} `yaml:"host"`
 		Port struct {
-			FetchService      string `yaml:"fetch"`
-			PrepareService    string `yaml:"prepare"`
-			EstimationService string `yaml:"estimation"`
+			FetchService      string `yaml:"fetch" validate:"port"`
+			PrepareService    string `yaml:"prepare" validate:"port"`
+			EstimationService string `yaml:"estimation" validate:"port"`
+
+			AuthenticationService string `yaml:"authenticationService" validate:"port"`
 		} `yaml:"port"`
+		TLS      authentication.TLSFiles `yaml:"tls"`
+		Audience struct {
+			FetchService      string `yaml:"fetch" validate:"required"`
+			PrepareService    string `yaml:"prepare" validate:"required"`
+			EstimationService string `yaml:"estimation" validate:"required"`
+		} `yaml:"audience"`
 		Timeout struct {
-			Connection int `yaml:"connection"`
-			Call       int `yaml:"call"`
-		} `yaml:"timeout"`
-		AuthenticatedMethods struct {
-			Name struct {
-				FetchDataService   string `yaml:"fetchDataService"`
-				PrepareDataService string `yaml:"prepareDataService"`
-				EstimateService    string `yaml:"estimateService"`
-			} `yaml:"name"`
-			RequiresAuthentication struct {
-				FetchDataService   bool `yaml:"fetchDataService"`
-				PrepareDataService bool `yaml:"prepareDataService"`
-				EstimateService    bool `yaml:"estimateService"`
-			} `yaml:"requiresAuthentication"`
-		} `yaml:"authenticatedMethods"`
+			Connection int `yaml:"connection" default:"5" validate:"positive"`
+			Call       int `yaml:"call" default:"15" validate:"positive"`
+		} `yaml:"timeout" reload:"true"`
+		Retry interceptors.RetryConfig `yaml:"retry" reload:"true"`
 	} `yaml:"client"`
 }
 
@@ -223,101 +438,106 @@ func (s *server) PowerEstimatorService(ctx context.Context, request *serverPB.Se
 	of the power required for the provided route. It first colelcts the required wave
 	data, then sends it to a processing service which structures the data for a ML
 	algorithm, before finally sending the structured data into the model for a
-	prediction */
+	prediction. Each of these stages is traced as a span of its own, under the span of the
+	call being served */
 
-	InfoLogger.Println("Received Power Estimator service call")
+	logging.FromContext(ctx).Infoln("Received Power Estimator service call")
 
 	// Load in credentials for the servers
-	creds, err := loadTLSCredentials()
+	creds := loadClientTLSCredentials()
+
+	/* Exchange the caller's token for short-lived tokens that are each only accepted by
+	one of the services called, rather than passing the caller's own token on */
+	exchangeContext, span := interceptors.StartSpan(ctx, "Exchange tokens")
+	tokenFS, err := authenticationService.ExchangeToken(exchangeContext, audienceFS)
 	if err != nil {
-		ErrorLogger.Printf("Error loading TLS credentials")
+		interceptors.EndSpan(span, err)
 		return nil, err
-	} else {
-		DebugLogger.Println("Succesfully loaded TLS certificates")
 	}
-
-	// Extract the user's JWT from the incoming request. Can ignore the ok output as ths has already been checked.
-	md, _ := metadata.FromIncomingContext(ctx)
-
-	// Create the interceptors required for this connection
-	clientAuthInterceptor := interceptors.ClientAuthStruct{ // Custom auth (JWT) interceptor
-		AccessToken:          md["authorisation"][0], // Pass the user's JWT to the outgoing request
-		AuthenticatedMethods: authMethods,
+	tokenPS, err := authenticationService.ExchangeToken(exchangeContext, audiencePS)
+	if err != nil {
+		interceptors.EndSpan(span, err)
+		return nil, err
 	}
-
-	// Create the retry options to specify how the client should retry connection interrupts
-	retryOptions := []grpc_retry.CallOption{
-		grpc_retry.WithBackoff(grpc_retry.BackoffExponential(100 * time.Millisecond)), // Use exponential backoff to progressively wait longer between retries
-		grpc_retry.WithMax(5), // Set the maximum number of retries
+	tokenES, err := authenticationService.ExchangeToken(exchangeContext, audienceES)
+	interceptors.EndSpan(span, err)
+	if err != nil {
+		return nil, err
 	}
 
-	// Create an interceptor chain with the above interceptors
-	interceptorChain := grpc_middleware.ChainUnaryClient(
-		clientMetricInterceptor.ClientMetricInterceptor,
-		clientAuthInterceptor.ClientAuthInterceptor,
-		grpc_retry.UnaryClientInterceptor(retryOptions...),
-	)
-
 	// Create an secure connection to the fetch data server
+	_, span = interceptors.StartSpan(ctx, "Connect to services")
+	interceptorFS, streamInterceptorFS := clientInterceptorChains(tokenFS)
 	connFS, err := createSecureServerConnection(
-		addrFS,           // Set the address of the server
-		creds,            // Add the TLS credentials
-		timeoutDuration,  // Set the duration the client will wait before timing out
-		interceptorChain, // Add the interceptor to this server
+		addrFS,                // Set the address of the server
+		creds,                 // Add the TLS credentials
+		timeoutDuration.Get(), // Set the duration the client will wait before timing out
+		interceptorFS,         // Add the interceptor to this server
+		streamInterceptorFS,   // And the stream interceptor
 	)
 	if err != nil {
+		interceptors.EndSpan(span, err)
 		return nil, err
 	}
+	defer connFS.Close()
 
 	// Create an secure connection to the prepare data server
+	interceptorPS, streamInterceptorPS := clientInterceptorChains(tokenPS)
 	connPS, err := createSecureServerConnection(
-		addrPS,           // Set the address of the server
-		creds,            // Add the TLS credentials
-		timeoutDuration,  // Set the duration the client will wait before timing out
-		interceptorChain, // Add the interceptor to this server
+		addrPS,                // Set the address of the server
+		creds,                 // Add the TLS credentials
+		timeoutDuration.Get(), // Set the duration the client will wait before timing out
+		interceptorPS,         // Add the interceptor to this server
+		streamInterceptorPS,   // And the stream interceptor
 	)
 	if err != nil {
+		interceptors.EndSpan(span, err)
 		return nil, err
 	}
+	defer connPS.Close()
 
 	// Create an secure connection to the estimation server
+	interceptorES, streamInterceptorES := clientInterceptorChains(tokenES)
 	connES, err := createSecureServerConnection(
-		addrES,           // Set the address of the server
-		creds,            // Add the TLS credentials
-		timeoutDuration,  // Set the duration the client will wait before timing out
-		interceptorChain, // Add the interceptor to this server
+		addrES,                // Set the address of the server
+		creds,                 // Add the TLS credentials
+		timeoutDuration.Get(), // Set the duration the client will wait before timing out
+		interceptorES,         // Add the interceptor to this server
+		streamInterceptorES,   // And the stream interceptor
 	)
+	interceptors.EndSpan(span, err)
 	if err != nil {
 		return nil, err
 	}
+	defer connES.Close()
 
 	/* Create the clients and pass the connections made above to them. After the clients have been created, we create the gRPC requests */
-	InfoLogger.Println("Creating Clients")
+	logging.FromContext(ctx).Infoln("Creating Clients")
 	clientFS := fetchDataServicePB.NewFetchDataClient(connFS)     // fetch data service client
 	clientPS := prepareDataServicePB.NewPrepareDataClient(connPS) // prepare data service client
 	clientES := estimateServicePB.NewEstimatePowerClient(connES)  // estimate service client
-	DebugLogger.Println("Succesfully created the GoLang clients")
+	logging.FromContext(ctx).Debugln("Succesfully created the GoLang clients")
 
 	// Create the request message for the fetch data service
 	requestMessageFS := fetchDataServicePB.FetchDataRequestMessage{
 		InputFile: request.InputFile,
 	}
-	DebugLogger.Println("Succesfully created a FetchDataRequestMessage")
+	logging.FromContext(ctx).Debugln("Succesfully created a FetchDataRequestMessage")
 
 	// Make the service call to the fetch data server
-	InfoLogger.Println("Making FetchData service call")
-	fetchDataContext, cancel := context.WithTimeout(context.Background(), callTimeoutDuration)
+	logging.FromContext(ctx).Infoln("Making FetchData service call")
+	stageContext, span := interceptors.StartSpan(ctx, "Fetch data")
+	fetchDataContext, cancel := context.WithTimeout(interceptors.CarrySpan(stageContext, context.Background()), callTimeoutDuration.Get())
 	defer cancel()
 	// Invoke the fetch data service
 	responseMessageFS, err := clientFS.FetchDataService(fetchDataContext, &requestMessageFS) // The responseMessageFS is a RawDataMessage
-	// Handle errors, if any, otherwise, close the connection
+	interceptors.EndSpan(span, err)
+	// Handle errors, if any
 	if err != nil {
-		ErrorLogger.Println("Failed to make the fetch data service call: ")
+		logging.FromContext(ctx).Errorln("Failed to make the fetch data service call: ")
 		return nil, err
-	} else {
-		DebugLogger.Println("Succesfully made service call to fetch data server.")
-		connFS.Close()
 	}
+	logging.FromContext(ctx).Debugln("Succesfully made service call to fetch data server.")
 
 	/* Create the request message for the prepare data service with the response
 	from the fetch data service */
@@ -365,19 +585,19 @@ func (s *server) PowerEstimatorService(ctx context.Context, request *serverPB.Se
 	}
 
 	// Make the service call to the prepare data server
-	InfoLogger.Println("Making PrepareEstimateData service call.")
-	prepareDataContext, cancel := context.WithTimeout(context.Background(), callTimeoutDuration)
+	logging.FromContext(ctx).Infoln("Making PrepareEstimateData service call.")
+	stageContext, span = interceptors.StartSpan(ctx, "Prepare data")
+	prepareDataContext, cancel := context.WithTimeout(interceptors.CarrySpan(stageContext, context.Background()), callTimeoutDuration.Get())
 	defer cancel()
 	// Invoke the prepare data service
 	responseMessagePS, err := clientPS.PrepareEstimateDataService(prepareDataContext, &requestMessagePS)
-	// Handle errors, if any, otherwise, close the connection
+	interceptors.EndSpan(span, err)
+	// Handle errors, if any
 	if err != nil {
-		ErrorLogger.Println("Failed to make PrepareData service call: ")
+		logging.FromContext(ctx).Errorln("Failed to make PrepareData service call: ")
 		return nil, err
-	} else {
-		DebugLogger.Println("Succesfully made service call to python prepareDataServer.")
-		connPS.Close()
 	}
+	logging.FromContext(ctx).Debugln("Succesfully made service call to python prepareDataServer.")
 
 	/* Create the request message for the estimate service with the response
 	from both the fetch data and prepare data services */
@@ -410,19 +630,19 @@ func (s *server) PowerEstimatorService(ctx context.Context, request *serverPB.Se
 	}
 
 	// Make the service call to the estimate server
-	InfoLogger.Println("Making EstimateRequestMessage service call.")
+	logging.FromContext(ctx).Infoln("Making EstimateRequestMessage service call.")
 	// Invoke the estimate service
-	estimateContext, cancel := context.WithTimeout(context.Background(), callTimeoutDuration)
+	stageContext, span = interceptors.StartSpan(ctx, "Estimate power")
+	estimateContext, cancel := context.WithTimeout(interceptors.CarrySpan(stageContext, context.Background()), callTimeoutDuration.Get())
 	defer cancel()
-	// Handle errors, if any, otherwise, close the connection
+	// Handle errors, if any
 	responseMessageES, err := clientES.EstimatePowerService(estimateContext, &requestMessageES)
+	interceptors.EndSpan(span, err)
 	if err != nil {
-		ErrorLogger.Println("Failed to make Estimate service call: ")
+		logging.FromContext(ctx).Errorln("Failed to make Estimate service call: ")
 		return nil, err
-	} else {
-		DebugLogger.Println("Succesfully made service call to Python estimateServer.")
-		connPS.Close()
 	}
+	logging.FromContext(ctx).Debugln("Succesfully made service call to Python estimateServer.")
 
 	// Create and populate the response message for the request being served
 	responseMessage := serverPB.EstimateResponseMessage{
@@ -434,72 +654,162 @@ func (s *server) PowerEstimatorService(ctx context.Context, request *serverPB.Se
 
 // ________SUPPORTING FUNCTIONS________
 
-func DecodeConfig(configPath string) (*Config, error) {
-	// Create a new config structure
-	config := &Config{}
-
-	// Open the config file
-	file, err := os.Open(configPath)
-	if err != nil {
-		fmt.Println("Could not open config file")
-		return nil, err
+func validateConfig(config *Config, check *authentication.ConfigCheck) {
+	/* This function checks every setting the service needs before it starts, recording the problems found in the provided check.
+	The settings with a validate tag (see the Config struct) are checked by the configuration package */
+	configuration.Validate(config, check)
+	check.CheckServerTLS("server.tls", config.Server.TLS)
+	check.RequireFile("server.authentication.policy.file", config.Server.Authentication.Policy.File, "")
+	if config.Server.Metrics.Push.Enabled {
+		check.RequireValue("server.metrics.push.host", config.Server.Metrics.Push.Host)
+		check.RequirePort("server.metrics.push.port", config.Server.Metrics.Push.Port)
+		check.RequirePositive("server.metrics.push.interval", config.Server.Metrics.Push.Interval)
+		check.RequirePositive("server.metrics.push.maxBackoff", config.Server.Metrics.Push.MaxBackoff)
 	}
-	defer file.Close()
-
-	// Initialise a new YAML decoder
-	decoder := yaml.NewDecoder(file)
+	config.Server.Tracing.Check("server.tracing", check)
+	check.CheckLogging("server.logging", config.Server.Logging)
+	check.CheckClientTLS("client.tls", config.Client.TLS)
+	config.Client.Retry.Check("client.retry", check)
+}
 
-	// Start YAML decoding from file
-	if err := decoder.Decode(&config); err != nil {
-		fmt.Println("Could not decode config file: \n", err)
-		return nil, err
+func checkReloadedConfig(loaded interface{}) error {
+	// This (unexported) function checks a reloaded configuration in the same way as the one the aggregator started with
+	check := authentication.NewConfigCheck("power estimation aggregator", configLoader.Path)
+	validateConfig(loaded.(*Config), check)
+	if check.Failed() {
+		return errors.New(check.Report())
 	}
 
-	return config, nil
+	return nil
 }
 
-func loadTLSCredentials() (credentials.TransportCredentials, error) {
-	/* This (unexported) function loads TLS credentials for both the client and server,
-	enabling mutual TLS authentication between the client and server. It takes no inputs and returns a gRPC TransportCredentials object. */
+func applyConfig(loaded interface{}) {
+	/* This (unexported) function applies the settings of a reloaded configuration that can be
+	changed while the aggregator is running (those tagged reload:"true" in the Config struct),
+	the log level, the client timeouts and the retry policies. Calls already made keep their
+	timeouts */
+	config := loaded.(*Config)
+	if err := logging.SetLevel(config.Server.Logging.Level); err != nil {
+		logging.Logger.Warnln("Could not change the log level: ", err)
+	}
+	timeoutDuration.Set(time.Duration(config.Client.Timeout.Connection) * time.Second)
+	callTimeoutDuration.Set(time.Duration(config.Client.Timeout.Call) * time.Second)
+	clientRetryInterceptor.Update(config.Client.Retry)
+}
 
-	// Load certificate of the CA who signed server's certificate
-	pemServerCA, err := ioutil.ReadFile("certification/ca-cert.pem")
+func loadCertificates(name string, files authentication.TLSFiles) (*authentication.CertificateManager, error) {
+	/* This (unexported) function loads the certificate, key and CA described by the provided
+	files, and watches them so that rotated certificates are picked up without restarting
+	the aggregator. It returns a certificate manager for the files. */
+	manager, err := authentication.NewCertificateManager(name, files)
 	if err != nil {
 		return nil, err
 	}
 
-	// Load the server CA's certificates
-	certificatePool := x509.NewCertPool()
-	if !certificatePool.AppendCertsFromPEM(pemServerCA) {
-		return nil, fmt.Errorf("failed to add the server CA's certificate")
-	}
+	manager.ExpiryWarning = certificateExpiryWarning
+	manager.Watch(certificateReloadInterval)
 
-	// Load the client's certificate and private key
-	clientCertificate, err := tls.LoadX509KeyPair("certification/client-cert.pem", "certification/client-key.pem")
-	if err != nil {
-		return nil, err
+	return manager, nil
+}
+
+func newHealthChecker() (*interceptors.HealthChecker, error) {
+	/* This (unexported) function creates the aggregator's health checker. The aggregator is
+	ready while it can connect to the Python services (which don't serve the health service),
+	the authentication service is alive, its certificates are valid and its configuration
+	(the policy and JWT secret) can still be loaded. Each dependency is checked over a
+	connection of its own, without the interceptors of the calls the aggregator makes, so
+	that health checks aren't traced, counted or authenticated as calls */
+	checker := interceptors.NewHealthChecker(healthInterval, healthTimeout)
+
+	dependencies := []struct {
+		name    string
+		address string
+		serves  bool // Whether the service serves the health service, or can only be connected to
+	}{
+		{"fetchDataService", addrFS, false},
+		{"prepareDataService", addrPS, false},
+		{"estimateService", addrES, false},
+		{"authenticationService", addrAuthenticationService, true},
 	}
+	for _, dependency := range dependencies {
+		conn, err := grpc.Dial(dependency.address, grpc.WithTransportCredentials(loadClientTLSCredentials()))
+		if err != nil {
+			return nil, fmt.Errorf("could not create the health check connection to %v: %v", dependency.name, err)
+		}
+		connections.Keep(conn)
+		if dependency.serves {
+			checker.AddCheck(dependency.name, interceptors.ServingCheck(conn, interceptors.LivenessService))
+		} else {
+			checker.AddCheck(dependency.name, interceptors.ConnectionCheck(conn))
+		}
+	}
+
+	checker.AddCheck("certificates", func(ctx context.Context) error {
+		if err := serverCertificates.Valid(time.Now()); err != nil {
+			return err
+		}
+		return clientCertificates.Valid(time.Now())
+	})
+	checker.AddCheck("configuration", func(ctx context.Context) error {
+		if _, err := authentication.LoadPolicy(policyFile); err != nil {
+			return fmt.Errorf("the authorisation policy can't be loaded, the last valid one is in use: %v", err)
+		}
+		if jwtSecret.Value() == "" {
+			return fmt.Errorf("the JWT secret %v is empty", jwtSecret)
+		}
+		return nil
+	})
+
+	return checker, nil
+}
 
-	// Create and return the credentials object
-	config := &tls.Config{
-		Certificates: []tls.Certificate{clientCertificate},
-		RootCAs:      certificatePool,
+func loadClientTLSCredentials() credentials.TransportCredentials {
+	/* This (unexported) function returns the TLS credentials the aggregator uses when calling
+	other services. The services' certificates are verified against the CA and the aggregator
+	presents its (currently active) client certificate so that they can identify it. It
+	takes no inputs and returns a gRPC TransportCredentials object. */
+	return credentials.NewTLS(clientCertificates.ClientTLSConfig())
+}
+
+func clientInterceptorChains(accessToken string) (grpc.UnaryClientInterceptor, grpc.StreamClientInterceptor) {
+	/* This (unexported) function creates the interceptor chains (for unary and streaming calls)
+	for a connection to one of the services called, attaching the provided token to every request */
+
+	// Create the interceptors required for this connection
+	clientAuthInterceptor := &interceptors.ClientAuthStruct{ // Custom auth (JWT) interceptor
+		AccessToken: accessToken,
 	}
 
-	return credentials.NewTLS(config), nil
+	// Create interceptor chains with the above interceptors
+	interceptorChain := grpc_middleware.ChainUnaryClient(
+		interceptors.ClientRecoveryInterceptor,
+		interceptors.ClientTracingInterceptor,
+		interceptors.ClientRequestIDInterceptor,
+		interceptors.ClientLoggingInterceptor,
+		clientMetricInterceptor.ClientMetricInterceptor,
+		clientAuthInterceptor.ClientAuthInterceptor,
+		clientRetryInterceptor.ClientRetryInterceptor, // Last, so that every attempt is made with the token and the interceptors above see one call
+	)
+	streamInterceptorChain := grpc_middleware.ChainStreamClient(
+		interceptors.ClientRecoveryStreamInterceptor,
+		interceptors.ClientTracingStreamInterceptor,
+		interceptors.ClientRequestIDStreamInterceptor,
+		interceptors.ClientLoggingStreamInterceptor,
+		clientMetricInterceptor.ClientMetricStreamInterceptor,
+		clientAuthInterceptor.ClientAuthStreamInterceptor,
+	)
+
+	return interceptorChain, streamInterceptorChain
 }
 
-func createSecureServerConnection(port string, credentials credentials.TransportCredentials, timeout int, interceptor grpc.UnaryClientInterceptor) (*grpc.ClientConn, error) {
+func createSecureServerConnection(port string, credentials credentials.TransportCredentials, timeout time.Duration, interceptor grpc.UnaryClientInterceptor, streamInterceptor grpc.StreamClientInterceptor) (*grpc.ClientConn, error) {
 	/* This (unexported) function takes a port address, gRPC TransportCredentials object, timeout,
-	and UnaryClientInterceptor object as inputs. It creates a connection to the server
-	at the port adress and returns a secure gRPC connection with the specified
-	interceptor */
+	and UnaryClientInterceptor and StreamClientInterceptor objects as inputs. It creates a connection
+	to the server at the port adress and returns a secure gRPC connection with the specified
+	interceptors */
 
 	// Create the context for the request
-	ctx, cancel := context.WithTimeout(
-		context.Background(),
-		(time.Duration(timeoutDuration) * time.Second),
-	)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
 	conn, err := grpc.DialContext(
@@ -508,28 +818,26 @@ func createSecureServerConnection(port string, credentials credentials.Transport
 		grpc.WithBlock(), // Make the dial a blocking call so that we can ensure the connection is indeed created
 		grpc.WithTransportCredentials(credentials), // Add the TLS credentials
 		grpc.WithUnaryInterceptor(interceptor),     // Add the provided interceptors to the connection
+		grpc.WithStreamInterceptor(streamInterceptor),
 	)
 
 	// Handle errors, if any
 	if err != nil {
-		ErrorLogger.Println("Failed to create connection to the server on port: " + port)
+		logging.Logger.Errorln("Failed to create connection to the server on port: " + port)
 		return nil, err
 	}
 
-	InfoLogger.Println("Succesfully created connection to the server on port: " + port)
+	logging.Logger.Infoln("Succesfully created connection to the server on port: " + port)
 	return conn, nil
 }
 
-func createInsecureServerConnection(port string, timeout int, interceptor grpc.UnaryClientInterceptor) (*grpc.ClientConn, error) {
+func createInsecureServerConnection(port string, timeout time.Duration, interceptor grpc.UnaryClientInterceptor) (*grpc.ClientConn, error) {
 	/* This (unexported) function takes a port address, timeout, and UnaryClientInterceptor
 	object as inputs. It creates a connection to the server	at the port adress
 	and returns an insecure gRPC connection with the specified interceptor */
 
 	// Create the context for the request
-	ctx, cancel := context.WithTimeout(
-		context.Background(),
-		(time.Duration(timeoutDuration) * time.Second),
-	)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
 	conn, err := grpc.DialContext(
@@ -542,10 +850,10 @@ func createInsecureServerConnection(port string, timeout int, interceptor grpc.U
 
 	// Hamndle errors, if any
 	if err != nil {
-		ErrorLogger.Println("Failed to create connection to the server on port: " + port)
+		logging.Logger.Errorln("Failed to create connection to the server on port: " + port)
 		return nil, err
 	}
 
-	InfoLogger.Println("Succesfully created connection to the server on port: " + port)
+	logging.Logger.Infoln("Succesfully created connection to the server on port: " + port)
 	return conn, nil
 }
@@ -0,0 +1,39 @@
+import re
+import uuid
+import logging
+import contextvars
+from grpc_interceptor import ServerInterceptor
+
+# Request IDs shared with the Go services: the ID of a request arrives in the "x-request-id" metadata key (sent by the
+# aggregator, which received it from the gateway), is returned in the call's trailer, and is added to every log record
+# written while serving the call so that a request can be followed through every service with one ID
+
+REQUEST_ID_KEY = "x-request-id"
+
+# The ID of the call being served by the current thread, "-" outside of a call
+requestID = contextvars.ContextVar("requestID", default = "-")
+
+# IDs sent by callers end up in the log, so only short IDs made of letters, digits, '-', '_' and '.' are accepted
+validRequestID = re.compile(r"^[A-Za-z0-9._-]{1,64}$")
+
+class RequestIDFilter(logging.Filter):
+	# This filter adds the ID of the call being served to every log record, as "requestID", to be used in a handler's format
+
+	def filter(self, record):
+		record.requestID = requestID.get()
+		return True
+
+class RequestIDInterceptor(ServerInterceptor):
+	# This interceptor accepts the request ID the caller sent, or assigns a new one, for every call served. It should come
+	# right after the tracing interceptor, so that the other interceptors log with the ID
+
+	def intercept(self, method, request, context, methodName):
+		incoming = dict((key, value) for key, value in context.invocation_metadata() if isinstance(value, str)).get(REQUEST_ID_KEY, "")
+		callID = incoming if validRequestID.match(incoming) else uuid.uuid4().hex
+		context.set_trailing_metadata(((REQUEST_ID_KEY, callID),))
+
+		token = requestID.set(callID)
+		try:
+			return method(request, context)
+		finally:
+			requestID.reset(token)
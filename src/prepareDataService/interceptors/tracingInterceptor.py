@@ -0,0 +1,80 @@
+import os
+import logging
+from grpc_interceptor import ServerInterceptor
+from opentelemetry import propagate, trace
+from opentelemetry.sdk.resources import Resource
+from opentelemetry.sdk.trace import TracerProvider
+from opentelemetry.sdk.trace.export import BatchSpanProcessor, ConsoleSpanExporter
+from opentelemetry.sdk.trace.sampling import ParentBased, TraceIdRatioBased
+from opentelemetry.trace import SpanKind, Status, StatusCode
+
+# Tracing shared with the Go services: the W3C trace context ("traceparent" and "tracestate") of a call arrives in its
+# metadata, and the span of the call served is a child of the caller's span. Spans are exported in the same ways as the
+# Go services export theirs, over OTLP to a collector or appended to a file (one JSON object per line) for offline use
+
+# Logger setup
+logger = logging.getLogger(__file__.rsplit("/")[-3].rsplit(".")[0])
+logger.setLevel(logging.DEBUG)
+
+def startTracing(serviceName, config):
+	# This function sets up the tracer that the service's spans are recorded with, exporting them as described by the
+	# "tracing" section of the configuration file. It returns a function that exports the remaining spans and stops the tracer
+	exporter = config["exporter"]
+	if exporter == "none":
+		logger.info("Tracing is disabled, trace context is still passed on")
+		return lambda: None
+
+	if exporter == "otlp":
+		from opentelemetry.exporter.otlp.proto.grpc.trace_exporter import OTLPSpanExporter
+		address = f'{os.getenv("OTELCOLLECTORHOST", default = "localhost")}:{config["port"]}' # Receives the collector's hostname from the environmental variables (for Docker network), or defaults to localhost for local testing
+		spanExporter = OTLPSpanExporter(endpoint = address, insecure = config["insecure"])
+		logger.info(f"Exporting spans to the collector at {address}")
+	elif exporter == "file":
+		directory = os.path.dirname(config["file"])
+		if directory:
+			os.makedirs(directory, exist_ok = True)
+		spanFile = open(config["file"], "a")
+		spanExporter = ConsoleSpanExporter(out = spanFile, formatter = lambda span: span.to_json(indent = None) + os.linesep)
+		logger.info(f"Exporting spans to {config['file']}")
+	else:
+		raise ValueError(f"{exporter} is not a trace exporter, use otlp, file or none")
+
+	provider = TracerProvider(
+		resource = Resource.create({"service.name": serviceName}),
+		sampler = ParentBased(TraceIdRatioBased(config["sampleRatio"])) # Calls that are part of a trace follow the caller's decision
+	)
+	provider.add_span_processor(BatchSpanProcessor(spanExporter))
+	trace.set_tracer_provider(provider)
+
+	return provider.shutdown
+
+def startSpan(name):
+	# This function starts a span (to be used in a "with" statement) for a stage of the work done while serving a call, as a child of the call's span
+	return trace.get_tracer(__name__).start_as_current_span(name)
+
+class TracingInterceptor(ServerInterceptor):
+	# This interceptor starts a span for every call served, continuing the trace of the caller. It should be the first
+	# interceptor in the list, so that the time spent in the other interceptors is part of the span
+
+	def intercept(self, method, request, context, methodName):
+		carrier = {key: value for key, value in context.invocation_metadata() if isinstance(value, str)} # Binary metadata can't carry trace context
+		callerContext = propagate.extract(carrier)
+		serviceName, rpcMethod = methodName.rsplit("/")[1::]
+
+		with trace.get_tracer(__name__).start_as_current_span(
+			methodName.lstrip("/"), # Named in the same way as the Go services name their spans ("package.Service/Method")
+			context = callerContext,
+			kind = SpanKind.SERVER,
+			attributes = {"rpc.system": "grpc", "rpc.service": serviceName, "rpc.method": rpcMethod},
+			record_exception = False,
+			set_status_on_exception = False
+		) as span:
+			try:
+				result = method(request, context)
+			except Exception as error:
+				span.record_exception(error)
+				span.set_status(Status(StatusCode.ERROR, str(error)))
+				raise
+			span.set_attribute("rpc.grpc.status_code", 0)
+
+			return result
@@ -8,6 +8,8 @@ import proto.prepareDataAPI_pb2 as power_estimation_pb2
 import proto.prepareDataAPI_pb2_grpc as power_estimation_pb2_grpc
 import interceptors.metricInterceptor as metricInterceptor
 import interceptors.authenticationInterceptor as authenticationInterceptor
+import interceptors.tracingInterceptor as tracingInterceptor
+import interceptors.requestIDInterceptor as requestIDInterceptor
 import numpy as np
 import pandas as pd
 from sklearn.preprocessing import MinMaxScaler
@@ -111,7 +113,8 @@ class PrepareDataServicer(power_estimation_pb2_grpc.PrepareDataServicer):
 					'Wave length': request.wave_length}
 		
 		# Process data
-		outputData = processData(pd.DataFrame(inputData))
+		with tracingInterceptor.startSpan("Process data"):
+			outputData = processData(pd.DataFrame(inputData))
 		logger.debug("Successfully processed data")
 
 		# Populate the reponse message fields
@@ -134,8 +137,8 @@ def loadTLSCredentials():
 	# a server credentials object with the key and certificate, and  returns 
 	# that object for use in the server connection
 	
-	serverKeyFile = "certification/server-key.pem"
-	serverCertFile = "certification/server-cert.pem"
+	serverKeyFile = "certification/preparedataservice/server-key.pem"
+	serverCertFile = "certification/preparedataservice/server-cert.pem"
 	caCertFile = "certification/ca-cert.pem"
 
 	# Load the server's certificate and private key
@@ -158,7 +161,10 @@ def serve():
 	# This function creates a server with specified interceptors, registers the service calls offered by that server, and exposes
 	# the server over a specified port. The connection to this port is secured with server-side TLS encryption.
 
-	activeInterceptors = [metricInterceptor.MetricInterceptor(), authenticationInterceptor.AuthenticationInterceptor("secret", 15, {"/prepareData.PrepareData/PrepareEstimateDataService": ["admin"]})] # List containing the interceptors to be chained
+	# Export the spans of the calls served, as part of the traces started by the Go services
+	stopTracing = tracingInterceptor.startTracing("PrepareDataService", config["tracing"])
+
+	activeInterceptors = [tracingInterceptor.TracingInterceptor(), requestIDInterceptor.RequestIDInterceptor(), metricInterceptor.MetricInterceptor(), authenticationInterceptor.AuthenticationInterceptor(config["authentication"]["jwt"]["secretKey"], config["authentication"]["jwt"]["tokenDuration"], config["authentication"]["policy"]["file"], config["authentication"]["jwt"]["audience"])] # List containing the interceptors to be chained
 
 	# Create a server to serve calls
 	server = grpc.server(
@@ -180,6 +186,7 @@ def serve():
 
 	# Defer termination for a 'persistent' service
 	server.wait_for_termination()
+	stopTracing()
 
 if __name__ == '__main__':
 		
@@ -192,11 +199,12 @@ if __name__ == '__main__':
 	logger.setLevel(logging.DEBUG)
 
 	# Set the fields to be included in the logs
-	formatter = logging.Formatter('%(asctime)s:%(name)s:%(levelname)s:%(module)s:%(funcName)s:%(message)s')
+	formatter = logging.Formatter('%(asctime)s:%(name)s:%(levelname)s:%(module)s:%(funcName)s:%(requestID)s:%(message)s')
 
 	# Create/set the file in which the log will be stored
 	fileHandler = logging.FileHandler("program logs/" + serviceName + ".log")
 	fileHandler.setFormatter(formatter)
+	fileHandler.addFilter(requestIDInterceptor.RequestIDFilter()) # Adds the ID of the request being served to each record
 
 	logger.addHandler(fileHandler)
 